@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+var gcDryRun bool
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Remove unreferenced data from the index and reclaim space",
+	Long: `Garbage-collect the project index:
+- delete chunks (and their vectors) that no document references
+- delete documents and chunks of files that were removed or are now ignored
+- compact the storage (rewrite the gob file and HNSW graph, VACUUM SQLite
+  and PostgreSQL)
+
+Embeddings shared between worktrees through the content-hash cache are kept
+as long as an indexed file still contains them. Stop the watcher before
+collecting, or set watch.gc_interval_sec to let it collect periodically.
+
+Examples:
+  grepai gc
+  grepai gc --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "Report what would be removed without modifying the index")
+	rootCmd.AddCommand(gcCmd)
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	if status := resolveWatcherRuntimeStatus(projectRoot); status.running {
+		return fmt.Errorf("watcher is running (PID %d); stop it with 'grepai watch --stop' or set watch.gc_interval_sec to collect from the watcher", status.pid)
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Ignore, cfg.ExternalGitignore)
+	if err != nil {
+		return fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+	fileExists, err := indexableFiles(newScanner(projectRoot, ignoreMatcher, cfg.Index))
+	if err != nil {
+		return err
+	}
+
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	result, err := store.GarbageCollect(ctx, st, store.GCOptions{
+		FileExists: fileExists,
+		Compact:    true,
+		DryRun:     gcDryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("garbage collection failed: %w", err)
+	}
+
+	if gcDryRun {
+		fmt.Println("Dry run, the index was not modified:")
+	} else {
+		fmt.Println("Garbage collection complete:")
+	}
+	fmt.Printf("  Chunks removed:    %d\n", result.ChunksRemoved)
+	fmt.Printf("  Documents removed: %d\n", result.DocumentsRemoved)
+	if result.SizeBefore > 0 && !gcDryRun {
+		fmt.Printf("  Index size:        %s -> %s\n", formatBytes(result.SizeBefore), formatBytes(result.SizeAfter))
+	}
+	fmt.Printf("  Space reclaimed:   %s\n", formatReclaimed(result))
+	return nil
+}
+
+// indexableFiles returns a predicate reporting whether a path is still an
+// indexable file of the project, as seen by scanner.
+func indexableFiles(scanner *indexer.Scanner) (func(path string) bool, error) {
+	metas, _, err := scanner.ScanMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan files: %w", err)
+	}
+	files := make(map[string]bool, len(metas))
+	for _, meta := range metas {
+		files[meta.Path] = true
+	}
+	return func(path string) bool { return files[path] }, nil
+}
+
+// formatReclaimed formats the space freed by a GC run, marking estimates for
+// stores that do not report their on-disk size.
+func formatReclaimed(result *store.GCResult) string {
+	reclaimed := result.Reclaimed()
+	if reclaimed == 0 {
+		return "0 B"
+	}
+	if result.SizeBefore > 0 && result.SizeAfter > 0 {
+		return formatBytes(reclaimed)
+	}
+	return "~" + formatBytes(reclaimed) + " (estimated)"
+}
+
+// runWatchGC garbage-collects the index from the watch loop, which owns all
+// writes to st while it runs.
+func runWatchGC(ctx context.Context, st store.VectorStore, scanner *indexer.Scanner, projectRoot string) {
+	fileExists, err := indexableFiles(scanner)
+	if err != nil {
+		log.Printf("Warning: garbage collection skipped for %s: %v", projectRoot, err)
+		return
+	}
+	collectFromWatcher(ctx, st, fileExists, projectRoot)
+}
+
+// runWorkspaceWatchGC garbage-collects the shared index of a workspace from
+// its watch loop. Files of projects without a runtime are left alone.
+func runWorkspaceWatchGC(ctx context.Context, st store.VectorStore, ws *config.Workspace, runtimes map[string]*workspaceProjectRuntime) {
+	fileExists, err := workspaceIndexableFiles(ws.Name, runtimes)
+	if err != nil {
+		log.Printf("Warning: garbage collection skipped for workspace %s: %v", ws.Name, err)
+		return
+	}
+	collectFromWatcher(ctx, st, fileExists, "workspace "+ws.Name)
+}
+
+// workspaceIndexableFiles returns a predicate reporting whether a path of
+// the shared index, prefixed with workspace and project name, is still an
+// indexable file of its project. Paths outside the projects of runtimes are
+// reported as existing, so a project that failed to start keeps its index.
+func workspaceIndexableFiles(workspaceName string, runtimes map[string]*workspaceProjectRuntime) (func(path string) bool, error) {
+	prefixes := make(map[string]func(path string) bool, len(runtimes))
+	for _, runtime := range runtimes {
+		fileExists, err := indexableFiles(runtime.scanner)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", runtime.project.Name, err)
+		}
+		prefixes[workspaceName+"/"+runtime.project.Name+"/"] = fileExists
+	}
+	return func(path string) bool {
+		for prefix, fileExists := range prefixes {
+			if rel, ok := strings.CutPrefix(path, prefix); ok {
+				return fileExists(rel)
+			}
+		}
+		return true
+	}, nil
+}
+
+// workspaceGCInterval returns the shortest watch.gc_interval_sec set by the
+// projects of a workspace, or zero when none enables periodic collection.
+func workspaceGCInterval(runtimes map[string]*workspaceProjectRuntime) time.Duration {
+	var interval time.Duration
+	for _, runtime := range runtimes {
+		sec := runtime.cfg.Watch.GCIntervalSec
+		if sec > 0 && (interval == 0 || time.Duration(sec)*time.Second < interval) {
+			interval = time.Duration(sec) * time.Second
+		}
+	}
+	return interval
+}
+
+// collectFromWatcher runs a garbage collection for a watcher, which owns all
+// writes to st while it runs. The store is compacted only when chunks were
+// removed, since compaction rewrites the whole index.
+func collectFromWatcher(ctx context.Context, st store.VectorStore, fileExists func(path string) bool, label string) {
+	result, err := store.GarbageCollect(ctx, st, store.GCOptions{
+		FileExists:         fileExists,
+		Compact:            true,
+		CompactIfCollected: true,
+	})
+	if err != nil {
+		log.Printf("Warning: garbage collection failed for %s: %v", label, err)
+		return
+	}
+	if result.ChunksRemoved > 0 || result.DocumentsRemoved > 0 {
+		log.Printf("Garbage collection for %s: removed %d chunks and %d documents, reclaimed %s",
+			label, result.ChunksRemoved, result.DocumentsRemoved, formatReclaimed(result))
+	}
+}
@@ -0,0 +1,777 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/rpg"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+var (
+	indexVerifyJSON  bool
+	indexImportForce bool
+	indexExplainJSON bool
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index <subcommand>",
+	Short: "Inspect and maintain the index",
+	Long: `Maintenance commands for the project index.
+
+Examples:
+  grepai index verify
+  grepai index repair
+  grepai index export grepai-index.tar.gz
+  grepai index import grepai-index.tar.gz
+  grepai index explain src/app.ts`,
+}
+
+var indexVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the index for inconsistencies",
+	Long: `Cross-check the index against the files on disk and report inconsistencies:
+- documents referencing chunks that no longer exist
+- chunks without a document, or not listed by their document
+- files whose content changed or disappeared since they were indexed
+- indexable files missing from the index
+- symbol index (symbols.gob) and RPG graph (rpg.gob) entries for changed or deleted files
+
+The index is not modified. Exits with an error when issues are found.
+
+Examples:
+  grepai index verify
+  grepai index verify --json`,
+	Args: cobra.NoArgs,
+	RunE: runIndexVerify,
+}
+
+var indexRepairCmd = &cobra.Command{
+	Use:   "repair",
+	Short: "Fix inconsistencies found by 'grepai index verify'",
+	Long: `Repair the index by deleting orphaned chunks and entries for deleted files,
+and by dropping stale or partially stored files so they are re-indexed.
+
+Files are re-embedded on the next 'grepai watch' run. Stop the watcher
+before repairing.
+
+Examples:
+  grepai index repair`,
+	Args: cobra.NoArgs,
+	RunE: runIndexRepair,
+}
+
+var indexExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export the index to a portable snapshot archive",
+	Long: `Pack the vector index (vectors included), the symbol index and the RPG graph
+into a single compressed, versioned archive. Paths are stored relative to the
+project root, so the snapshot can be imported in any checkout of the project.
+
+Build the index once (for example in CI on the main branch) and let other
+machines import it instead of re-embedding the whole project.
+
+Examples:
+  grepai index export grepai-index.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIndexExport,
+}
+
+var indexImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import an index snapshot created by 'grepai index export'",
+	Long: `Replace the local index with a snapshot created by 'grepai index export'.
+
+Only files whose content matches the snapshot are imported. Files that differ
+locally, or are missing from the snapshot, are re-indexed by the next
+'grepai watch' run. The snapshot must have been built with the same embedder
+as the local configuration.
+
+Examples:
+  grepai index import grepai-index.tar.gz
+  grepai index import grepai-index.tar.gz --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIndexImport,
+}
+
+var indexExplainCmd = &cobra.Command{
+	Use:   "explain <path>",
+	Short: "Explain why a file is or isn't indexed",
+	Long: `Run the indexing checks on one file and print each decision:
+- ignore rules (.gitignore, .grepaiignore, external_gitignore and the ignore
+  list in config.yaml), with the pattern that matched and where it comes from
+- file type (extension lists, index.filenames, shebang detection)
+- minified file names, size limit and binary detection
+
+For indexed files, also prints the chunk count, the symbols found by the
+trace extractor and the file's place in the RPG graph.
+
+Examples:
+  grepai index explain src/app.ts
+  grepai index explain scripts/deploy --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIndexExplain,
+}
+
+func init() {
+	indexVerifyCmd.Flags().BoolVar(&indexVerifyJSON, "json", false, "Output the report in JSON format")
+	indexImportCmd.Flags().BoolVar(&indexImportForce, "force", false, "Replace an existing local index")
+	indexExplainCmd.Flags().BoolVar(&indexExplainJSON, "json", false, "Output the explanation in JSON format")
+
+	indexCmd.AddCommand(indexVerifyCmd)
+	indexCmd.AddCommand(indexRepairCmd)
+	indexCmd.AddCommand(indexExportCmd)
+	indexCmd.AddCommand(indexImportCmd)
+	indexCmd.AddCommand(indexExplainCmd)
+	rootCmd.AddCommand(indexCmd)
+}
+
+// openIntegrityChecker opens every index of the project. The returned
+// cleanup closes the vector store; the symbol and RPG stores are only
+// written when the caller persists them.
+func openIntegrityChecker(ctx context.Context, projectRoot string) (*indexer.IntegrityChecker, func(), error) {
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	checker, err := newIntegrityChecker(ctx, cfg, projectRoot, st)
+	if err != nil {
+		_ = st.Close()
+		return nil, nil, err
+	}
+	return checker, func() {
+		_ = st.Close()
+		if checker.RPG != nil {
+			rpg.Discard(checker.RPG)
+		}
+	}, nil
+}
+
+// rpgIndexExists reports whether the project has an RPG graph to check. A
+// database-backed graph is assumed to exist; an empty one checks trivially.
+func rpgIndexExists(cfg *config.Config, projectRoot string) bool {
+	if cfg.RPG.Backend == "postgres" {
+		return true
+	}
+	_, err := os.Stat(config.GetRPGIndexPath(projectRoot))
+	return err == nil
+}
+
+// newIntegrityChecker builds a checker over st and the project's symbol
+// index and RPG graph, when they exist.
+func newIntegrityChecker(ctx context.Context, cfg *config.Config, projectRoot string, st store.VectorStore) (*indexer.IntegrityChecker, error) {
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Ignore, cfg.ExternalGitignore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+
+	checker := &indexer.IntegrityChecker{
+		Store:   st,
+		Scanner: newScanner(projectRoot, ignoreMatcher, cfg.Index),
+	}
+
+	symbolPath := config.GetSymbolIndexPath(projectRoot)
+	if _, err := os.Stat(symbolPath); err == nil {
+		symbolStore := trace.NewGOBSymbolStore(symbolPath)
+		if err := symbolStore.Load(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load symbol index: %w", err)
+		}
+		checker.Symbols = symbolStore
+	}
+
+	if cfg.RPG.Enabled && rpgIndexExists(cfg, projectRoot) {
+		rpgStore, err := rpg.NewStoreFromConfig(ctx, cfg, projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open RPG store: %w", err)
+		}
+		if err := rpgStore.Load(ctx); err != nil {
+			rpg.Discard(rpgStore)
+			return nil, fmt.Errorf("failed to load RPG graph: %w", err)
+		}
+		checker.RPG = rpgStore
+	}
+
+	return checker, nil
+}
+
+func runIndexVerify(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	checker, cleanup, err := openIntegrityChecker(ctx, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	report, err := checker.Verify(ctx)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+
+	if indexVerifyJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printIntegrityReport(report)
+	}
+
+	if !report.OK() {
+		return fmt.Errorf("index has %d issue(s); run 'grepai index repair' to fix them", len(report.Issues))
+	}
+	return nil
+}
+
+func runIndexRepair(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	if status := resolveWatcherRuntimeStatus(projectRoot); status.running {
+		return fmt.Errorf("watcher is running (PID %d); stop it with 'grepai watch --stop' before repairing", status.pid)
+	}
+
+	checker, cleanup, err := openIntegrityChecker(ctx, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	report, err := checker.Verify(ctx)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	printIntegrityReport(report)
+	if report.OK() {
+		return nil
+	}
+
+	stats, err := checker.Repair(ctx, report)
+	if err != nil {
+		return fmt.Errorf("repair failed: %w", err)
+	}
+
+	if err := checker.Store.Persist(ctx); err != nil {
+		return fmt.Errorf("failed to persist index: %w", err)
+	}
+	if checker.Symbols != nil {
+		if err := checker.Symbols.Persist(ctx); err != nil {
+			return fmt.Errorf("failed to persist symbol index: %w", err)
+		}
+	}
+	if checker.RPG != nil {
+		if err := checker.RPG.Persist(ctx); err != nil {
+			return fmt.Errorf("failed to persist RPG graph: %w", err)
+		}
+	}
+	if stats.FilesRequeued > 0 {
+		if err := forgetGitIndexState(projectRoot); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\nRepair complete:")
+	fmt.Printf("  Orphaned chunks deleted:  %d\n", stats.OrphanChunksDeleted)
+	fmt.Printf("  Deleted files removed:    %d\n", stats.DocumentsRemoved)
+	fmt.Printf("  Files queued for reindex: %d\n", stats.FilesRequeued)
+	fmt.Printf("  Symbol entries removed:   %d\n", stats.SymbolFilesRemoved)
+	fmt.Printf("  RPG files removed:        %d\n", stats.RPGFilesRemoved)
+
+	if needsIndexing(report) {
+		fmt.Println("\nRun 'grepai watch' to re-index queued and unindexed files.")
+	}
+	return nil
+}
+
+// needsIndexing reports whether any issue is resolved by the next indexing run.
+func needsIndexing(report *indexer.IntegrityReport) bool {
+	for _, issue := range report.Issues {
+		switch issue.Kind {
+		case indexer.IssueMissingChunks, indexer.IssueUnreferencedChunks, indexer.IssueStaleFile,
+			indexer.IssueUnindexedFile, indexer.IssueStaleSymbols:
+			return true
+		}
+	}
+	return false
+}
+
+func printIntegrityReport(report *indexer.IntegrityReport) {
+	fmt.Printf("Checked %d documents, %d chunks and %d files on disk", report.Documents, report.Chunks, report.Files)
+	if report.SymbolFiles > 0 {
+		fmt.Printf(", %d symbol files", report.SymbolFiles)
+	}
+	if report.RPGFiles > 0 {
+		fmt.Printf(", %d RPG files", report.RPGFiles)
+	}
+	fmt.Println()
+
+	if report.OK() {
+		fmt.Println("No issues found.")
+		return
+	}
+
+	fmt.Printf("\nFound %d issue(s):\n", len(report.Issues))
+	for _, issue := range report.Issues {
+		fmt.Printf("  [%s] %s: %s\n", issue.Kind, issue.Path, issue.Detail)
+	}
+}
+
+// snapshotExtras maps snapshot archive names to the project's index files
+// that are packed verbatim next to the vector store.
+func snapshotExtras(projectRoot string) map[string]string {
+	return map[string]string{
+		config.SymbolIndexFileName: config.GetSymbolIndexPath(projectRoot),
+		config.RPGIndexFileName:    config.GetRPGIndexPath(projectRoot),
+	}
+}
+
+// usesPostgresRPG reports whether the project's RPG graph lives in
+// PostgreSQL. Snapshots carry it as an rpg.gob extra, converted through a
+// temporary file on export and import.
+func usesPostgresRPG(cfg *config.Config) bool {
+	return cfg.RPG.Enabled && cfg.RPG.Backend == "postgres"
+}
+
+// exportPostgresRPG writes the project's PostgreSQL RPG graph to a GOB file
+// at gobPath.
+func exportPostgresRPG(ctx context.Context, cfg *config.Config, projectRoot, gobPath string) error {
+	pg, err := rpg.NewStoreFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open RPG store: %w", err)
+	}
+	defer pg.Close()
+	if err := pg.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load RPG graph: %w", err)
+	}
+	return rpg.CopyGraph(ctx, rpg.NewGOBRPGStore(gobPath), pg)
+}
+
+// importPostgresRPG replaces the project's PostgreSQL RPG graph with the one
+// in the GOB file at gobPath.
+func importPostgresRPG(ctx context.Context, cfg *config.Config, projectRoot, gobPath string) error {
+	src := rpg.NewGOBRPGStore(gobPath)
+	if err := src.Load(ctx); err != nil {
+		return fmt.Errorf("failed to read RPG graph: %w", err)
+	}
+	pg, err := rpg.NewStoreFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open RPG store: %w", err)
+	}
+	defer pg.Close()
+	if err := pg.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load RPG graph: %w", err)
+	}
+	return rpg.CopyGraph(ctx, pg, src)
+}
+
+func runIndexExport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	outPath := args[0]
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	active := store.NewManifest(cfg.Embedder, effectiveChunking(cfg.Chunking), version)
+	manifest, err := store.CheckManifest(ctx, st, active)
+	if err != nil {
+		return err
+	}
+	if manifest == nil {
+		manifest = &active
+	}
+
+	extras := snapshotExtras(projectRoot)
+	if usesPostgresRPG(cfg) {
+		tmpDir, err := os.MkdirTemp("", "grepai-export-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		rpgPath := filepath.Join(tmpDir, config.RPGIndexFileName)
+		if err := exportPostgresRPG(ctx, cfg, projectRoot, rpgPath); err != nil {
+			return fmt.Errorf("failed to export RPG graph: %w", err)
+		}
+		extras[config.RPGIndexFileName] = rpgPath
+	}
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", outPath, err)
+	}
+
+	header, err := store.ExportSnapshot(ctx, out, st, store.SnapshotExportOptions{
+		ProjectRoot: projectRoot,
+		Version:     version,
+		Manifest:    manifest,
+		Extras:      extras,
+	})
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", outPath, closeErr)
+	}
+	if err != nil {
+		_ = os.Remove(outPath)
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	fmt.Printf("Exported %d documents and %d chunks (%s) to %s\n",
+		header.Documents, header.Chunks, manifest.EmbedderString(), outPath)
+	if len(header.Extras) > 0 {
+		fmt.Printf("Included: %s\n", strings.Join(header.Extras, ", "))
+	}
+	return nil
+}
+
+func runIndexImport(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	inPath := args[0]
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	if status := resolveWatcherRuntimeStatus(projectRoot); status.running {
+		return fmt.Errorf("watcher is running (PID %d); stop it with 'grepai watch --stop' before importing", status.pid)
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	in, err := os.Open(inPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", inPath, err)
+	}
+	defer in.Close()
+
+	header, err := store.ReadSnapshotHeader(in)
+	if err != nil {
+		return err
+	}
+	active := store.NewManifest(cfg.Embedder, effectiveChunking(cfg.Chunking), version)
+	if header.Manifest != nil {
+		if !header.Manifest.EmbedderMatches(active) {
+			return fmt.Errorf("snapshot was built with embedder %s but the configuration uses %s",
+				header.Manifest.EmbedderString(), active.EmbedderString())
+		}
+		if !header.Manifest.ChunkingMatches(active) {
+			fmt.Printf("Warning: snapshot uses chunking %s, configuration uses %s\n",
+				header.Manifest.ChunkingString(), active.ChunkingString())
+		}
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to rewind %s: %w", inPath, err)
+	}
+
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	existing, err := st.GetStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to inspect local index: %w", err)
+	}
+	if existing.TotalChunks > 0 {
+		if !indexImportForce {
+			return fmt.Errorf("local index already contains %d chunks; use --force to replace it", existing.TotalChunks)
+		}
+		fmt.Printf("Clearing %d existing chunks...\n", existing.TotalChunks)
+		if err := store.ClearStore(ctx, st); err != nil {
+			return fmt.Errorf("failed to clear local index: %w", err)
+		}
+	}
+
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Ignore, cfg.ExternalGitignore)
+	if err != nil {
+		return fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+	scanner := newScanner(projectRoot, ignoreMatcher, cfg.Index)
+
+	extras := snapshotExtras(projectRoot)
+	var rpgPath string
+	if usesPostgresRPG(cfg) {
+		tmpDir, err := os.MkdirTemp("", "grepai-import-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		rpgPath = filepath.Join(tmpDir, config.RPGIndexFileName)
+		extras[config.RPGIndexFileName] = rpgPath
+	}
+
+	result, err := store.ImportSnapshot(ctx, in, st, store.SnapshotImportOptions{
+		KeepDocument: func(doc store.Document) bool {
+			file, err := scanner.ScanFile(doc.Path)
+			return err == nil && file != nil && file.Hash == doc.Hash
+		},
+		Extras: extras,
+	})
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	if rpgPath != "" && slices.Contains(result.Extras, config.RPGIndexFileName) {
+		if err := importPostgresRPG(ctx, cfg, projectRoot, rpgPath); err != nil {
+			return fmt.Errorf("failed to import RPG graph: %w", err)
+		}
+	}
+
+	// Every file is hash-checked on the next watch run, not only recently
+	// modified ones, so local changes are picked up.
+	cfg.Watch.LastIndexTime = time.Time{}
+	cfg.Watch.LastIndexGit = nil
+	if err := cfg.Save(projectRoot); err != nil {
+		return fmt.Errorf("failed to update configuration: %w", err)
+	}
+
+	// Drop symbol and RPG entries for files that differ from the snapshot.
+	checker, err := newIntegrityChecker(ctx, cfg, projectRoot, st)
+	if err != nil {
+		return err
+	}
+	report, err := checker.Verify(ctx)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	if _, err := checker.Repair(ctx, report); err != nil {
+		return fmt.Errorf("failed to clean up imported index: %w", err)
+	}
+	if checker.Symbols != nil {
+		if err := checker.Symbols.Persist(ctx); err != nil {
+			return fmt.Errorf("failed to persist symbol index: %w", err)
+		}
+	}
+	if checker.RPG != nil {
+		if err := checker.RPG.Persist(ctx); err != nil {
+			return fmt.Errorf("failed to persist RPG graph: %w", err)
+		}
+	}
+
+	pending := 0
+	for _, issue := range report.Issues {
+		if issue.Kind == indexer.IssueUnindexedFile {
+			pending++
+		}
+	}
+
+	fmt.Printf("Imported %d documents and %d chunks from %s", result.Documents, result.Chunks, inPath)
+	if result.Header.Version != "" {
+		fmt.Printf(" (exported by grepai %s)", result.Header.Version)
+	}
+	fmt.Println()
+	if result.SkippedDocuments > 0 {
+		fmt.Printf("Skipped %d files that differ locally or no longer exist.\n", result.SkippedDocuments)
+	}
+	if pending > 0 {
+		fmt.Printf("Run 'grepai watch' to index the %d remaining files.\n", pending)
+	}
+	return nil
+}
+
+// fileIndexExplanation is the output of 'grepai index explain'.
+type fileIndexExplanation struct {
+	*indexer.FileExplanation
+	Indexed     bool     `json:"indexed"`
+	Stale       bool     `json:"stale,omitempty"`
+	Chunks      int      `json:"chunks"`
+	Symbols     []string `json:"symbols,omitempty"`
+	FeaturePath string   `json:"feature_path,omitempty"`
+}
+
+func runIndexExplain(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+	relPath, err := projectRelPath(projectRoot, args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Ignore, cfg.ExternalGitignore)
+	if err != nil {
+		return fmt.Errorf("failed to initialize ignore matcher: %w", err)
+	}
+	explanation, err := newScanner(projectRoot, ignoreMatcher, cfg.Index).Explain(relPath)
+	if err != nil {
+		return err
+	}
+
+	result := fileIndexExplanation{FileExplanation: explanation}
+	if err := describeIndexedFile(ctx, cfg, projectRoot, &result); err != nil {
+		return err
+	}
+
+	if indexExplainJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	printFileExplanation(&result)
+	return nil
+}
+
+// projectRelPath resolves path, absolute or relative to the working
+// directory, to a path relative to the project root.
+func projectRelPath(projectRoot, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	relPath, err := filepath.Rel(projectRoot, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the project root %s", path, projectRoot)
+	}
+	return relPath, nil
+}
+
+// describeIndexedFile fills in what the indexes hold for the file: its
+// chunks, symbols and RPG feature path.
+func describeIndexedFile(ctx context.Context, cfg *config.Config, projectRoot string, result *fileIndexExplanation) error {
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	path := result.Path
+	doc, err := st.GetDocument(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to get document %s: %w", path, err)
+	}
+	if doc == nil {
+		return nil
+	}
+	result.Indexed = true
+	result.Chunks = len(doc.ChunkIDs)
+	if hash, err := indexer.HashFile(filepath.Join(projectRoot, path)); err == nil && hash != doc.Hash {
+		result.Stale = true
+	}
+
+	symbolStore, err := trace.NewProjectSymbolStore(ctx, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open symbol index: %w", err)
+	}
+	if symbolStore != nil {
+		// The index is only read.
+		defer trace.Discard(symbolStore)
+		if err := symbolStore.Load(ctx); err != nil {
+			return fmt.Errorf("failed to load symbol index: %w", err)
+		}
+		symbols, err := symbolStore.GetSymbolsForFile(ctx, path)
+		if err != nil {
+			return fmt.Errorf("failed to get symbols: %w", err)
+		}
+		for _, sym := range symbols {
+			name := sym.Name
+			if sym.Receiver != "" {
+				name = sym.Receiver + "." + sym.Name
+			}
+			result.Symbols = append(result.Symbols, fmt.Sprintf("%s (%s, line %d)", name, sym.Kind, sym.Line))
+		}
+	}
+
+	if cfg.RPG.Enabled && rpgIndexExists(cfg, projectRoot) {
+		rpgStore, err := rpg.NewStoreFromConfig(ctx, cfg, projectRoot)
+		if err != nil {
+			return fmt.Errorf("failed to open RPG store: %w", err)
+		}
+		// The graph is only read.
+		defer rpg.Discard(rpgStore)
+		if err := rpg.LoadFiles(ctx, rpgStore, []string{path}); err != nil {
+			return fmt.Errorf("failed to load RPG graph: %w", err)
+		}
+		qe := rpg.NewQueryEngine(rpgStore.GetGraph())
+		node, err := qe.FetchNode(ctx, rpg.FetchNodeRequest{NodeID: rpg.MakeNodeID(rpg.KindFile, path)})
+		if err == nil && node != nil {
+			result.FeaturePath = node.FeaturePath
+		}
+	}
+	return nil
+}
+
+func printFileExplanation(result *fileIndexExplanation) {
+	verdict := "indexable"
+	if !result.Indexable {
+		verdict = "not indexed (" + string(result.Reason) + ")"
+	}
+	fmt.Printf("%s: %s\n\n", result.Path, verdict)
+
+	for _, check := range result.Checks {
+		mark := "ok  "
+		if !check.Passed {
+			mark = "SKIP"
+		}
+		fmt.Printf("  [%s] %-13s %s\n", mark, check.Name, check.Detail)
+	}
+
+	fmt.Println()
+	switch {
+	case !result.Indexed && result.Indexable:
+		fmt.Println("Not in the index yet; run 'grepai watch' to index it.")
+		return
+	case !result.Indexed:
+		return
+	case !result.Indexable:
+		fmt.Println("Still in the index; it will be removed on the next 'grepai watch' scan.")
+	case result.Stale:
+		fmt.Println("In the index, but the file changed since; it will be re-indexed on the next 'grepai watch' scan.")
+	}
+
+	fmt.Printf("Chunks:  %d\n", result.Chunks)
+	if len(result.Symbols) > 0 {
+		fmt.Printf("Symbols: %s\n", strings.Join(result.Symbols, ", "))
+	}
+	if result.FeaturePath != "" {
+		fmt.Printf("RPG:     %s\n", result.FeaturePath)
+	}
+}
@@ -33,7 +33,7 @@ var initCmd = &cobra.Command{
 This command will:
 - Create .grepai/config.yaml with default settings
 - Prompt for embedding provider (Ollama or OpenAI)
-- Prompt for storage backend (GOB file or PostgreSQL)
+- Prompt for storage backend (GOB file, SQLite, PostgreSQL or Qdrant)
 - Add .grepai/ to .gitignore if present`,
 	RunE: runInit,
 }
@@ -41,7 +41,7 @@ This command will:
 func init() {
 	initCmd.Flags().StringVarP(&initProvider, "provider", "p", "", "Embedding provider (ollama, lmstudio, openai, synthetic, or openrouter)")
 	initCmd.Flags().StringVarP(&initModel, "model", "m", "", "Embedding model (for openrouter: text-embedding-3-small, text-embedding-3-large, qwen3-embedding-8b)")
-	initCmd.Flags().StringVarP(&initBackend, "backend", "b", "", "Storage backend (gob, postgres, or qdrant)")
+	initCmd.Flags().StringVarP(&initBackend, "backend", "b", "", "Storage backend (gob, sqlite, postgres, or qdrant)")
 	initCmd.Flags().BoolVar(&initNonInteractive, "yes", false, "Use defaults without prompting")
 	initCmd.Flags().BoolVar(&initInherit, "inherit", false, "Inherit configuration from main worktree (for git worktrees)")
 	initCmd.Flags().BoolVar(&initUI, "ui", false, "Run interactive Bubble Tea UI wizard")
@@ -90,8 +90,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 				cfg = mainCfg
 				skipPrompts = true
 
-				if cfg.Store.Backend == "gob" {
-					fmt.Println("\nNote: GOB backend creates an independent index per worktree.")
+				if cfg.Store.Backend == "gob" || cfg.Store.Backend == "sqlite" {
+					fmt.Printf("\nNote: %s backend creates an independent index per worktree.\n", strings.ToUpper(cfg.Store.Backend))
 					fmt.Println("For shared indexing across worktrees, consider using 'postgres' or 'qdrant' backend.")
 				} else {
 					fmt.Printf("\nUsing %s backend - each worktree maintains its own project scope within the shared store.\n", cfg.Store.Backend)
@@ -215,6 +215,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 			fmt.Println("  1) gob (local file, recommended for most projects)")
 			fmt.Println("  2) postgres (pgvector, for large monorepos or shared index)")
 			fmt.Println("  3) qdrant (Docker-based vector database)")
+			fmt.Println("  4) sqlite (local database file, incremental writes for large repos)")
 			fmt.Print("Choice [1]: ")
 
 			input, _ := reader.ReadString('\n')
@@ -262,6 +263,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 				fmt.Print("API key (optional, for Qdrant Cloud): ")
 				apiKey, _ := reader.ReadString('\n')
 				cfg.Store.Qdrant.APIKey = strings.TrimSpace(apiKey)
+			case "4", "sqlite":
+				cfg.Store.Backend = "sqlite"
 			default:
 				cfg.Store.Backend = "gob"
 			}
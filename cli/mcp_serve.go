@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -167,5 +168,9 @@ func runMCPServe(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
 
+	if err := srv.CheckIndexManifest(context.Background()); err != nil {
+		return err
+	}
+
 	return srv.Serve()
 }
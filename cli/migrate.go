@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+var (
+	migrateTo               string
+	migrateDSN              string
+	migrateQdrantEndpoint   string
+	migrateQdrantPort       int
+	migrateQdrantCollection string
+	migrateQdrantAPIKey     string
+	migrateQdrantTLS        bool
+	migrateForce            bool
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move the index to another storage backend",
+	Long: `Copy the current index, vectors included, into another storage backend
+without re-embedding.
+
+Examples:
+  grepai migrate --to postgres --dsn postgres://localhost:5432/grepai
+  grepai migrate --to qdrant --qdrant-endpoint localhost --qdrant-port 6334
+  grepai migrate --to gob
+
+The command will:
+- Copy every chunk and document from the current backend into the target
+- Verify that the target holds the same number of chunks and documents
+- Update .grepai/config.yaml to use the target backend (only on success)
+
+Stop the watcher before migrating. The source index is left untouched.`,
+	RunE: runMigrate,
+}
+
+func init() {
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "Target storage backend (gob, sqlite, postgres, or qdrant)")
+	migrateCmd.Flags().StringVar(&migrateDSN, "dsn", "", "PostgreSQL DSN (when --to postgres)")
+	migrateCmd.Flags().StringVar(&migrateQdrantEndpoint, "qdrant-endpoint", "", "Qdrant endpoint (default: localhost)")
+	migrateCmd.Flags().IntVar(&migrateQdrantPort, "qdrant-port", 0, "Qdrant gRPC port (default: 6334)")
+	migrateCmd.Flags().StringVar(&migrateQdrantCollection, "collection", "", "Qdrant collection name (empty = auto)")
+	migrateCmd.Flags().StringVar(&migrateQdrantAPIKey, "qdrant-api-key", "", "Qdrant API key (for Qdrant Cloud)")
+	migrateCmd.Flags().BoolVar(&migrateQdrantTLS, "qdrant-tls", false, "Use TLS for Qdrant")
+	migrateCmd.Flags().BoolVar(&migrateForce, "force", false, "Clear existing data in the target before migrating")
+	_ = migrateCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(migrateCmd)
+}
+
+// buildMigrateStoreConfig returns the store configuration for the migration
+// target, keeping backend-independent settings from the current one.
+func buildMigrateStoreConfig(current config.StoreConfig, backend string) (config.StoreConfig, error) {
+	switch backend {
+	case "gob", "sqlite", "postgres", "qdrant":
+	default:
+		return config.StoreConfig{}, fmt.Errorf("unsupported target backend: %s (expected gob, sqlite, postgres, or qdrant)", backend)
+	}
+	if backend == current.Backend {
+		return config.StoreConfig{}, fmt.Errorf("index already uses the %s backend", backend)
+	}
+
+	target := config.DefaultStoreForBackend(backend)
+	target.Quantization = current.Quantization
+	target.GOB = current.GOB
+
+	switch backend {
+	case "postgres":
+		if current.Postgres.DSN != "" {
+			target.Postgres.DSN = current.Postgres.DSN
+		}
+		if migrateDSN != "" {
+			target.Postgres.DSN = migrateDSN
+		}
+	case "qdrant":
+		if current.Qdrant.Endpoint != "" {
+			target.Qdrant = current.Qdrant
+		}
+		if migrateQdrantEndpoint != "" {
+			target.Qdrant.Endpoint = migrateQdrantEndpoint
+		}
+		if migrateQdrantPort > 0 {
+			target.Qdrant.Port = migrateQdrantPort
+		}
+		if migrateQdrantCollection != "" {
+			target.Qdrant.Collection = migrateQdrantCollection
+		}
+		if migrateQdrantAPIKey != "" {
+			target.Qdrant.APIKey = migrateQdrantAPIKey
+		}
+		if migrateQdrantTLS {
+			target.Qdrant.UseTLS = true
+		}
+	}
+
+	return target, nil
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	projectRoot, err := config.FindProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	targetStore, err := buildMigrateStoreConfig(cfg.Store, migrateTo)
+	if err != nil {
+		return err
+	}
+
+	if status := resolveWatcherRuntimeStatus(projectRoot); status.running {
+		return fmt.Errorf("watcher is running (PID %d); stop it with 'grepai watch --stop' before migrating", status.pid)
+	}
+
+	src, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open source store: %w", err)
+	}
+	defer src.Close()
+
+	targetCfg := *cfg
+	targetCfg.Store = targetStore
+	dst, err := store.NewFromConfig(ctx, &targetCfg, projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to open target store: %w", err)
+	}
+	defer dst.Close()
+
+	existing, err := dst.GetStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to inspect target store: %w", err)
+	}
+	if existing.TotalChunks > 0 {
+		if !migrateForce {
+			return fmt.Errorf("target %s store already contains %d chunks; use --force to clear it first", migrateTo, existing.TotalChunks)
+		}
+		fmt.Printf("Clearing %d existing chunks from target...\n", existing.TotalChunks)
+		if err := store.ClearStore(ctx, dst); err != nil {
+			return fmt.Errorf("failed to clear target store: %w", err)
+		}
+	}
+
+	if q := cfg.Store.Quantization; q != "" && q != string(store.QuantizationNone) {
+		fmt.Printf("Note: source vectors are %s-quantized; the target receives the dequantized approximations.\n", q)
+	}
+
+	fmt.Printf("Migrating index from %s to %s...\n", cfg.Store.Backend, migrateTo)
+	result, err := store.Migrate(ctx, src, dst, store.MigrateOptions{
+		Progress: func(done, total int) {
+			percent := 100
+			if total > 0 {
+				percent = done * 100 / total
+			}
+			fmt.Printf("\rCopying chunks [%s] %d/%d", progressBar(percent, 30), done, total)
+		},
+	})
+	fmt.Println()
+	if err != nil {
+		return fmt.Errorf("migration failed (config.yaml unchanged): %w", err)
+	}
+
+	cfg.Store = targetStore
+	if err := cfg.Save(projectRoot); err != nil {
+		return fmt.Errorf("failed to update configuration: %w", err)
+	}
+
+	fmt.Printf("Migrated %d chunks and %d documents to %s.\n", result.Chunks, result.Documents, migrateTo)
+	fmt.Printf("Configuration updated: %s\n", config.GetConfigPath(projectRoot))
+	return nil
+}
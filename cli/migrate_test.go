@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func TestBuildMigrateStoreConfig(t *testing.T) {
+	current := config.StoreConfig{Backend: "gob", Quantization: "int8"}
+
+	if _, err := buildMigrateStoreConfig(current, "gob"); err == nil {
+		t.Error("expected error when migrating to the current backend")
+	}
+	if _, err := buildMigrateStoreConfig(current, "mysql"); err == nil {
+		t.Error("expected error for unsupported backend")
+	}
+
+	oldDSN := migrateDSN
+	t.Cleanup(func() { migrateDSN = oldDSN })
+	migrateDSN = "postgres://db:5432/shared"
+
+	target, err := buildMigrateStoreConfig(current, "postgres")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Backend != "postgres" || target.Postgres.DSN != migrateDSN {
+		t.Errorf("unexpected postgres target: %+v", target)
+	}
+	if target.Quantization != "int8" {
+		t.Errorf("expected quantization to be kept, got %q", target.Quantization)
+	}
+
+	migrateDSN = ""
+	target, err = buildMigrateStoreConfig(current, "qdrant")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Qdrant.Endpoint != config.DefaultQdrantEndpoint || target.Qdrant.Port != config.DefaultQdrantPort {
+		t.Errorf("expected qdrant defaults, got %+v", target.Qdrant)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
 )
 
 var (
@@ -37,6 +39,8 @@ func GetRootCmd() *cobra.Command {
 }
 
 func init() {
+	config.SQLiteIndexCopier = store.CopySQLiteDatabase
+
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(watchCmd)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/alpkeskin/gotoon"
 	"github.com/spf13/cobra"
@@ -24,6 +25,14 @@ var (
 	searchWorkspace string
 	searchProjects  []string
 	searchPath      string
+	searchLangs     []string
+	searchGlobs     []string
+	searchExcludes  []string
+	searchKinds     []string
+	searchSince     string
+	searchByFile    bool
+	searchDiverse   bool
+	searchExplain   bool
 )
 
 // SearchResultJSON is a lightweight struct for JSON output (excludes vector, hash, updated_at)
@@ -31,8 +40,10 @@ type SearchResultJSON struct {
 	FilePath    string  `json:"file_path"`
 	StartLine   int     `json:"start_line"`
 	EndLine     int     `json:"end_line"`
+	Cell        int     `json:"cell,omitempty"` // notebook cell number; start and end lines hold it too
 	Score       float32 `json:"score"`
 	Content     string  `json:"content"`
+	Section     string  `json:"section,omitempty"`
 	FeaturePath string  `json:"feature_path,omitempty"`
 	SymbolName  string  `json:"symbol_name,omitempty"`
 }
@@ -42,7 +53,9 @@ type SearchResultCompactJSON struct {
 	FilePath    string  `json:"file_path"`
 	StartLine   int     `json:"start_line"`
 	EndLine     int     `json:"end_line"`
+	Cell        int     `json:"cell,omitempty"` // notebook cell number; start and end lines hold it too
 	Score       float32 `json:"score"`
+	Section     string  `json:"section,omitempty"`
 	FeaturePath string  `json:"feature_path,omitempty"`
 	SymbolName  string  `json:"symbol_name,omitempty"`
 }
@@ -55,7 +68,23 @@ var searchCmd = &cobra.Command{
 The search will:
 - Vectorize your query using the configured embedding provider
 - Calculate cosine similarity against indexed code chunks
-- Return the most relevant results with file path, line numbers, and score`,
+- Return the most relevant results with file path, line numbers, and score
+
+Filters:
+  --lang go,python             Only files in these languages
+  --glob '**/handlers/**'      Only files matching a glob (repeatable)
+  --exclude '*_test.go'        Skip files matching a glob (repeatable)
+  --since 30d                  Only files modified in the last 30 days (also 2w, 12h, 2024-06-01)
+  --kind function,method       Only chunks defining symbols of these kinds
+
+Globs without a slash match the file name in any directory.
+
+Overlapping and adjacent hits from the same file are merged into one result.
+With --group-by-file, each result is a file with up to three of its best
+snippets. With --diverse, results are ordered by maximal marginal relevance
+so near-duplicates from one file or package give way to other matches
+(see search.diversity in the configuration). --explain lists the boost rules
+that matched each result.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSearch,
 }
@@ -68,13 +97,23 @@ func init() {
 	searchCmd.Flags().StringVar(&searchWorkspace, "workspace", "", "Workspace name for cross-project search")
 	searchCmd.Flags().StringArrayVar(&searchProjects, "project", nil, "Project name(s) to search (requires --workspace, can be repeated)")
 	searchCmd.Flags().StringVar(&searchPath, "path", "", "Path prefix to filter search results")
+	searchCmd.Flags().StringSliceVar(&searchLangs, "lang", nil, "Only search files in these languages (e.g. go,python)")
+	searchCmd.Flags().StringArrayVar(&searchGlobs, "glob", nil, "Only search files matching this glob (can be repeated)")
+	searchCmd.Flags().StringArrayVar(&searchExcludes, "exclude", nil, "Skip files matching this glob (can be repeated)")
+	searchCmd.Flags().StringSliceVar(&searchKinds, "kind", nil, "Only return chunks defining symbols of these kinds (function, method, class, interface, type)")
+	searchCmd.Flags().StringVar(&searchSince, "since", "", "Only search files modified within this period (e.g. 30d, 2w, 12h) or since a date (2024-06-01)")
+	searchCmd.Flags().BoolVar(&searchByFile, "group-by-file", false, "Return one entry per file with its best snippets")
+	searchCmd.Flags().BoolVar(&searchDiverse, "diverse", false, "Spread results across files and directories instead of returning near-duplicates")
+	searchCmd.Flags().BoolVar(&searchExplain, "explain", false, "Show which boost rules matched each result (text output only)")
 	searchCmd.MarkFlagsMutuallyExclusive("json", "toon")
 }
 
-// rpgEnrichment holds RPG context for a search result
+// rpgEnrichment holds RPG context for a search result, and the boost rules
+// it matched when --explain is set
 type rpgEnrichment struct {
 	FeaturePath string
 	SymbolName  string
+	Boosts      []string
 }
 
 // enrichWithRPG enriches search results with RPG feature paths and symbol names
@@ -85,9 +124,18 @@ func enrichWithRPG(projectRoot string, cfg *config.Config, results []store.Searc
 	}
 
 	ctx := context.Background()
-	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(projectRoot))
-	if err := rpgStore.Load(ctx); err != nil {
+	rpgStore, err := rpg.NewStoreFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return enrichments
+	}
+
+	paths := make([]string, 0, len(results))
+	for _, r := range results {
+		paths = append(paths, r.Chunk.FilePath)
+	}
+	if err := rpg.LoadFiles(ctx, rpgStore, paths); err != nil {
 		// Silently fail - RPG enrichment is best-effort
+		rpg.Discard(rpgStore)
 		return enrichments
 	}
 	defer rpgStore.Close()
@@ -197,6 +245,9 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	if searchCompact && !searchJSON && !searchTOON {
 		return fmt.Errorf("--compact flag requires --json or --toon flag")
 	}
+	if searchExplain && (searchJSON || searchTOON) {
+		return fmt.Errorf("--explain flag cannot be combined with --json or --toon")
+	}
 
 	// Validate workspace-related flags
 	if len(searchProjects) > 0 && searchWorkspace == "" {
@@ -228,58 +279,67 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	defer emb.Close()
 
 	// Initialize store
-	var st store.VectorStore
-	switch cfg.Store.Backend {
-	case "gob":
-		indexPath := config.GetIndexPath(projectRoot)
-		gobStore := store.NewGOBStore(indexPath)
-		if err := gobStore.Load(ctx); err != nil {
-			return fmt.Errorf("failed to load index: %w", err)
-		}
-		st = gobStore
-	case "postgres":
-		var err error
-		st, err = store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, cfg.Embedder.GetDimensions())
-		if err != nil {
-			return fmt.Errorf("failed to connect to postgres: %w", err)
-		}
-	case "qdrant":
-		collectionName := cfg.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = store.SanitizeCollectionName(projectRoot)
-		}
-		var err error
-		st, err = store.NewQdrantStore(ctx, cfg.Store.Qdrant.Endpoint, cfg.Store.Qdrant.Port, cfg.Store.Qdrant.UseTLS, collectionName, cfg.Store.Qdrant.APIKey, cfg.Embedder.GetDimensions())
-		if err != nil {
-			return fmt.Errorf("failed to connect to qdrant: %w", err)
-		}
-	default:
-		return fmt.Errorf("unknown storage backend: %s", cfg.Store.Backend)
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
 	}
 	defer st.Close()
 
+	if _, err := store.CheckManifest(ctx, st, store.NewManifest(cfg.Embedder, cfg.Chunking, version)); err != nil {
+		return err
+	}
+
+	reranker, err := search.NewRerankerFromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize reranker: %w", err)
+	}
+
+	signals := search.NewProjectSignals(projectRoot)
+	defer signals.Close()
+
 	// Create searcher with boost config
-	searcher := search.NewSearcher(st, emb, cfg.Search)
+	searcherOpts := []search.SearcherOption{
+		search.WithReranker(reranker, cfg.Search.Rerank.TopN),
+		search.WithBoostSignals(signals),
+	}
+	if searchDiverse {
+		searcherOpts = append(searcherOpts, search.WithDiversity(cfg.Search.Diversity.Lambda))
+	}
+	searcher := search.NewSearcher(st, emb, cfg.Search, searcherOpts...)
 
 	normalizedPath, err := search.NormalizeProjectPathPrefix(searchPath, projectRoot)
 	if err != nil {
 		return fmt.Errorf("invalid --path value: %w", err)
 	}
 
-	// Search with boosting
-	results, err := searcher.Search(ctx, query, searchLimit, normalizedPath)
+	opts, err := searchFilters().Options(normalizedPath, time.Now())
 	if err != nil {
-		if searchJSON {
-			return outputSearchErrorJSON(err)
+		return fmt.Errorf("invalid search filter: %w", err)
+	}
+
+	if searchByFile {
+		groups, err := searcher.SearchByFile(ctx, query, searchLimit, opts)
+		if err != nil {
+			return outputSearchError(err)
 		}
-		if searchTOON {
-			return outputSearchErrorTOON(err)
+		enrichments := enrichGroupsWithRPG(projectRoot, cfg, groups)
+		if searchExplain {
+			explainGroups(ctx, searcher, groups, enrichments)
 		}
-		return fmt.Errorf("search failed: %w", err)
+		return outputFileResults(query, groups, enrichments)
+	}
+
+	// Search with boosting
+	results, err := searcher.SearchWithOptions(ctx, query, searchLimit, opts)
+	if err != nil {
+		return outputSearchError(err)
 	}
 
 	// Enrich results with RPG context
 	enrichments := enrichWithRPG(projectRoot, cfg, results)
+	if searchExplain {
+		explainResults(ctx, searcher, results, enrichments)
+	}
 
 	// JSON output mode
 	if searchJSON {
@@ -307,37 +367,37 @@ func runSearch(cmd *cobra.Command, args []string) error {
 
 	for i, result := range results {
 		fmt.Printf("─── Result %d (score: %.4f) ───\n", i+1, result.Score)
-		fmt.Printf("File: %s:%d-%d\n", result.Chunk.FilePath, result.Chunk.StartLine, result.Chunk.EndLine)
+		fmt.Printf("File: %s\n", result.Chunk.Location())
+		if section := result.Chunk.SectionPath(); section != "" {
+			fmt.Printf("Section: %s\n", section)
+		}
 		if enrichments[i].FeaturePath != "" {
 			fmt.Printf("Feature: %s\n", enrichments[i].FeaturePath)
 		}
 		if enrichments[i].SymbolName != "" {
 			fmt.Printf("Symbol: %s\n", enrichments[i].SymbolName)
 		}
+		printBoosts(enrichments[i].Boosts)
 		fmt.Println()
 
-		// Display content with line numbers
-		lines := strings.Split(result.Chunk.Content, "\n")
-		// Skip the "File: xxx" prefix line if present
-		startIdx := 0
-		if len(lines) > 0 && strings.HasPrefix(lines[0], "File: ") {
-			startIdx = 2 // Skip "File: xxx" and empty line
-		}
-
-		lineNum := result.Chunk.StartLine
-		for j := startIdx; j < len(lines) && j < startIdx+15; j++ {
-			fmt.Printf("%4d │ %s\n", lineNum, lines[j])
-			lineNum++
-		}
-		if len(lines)-startIdx > 15 {
-			fmt.Printf("     │ ... (%d more lines)\n", len(lines)-startIdx-15)
-		}
+		printChunkContent(result.Chunk)
 		fmt.Println()
 	}
 
 	return nil
 }
 
+// searchFilters collects the metadata filter flags.
+func searchFilters() search.Filters {
+	return search.Filters{
+		Languages: searchLangs,
+		Include:   searchGlobs,
+		Exclude:   searchExcludes,
+		Kinds:     searchKinds,
+		Since:     searchSince,
+	}
+}
+
 // outputSearchJSON outputs results in JSON format for AI agents
 func outputSearchJSON(results []store.SearchResult, enrichments []rpgEnrichment) error {
 	jsonResults := make([]SearchResultJSON, len(results))
@@ -346,7 +406,9 @@ func outputSearchJSON(results []store.SearchResult, enrichments []rpgEnrichment)
 			FilePath:    r.Chunk.FilePath,
 			StartLine:   r.Chunk.StartLine,
 			EndLine:     r.Chunk.EndLine,
+			Cell:        r.Chunk.Cell(),
 			Score:       r.Score,
+			Section:     r.Chunk.SectionPath(),
 			Content:     r.Chunk.Content,
 			FeaturePath: enrichments[i].FeaturePath,
 			SymbolName:  enrichments[i].SymbolName,
@@ -366,7 +428,9 @@ func outputSearchCompactJSON(results []store.SearchResult, enrichments []rpgEnri
 			FilePath:    r.Chunk.FilePath,
 			StartLine:   r.Chunk.StartLine,
 			EndLine:     r.Chunk.EndLine,
+			Cell:        r.Chunk.Cell(),
 			Score:       r.Score,
+			Section:     r.Chunk.SectionPath(),
 			FeaturePath: enrichments[i].FeaturePath,
 			SymbolName:  enrichments[i].SymbolName,
 		}
@@ -393,7 +457,9 @@ func outputSearchTOON(results []store.SearchResult, enrichments []rpgEnrichment)
 			FilePath:    r.Chunk.FilePath,
 			StartLine:   r.Chunk.StartLine,
 			EndLine:     r.Chunk.EndLine,
+			Cell:        r.Chunk.Cell(),
 			Score:       r.Score,
+			Section:     r.Chunk.SectionPath(),
 			Content:     r.Chunk.Content,
 			FeaturePath: enrichments[i].FeaturePath,
 			SymbolName:  enrichments[i].SymbolName,
@@ -416,7 +482,9 @@ func outputSearchCompactTOON(results []store.SearchResult, enrichments []rpgEnri
 			FilePath:    r.Chunk.FilePath,
 			StartLine:   r.Chunk.StartLine,
 			EndLine:     r.Chunk.EndLine,
+			Cell:        r.Chunk.Cell(),
 			Score:       r.Score,
+			Section:     r.Chunk.SectionPath(),
 			FeaturePath: enrichments[i].FeaturePath,
 			SymbolName:  enrichments[i].SymbolName,
 		}
@@ -455,25 +523,29 @@ func SearchJSON(projectRoot string, query string, limit int) ([]store.SearchResu
 	}
 	defer emb.Close()
 
-	var st store.VectorStore
-	switch cfg.Store.Backend {
-	case "gob":
-		gobStore := store.NewGOBStore(config.GetIndexPath(projectRoot))
-		if err := gobStore.Load(ctx); err != nil {
-			return nil, err
-		}
-		st = gobStore
-	case "postgres":
-		var err error
-		st, err = store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, cfg.Embedder.GetDimensions())
-		if err != nil {
-			return nil, err
-		}
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return nil, err
 	}
 	defer st.Close()
 
+	if _, err := store.CheckManifest(ctx, st, store.NewManifest(cfg.Embedder, cfg.Chunking, version)); err != nil {
+		return nil, err
+	}
+
+	reranker, err := search.NewRerankerFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize reranker: %w", err)
+	}
+
+	signals := search.NewProjectSignals(projectRoot)
+	defer signals.Close()
+
 	// Create searcher with boost config
-	searcher := search.NewSearcher(st, emb, cfg.Search)
+	searcher := search.NewSearcher(st, emb, cfg.Search,
+		search.WithReranker(reranker, cfg.Search.Rerank.TopN),
+		search.WithBoostSignals(signals),
+	)
 
 	return searcher.Search(ctx, query, limit, "")
 }
@@ -545,7 +617,11 @@ func runWorkspaceSearch(ctx context.Context, query string, projects []string, pa
 		Hybrid: config.HybridConfig{Enabled: false, K: 60},
 		Boost:  config.DefaultConfig().Search.Boost,
 	}
-	searcher := search.NewSearcher(st, emb, searchCfg)
+	var searcherOpts []search.SearcherOption
+	if searchDiverse {
+		searcherOpts = append(searcherOpts, search.WithDiversity(0))
+	}
+	searcher := search.NewSearcher(st, emb, searchCfg, searcherOpts...)
 
 	// Construct full path prefix for database query
 	// Database stores paths as: workspaceName/projectName/relativePath
@@ -560,37 +636,68 @@ func runWorkspaceSearch(ctx context.Context, query string, projects []string, pa
 		fullPathPrefix += normalizedPath
 	}
 
-	// Search
-	results, err := searcher.Search(ctx, query, searchLimit, fullPathPrefix)
+	opts, err := searchFilters().WithWorkspaceRoot(ws.Name).Options(fullPathPrefix, time.Now())
 	if err != nil {
-		if searchJSON {
-			return outputSearchErrorJSON(err)
+		return fmt.Errorf("invalid search filter: %w", err)
+	}
+
+	// File paths are stored as: workspaceName/projectName/relativePath
+	inProjects := func(filePath string) bool {
+		if len(resolvedProjects) == 0 {
+			return true
+		}
+		for _, projectName := range resolvedProjects {
+			// Match workspace/project/ prefix
+			if strings.HasPrefix(filePath, ws.Name+"/"+projectName+"/") {
+				return true
+			}
 		}
-		if searchTOON {
-			return outputSearchErrorTOON(err)
+		return false
+	}
+
+	if searchByFile {
+		groups, err := searcher.SearchByFile(ctx, query, searchLimit, opts)
+		if err != nil {
+			return outputSearchError(err)
 		}
-		return fmt.Errorf("search failed: %w", err)
+		filteredGroups := make([]search.FileResult, 0, len(groups))
+		for _, g := range groups {
+			if inProjects(g.FilePath) {
+				filteredGroups = append(filteredGroups, g)
+			}
+		}
+		// Workspace mode doesn't have RPG enrichment (no single projectRoot)
+		var enrichments [][]rpgEnrichment
+		if searchExplain {
+			enrichments = make([][]rpgEnrichment, len(filteredGroups))
+			for i, g := range filteredGroups {
+				enrichments[i] = make([]rpgEnrichment, len(g.Snippets))
+			}
+			explainGroups(ctx, searcher, filteredGroups, enrichments)
+		}
+		return outputFileResults(query, filteredGroups, enrichments)
+	}
+
+	// Search
+	results, err := searcher.SearchWithOptions(ctx, query, searchLimit, opts)
+	if err != nil {
+		return outputSearchError(err)
 	}
 
 	// Filter by projects if specified (additional client-side filtering for multiple projects)
-	// File paths are stored as: workspaceName/projectName/relativePath
-	if len(resolvedProjects) > 0 {
-		filteredResults := make([]store.SearchResult, 0)
-		for _, r := range results {
-			for _, projectName := range resolvedProjects {
-				// Match workspace/project/ prefix
-				expectedPrefix := ws.Name + "/" + projectName + "/"
-				if strings.HasPrefix(r.Chunk.FilePath, expectedPrefix) {
-					filteredResults = append(filteredResults, r)
-					break
-				}
-			}
+	filteredResults := make([]store.SearchResult, 0, len(results))
+	for _, r := range results {
+		if inProjects(r.Chunk.FilePath) {
+			filteredResults = append(filteredResults, r)
 		}
-		results = filteredResults
 	}
+	results = filteredResults
 
 	// Workspace mode doesn't have RPG enrichment (no single projectRoot)
 	enrichments := make([]rpgEnrichment, len(results))
+	if searchExplain {
+		explainResults(ctx, searcher, results, enrichments)
+	}
 
 	// JSON output mode
 	if searchJSON {
@@ -618,30 +725,20 @@ func runWorkspaceSearch(ctx context.Context, query string, projects []string, pa
 
 	for i, result := range results {
 		fmt.Printf("─── Result %d (score: %.4f) ───\n", i+1, result.Score)
-		fmt.Printf("File: %s:%d-%d\n", result.Chunk.FilePath, result.Chunk.StartLine, result.Chunk.EndLine)
+		fmt.Printf("File: %s\n", result.Chunk.Location())
+		if section := result.Chunk.SectionPath(); section != "" {
+			fmt.Printf("Section: %s\n", section)
+		}
 		if enrichments[i].FeaturePath != "" {
 			fmt.Printf("Feature: %s\n", enrichments[i].FeaturePath)
 		}
 		if enrichments[i].SymbolName != "" {
 			fmt.Printf("Symbol: %s\n", enrichments[i].SymbolName)
 		}
+		printBoosts(enrichments[i].Boosts)
 		fmt.Println()
 
-		// Display content with line numbers
-		lines := strings.Split(result.Chunk.Content, "\n")
-		startIdx := 0
-		if len(lines) > 0 && strings.HasPrefix(lines[0], "File: ") {
-			startIdx = 2
-		}
-
-		lineNum := result.Chunk.StartLine
-		for j := startIdx; j < len(lines) && j < startIdx+15; j++ {
-			fmt.Printf("%4d │ %s\n", lineNum, lines[j])
-			lineNum++
-		}
-		if len(lines)-startIdx > 15 {
-			fmt.Printf("     │ ... (%d more lines)\n", len(lines)-startIdx-15)
-		}
+		printChunkContent(result.Chunk)
 		fmt.Println()
 	}
 
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/alpkeskin/gotoon"
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/search"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// maxDisplayedLines caps the content lines printed per result in text output.
+const maxDisplayedLines = 15
+
+// SearchFileResultJSON is one file of --group-by-file output
+type SearchFileResultJSON struct {
+	FilePath string              `json:"file_path"`
+	Score    float32             `json:"score"`
+	Snippets []SearchSnippetJSON `json:"snippets"`
+}
+
+// SearchSnippetJSON is one snippet of a file in --group-by-file output.
+// Content is left out in compact mode.
+type SearchSnippetJSON struct {
+	StartLine   int     `json:"start_line"`
+	EndLine     int     `json:"end_line"`
+	Cell        int     `json:"cell,omitempty"` // notebook cell number; start and end lines hold it too
+	Score       float32 `json:"score"`
+	Content     string  `json:"content,omitempty"`
+	Section     string  `json:"section,omitempty"`
+	FeaturePath string  `json:"feature_path,omitempty"`
+	SymbolName  string  `json:"symbol_name,omitempty"`
+}
+
+// outputSearchError reports a failed search in the selected output format.
+func outputSearchError(err error) error {
+	if searchJSON {
+		return outputSearchErrorJSON(err)
+	}
+	if searchTOON {
+		return outputSearchErrorTOON(err)
+	}
+	return fmt.Errorf("search failed: %w", err)
+}
+
+// enrichGroupsWithRPG enriches the snippets of each file with RPG context.
+func enrichGroupsWithRPG(projectRoot string, cfg *config.Config, groups []search.FileResult) [][]rpgEnrichment {
+	var snippets []store.SearchResult
+	for _, g := range groups {
+		snippets = append(snippets, g.Snippets...)
+	}
+	enrichments := enrichWithRPG(projectRoot, cfg, snippets)
+
+	byGroup := make([][]rpgEnrichment, len(groups))
+	for i, g := range groups {
+		byGroup[i], enrichments = enrichments[:len(g.Snippets)], enrichments[len(g.Snippets):]
+	}
+	return byGroup
+}
+
+// fileResultsJSON converts grouped results for JSON and TOON output. The
+// enrichments may be nil.
+func fileResultsJSON(groups []search.FileResult, enrichments [][]rpgEnrichment, withContent bool) []SearchFileResultJSON {
+	out := make([]SearchFileResultJSON, len(groups))
+	for i, g := range groups {
+		snippets := make([]SearchSnippetJSON, len(g.Snippets))
+		for j, r := range g.Snippets {
+			snippets[j] = SearchSnippetJSON{
+				StartLine: r.Chunk.StartLine,
+				EndLine:   r.Chunk.EndLine,
+				Cell:      r.Chunk.Cell(),
+				Score:     r.Score,
+				Section:   r.Chunk.SectionPath(),
+			}
+			if withContent {
+				snippets[j].Content = r.Chunk.Content
+			}
+			if enrichments != nil {
+				snippets[j].FeaturePath = enrichments[i][j].FeaturePath
+				snippets[j].SymbolName = enrichments[i][j].SymbolName
+			}
+		}
+		out[i] = SearchFileResultJSON{FilePath: g.FilePath, Score: g.Score, Snippets: snippets}
+	}
+	return out
+}
+
+// outputFileResults prints --group-by-file results in the selected output
+// format. The enrichments may be nil.
+func outputFileResults(query string, groups []search.FileResult, enrichments [][]rpgEnrichment) error {
+	if searchJSON || searchTOON {
+		results := fileResultsJSON(groups, enrichments, !searchCompact)
+		if searchJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(results)
+		}
+		output, err := gotoon.Encode(results)
+		if err != nil {
+			return fmt.Errorf("failed to encode TOON: %w", err)
+		}
+		fmt.Println(output)
+		return nil
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No results found.")
+		return nil
+	}
+
+	fmt.Printf("Found %d files for: %q\n\n", len(groups), query)
+
+	for i, g := range groups {
+		fmt.Printf("═══ File %d: %s (score: %.4f) ═══\n\n", i+1, g.FilePath, g.Score)
+		for j, r := range g.Snippets {
+			fmt.Printf("─── %s (score: %.4f) ───\n", r.Chunk.Location(), r.Score)
+			if section := r.Chunk.SectionPath(); section != "" {
+				fmt.Printf("Section: %s\n", section)
+			}
+			if enrichments != nil {
+				if enrichments[i][j].FeaturePath != "" {
+					fmt.Printf("Feature: %s\n", enrichments[i][j].FeaturePath)
+				}
+				if enrichments[i][j].SymbolName != "" {
+					fmt.Printf("Symbol: %s\n", enrichments[i][j].SymbolName)
+				}
+				printBoosts(enrichments[i][j].Boosts)
+			}
+			fmt.Println()
+
+			printChunkContent(r.Chunk)
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+// explainResults records in enrichments the boost rules each result matched.
+func explainResults(ctx context.Context, searcher *search.Searcher, results []store.SearchResult, enrichments []rpgEnrichment) {
+	for i, matches := range searcher.ExplainBoost(ctx, results) {
+		enrichments[i].Boosts = make([]string, len(matches))
+		for j, m := range matches {
+			enrichments[i].Boosts[j] = m.String()
+		}
+	}
+}
+
+// explainGroups is explainResults for the snippets of grouped results.
+func explainGroups(ctx context.Context, searcher *search.Searcher, groups []search.FileResult, enrichments [][]rpgEnrichment) {
+	for i, g := range groups {
+		explainResults(ctx, searcher, g.Snippets, enrichments[i])
+	}
+}
+
+// printBoosts prints the boost rules a result matched in text output. Boosts
+// is nil unless --explain is set.
+func printBoosts(boosts []string) {
+	if boosts == nil {
+		return
+	}
+	if len(boosts) == 0 {
+		fmt.Println("Boost: no rule matched")
+		return
+	}
+	for _, b := range boosts {
+		fmt.Printf("Boost: %s\n", b)
+	}
+}
+
+// printChunkContent prints the first lines of a chunk with line numbers,
+// skipping the "File: xxx" context header when present.
+func printChunkContent(chunk store.Chunk) {
+	lines := strings.Split(chunk.Content, "\n")
+	startIdx := 0
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "File: ") {
+		startIdx = 2 // Skip "File: xxx" and empty line
+	}
+
+	lineNum := chunk.StartLine
+	if store.IsNotebook(chunk.FilePath) {
+		lineNum = 1 // lines within the cell
+	}
+	for j := startIdx; j < len(lines) && j < startIdx+maxDisplayedLines; j++ {
+		fmt.Printf("%4d │ %s\n", lineNum, lines[j])
+		lineNum++
+	}
+	if len(lines)-startIdx > maxDisplayedLines {
+		fmt.Printf("     │ ... (%d more lines)\n", len(lines)-startIdx-maxDisplayedLines)
+	}
+}
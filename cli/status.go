@@ -14,6 +14,7 @@ import (
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/daemon"
 	"github.com/yoanbernabeu/grepai/git"
+	"github.com/yoanbernabeu/grepai/indexer"
 	"github.com/yoanbernabeu/grepai/store"
 )
 
@@ -45,6 +46,7 @@ type model struct {
 	cfg           *config.Config
 	state         viewState
 	stats         *store.IndexStats
+	skipped       map[indexer.SkipReason]int
 	files         []store.FileStats
 	chunks        []store.Chunk
 	selectedFile  int
@@ -197,6 +199,9 @@ func (m model) viewStats() string {
 		sb.WriteString(fmt.Sprintf("%s\n", m.stats.LastUpdated.Format("2006-01-02 15:04:05")))
 	}
 
+	sb.WriteString(normalStyle.Render("Files skipped:    "))
+	sb.WriteString(fmt.Sprintf("%s\n", formatSkipCounts(m.skipped)))
+
 	sb.WriteString(normalStyle.Render("Provider:         "))
 	sb.WriteString(fmt.Sprintf("%s (%s)\n", m.cfg.Embedder.Provider, m.cfg.Embedder.Model))
 
@@ -281,8 +286,12 @@ func (m model) viewChunks() string {
 	sb.WriteString("\n\n")
 
 	chunk := m.chunks[m.selectedChunk]
-	sb.WriteString(normalStyle.Render(fmt.Sprintf("Chunk %d/%d  [Lines %d-%d]",
-		m.selectedChunk+1, len(m.chunks), chunk.StartLine, chunk.EndLine)))
+	span := fmt.Sprintf("Lines %d-%d", chunk.StartLine, chunk.EndLine)
+	if store.IsNotebook(chunk.FilePath) {
+		span = fmt.Sprintf("Cell %d", chunk.StartLine)
+	}
+	sb.WriteString(normalStyle.Render(fmt.Sprintf("Chunk %d/%d  [%s]",
+		m.selectedChunk+1, len(m.chunks), span)))
 	sb.WriteString("\n")
 	sb.WriteString(dimStyle.Render(strings.Repeat("-", 50)))
 	sb.WriteString("\n\n")
@@ -341,33 +350,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Initialize store
-	var st store.VectorStore
-	switch cfg.Store.Backend {
-	case "gob":
-		indexPath := config.GetIndexPath(projectRoot)
-		gobStore := store.NewGOBStore(indexPath)
-		if err := gobStore.Load(ctx); err != nil {
-			return fmt.Errorf("failed to load index: %w", err)
-		}
-		st = gobStore
-	case "postgres":
-		var err error
-		st, err = store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, cfg.Embedder.GetDimensions())
-		if err != nil {
-			return fmt.Errorf("failed to connect to postgres: %w", err)
-		}
-	case "qdrant":
-		collectionName := cfg.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = store.SanitizeCollectionName(projectRoot)
-		}
-		var err error
-		st, err = store.NewQdrantStore(ctx, cfg.Store.Qdrant.Endpoint, cfg.Store.Qdrant.Port, cfg.Store.Qdrant.UseTLS, collectionName, cfg.Store.Qdrant.APIKey, cfg.Embedder.GetDimensions())
-		if err != nil {
-			return fmt.Errorf("failed to connect to qdrant: %w", err)
-		}
-	default:
-		return fmt.Errorf("unknown storage backend: %s", cfg.Store.Backend)
+	st, err := store.NewFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return err
 	}
 	defer st.Close()
 
@@ -377,11 +362,13 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to get stats: %w", err)
 	}
 
+	skipped := recordedSkipCounts(cfg)
+
 	watchStatus := resolveWatcherRuntimeStatus(projectRoot)
 	useUI := shouldUseStatusUI(isInteractiveTerminal(), statusNoUI)
 
 	if !useUI {
-		fmt.Print(renderStatusSummary(cfg, stats, watchStatus))
+		fmt.Print(renderStatusSummary(cfg, stats, skipped, watchStatus))
 		return nil
 	}
 
@@ -396,6 +383,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		cfg:          cfg,
 		state:        viewStats,
 		stats:        stats,
+		skipped:      skipped,
 		files:        files,
 		watchRunning: watchStatus.running,
 		watchPID:     watchStatus.pid,
@@ -410,6 +398,47 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+// recordedSkipCounts returns the skipped file counts recorded by the last
+// full scan of the watcher, or nil when none was recorded. The project is
+// not rescanned, so status stays fast on large trees.
+func recordedSkipCounts(cfg *config.Config) map[indexer.SkipReason]int {
+	if cfg.Watch.LastSkipCounts == nil {
+		return nil
+	}
+	skipped := make(map[indexer.SkipReason]int, len(cfg.Watch.LastSkipCounts))
+	for reason, n := range cfg.Watch.LastSkipCounts {
+		skipped[indexer.SkipReason(reason)] = n
+	}
+	return skipped
+}
+
+// skipReasonOrder lists skip reasons in the order status reports them.
+var skipReasonOrder = []indexer.SkipReason{
+	indexer.SkipTooLarge,
+	indexer.SkipMinified,
+	indexer.SkipExcluded,
+	indexer.SkipUnsupported,
+	indexer.SkipInvalidNotebook,
+}
+
+// formatSkipCounts formats skipped file counts as "3 too large, 1 minified",
+// or "unknown" when no scan recorded them.
+func formatSkipCounts(skipped map[indexer.SkipReason]int) string {
+	if skipped == nil {
+		return "unknown"
+	}
+	var parts []string
+	for _, reason := range skipReasonOrder {
+		if n := skipped[reason]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, reason))
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
 func formatBytes(b int64) string {
 	if b == 0 {
 		return "N/A"
@@ -512,7 +541,7 @@ func resolveWatcherCandidateLogDirs(projectRoot string) ([]string, error) {
 	return logDirs, nil
 }
 
-func renderStatusSummary(cfg *config.Config, stats *store.IndexStats, watch watcherRuntimeStatus) string {
+func renderStatusSummary(cfg *config.Config, stats *store.IndexStats, skipped map[indexer.SkipReason]int, watch watcherRuntimeStatus) string {
 	var sb strings.Builder
 	sb.WriteString("grepai index status\n")
 	sb.WriteString(fmt.Sprintf("Files indexed: %d\n", stats.TotalFiles))
@@ -523,6 +552,7 @@ func renderStatusSummary(cfg *config.Config, stats *store.IndexStats, watch watc
 	} else {
 		sb.WriteString(fmt.Sprintf("Last updated: %s\n", stats.LastUpdated.Format("2006-01-02 15:04:05")))
 	}
+	sb.WriteString(fmt.Sprintf("Files skipped: %s\n", formatSkipCounts(skipped)))
 	sb.WriteString(fmt.Sprintf("Provider: %s (%s)\n", cfg.Embedder.Provider, cfg.Embedder.Model))
 	if watch.running {
 		sb.WriteString(fmt.Sprintf("Watcher: running (PID %d)\n", watch.pid))
@@ -473,6 +473,32 @@ func runTraceGraph(cmd *cobra.Command, args []string) error {
 	return outputTraceResult(result, traceViewGraph)
 }
 
+// traceResultFiles returns the distinct files of every symbol in a TraceResult.
+func traceResultFiles(result *trace.TraceResult) []string {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(sym *trace.Symbol) {
+		if sym != nil && sym.File != "" && !seen[sym.File] {
+			seen[sym.File] = true
+			files = append(files, sym.File)
+		}
+	}
+
+	add(result.Symbol)
+	for i := range result.Callers {
+		add(&result.Callers[i].Symbol)
+	}
+	for i := range result.Callees {
+		add(&result.Callees[i].Symbol)
+	}
+	if result.Graph != nil {
+		for _, sym := range result.Graph.Nodes {
+			add(&sym)
+		}
+	}
+	return files
+}
+
 // enrichTraceWithRPG enriches all symbols in a TraceResult with RPG feature paths.
 func enrichTraceWithRPG(projectRoot string, cfg *config.Config, result *trace.TraceResult) {
 	if !cfg.RPG.Enabled {
@@ -480,8 +506,12 @@ func enrichTraceWithRPG(projectRoot string, cfg *config.Config, result *trace.Tr
 	}
 
 	ctx := context.Background()
-	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(projectRoot))
-	if err := rpgStore.Load(ctx); err != nil {
+	rpgStore, err := rpg.NewStoreFromConfig(ctx, cfg, projectRoot)
+	if err != nil {
+		return // best-effort
+	}
+	if err := rpg.LoadFiles(ctx, rpgStore, traceResultFiles(result)); err != nil {
+		rpg.Discard(rpgStore)
 		return // best-effort
 	}
 	defer rpgStore.Close()
@@ -675,7 +705,7 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// loadWorkspaceSymbolStores loads GOBSymbolStores for workspace projects.
+// loadWorkspaceSymbolStores loads the symbol stores of workspace projects.
 // If projectName is non-empty, only that project's store is loaded.
 func loadWorkspaceSymbolStores(ctx context.Context, workspaceName, projectName string) ([]trace.SymbolStore, error) {
 	wsCfg, err := config.LoadWorkspaceConfig()
@@ -708,6 +738,20 @@ func loadWorkspaceSymbolStores(ctx context.Context, workspaceName, projectName s
 		projects = ws.Projects
 	}
 
+	// Postgres workspaces share one symbol database: a single store spanning
+	// the selected projects answers every query in SQL.
+	if ws.Store.Backend == "postgres" {
+		projectIDs := make([]string, 0, len(projects))
+		for _, p := range projects {
+			projectIDs = append(projectIDs, ws.SymbolProjectID(p.Name))
+		}
+		ss, err := trace.NewPostgresSymbolStore(ctx, ws.Store.Postgres.DSN, projectIDs...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open workspace symbol store: %w", err)
+		}
+		return []trace.SymbolStore{ss}, nil
+	}
+
 	stores := make([]trace.SymbolStore, 0, len(projects))
 	for _, p := range projects {
 		ss := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(p.Path))
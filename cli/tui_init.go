@@ -27,7 +27,7 @@ const (
 )
 
 var initProviderOptions = []string{"ollama", "lmstudio", "openai"}
-var initBackendOptions = []string{"gob", "postgres", "qdrant"}
+var initBackendOptions = []string{"gob", "postgres", "qdrant", "sqlite"}
 
 type initUIModel struct {
 	theme tuiTheme
@@ -417,7 +417,7 @@ func (m *initUIModel) initBackendInputs() {
 	backend := initBackendOptions[m.backendIdx]
 	backendDefaults := config.DefaultStoreForBackend(backend)
 	switch backend {
-	case "gob":
+	case "gob", "sqlite":
 		// No config needed
 	case "postgres":
 		tiDSN := textinput.New()
@@ -535,7 +535,7 @@ func (m initUIModel) renderStepContent() string {
 		} else if initBackendOptions[m.backendIdx] == "qdrant" {
 			labels = []string{"Endpoint", "Port", "Collection", "API Key"}
 		} else {
-			return m.theme.text.Render("No configuration needed for GOB backend.\n\nPress Enter to continue.")
+			return m.theme.text.Render(fmt.Sprintf("No configuration needed for %s backend.\n\nPress Enter to continue.", strings.ToUpper(initBackendOptions[m.backendIdx])))
 		}
 		return m.renderInputs("Backend Configuration", labels, m.backendInputs)
 	case initStepRPG:
@@ -8,6 +8,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
 	"github.com/yoanbernabeu/grepai/store"
 )
 
@@ -420,13 +421,23 @@ func TestRenderStatusSummaryIncludesWatcherInfo(t *testing.T) {
 		logFile: "/tmp/grepai-watch.log",
 	}
 
-	out := renderStatusSummary(cfg, stats, watch)
+	skipped := map[indexer.SkipReason]int{indexer.SkipMinified: 1, indexer.SkipTooLarge: 3}
+
+	out := renderStatusSummary(cfg, stats, skipped, watch)
 	if !strings.Contains(out, "Files indexed: 12") {
 		t.Fatalf("summary missing files count: %q", out)
 	}
+	if !strings.Contains(out, "Files skipped: 3 too large, 1 minified") {
+		t.Fatalf("summary missing skipped counts: %q", out)
+	}
 	if !strings.Contains(out, "Watcher: running (PID 999)") {
 		t.Fatalf("summary missing watcher status: %q", out)
 	}
+
+	out = renderStatusSummary(cfg, stats, nil, watch)
+	if !strings.Contains(out, "Files skipped: unknown") {
+		t.Fatalf("summary should report unrecorded skip counts as unknown: %q", out)
+	}
 	if !strings.Contains(out, "/tmp/grepai-watch.log") {
 		t.Fatalf("summary missing watcher log path: %q", out)
 	}
@@ -468,3 +479,23 @@ func TestWatchUILogSourceResolver(t *testing.T) {
 		t.Fatalf("resolve(fallback) = %q, want %q", got, watchUILogSystem)
 	}
 }
+
+func TestRecordedSkipCounts(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if skipped := recordedSkipCounts(cfg); skipped != nil {
+		t.Fatalf("expected no counts before a scan recorded them, got %v", skipped)
+	}
+
+	stats := &indexer.IndexStats{SkipCounts: map[indexer.SkipReason]int{indexer.SkipInvalidNotebook: 2}}
+	if !updateIndexState(cfg, t.TempDir(), "", stats) {
+		t.Fatal("expected recording skip counts to change the config")
+	}
+	if got := formatSkipCounts(recordedSkipCounts(cfg)); got != "2 invalid notebook" {
+		t.Fatalf("formatSkipCounts() = %q, want %q", got, "2 invalid notebook")
+	}
+
+	// Incremental scans don't count skipped files and keep the last counts.
+	if updateIndexState(cfg, t.TempDir(), "", &indexer.IndexStats{}) {
+		t.Fatal("expected an incremental scan to leave the config unchanged")
+	}
+}
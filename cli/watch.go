@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -35,6 +36,7 @@ var (
 	watchStop       bool
 	watchWorkspace  string
 	watchNoUI       bool
+	watchReindex    bool
 )
 
 var (
@@ -58,6 +60,10 @@ The watcher will:
 - Apply debouncing (500ms) to batch rapid changes
 - Handle atomic updates to avoid duplicate vectors
 
+Re-indexing:
+  grepai watch --reindex                 Clear the index and embed every file again
+                                         (required after changing the embedder)
+
 Background mode:
   grepai watch --background              Run in background with default log directory
   grepai watch --background --log-dir /custom/path  Run with custom log directory
@@ -84,6 +90,7 @@ func init() {
 	watchCmd.Flags().BoolVar(&watchStop, "stop", false, "Stop the background watcher")
 	watchCmd.Flags().StringVar(&watchWorkspace, "workspace", "", "Workspace name for multi-project mode")
 	watchCmd.Flags().BoolVar(&watchNoUI, "no-ui", false, "Disable interactive UI in foreground mode")
+	watchCmd.Flags().BoolVar(&watchReindex, "reindex", false, "Clear the existing index and re-embed all files")
 }
 
 func runWatch(cmd *cobra.Command, args []string) error {
@@ -353,6 +360,9 @@ func startBackgroundWatch(logDir, worktreeID string) error {
 	if watchLogDir != "" {
 		args = append(args, "--log-dir", watchLogDir)
 	}
+	if watchReindex {
+		args = append(args, "--reindex")
+	}
 
 	// Spawn background process
 	var childPID int
@@ -436,25 +446,7 @@ func initializeEmbedder(ctx context.Context, cfg *config.Config) (embedder.Embed
 }
 
 func initializeStore(ctx context.Context, cfg *config.Config, projectRoot string) (store.VectorStore, error) {
-	switch cfg.Store.Backend {
-	case "gob":
-		indexPath := config.GetIndexPath(projectRoot)
-		gobStore := store.NewGOBStore(indexPath)
-		if err := gobStore.Load(ctx); err != nil {
-			return nil, fmt.Errorf("failed to load index: %w", err)
-		}
-		return gobStore, nil
-	case "postgres":
-		return store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, cfg.Embedder.GetDimensions())
-	case "qdrant":
-		collectionName := cfg.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = store.SanitizeCollectionName(projectRoot)
-		}
-		return store.NewQdrantStore(ctx, cfg.Store.Qdrant.Endpoint, cfg.Store.Qdrant.Port, cfg.Store.Qdrant.UseTLS, collectionName, cfg.Store.Qdrant.APIKey, cfg.Embedder.GetDimensions())
-	default:
-		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Store.Backend)
-	}
+	return store.NewFromConfig(ctx, cfg, projectRoot)
 }
 
 const configWriteThrottle = 30 * time.Second
@@ -679,7 +671,7 @@ func startRPGRealtimeWorkers(ctx context.Context, projectLabel string, symbolSto
 }
 
 //nolint:unused // Retained for upcoming watch-loop refactor across fg/bg modes.
-func runWatchLoop(ctx context.Context, st store.VectorStore, symbolStore *trace.GOBSymbolStore, w *watcher.Watcher, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, tracedLanguages []string, projectRoot string, cfg *config.Config, isBackgroundChild bool) error {
+func runWatchLoop(ctx context.Context, st store.VectorStore, symbolStore trace.ContentHashSymbolStore, w *watcher.Watcher, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, tracedLanguages []string, projectRoot string, cfg *config.Config, isBackgroundChild bool) error {
 	// Handle signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -738,18 +730,32 @@ func runWatchLoop(ctx context.Context, st store.VectorStore, symbolStore *trace.
 	}
 }
 
-func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore *trace.GOBSymbolStore, tracedLanguages []string, lastIndexTime time.Time, isBackgroundChild bool, onScan func(current, total int, file string), onEmbed func(info indexer.BatchProgressInfo)) (*indexer.IndexStats, error) {
+// runInitialScan brings the index and the symbol index up to date. When
+// changes is non-nil, only those files are checked instead of the whole
+// project.
+func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore trace.ContentHashSymbolStore, tracedLanguages []string, lastIndexTime time.Time, changes []indexer.FileChange, isBackgroundChild bool, onScan func(current, total int, file string), onEmbed func(info indexer.BatchProgressInfo)) (*indexer.IndexStats, error) {
 	// Initial scan with progress
+	message := "Performing initial scan..."
+	if changes != nil {
+		message = fmt.Sprintf("Performing initial scan of %d files changed according to git...", len(changes))
+	}
 	if !isBackgroundChild {
-		fmt.Println("\nPerforming initial scan...")
+		fmt.Println("\n" + message)
 	} else {
-		log.Println("Performing initial scan...")
+		log.Println(message)
+	}
+
+	indexAll := idx.IndexAllWithBatchProgress
+	if changes != nil {
+		indexAll = func(ctx context.Context, onProgress indexer.ProgressCallback, onBatchProgress indexer.BatchProgressCallback) (*indexer.IndexStats, error) {
+			return idx.IndexChanges(ctx, changes, onProgress, onBatchProgress)
+		}
 	}
 
 	var stats *indexer.IndexStats
 	var err error
 	if !isBackgroundChild {
-		stats, err = idx.IndexAllWithBatchProgress(ctx,
+		stats, err = indexAll(ctx,
 			func(info indexer.ProgressInfo) {
 				if onScan != nil {
 					onScan(info.Current, info.Total, info.CurrentFile)
@@ -768,7 +774,7 @@ func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.
 		// Clear progress line
 		fmt.Print("\r" + strings.Repeat(" ", 80) + "\r")
 	} else {
-		stats, err = idx.IndexAllWithBatchProgress(ctx, func(info indexer.ProgressInfo) {
+		stats, err = indexAll(ctx, func(info indexer.ProgressInfo) {
 			if onScan != nil {
 				onScan(info.Current, info.Total, info.CurrentFile)
 			}
@@ -798,49 +804,25 @@ func runInitialScan(ctx context.Context, idx *indexer.Indexer, scanner *indexer.
 		log.Println("Building symbol index...")
 	}
 	symbolCount := 0
-	files, _, err := scanner.ScanMetadata()
-	if err != nil {
-		log.Printf("Warning: failed to scan files for symbol index: %v", err)
-		return stats, nil
-	}
-
-	for _, file := range files {
-		ext := strings.ToLower(filepath.Ext(file.Path))
-		if !isTracedLanguage(ext, tracedLanguages) {
-			continue
-		}
-
-		// Skip files that are unchanged since the last index run and already tracked.
-		if !lastIndexTime.IsZero() {
-			fileModTime := time.Unix(file.ModTime, 0)
-			if (fileModTime.Before(lastIndexTime) || fileModTime.Equal(lastIndexTime)) && symbolStore.IsFileIndexed(file.Path) {
-				continue
-			}
-		}
-
-		fileInfo, err := scanner.ScanFile(file.Path)
+	if changes != nil {
+		symbolCount = indexChangedSymbols(ctx, scanner, extractor, symbolStore, tracedLanguages, changes)
+	} else {
+		files, _, err := scanner.ScanMetadata()
 		if err != nil {
-			log.Printf("Warning: failed to scan %s for symbols: %v", file.Path, err)
-			continue
-		}
-		if fileInfo == nil {
-			continue
+			log.Printf("Warning: failed to scan files for symbol index: %v", err)
+			return stats, nil
 		}
 
-		// Skip extraction when content hash matches what we already persisted.
-		if existingHash, ok := symbolStore.GetFileContentHash(fileInfo.Path); ok && existingHash == fileInfo.Hash {
-			continue
-		}
-
-		symbols, refs, err := extractor.ExtractAll(ctx, fileInfo.Path, fileInfo.Content)
-		if err != nil {
-			log.Printf("Warning: failed to extract symbols from %s: %v", fileInfo.Path, err)
-			continue
-		}
-		if err := symbolStore.SaveFileWithContentHash(ctx, fileInfo.Path, fileInfo.Hash, symbols, refs); err != nil {
-			log.Printf("Warning: failed to save symbols for %s: %v", fileInfo.Path, err)
+		for _, file := range files {
+			// Skip files that are unchanged since the last index run and already tracked.
+			if !lastIndexTime.IsZero() {
+				fileModTime := time.Unix(file.ModTime, 0)
+				if (fileModTime.Before(lastIndexTime) || fileModTime.Equal(lastIndexTime)) && symbolStore.IsFileIndexed(file.Path) {
+					continue
+				}
+			}
+			symbolCount += updateFileSymbols(ctx, scanner, extractor, symbolStore, tracedLanguages, file.Path)
 		}
-		symbolCount += len(symbols)
 	}
 	if err := symbolStore.Persist(ctx); err != nil {
 		log.Printf("Warning: failed to persist symbol index: %v", err)
@@ -911,6 +893,121 @@ func discoverWorktreesForWatch(projectRoot string) []string {
 	return worktrees
 }
 
+// effectiveChunking returns cfg with the strategy this build applies. Without
+// the treesitter build tag AST chunking falls back to fixed-size chunks, and
+// the index manifest must describe the chunks actually written.
+func effectiveChunking(cfg config.ChunkingConfig) config.ChunkingConfig {
+	if cfg.Strategy == config.ChunkingStrategyAST && !indexer.ASTChunkingSupported {
+		cfg.Strategy = config.ChunkingStrategyFixed
+	}
+	return cfg
+}
+
+// newChunker builds the chunker for the configured chunking strategy.
+func newChunker(cfg config.ChunkingConfig) *indexer.Chunker {
+	var opts []indexer.ChunkerOption
+	if cfg.Strategy == config.ChunkingStrategyAST {
+		opts = append(opts, indexer.WithASTChunking())
+	}
+	if cfg.NotebookOutputs {
+		opts = append(opts, indexer.WithNotebookOutputs())
+	}
+	return indexer.NewChunker(cfg.Size, cfg.Overlap, opts...)
+}
+
+// newFileFilter builds the scanner and watcher file filter from the index
+// configuration.
+func newFileFilter(cfg config.IndexConfig) *indexer.FileFilter {
+	return indexer.NewFileFilter(indexer.FileFilterConfig{
+		IncludeExtensions: cfg.IncludeExtensions,
+		ExcludeExtensions: cfg.ExcludeExtensions,
+		Filenames:         cfg.Filenames,
+		Shebang:           cfg.Shebang,
+		MaxFileSize:       cfg.MaxFileSizeBytes(),
+	})
+}
+
+// newScanner creates a scanner for root that indexes the files selected by
+// the index configuration.
+func newScanner(root string, ignoreMatcher *indexer.IgnoreMatcher, cfg config.IndexConfig) *indexer.Scanner {
+	return indexer.NewScanner(root, ignoreMatcher, indexer.WithFileFilter(newFileFilter(cfg)))
+}
+
+// newRedactor returns the secret redactor for a project indexed with the
+// given embedder provider, or nil when redaction is disabled. Redactions are
+// appended to the project's audit log, which also tells which files were
+// already skipped.
+func newRedactor(cfg config.RedactionConfig, provider, projectRoot string) (*indexer.Redactor, error) {
+	if !cfg.IsEnabled(provider) {
+		return nil, nil
+	}
+	rules := make([]indexer.SecretRule, 0, len(cfg.Patterns))
+	for _, p := range cfg.Patterns {
+		pattern, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %w", p.Name, err)
+		}
+		rules = append(rules, indexer.SecretRule{Name: p.Name, Pattern: pattern})
+	}
+	logPath := config.GetRedactionLogPath(projectRoot)
+	redactorCfg := indexer.RedactorConfig{
+		Rules:     rules,
+		Disable:   cfg.Disable,
+		SkipFiles: cfg.SkipFiles,
+		Audit:     appendFile(logPath),
+	}
+	if cfg.SkipFiles {
+		if history, err := os.Open(logPath); err == nil {
+			defer history.Close()
+			redactorCfg.History = history
+		}
+	}
+	redactor, err := indexer.NewRedactor(redactorCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize secret redaction: %w", err)
+	}
+	return redactor, nil
+}
+
+// appendFile is an io.Writer appending each write to the named file.
+type appendFile string
+
+func (f appendFile) Write(p []byte) (int, error) {
+	file, err := os.OpenFile(string(f), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := file.Write(p)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// openEmbeddingCache opens the embedding cache shared by all projects when
+// the embedder configuration enables it. Failures are logged and leave the
+// cache disabled, since it only saves embedding calls.
+func openEmbeddingCache(ctx context.Context, emb config.EmbedderConfig) *store.DiskEmbeddingCache {
+	if !emb.Cache.Enabled {
+		return nil
+	}
+	path, err := config.GetEmbeddingCachePath()
+	if err != nil {
+		log.Printf("Warning: embedding cache unavailable: %v", err)
+		return nil
+	}
+	maxSizeMB := emb.Cache.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = config.DefaultEmbeddingCacheMaxSizeMB
+	}
+	cache, err := store.NewDiskEmbeddingCache(ctx, path, emb, int64(maxSizeMB)<<20)
+	if err != nil {
+		log.Printf("Warning: embedding cache unavailable: %v", err)
+		return nil
+	}
+	return cache
+}
+
 func canonicalPath(path string) string {
 	if resolved, err := filepath.EvalSymlinks(path); err == nil {
 		path = resolved
@@ -955,6 +1052,15 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 	}
 	defer st.Close()
 
+	cleared, err := ensureIndexManifest(ctx, st, store.NewManifest(cfg.Embedder, effectiveChunking(cfg.Chunking), version), watchReindex)
+	if err != nil {
+		return err
+	}
+	if cleared {
+		cfg.Watch.LastIndexTime = time.Time{}
+		cfg.Watch.LastIndexGit = nil
+	}
+
 	// Initialize ignore matcher
 	ignoreMatcher, err := indexer.NewIgnoreMatcher(projectRoot, cfg.Ignore, cfg.ExternalGitignore)
 	if err != nil {
@@ -962,13 +1068,27 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 	}
 
 	// Initialize scanner
-	scanner := indexer.NewScanner(projectRoot, ignoreMatcher)
+	scanner := newScanner(projectRoot, ignoreMatcher, cfg.Index)
 
 	// Initialize chunker
-	chunker := indexer.NewChunker(cfg.Chunking.Size, cfg.Chunking.Overlap)
+	chunker := newChunker(cfg.Chunking)
 
 	// Initialize indexer
 	idx := indexer.NewIndexer(projectRoot, st, emb, chunker, scanner, cfg.Watch.LastIndexTime)
+	if cache := openEmbeddingCache(ctx, cfg.Embedder); cache != nil {
+		defer cache.Close()
+		idx.SetEmbeddingCache(cache)
+	}
+	if cfg.Chunking.ContextHeaders {
+		idx.EnableContextHeaders()
+	}
+	redactor, err := newRedactor(cfg.Redaction, cfg.Embedder.Provider, projectRoot)
+	if err != nil {
+		return err
+	}
+	if redactor != nil {
+		idx.SetRedactor(redactor)
+	}
 
 	// Initialize symbol store and extractor
 	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(projectRoot))
@@ -983,7 +1103,10 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 	var rpgEncoder *rpg.RPGEncoder
 	var rpgStore rpg.RPGStore
 	if cfg.RPG.Enabled {
-		rpgStore = rpg.NewGOBRPGStore(config.GetRPGIndexPath(projectRoot))
+		rpgStore, err = rpg.NewStoreFromConfig(ctx, cfg, projectRoot)
+		if err != nil {
+			return fmt.Errorf("failed to open RPG store: %w", err)
+		}
 		if err := rpgStore.Load(ctx); err != nil {
 			log.Printf("Warning: failed to load RPG index for %s: %v", projectRoot, err)
 		}
@@ -1012,6 +1135,7 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 			MaxTraversalDepth:    cfg.RPG.MaxTraversalDepth,
 			FeatureGroupStrategy: cfg.RPG.FeatureGroupStrategy,
 		})
+		idx.SetFeaturePathFunc(rpgEncoder.FeaturePathForFile)
 	}
 
 	if rpgStore != nil {
@@ -1025,13 +1149,14 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 	// In multi-worktree mode callers pass isBackgroundChild=true for non-interactive output.
 	// Run initial scan and build symbol index.
 	// In multi-worktree mode callers pass isBackgroundChild=true for non-interactive output.
-	stats, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, tracedLanguages, cfg.Watch.LastIndexTime, isBackgroundChild, onScan, onEmbed)
+	selection := indexSelection(ignoreMatcher, cfg.Index)
+	changes := gitStartupChanges(projectRoot, cfg.Watch, ignoreMatcher, selection)
+	stats, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, tracedLanguages, cfg.Watch.LastIndexTime, changes, isBackgroundChild, onScan, onEmbed)
 	if err != nil {
 		return err
 	}
 
-	if stats.FilesIndexed > 0 || stats.ChunksCreated > 0 {
-		cfg.Watch.LastIndexTime = time.Now()
+	if updateIndexState(cfg, projectRoot, selection, stats) {
 		if err := cfg.Save(projectRoot); err != nil {
 			log.Printf("Warning: failed to save config: %v", err)
 		}
@@ -1062,6 +1187,7 @@ func watchProjectWithEventObserver(ctx context.Context, projectRoot string, emb
 	if err != nil {
 		return fmt.Errorf("failed to initialize watcher for %s: %w", projectRoot, err)
 	}
+	w.SetFileFilter(newFileFilter(cfg.Index))
 	defer w.Close()
 
 	if err := w.Start(ctx); err != nil {
@@ -1111,10 +1237,18 @@ func emitInitialStatsSnapshot(ctx context.Context, vectorStore store.VectorStore
 	}
 }
 
-func runProjectWatchLoop(ctx context.Context, st store.VectorStore, symbolStore *trace.GOBSymbolStore, w *watcher.Watcher, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, rpgEncoder *rpg.RPGEncoder, rpgStore rpg.RPGStore, tracedLanguages []string, projectRoot string, cfg *config.Config, onEvent watchEventObserver, onActivity watchActivityObserver, onStats watchStatsObserver) error {
+func runProjectWatchLoop(ctx context.Context, st store.VectorStore, symbolStore trace.ContentHashSymbolStore, w *watcher.Watcher, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, rpgEncoder *rpg.RPGEncoder, rpgStore rpg.RPGStore, tracedLanguages []string, projectRoot string, cfg *config.Config, onEvent watchEventObserver, onActivity watchActivityObserver, onStats watchStatsObserver) error {
 	persistTicker := time.NewTicker(30 * time.Second)
 	defer persistTicker.Stop()
 
+	// A nil channel never fires, leaving periodic GC disabled.
+	var gcTick <-chan time.Time
+	if cfg.Watch.GCIntervalSec > 0 {
+		gcTicker := time.NewTicker(time.Duration(cfg.Watch.GCIntervalSec) * time.Second)
+		defer gcTicker.Stop()
+		gcTick = gcTicker.C
+	}
+
 	var lastConfigWrite time.Time
 	var rpgManager *rpgRealtimeManager
 	if rpgEncoder != nil && rpgStore != nil {
@@ -1128,6 +1262,7 @@ func runProjectWatchLoop(ctx context.Context, st store.VectorStore, symbolStore
 			if err := st.Persist(ctx); err != nil {
 				log.Printf("Warning: failed to persist index on shutdown for %s: %v", projectRoot, err)
 			}
+			saveGitIndexState(cfg, projectRoot)
 			if err := symbolStore.Persist(ctx); err != nil {
 				log.Printf("Warning: failed to persist symbol index on shutdown for %s: %v", projectRoot, err)
 			}
@@ -1151,6 +1286,9 @@ func runProjectWatchLoop(ctx context.Context, st store.VectorStore, symbolStore
 				}
 			}
 
+		case <-gcTick:
+			runWatchGC(ctx, st, scanner, projectRoot)
+
 		case event := <-w.Events():
 			if onEvent != nil {
 				onEvent(projectRoot, event)
@@ -1970,7 +2108,7 @@ func runWatchForeground() error {
 	)
 }
 
-func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore *trace.GOBSymbolStore, rpgEncoder *rpg.RPGEncoder, vectorStore store.VectorStore, enabledLanguages []string, projectRoot string, cfg *config.Config, lastConfigWrite *time.Time, rpgManager *rpgRealtimeManager, event watcher.FileEvent, onActivity watchActivityObserver, onStats watchStatsObserver) {
+func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore trace.ContentHashSymbolStore, rpgEncoder *rpg.RPGEncoder, vectorStore store.VectorStore, enabledLanguages []string, projectRoot string, cfg *config.Config, lastConfigWrite *time.Time, rpgManager *rpgRealtimeManager, event watcher.FileEvent, onActivity watchActivityObserver, onStats watchStatsObserver) {
 	if onActivity != nil {
 		op := "processing"
 		if event.Type == watcher.EventDelete {
@@ -2042,6 +2180,7 @@ func handleFileEvent(ctx context.Context, idx *indexer.Indexer, scanner *indexer
 		now := time.Now()
 		if now.Sub(*lastConfigWrite) >= configWriteThrottle {
 			cfg.Watch.LastIndexTime = now
+			refreshGitIndexState(cfg, projectRoot)
 			if err := cfg.Save(projectRoot); err != nil {
 				log.Printf("Warning: failed to save config: %v", err)
 			}
@@ -2324,6 +2463,9 @@ func startBackgroundWorkspaceWatch(logDir string, ws *config.Workspace) error {
 	if watchLogDir != "" {
 		extraArgs = append(extraArgs, "--log-dir", watchLogDir)
 	}
+	if watchReindex {
+		extraArgs = append(extraArgs, "--reindex")
+	}
 
 	// Spawn background process
 	childPID, exitCh, err := daemon.SpawnWorkspaceBackground(logDir, ws.Name, extraArgs)
@@ -2414,6 +2556,17 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 	}
 	defer st.Close()
 
+	var embCache store.EmbeddingCache
+	if cache := openEmbeddingCache(ctx, ws.Embedder); cache != nil {
+		defer cache.Close()
+		embCache = cache
+	}
+
+	// Projects may use different chunking, so only the embedder is recorded.
+	if _, err := ensureIndexManifest(ctx, st, store.NewManifest(ws.Embedder, config.ChunkingConfig{}, version), watchReindex); err != nil {
+		return err
+	}
+
 	runtimes := make(map[string]*workspaceProjectRuntime, len(ws.Projects))
 	watchers := make([]*watcher.Watcher, 0, len(ws.Projects))
 
@@ -2424,7 +2577,7 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 			log.Printf("Indexing project: %s (%s)", project.Name, project.Path)
 		}
 
-		runtime, w, rtErr := initializeWorkspaceRuntime(ctx, ws, project, emb, st, isBackgroundChild)
+		runtime, w, rtErr := initializeWorkspaceRuntime(ctx, ws, project, emb, st, embCache, isBackgroundChild)
 		if rtErr != nil {
 			log.Printf("Warning: failed to initialize runtime for %s: %v", project.Name, rtErr)
 			continue
@@ -2502,6 +2655,7 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 			if err := runtime.symbolStore.Persist(ctx); err != nil {
 				log.Printf("Warning: failed to persist symbol index on shutdown for %s: %v", runtime.project.Name, err)
 			}
+			saveGitIndexState(runtime.cfg, runtime.project.Path)
 			if runtime.rpgStore != nil {
 				if err := runtime.rpgStore.Persist(ctx); err != nil {
 					log.Printf("Warning: failed to persist RPG graph on shutdown for %s: %v", runtime.project.Name, err)
@@ -2514,6 +2668,14 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 	persistTicker := time.NewTicker(30 * time.Second)
 	defer persistTicker.Stop()
 
+	// A nil channel never fires, leaving periodic GC disabled.
+	var gcTick <-chan time.Time
+	if interval := workspaceGCInterval(runtimes); interval > 0 {
+		gcTicker := time.NewTicker(interval)
+		defer gcTicker.Stop()
+		gcTick = gcTicker.C
+	}
+
 	for {
 		select {
 		case <-sigChan:
@@ -2545,6 +2707,9 @@ func runWorkspaceWatchForeground(logDir string, ws *config.Workspace) error {
 				}
 			}
 
+		case <-gcTick:
+			runWorkspaceWatchGC(ctx, st, ws, runtimes)
+
 		case event := <-eventChan:
 			projectKey := canonicalPath(event.projectPath)
 			runtime := runtimes[projectKey]
@@ -2584,7 +2749,7 @@ type workspaceProjectRuntime struct {
 	idx             *indexer.Indexer
 	scanner         *indexer.Scanner
 	extractor       *trace.RegexExtractor
-	symbolStore     *trace.GOBSymbolStore
+	symbolStore     trace.ContentHashSymbolStore
 	rpgEncoder      *rpg.RPGEncoder
 	rpgStore        rpg.RPGStore
 	vectorStore     store.VectorStore
@@ -2594,7 +2759,7 @@ type workspaceProjectRuntime struct {
 	watcher         *watcher.Watcher
 }
 
-func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, project config.ProjectEntry, emb embedder.Embedder, sharedStore store.VectorStore, isBackgroundChild bool) (*workspaceProjectRuntime, *watcher.Watcher, error) {
+func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, project config.ProjectEntry, emb embedder.Embedder, sharedStore store.VectorStore, embCache store.EmbeddingCache, isBackgroundChild bool) (*workspaceProjectRuntime, *watcher.Watcher, error) {
 	projectCfg := config.DefaultConfig()
 	if config.Exists(project.Path) {
 		loadedCfg, err := config.Load(project.Path)
@@ -2610,8 +2775,8 @@ func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, proje
 		return nil, nil, fmt.Errorf("failed to initialize ignore matcher: %w", err)
 	}
 
-	scanner := indexer.NewScanner(project.Path, ignoreMatcher)
-	chunker := indexer.NewChunker(projectCfg.Chunking.Size, projectCfg.Chunking.Overlap)
+	scanner := newScanner(project.Path, ignoreMatcher, projectCfg.Index)
+	chunker := newChunker(projectCfg.Chunking)
 	vectorStore := &projectPrefixStore{
 		store:         sharedStore,
 		workspaceName: ws.Name,
@@ -2619,10 +2784,23 @@ func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, proje
 		projectPath:   project.Path,
 	}
 	idx := indexer.NewIndexer(project.Path, vectorStore, emb, chunker, scanner, projectCfg.Watch.LastIndexTime)
+	if embCache != nil {
+		idx.SetEmbeddingCache(embCache)
+	}
+	if projectCfg.Chunking.ContextHeaders {
+		idx.EnableContextHeaders()
+	}
+	redactor, err := newRedactor(projectCfg.Redaction, ws.Embedder.Provider, project.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if redactor != nil {
+		idx.SetRedactor(redactor)
+	}
 	extractor := trace.NewRegexExtractor()
-	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(project.Path))
-	if err := symbolStore.Load(ctx); err != nil {
-		log.Printf("Warning: failed to load symbol index for %s: %v", project.Path, err)
+	symbolStore, err := initializeWorkspaceSymbolStore(ctx, ws, project)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	tracedLanguages := projectCfg.Trace.EnabledLanguages
@@ -2630,13 +2808,14 @@ func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, proje
 		tracedLanguages = []string{".go", ".js", ".ts", ".jsx", ".tsx", ".py", ".php", ".java", ".cs", ".fs", ".fsx", ".fsi"}
 	}
 
-	stats, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, tracedLanguages, projectCfg.Watch.LastIndexTime, isBackgroundChild, nil, nil)
+	selection := indexSelection(ignoreMatcher, projectCfg.Index)
+	changes := gitStartupChanges(project.Path, projectCfg.Watch, ignoreMatcher, selection)
+	stats, err := runInitialScan(ctx, idx, scanner, extractor, symbolStore, tracedLanguages, projectCfg.Watch.LastIndexTime, changes, isBackgroundChild, nil, nil)
 	if err != nil {
 		_ = symbolStore.Close()
 		return nil, nil, err
 	}
-	if stats.FilesIndexed > 0 || stats.ChunksCreated > 0 {
-		projectCfg.Watch.LastIndexTime = time.Now()
+	if updateIndexState(projectCfg, project.Path, selection, stats) {
 		if err := projectCfg.Save(project.Path); err != nil {
 			log.Printf("Warning: failed to save config for %s: %v", project.Name, err)
 		}
@@ -2646,7 +2825,11 @@ func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, proje
 	var rpgEncoder *rpg.RPGEncoder
 	var manager *rpgRealtimeManager
 	if projectCfg.RPG.Enabled {
-		rpgStore = rpg.NewGOBRPGStore(config.GetRPGIndexPath(project.Path))
+		rpgStore, err = rpg.NewStoreFromConfig(ctx, projectCfg, project.Path)
+		if err != nil {
+			_ = symbolStore.Close()
+			return nil, nil, fmt.Errorf("failed to open RPG store for %s: %w", project.Name, err)
+		}
 		if err := rpgStore.Load(ctx); err != nil {
 			log.Printf("Warning: failed to load RPG index for %s: %v", project.Path, err)
 		}
@@ -2675,6 +2858,7 @@ func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, proje
 			MaxTraversalDepth:    projectCfg.RPG.MaxTraversalDepth,
 			FeatureGroupStrategy: projectCfg.RPG.FeatureGroupStrategy,
 		})
+		idx.SetFeaturePathFunc(rpgEncoder.FeaturePathForFile)
 		if err := rpgEncoder.BuildFull(ctx, symbolStore, vectorStore, nil); err != nil {
 			log.Printf("Warning: failed to build RPG graph for %s: %v", project.Path, err)
 		}
@@ -2694,6 +2878,7 @@ func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, proje
 		_ = symbolStore.Close()
 		return nil, nil, fmt.Errorf("failed to create watcher: %w", err)
 	}
+	w.SetFileFilter(newFileFilter(projectCfg.Index))
 	if err := w.Start(ctx); err != nil {
 		w.Close()
 		if rpgStore != nil {
@@ -2720,6 +2905,36 @@ func initializeWorkspaceRuntime(ctx context.Context, ws *config.Workspace, proje
 	return runtime, w, nil
 }
 
+// ensureIndexManifest verifies that the index was built with the configured
+// embedder and records the manifest. With reindex set, the index is cleared
+// first so every file gets embedded again; the returned bool reports whether
+// that happened.
+func ensureIndexManifest(ctx context.Context, st store.VectorStore, active store.IndexManifest, reindex bool) (bool, error) {
+	stored, err := store.CheckManifest(ctx, st, active)
+	var mismatch *store.ManifestMismatchError
+	if err != nil && (!reindex || !errors.As(err, &mismatch)) {
+		return false, err
+	}
+
+	if reindex {
+		log.Printf("Clearing index for re-index with embedder %s", active.EmbedderString())
+		if err := store.ClearStore(ctx, st); err != nil {
+			return false, fmt.Errorf("failed to clear index: %w", err)
+		}
+	} else if stored != nil && !stored.ChunkingMatches(active) {
+		log.Printf("Warning: chunking settings changed (%s -> %s); existing files keep their old chunks until 'grepai watch --reindex'",
+			stored.ChunkingString(), active.ChunkingString())
+		// The manifest describes the chunks on disk, so keep the old values.
+		active.ChunkSize, active.ChunkOverlap, active.ChunkStrategy = stored.ChunkSize, stored.ChunkOverlap, stored.ChunkStrategy
+		active.ContextHeaders = stored.ContextHeaders
+	}
+
+	if err := store.SaveManifest(ctx, st, active); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	return reindex, nil
+}
+
 func initializeWorkspaceStore(ctx context.Context, ws *config.Workspace) (store.VectorStore, error) {
 	// Use workspace name as project ID for shared store
 	projectID := "workspace:" + ws.Name
@@ -2738,6 +2953,30 @@ func initializeWorkspaceStore(ctx context.Context, ws *config.Workspace) (store.
 	}
 }
 
+// initializeWorkspaceSymbolStore opens the symbol index of a workspace
+// project. Postgres workspaces keep symbols in the shared database so
+// cross-project traces can be answered from any machine; otherwise the
+// project's local symbols.gob is used.
+func initializeWorkspaceSymbolStore(ctx context.Context, ws *config.Workspace, project config.ProjectEntry) (trace.ContentHashSymbolStore, error) {
+	if ws.Store.Backend == "postgres" {
+		symbolStore, err := trace.NewPostgresSymbolStore(ctx, ws.Store.Postgres.DSN, ws.SymbolProjectID(project.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open symbol store: %w", err)
+		}
+		if err := symbolStore.Load(ctx); err != nil {
+			_ = symbolStore.Close()
+			return nil, fmt.Errorf("failed to load symbol index: %w", err)
+		}
+		return symbolStore, nil
+	}
+
+	symbolStore := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(project.Path))
+	if err := symbolStore.Load(ctx); err != nil {
+		log.Printf("Warning: failed to load symbol index for %s: %v", project.Path, err)
+	}
+	return symbolStore, nil
+}
+
 // projectPrefixStore wraps a VectorStore to prefix file paths with workspace and project name
 type projectPrefixStore struct {
 	store         store.VectorStore
@@ -2839,3 +3078,17 @@ func (p *projectPrefixStore) GetChunksForFile(ctx context.Context, filePath stri
 func (p *projectPrefixStore) GetAllChunks(ctx context.Context) ([]store.Chunk, error) {
 	return p.store.GetAllChunks(ctx)
 }
+
+func (p *projectPrefixStore) GetManifest(ctx context.Context) (*store.IndexManifest, error) {
+	if ms, ok := p.store.(store.ManifestStore); ok {
+		return ms.GetManifest(ctx)
+	}
+	return nil, nil
+}
+
+func (p *projectPrefixStore) SaveManifest(ctx context.Context, manifest store.IndexManifest) error {
+	if ms, ok := p.store.(store.ManifestStore); ok {
+		return ms.SaveManifest(ctx, manifest)
+	}
+	return nil
+}
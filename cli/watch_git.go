@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/git"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// indexSelection fingerprints the settings choosing which files are indexed:
+// the ignore rules and the index section of the configuration.
+func indexSelection(ignoreMatcher *indexer.IgnoreMatcher, cfg config.IndexConfig) string {
+	settings, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(ignoreMatcher.Fingerprint() + "\n" + string(settings)))
+	return hex.EncodeToString(sum[:])
+}
+
+// gitStartupChanges returns the files to check at startup when the git state
+// recorded by the last index run can be trusted: the files changed since the
+// recorded commit, in commits or in the working tree, and those that already
+// differed from it then. It returns nil when the whole project must be
+// scanned, including when git can't see every indexed file change: files
+// that .gitignore excludes but a .grepaiignore re-includes, and files inside
+// submodules.
+func gitStartupChanges(projectRoot string, watchCfg config.WatchConfig, ignoreMatcher *indexer.IgnoreMatcher, selection string) []indexer.FileChange {
+	state := watchCfg.LastIndexGit
+	if state == nil || watchCfg.LastIndexTime.IsZero() {
+		return nil
+	}
+	if state.Selection != selection {
+		log.Printf("File selection settings changed since the last index run, scanning all files")
+		return nil
+	}
+	if ignoreMatcher.HasNegations() {
+		return nil
+	}
+	if hasSubmodules, err := git.HasSubmodules(projectRoot); err != nil || hasSubmodules {
+		return nil
+	}
+
+	changed, err := git.ChangedFiles(projectRoot, state.Commit)
+	if err != nil {
+		log.Printf("Warning: failed to list files changed since %s, scanning all files: %v", shortCommit(state.Commit), err)
+		return nil
+	}
+
+	changes := make([]indexer.FileChange, 0, len(changed)+len(state.Dirty))
+	seen := make(map[string]bool, len(changed))
+	for _, c := range changed {
+		change := indexer.FileChange{Path: filepath.FromSlash(c.Path)}
+		if c.Status == git.Renamed {
+			change.OldPath = filepath.FromSlash(c.OldPath)
+			seen[change.OldPath] = true
+		}
+		seen[change.Path] = true
+		changes = append(changes, change)
+	}
+	for _, path := range state.Dirty {
+		path = filepath.FromSlash(path)
+		if !seen[path] {
+			seen[path] = true
+			changes = append(changes, indexer.FileChange{Path: path})
+		}
+	}
+	return changes
+}
+
+// currentGitIndexState returns the git state to record once the index is up
+// to date, or nil outside a git repository.
+func currentGitIndexState(projectRoot, selection string) *config.GitIndexState {
+	commit, err := git.HeadCommit(projectRoot)
+	if err != nil {
+		return nil
+	}
+	changed, err := git.ChangedFiles(projectRoot, commit)
+	if err != nil {
+		log.Printf("Warning: failed to list uncommitted changes: %v", err)
+		return nil
+	}
+
+	dirty := make([]string, 0, len(changed))
+	for _, c := range changed {
+		dirty = append(dirty, c.Path)
+		if c.OldPath != "" {
+			dirty = append(dirty, c.OldPath)
+		}
+	}
+	sort.Strings(dirty)
+	state := &config.GitIndexState{Commit: commit, Selection: selection}
+	if len(dirty) > 0 {
+		state.Dirty = slices.Compact(dirty)
+	}
+	return state
+}
+
+// refreshGitIndexState records the current git state in cfg, keeping the
+// selection fingerprint computed at startup. It does nothing when no state
+// is recorded, as after an incomplete initial scan.
+func refreshGitIndexState(cfg *config.Config, projectRoot string) {
+	if cfg.Watch.LastIndexGit == nil {
+		return
+	}
+	cfg.Watch.LastIndexGit = currentGitIndexState(projectRoot, cfg.Watch.LastIndexGit.Selection)
+}
+
+// saveGitIndexState records the current git state in the configuration, so
+// changes made after the last indexed event are picked up on the next
+// startup.
+func saveGitIndexState(cfg *config.Config, projectRoot string) {
+	if cfg.Watch.LastIndexGit == nil {
+		return
+	}
+	refreshGitIndexState(cfg, projectRoot)
+	if err := cfg.Save(projectRoot); err != nil {
+		log.Printf("Warning: failed to save config: %v", err)
+	}
+}
+
+// updateIndexState records in cfg that the index is up to date after the
+// initial scan, and reports whether cfg changed. No git state is recorded
+// when some files failed, so the next startup scans the whole project again.
+func updateIndexState(cfg *config.Config, projectRoot, selection string, stats *indexer.IndexStats) bool {
+	changed := false
+	if stats.FilesIndexed > 0 || stats.ChunksCreated > 0 {
+		cfg.Watch.LastIndexTime = time.Now()
+		changed = true
+	}
+
+	if stats.SkipCounts != nil {
+		counts := make(map[string]int, len(stats.SkipCounts))
+		for reason, n := range stats.SkipCounts {
+			counts[string(reason)] = n
+		}
+		if !reflect.DeepEqual(counts, cfg.Watch.LastSkipCounts) {
+			cfg.Watch.LastSkipCounts = counts
+			changed = true
+		}
+	}
+
+	var state *config.GitIndexState
+	if stats.FilesFailed == 0 {
+		state = currentGitIndexState(projectRoot, selection)
+	}
+	if !reflect.DeepEqual(state, cfg.Watch.LastIndexGit) {
+		cfg.Watch.LastIndexGit = state
+		changed = true
+	}
+	return changed
+}
+
+// forgetGitIndexState drops the recorded git state, so the next watch run
+// scans the whole project. Files removed from the index to be re-indexed
+// need it, as git does not report them as changed.
+func forgetGitIndexState(projectRoot string) error {
+	cfg, err := config.Load(projectRoot)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.Watch.LastIndexGit == nil {
+		return nil
+	}
+	cfg.Watch.LastIndexGit = nil
+	if err := cfg.Save(projectRoot); err != nil {
+		return fmt.Errorf("failed to update configuration: %w", err)
+	}
+	return nil
+}
+
+// shortCommit abbreviates a commit hash for messages.
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// indexChangedSymbols updates the symbol index for the given files only and
+// returns the number of symbols extracted. Renamed files keep their symbols
+// when the store can move them.
+func indexChangedSymbols(ctx context.Context, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore trace.ContentHashSymbolStore, tracedLanguages []string, changes []indexer.FileChange) int {
+	symbolCount := 0
+	for _, change := range changes {
+		if change.OldPath != "" && change.OldPath != change.Path {
+			renamer, ok := symbolStore.(trace.FileRenamer)
+			if ok && isTracedFile(change.OldPath, tracedLanguages) && isTracedFile(change.Path, tracedLanguages) {
+				if err := renamer.RenameFile(ctx, change.OldPath, change.Path); err != nil {
+					log.Printf("Warning: failed to move symbols of %s: %v", change.OldPath, err)
+				}
+			}
+			symbolCount += updateFileSymbols(ctx, scanner, extractor, symbolStore, tracedLanguages, change.OldPath)
+		}
+		symbolCount += updateFileSymbols(ctx, scanner, extractor, symbolStore, tracedLanguages, change.Path)
+	}
+	return symbolCount
+}
+
+// updateFileSymbols extracts the symbols of the file at path when its
+// content hash differs from the stored one, and drops them when the file can
+// no longer be indexed. It returns the number of symbols extracted.
+func updateFileSymbols(ctx context.Context, scanner *indexer.Scanner, extractor *trace.RegexExtractor, symbolStore trace.ContentHashSymbolStore, tracedLanguages []string, path string) int {
+	if !isTracedFile(path, tracedLanguages) {
+		return 0
+	}
+
+	var fileInfo *indexer.FileInfo
+	if scanner.Matches(path) {
+		var err error
+		fileInfo, err = scanner.ScanFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Warning: failed to scan %s for symbols: %v", path, err)
+			return 0
+		}
+	}
+	if fileInfo == nil {
+		if symbolStore.IsFileIndexed(path) {
+			if err := symbolStore.DeleteFile(ctx, path); err != nil {
+				log.Printf("Warning: failed to delete symbols for %s: %v", path, err)
+			}
+		}
+		return 0
+	}
+
+	// Skip extraction when content hash matches what we already persisted.
+	if existingHash, ok := symbolStore.GetFileContentHash(fileInfo.Path); ok && existingHash == fileInfo.Hash {
+		return 0
+	}
+
+	symbols, refs, err := extractor.ExtractAll(ctx, fileInfo.Path, fileInfo.Content)
+	if err != nil {
+		log.Printf("Warning: failed to extract symbols from %s: %v", fileInfo.Path, err)
+		return 0
+	}
+	if err := symbolStore.SaveFileWithContentHash(ctx, fileInfo.Path, fileInfo.Hash, symbols, refs); err != nil {
+		log.Printf("Warning: failed to save symbols for %s: %v", fileInfo.Path, err)
+	}
+	return len(symbols)
+}
+
+// isTracedFile reports whether the symbols of the file at path are indexed.
+func isTracedFile(path string, tracedLanguages []string) bool {
+	return isTracedLanguage(strings.ToLower(filepath.Ext(path)), tracedLanguages)
+}
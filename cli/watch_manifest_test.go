@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/indexer"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func TestEnsureIndexManifest(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := st.SaveChunks(ctx, []store.Chunk{{ID: "a_0", FilePath: "a.go", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := st.SaveDocument(ctx, store.Document{Path: "a.go", ChunkIDs: []string{"a_0"}}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	chunking := config.ChunkingConfig{Size: 512, Overlap: 50}
+	nomic := store.NewManifest(config.EmbedderConfig{Provider: "ollama", Model: "nomic-embed-text"}, chunking, "test")
+	mxbai := store.NewManifest(config.EmbedderConfig{Provider: "ollama", Model: "mxbai-embed-large"}, chunking, "test")
+
+	cleared, err := ensureIndexManifest(ctx, st, nomic, false)
+	if err != nil || cleared {
+		t.Fatalf("first run: cleared=%v err=%v", cleared, err)
+	}
+
+	_, err = ensureIndexManifest(ctx, st, mxbai, false)
+	var mismatch *store.ManifestMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected ManifestMismatchError, got %v", err)
+	}
+
+	cleared, err = ensureIndexManifest(ctx, st, mxbai, true)
+	if err != nil || !cleared {
+		t.Fatalf("reindex: cleared=%v err=%v", cleared, err)
+	}
+	if docs, chunks := st.Stats(); docs != 0 || chunks != 0 {
+		t.Errorf("expected reindex to clear the store, got %d docs and %d chunks", docs, chunks)
+	}
+	stored, err := st.GetManifest(ctx)
+	if err != nil || stored == nil || stored.Model != "mxbai-embed-large" {
+		t.Errorf("expected manifest for new embedder, got %+v, %v", stored, err)
+	}
+}
+
+func TestEnsureIndexManifestKeepsStoredChunking(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	emb := config.EmbedderConfig{Provider: "ollama", Model: "nomic-embed-text"}
+
+	old := store.NewManifest(emb, config.ChunkingConfig{Size: 512, Overlap: 50}, "test")
+	if _, err := ensureIndexManifest(ctx, st, old, false); err != nil {
+		t.Fatalf("first run failed: %v", err)
+	}
+
+	changed := store.NewManifest(emb, config.ChunkingConfig{Size: 256, Overlap: 20, ContextHeaders: true}, "test")
+	if _, err := ensureIndexManifest(ctx, st, changed, false); err != nil {
+		t.Fatalf("second run failed: %v", err)
+	}
+
+	stored, err := st.GetManifest(ctx)
+	if err != nil || stored == nil {
+		t.Fatalf("expected a stored manifest, got %+v, %v", stored, err)
+	}
+	if !stored.ChunkingMatches(old) {
+		t.Errorf("stored chunking = %s, want the chunking of the existing chunks %s", stored.ChunkingString(), old.ChunkingString())
+	}
+}
+
+func TestEffectiveChunking(t *testing.T) {
+	cfg := config.ChunkingConfig{Size: 512, Overlap: 50, Strategy: config.ChunkingStrategyAST}
+	want := config.ChunkingStrategyFixed
+	if indexer.ASTChunkingSupported {
+		want = config.ChunkingStrategyAST
+	}
+	if got := effectiveChunking(cfg).Strategy; got != want {
+		t.Errorf("effectiveChunking strategy = %q, want %q", got, want)
+	}
+
+	cfg.Strategy = config.ChunkingStrategyFixed
+	if got := effectiveChunking(cfg).Strategy; got != config.ChunkingStrategyFixed {
+		t.Errorf("effectiveChunking changed fixed strategy to %q", got)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/daemon"
@@ -277,3 +278,50 @@ func TestPrintProgressAndBatchProgress(t *testing.T) {
 		CompletedChunks: 5,
 	})
 }
+
+func TestWorkspaceIndexableFiles(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ignoreMatcher, err := indexer.NewIgnoreMatcher(root, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtimes := map[string]*workspaceProjectRuntime{
+		root: {
+			project: config.ProjectEntry{Name: "api", Path: root},
+			scanner: newScanner(root, ignoreMatcher, config.IndexConfig{}),
+		},
+	}
+
+	fileExists, err := workspaceIndexableFiles("ws", runtimes)
+	if err != nil {
+		t.Fatalf("workspaceIndexableFiles failed: %v", err)
+	}
+	for path, want := range map[string]bool{
+		"ws/api/main.go":    true,
+		"ws/api/deleted.go": false,
+		"ws/web/deleted.go": true, // project without a runtime
+	} {
+		if got := fileExists(path); got != want {
+			t.Errorf("fileExists(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestWorkspaceGCInterval(t *testing.T) {
+	runtime := func(sec int) *workspaceProjectRuntime {
+		cfg := config.DefaultConfig()
+		cfg.Watch.GCIntervalSec = sec
+		return &workspaceProjectRuntime{cfg: cfg}
+	}
+
+	if got := workspaceGCInterval(map[string]*workspaceProjectRuntime{"a": runtime(0)}); got != 0 {
+		t.Errorf("expected periodic GC disabled, got %v", got)
+	}
+	runtimes := map[string]*workspaceProjectRuntime{"a": runtime(0), "b": runtime(600), "c": runtime(120)}
+	if got := workspaceGCInterval(runtimes); got != 120*time.Second {
+		t.Errorf("expected the shortest interval, got %v", got)
+	}
+}
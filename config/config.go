@@ -1,9 +1,12 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,11 +15,16 @@ import (
 )
 
 const (
-	ConfigDir           = ".grepai"
-	ConfigFileName      = "config.yaml"
-	IndexFileName       = "index.gob"
-	SymbolIndexFileName = "symbols.gob"
-	RPGIndexFileName    = "rpg.gob"
+	ConfigDir            = ".grepai"
+	ConfigFileName       = "config.yaml"
+	IndexFileName        = "index.gob"
+	SQLiteIndexFileName  = "index.db"
+	SymbolIndexFileName  = "symbols.gob"
+	RPGIndexFileName     = "rpg.gob"
+	RedactionLogFileName = "redactions.log"
+
+	EmbeddingCacheDir      = "cache"
+	EmbeddingCacheFileName = "embeddings.db"
 
 	DefaultEmbedderProvider         = "ollama"
 	DefaultOllamaEmbeddingModel     = "nomic-embed-text"
@@ -38,6 +46,12 @@ const (
 	DefaultQdrantEndpoint = "localhost"
 	DefaultQdrantPort     = 6334
 
+	// HNSW (approximate nearest neighbour) defaults for the GOB store.
+	DefaultHNSWM              = 16
+	DefaultHNSWEfConstruction = 200
+	DefaultHNSWEfSearch       = 64
+	DefaultHNSWMinChunks      = 10000
+
 	// RPG default configuration values.
 	DefaultRPGDriftThreshold       = 0.35
 	DefaultRPGMaxTraversalDepth    = 3
@@ -45,6 +59,14 @@ const (
 	DefaultRPGFeatureMode          = "local"
 	DefaultRPGFeatureGroupStrategy = "sample"
 
+	// Search re-ranking defaults.
+	DefaultRerankProvider  = "heuristic"
+	DefaultRerankTopN      = 20
+	DefaultRerankTimeoutMs = 10000
+
+	// Search diversification default: relevance weight of MMR.
+	DefaultDiversityLambda = 0.7
+
 	// Watch defaults for RPG realtime updates.
 	DefaultWatchRPGPersistIntervalMs      = 1000
 	DefaultWatchRPGDerivedDebounceMs      = 300
@@ -53,17 +75,132 @@ const (
 )
 
 type Config struct {
-	Version           int            `yaml:"version"`
-	Embedder          EmbedderConfig `yaml:"embedder"`
-	Store             StoreConfig    `yaml:"store"`
-	Chunking          ChunkingConfig `yaml:"chunking"`
-	Watch             WatchConfig    `yaml:"watch"`
-	Search            SearchConfig   `yaml:"search"`
-	Trace             TraceConfig    `yaml:"trace"`
-	RPG               RPGConfig      `yaml:"rpg"`
-	Update            UpdateConfig   `yaml:"update"`
-	Ignore            []string       `yaml:"ignore"`
-	ExternalGitignore string         `yaml:"external_gitignore,omitempty"`
+	Version           int             `yaml:"version"`
+	Embedder          EmbedderConfig  `yaml:"embedder"`
+	Store             StoreConfig     `yaml:"store"`
+	Chunking          ChunkingConfig  `yaml:"chunking"`
+	Watch             WatchConfig     `yaml:"watch"`
+	Search            SearchConfig    `yaml:"search"`
+	Trace             TraceConfig     `yaml:"trace"`
+	RPG               RPGConfig       `yaml:"rpg"`
+	Index             IndexConfig     `yaml:"index"`
+	Redaction         RedactionConfig `yaml:"redaction,omitempty"`
+	Update            UpdateConfig    `yaml:"update"`
+	Ignore            []string        `yaml:"ignore"`
+	ExternalGitignore string          `yaml:"external_gitignore,omitempty"`
+}
+
+// IndexConfig selects the files to index, on top of the ignore rules.
+type IndexConfig struct {
+	IncludeExtensions []string `yaml:"include_extensions,omitempty"` // indexed in addition to the built-in extensions
+	ExcludeExtensions []string `yaml:"exclude_extensions,omitempty"` // never indexed, even when built in
+	Filenames         []string `yaml:"filenames,omitempty"`          // exact basenames to index, e.g. Dockerfile
+	Shebang           bool     `yaml:"shebang"`                      // index extensionless files starting with #!
+	MaxFileSize       string   `yaml:"max_file_size,omitempty"`      // e.g. 512KB, 4MB (default: 1MB)
+}
+
+// ValidateIndexConfig checks index configuration values for validity.
+func ValidateIndexConfig(cfg IndexConfig) error {
+	if cfg.MaxFileSize != "" {
+		size, err := ParseSize(cfg.MaxFileSize)
+		if err != nil {
+			return fmt.Errorf("index.max_file_size: %w", err)
+		}
+		if size <= 0 {
+			return fmt.Errorf("index.max_file_size must be positive, got %q", cfg.MaxFileSize)
+		}
+	}
+	return nil
+}
+
+// MaxFileSizeBytes returns the configured file size limit in bytes, or 0 when
+// unset or invalid.
+func (c IndexConfig) MaxFileSizeBytes() int64 {
+	size, err := ParseSize(c.MaxFileSize)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// ParseSize parses a size such as "512KB", "4MB" or "1048576" (bytes).
+// Units are powers of 1024 and case-insensitive.
+func ParseSize(size string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(size))
+	if s == "" {
+		return 0, nil
+	}
+
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		factor int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			multiplier = unit.factor
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return n * multiplier, nil
+}
+
+// RedactionConfig controls the removal of secrets from file content before
+// it is chunked, embedded and stored.
+type RedactionConfig struct {
+	Enabled   *bool              `yaml:"enabled,omitempty"`    // default: on for remote embedders only
+	Patterns  []RedactionPattern `yaml:"patterns,omitempty"`   // secret patterns added to the built-in ones
+	Disable   []string           `yaml:"disable,omitempty"`    // built-in patterns to turn off, by name
+	SkipFiles bool               `yaml:"skip_files,omitempty"` // leave files containing secrets out of the index instead
+}
+
+// RedactionPattern is a user-defined secret pattern. When Regex has a group
+// named "secret", only that group is redacted.
+type RedactionPattern struct {
+	Name  string `yaml:"name"`
+	Regex string `yaml:"regex"`
+}
+
+// IsEnabled reports whether redaction applies when embedding with provider.
+func (c RedactionConfig) IsEnabled(provider string) bool {
+	if c.Enabled != nil {
+		return *c.Enabled
+	}
+	return IsRemoteEmbedder(provider)
+}
+
+// IsRemoteEmbedder reports whether provider sends text to a third-party
+// service.
+func IsRemoteEmbedder(provider string) bool {
+	switch provider {
+	case "openai", "openrouter", "synthetic":
+		return true
+	default:
+		return false
+	}
+}
+
+// ValidateRedactionConfig checks redaction configuration values for validity.
+func ValidateRedactionConfig(cfg RedactionConfig) error {
+	for i, p := range cfg.Patterns {
+		if p.Name == "" {
+			return fmt.Errorf("redaction.patterns[%d]: name is required", i)
+		}
+		if _, err := regexp.Compile(p.Regex); err != nil {
+			return fmt.Errorf("redaction.patterns[%d] (%s): invalid regex: %w", i, p.Name, err)
+		}
+	}
+	return nil
 }
 
 // UpdateConfig holds auto-update settings
@@ -72,8 +209,30 @@ type UpdateConfig struct {
 }
 
 type SearchConfig struct {
-	Boost  BoostConfig  `yaml:"boost"`
-	Hybrid HybridConfig `yaml:"hybrid"`
+	Boost     BoostConfig     `yaml:"boost"`
+	Hybrid    HybridConfig    `yaml:"hybrid"`
+	Rerank    RerankConfig    `yaml:"rerank,omitempty"`
+	Diversity DiversityConfig `yaml:"diversity,omitempty"`
+}
+
+// DiversityConfig configures maximal marginal relevance (MMR)
+// diversification, which trades relevance against redundancy when ordering
+// search results.
+type DiversityConfig struct {
+	Enabled bool    `yaml:"enabled"`
+	Lambda  float64 `yaml:"lambda,omitempty"` // relevance weight in (0, 1] (default: 0.7); lower values favour diversity
+}
+
+// RerankConfig configures the re-ranking stage, which re-scores the best
+// candidates of a search after fusion and boosting.
+type RerankConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Provider  string `yaml:"provider,omitempty"`   // heuristic | http | llm
+	TopN      int    `yaml:"top_n,omitempty"`      // candidates re-scored (default: 20)
+	Endpoint  string `yaml:"endpoint,omitempty"`   // http: base URL of the /rerank API; llm: defaults to rpg.llm_endpoint
+	Model     string `yaml:"model,omitempty"`      // llm: defaults to rpg.llm_model
+	APIKey    string `yaml:"api_key,omitempty"`    // llm: defaults to rpg.llm_api_key
+	TimeoutMs int    `yaml:"timeout_ms,omitempty"` // http: default 10000; llm: defaults to rpg.llm_timeout_ms
 }
 
 type HybridConfig struct {
@@ -87,20 +246,76 @@ type BoostConfig struct {
 	Bonuses   []BoostRule `yaml:"bonuses"`
 }
 
+// BoostRule multiplies the score of the results matching all of its
+// conditions by Factor.
 type BoostRule struct {
-	Pattern string  `yaml:"pattern"`
-	Factor  float32 `yaml:"factor"`
+	Pattern        string  `yaml:"pattern,omitempty"`
+	Match          string  `yaml:"match,omitempty"`           // how pattern matches the file path: substring (default) | glob | regex
+	Language       string  `yaml:"language,omitempty"`        // language of the file, e.g. go, python
+	Kind           string  `yaml:"kind,omitempty"`            // kind of a symbol defined in the chunk: function, method, class, interface, type
+	MinSize        int64   `yaml:"min_size,omitempty"`        // minimum file size in bytes
+	MaxSize        int64   `yaml:"max_size,omitempty"`        // maximum file size in bytes
+	ModifiedWithin string  `yaml:"modified_within,omitempty"` // last git change within this period, e.g. 30d, 2w, 12h
+	Factor         float32 `yaml:"factor"`
+}
+
+// Match types of BoostRule patterns.
+const (
+	BoostMatchSubstring = "substring"
+	BoostMatchGlob      = "glob"
+	BoostMatchRegex     = "regex"
+)
+
+// RecencyWindow returns the period of ModifiedWithin, or zero when the rule
+// has no recency condition. It accepts a number of days (d) or weeks (w), or
+// a Go duration such as 12h.
+func (r BoostRule) RecencyWindow() (time.Duration, error) {
+	value := strings.TrimSpace(r.ModifiedWithin)
+	if value == "" {
+		return 0, nil
+	}
+	invalid := fmt.Errorf("invalid modified_within %q (expected e.g. 30d, 2w or 12h)", r.ModifiedWithin)
+
+	switch unit := value[len(value)-1]; unit {
+	case 'd', 'w':
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n <= 0 {
+			return 0, invalid
+		}
+		if unit == 'w' {
+			n *= 7
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, invalid
+	}
+	return d, nil
 }
 
 type EmbedderConfig struct {
-	Provider    string `yaml:"provider"` // ollama | lmstudio | openai | synthetic | openrouter
-	Model       string `yaml:"model"`
-	Endpoint    string `yaml:"endpoint,omitempty"`
-	APIKey      string `yaml:"api_key,omitempty"`
-	Dimensions  *int   `yaml:"dimensions,omitempty"`
-	Parallelism int    `yaml:"parallelism"` // Number of parallel workers for batch embedding (default: 4)
+	Provider    string               `yaml:"provider"` // ollama | lmstudio | openai | synthetic | openrouter
+	Model       string               `yaml:"model"`
+	Endpoint    string               `yaml:"endpoint,omitempty"`
+	APIKey      string               `yaml:"api_key,omitempty"`
+	Dimensions  *int                 `yaml:"dimensions,omitempty"`
+	Parallelism int                  `yaml:"parallelism"` // Number of parallel workers for batch embedding (default: 4)
+	Cache       EmbeddingCacheConfig `yaml:"cache,omitempty"`
 }
 
+// EmbeddingCacheConfig configures the embedding cache shared by all projects
+// under ~/.grepai/cache.
+type EmbeddingCacheConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	MaxSizeMB int  `yaml:"max_size_mb,omitempty"`
+}
+
+// Embedding cache defaults.
+const (
+	DefaultEmbeddingCacheMaxSizeMB = 2048 // least recently used entries are evicted above this size
+)
+
 // GetDimensions returns the configured dimensions or a default value.
 // For OpenAI/OpenRouter, defaults to 1536 (text-embedding-3-small).
 // For Ollama/LMStudio/Synthetic, defaults to 768 (nomic-embed-text-v1.5).
@@ -162,9 +377,25 @@ func DefaultEmbedderForProvider(provider string) EmbedderConfig {
 }
 
 type StoreConfig struct {
-	Backend  string         `yaml:"backend"` // gob | postgres | qdrant
-	Postgres PostgresConfig `yaml:"postgres,omitempty"`
-	Qdrant   QdrantConfig   `yaml:"qdrant,omitempty"`
+	Backend      string         `yaml:"backend"`                // gob | sqlite | postgres | qdrant
+	Quantization string         `yaml:"quantization,omitempty"` // none | int8 | binary (gob and sqlite only)
+	Postgres     PostgresConfig `yaml:"postgres,omitempty"`
+	Qdrant       QdrantConfig   `yaml:"qdrant,omitempty"`
+	GOB          GOBConfig      `yaml:"gob,omitempty"`
+}
+
+type GOBConfig struct {
+	HNSW HNSWConfig `yaml:"hnsw,omitempty"`
+}
+
+// HNSWConfig enables an approximate nearest neighbour index for the GOB store.
+// Indexes smaller than MinChunks keep using exact search.
+type HNSWConfig struct {
+	Enabled        bool `yaml:"enabled"`
+	M              int  `yaml:"m,omitempty"`               // Max neighbours per node
+	EfConstruction int  `yaml:"ef_construction,omitempty"` // Build-time candidate list size
+	EfSearch       int  `yaml:"ef_search,omitempty"`       // Query-time candidate list size
+	MinChunks      int  `yaml:"min_chunks,omitempty"`      // Exact search below this size
 }
 
 type PostgresConfig struct {
@@ -180,8 +411,28 @@ type QdrantConfig struct {
 }
 
 type ChunkingConfig struct {
-	Size    int `yaml:"size"`
-	Overlap int `yaml:"overlap"`
+	Size            int    `yaml:"size"`
+	Overlap         int    `yaml:"overlap"`
+	Strategy        string `yaml:"strategy,omitempty"`         // fixed (default) | ast
+	ContextHeaders  bool   `yaml:"context_headers,omitempty"`  // embed file, language, symbols and feature path with each chunk
+	NotebookOutputs bool   `yaml:"notebook_outputs,omitempty"` // index text outputs of Jupyter code cells
+}
+
+// Chunking strategies.
+const (
+	ChunkingStrategyFixed = "fixed"
+	ChunkingStrategyAST   = "ast"
+)
+
+// ValidateChunkingConfig checks chunking configuration values for validity.
+func ValidateChunkingConfig(cfg ChunkingConfig) error {
+	switch cfg.Strategy {
+	case "", ChunkingStrategyFixed, ChunkingStrategyAST:
+		// valid
+	default:
+		return fmt.Errorf("chunking.strategy must be one of: fixed, ast; got %q", cfg.Strategy)
+	}
+	return nil
 }
 
 func DefaultStoreForBackend(backend string) StoreConfig {
@@ -207,6 +458,23 @@ type WatchConfig struct {
 	RPGDerivedDebounceMs        int       `yaml:"rpg_derived_debounce_ms,omitempty"`
 	RPGFullReconcileIntervalSec int       `yaml:"rpg_full_reconcile_interval_sec,omitempty"`
 	RPGMaxDirtyFilesPerBatch    int       `yaml:"rpg_max_dirty_files_per_batch,omitempty"`
+	GCIntervalSec               int       `yaml:"gc_interval_sec,omitempty"` // 0 disables periodic garbage collection
+	// LastIndexGit records the repository state at LastIndexTime, so the
+	// next startup only indexes the files git reports as changed.
+	LastIndexGit *GitIndexState `yaml:"last_index_git,omitempty"`
+	// LastSkipCounts counts the files the last full scan left out of the
+	// index, by skip reason, for grepai status.
+	LastSkipCounts map[string]int `yaml:"last_skip_counts,omitempty"`
+}
+
+// GitIndexState is the state of a git repository when the index was last
+// brought up to date.
+type GitIndexState struct {
+	Commit string   `yaml:"commit"`
+	Dirty  []string `yaml:"dirty,omitempty"` // files that differed from Commit
+	// Selection fingerprints the settings choosing the indexed files; when
+	// they change, git changes alone can't tell which files to add or drop.
+	Selection string `yaml:"selection"`
 }
 
 type TraceConfig struct {
@@ -217,6 +485,7 @@ type TraceConfig struct {
 
 type RPGConfig struct {
 	Enabled              bool    `yaml:"enabled"`
+	Backend              string  `yaml:"backend,omitempty"` // gob | postgres (uses store.postgres.dsn)
 	StorePath            string  `yaml:"store_path,omitempty"`
 	FeatureMode          string  `yaml:"feature_mode"` // local | hybrid | llm
 	DriftThreshold       float64 `yaml:"drift_threshold"`
@@ -249,6 +518,85 @@ func ValidateRPGConfig(cfg RPGConfig) error {
 	default:
 		return fmt.Errorf("rpg.feature_group_strategy must be one of: sample, split; got %q", cfg.FeatureGroupStrategy)
 	}
+	switch cfg.Backend {
+	case "", "gob", "postgres":
+		// valid
+	default:
+		return fmt.Errorf("rpg.backend must be one of: gob, postgres; got %q", cfg.Backend)
+	}
+	return nil
+}
+
+// ValidateRerankConfig checks search re-ranking configuration values for
+// validity.
+func ValidateRerankConfig(cfg RerankConfig) error {
+	switch cfg.Provider {
+	case "heuristic", "llm":
+		// valid
+	case "http":
+		if cfg.Endpoint == "" {
+			return fmt.Errorf("search.rerank.endpoint is required for the http provider")
+		}
+	default:
+		return fmt.Errorf("search.rerank.provider must be one of: heuristic, http, llm; got %q", cfg.Provider)
+	}
+	if cfg.TopN < 2 || cfg.TopN > 200 {
+		return fmt.Errorf("search.rerank.top_n must be between 2 and 200, got %d", cfg.TopN)
+	}
+	return nil
+}
+
+// ValidateBoostConfig checks search boost rules for validity.
+func ValidateBoostConfig(cfg BoostConfig) error {
+	check := func(section string, rules []BoostRule) error {
+		for i, rule := range rules {
+			if err := validateBoostRule(rule); err != nil {
+				return fmt.Errorf("search.boost.%s[%d]: %w", section, i, err)
+			}
+		}
+		return nil
+	}
+	if err := check("penalties", cfg.Penalties); err != nil {
+		return err
+	}
+	return check("bonuses", cfg.Bonuses)
+}
+
+func validateBoostRule(rule BoostRule) error {
+	if rule.Factor <= 0 {
+		return fmt.Errorf("factor must be greater than 0, got %g", rule.Factor)
+	}
+	if rule.Pattern == "" && rule.Language == "" && rule.Kind == "" && rule.MinSize == 0 && rule.MaxSize == 0 && rule.ModifiedWithin == "" {
+		return fmt.Errorf("at least one of pattern, language, kind, min_size, max_size or modified_within is required")
+	}
+	switch rule.Match {
+	case "", BoostMatchSubstring, BoostMatchGlob:
+		// valid
+	case BoostMatchRegex:
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid regex %q: %w", rule.Pattern, err)
+		}
+	default:
+		return fmt.Errorf("match must be one of: substring, glob, regex; got %q", rule.Match)
+	}
+	if rule.Match != "" && rule.Pattern == "" {
+		return fmt.Errorf("match requires a pattern")
+	}
+	if rule.MinSize < 0 || rule.MaxSize < 0 || (rule.MaxSize > 0 && rule.MinSize > rule.MaxSize) {
+		return fmt.Errorf("invalid size range: min_size %d, max_size %d", rule.MinSize, rule.MaxSize)
+	}
+	if _, err := rule.RecencyWindow(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateDiversityConfig checks search diversification configuration values
+// for validity.
+func ValidateDiversityConfig(cfg DiversityConfig) error {
+	if cfg.Lambda <= 0 || cfg.Lambda > 1 {
+		return fmt.Errorf("search.diversity.lambda must be greater than 0 and at most 1, got %g", cfg.Lambda)
+	}
 	return nil
 }
 
@@ -266,6 +614,9 @@ func ValidateWatchConfig(cfg WatchConfig) error {
 	if cfg.RPGMaxDirtyFilesPerBatch < 1 {
 		return fmt.Errorf("watch.rpg_max_dirty_files_per_batch must be >= 1, got %d", cfg.RPGMaxDirtyFilesPerBatch)
 	}
+	if cfg.GCIntervalSec != 0 && cfg.GCIntervalSec < 60 {
+		return fmt.Errorf("watch.gc_interval_sec must be 0 (disabled) or >= 60, got %d", cfg.GCIntervalSec)
+	}
 	return nil
 }
 
@@ -278,6 +629,10 @@ func DefaultConfig() *Config {
 			Size:    512,
 			Overlap: 50,
 		},
+		Index: IndexConfig{
+			Filenames: []string{"Dockerfile", "Makefile"},
+			Shebang:   true,
+		},
 		Watch: WatchConfig{
 			DebounceMs:                  500,
 			RPGPersistIntervalMs:        DefaultWatchRPGPersistIntervalMs,
@@ -300,7 +655,7 @@ func DefaultConfig() *Config {
 					{Pattern: "_test.", Factor: 0.5},
 					{Pattern: ".test.", Factor: 0.5},
 					{Pattern: ".spec.", Factor: 0.5},
-					{Pattern: "test_", Factor: 0.5},
+					{Pattern: "test_*", Match: BoostMatchGlob, Factor: 0.5},
 					// Mocks
 					{Pattern: "/mocks/", Factor: 0.4},
 					{Pattern: "/mock/", Factor: 0.4},
@@ -313,7 +668,7 @@ func DefaultConfig() *Config {
 					{Pattern: ".generated.", Factor: 0.4},
 					{Pattern: ".gen.", Factor: 0.4},
 					// Documentation
-					{Pattern: ".md", Factor: 0.6},
+					{Pattern: "*.md", Match: BoostMatchGlob, Factor: 0.6},
 					{Pattern: "/docs/", Factor: 0.6},
 				},
 				Bonuses: []BoostRule{
@@ -388,14 +743,33 @@ func GetIndexPath(projectRoot string) string {
 	return filepath.Join(GetConfigDir(projectRoot), IndexFileName)
 }
 
+func GetSQLiteIndexPath(projectRoot string) string {
+	return filepath.Join(GetConfigDir(projectRoot), SQLiteIndexFileName)
+}
+
 func GetSymbolIndexPath(projectRoot string) string {
 	return filepath.Join(GetConfigDir(projectRoot), SymbolIndexFileName)
 }
 
+// GetRedactionLogPath returns the path of the audit log of redacted secrets.
+func GetRedactionLogPath(projectRoot string) string {
+	return filepath.Join(projectRoot, ConfigDir, RedactionLogFileName)
+}
+
 func GetRPGIndexPath(projectRoot string) string {
 	return filepath.Join(GetConfigDir(projectRoot), RPGIndexFileName)
 }
 
+// GetEmbeddingCachePath returns the path of the embedding cache shared by
+// all projects, ~/.grepai/cache/embeddings.db.
+func GetEmbeddingCachePath() (string, error) {
+	globalDir, err := GetGlobalConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(globalDir, EmbeddingCacheDir, EmbeddingCacheFileName), nil
+}
+
 func Load(projectRoot string) (*Config, error) {
 	configPath := GetConfigPath(projectRoot)
 
@@ -417,6 +791,36 @@ func Load(projectRoot string) (*Config, error) {
 		return nil, fmt.Errorf("invalid watch configuration: %w", err)
 	}
 
+	if err := ValidateChunkingConfig(cfg.Chunking); err != nil {
+		return nil, fmt.Errorf("invalid chunking configuration: %w", err)
+	}
+
+	if err := ValidateIndexConfig(cfg.Index); err != nil {
+		return nil, fmt.Errorf("invalid index configuration: %w", err)
+	}
+
+	if err := ValidateRedactionConfig(cfg.Redaction); err != nil {
+		return nil, fmt.Errorf("invalid redaction configuration: %w", err)
+	}
+
+	if cfg.Search.Boost.Enabled {
+		if err := ValidateBoostConfig(cfg.Search.Boost); err != nil {
+			return nil, fmt.Errorf("invalid search configuration: %w", err)
+		}
+	}
+
+	if cfg.Search.Rerank.Enabled {
+		if err := ValidateRerankConfig(cfg.Search.Rerank); err != nil {
+			return nil, fmt.Errorf("invalid search configuration: %w", err)
+		}
+	}
+
+	if cfg.Search.Diversity.Enabled {
+		if err := ValidateDiversityConfig(cfg.Search.Diversity); err != nil {
+			return nil, fmt.Errorf("invalid search configuration: %w", err)
+		}
+	}
+
 	// Validate RPG config when enabled
 	if cfg.RPG.Enabled {
 		if err := ValidateRPGConfig(cfg.RPG); err != nil {
@@ -453,6 +857,10 @@ func (c *Config) applyDefaults() {
 		c.Embedder.Parallelism = 4
 	}
 
+	if c.Embedder.Cache.Enabled && c.Embedder.Cache.MaxSizeMB <= 0 {
+		c.Embedder.Cache.MaxSizeMB = DefaultEmbeddingCacheMaxSizeMB
+	}
+
 	// Chunking defaults
 	if c.Chunking.Size == 0 {
 		c.Chunking.Size = defaults.Chunking.Size
@@ -483,6 +891,37 @@ func (c *Config) applyDefaults() {
 		c.Store.Qdrant.Port = DefaultStoreForBackend("qdrant").Qdrant.Port
 	}
 
+	// HNSW defaults (only when enabled, to keep config files minimal)
+	if c.Store.GOB.HNSW.Enabled {
+		if c.Store.GOB.HNSW.M <= 0 {
+			c.Store.GOB.HNSW.M = DefaultHNSWM
+		}
+		if c.Store.GOB.HNSW.EfConstruction <= 0 {
+			c.Store.GOB.HNSW.EfConstruction = DefaultHNSWEfConstruction
+		}
+		if c.Store.GOB.HNSW.EfSearch <= 0 {
+			c.Store.GOB.HNSW.EfSearch = DefaultHNSWEfSearch
+		}
+		if c.Store.GOB.HNSW.MinChunks <= 0 {
+			c.Store.GOB.HNSW.MinChunks = DefaultHNSWMinChunks
+		}
+	}
+
+	// Re-ranking defaults (only when enabled, to keep config files minimal)
+	if c.Search.Rerank.Enabled {
+		if c.Search.Rerank.Provider == "" {
+			c.Search.Rerank.Provider = DefaultRerankProvider
+		}
+		if c.Search.Rerank.TopN == 0 {
+			c.Search.Rerank.TopN = DefaultRerankTopN
+		}
+	}
+
+	// Diversification defaults (only when enabled, to keep config files minimal)
+	if c.Search.Diversity.Enabled && c.Search.Diversity.Lambda == 0 {
+		c.Search.Diversity.Lambda = DefaultDiversityLambda
+	}
+
 	// RPG defaults
 	if c.RPG.FeatureMode == "" {
 		c.RPG.FeatureMode = DefaultRPGFeatureMode
@@ -589,6 +1028,12 @@ func FindProjectRoot() (string, error) {
 	return "", fmt.Errorf("no grepai project found (run 'grepai init' first)")
 }
 
+// SQLiteIndexCopier copies the SQLite index at src to dst when a linked
+// worktree is seeded from its main worktree. The config package does not link
+// a SQLite driver, so the CLI sets it; while nil, worktrees start without a
+// SQLite seed.
+var SQLiteIndexCopier func(ctx context.Context, src, dst string) error
+
 // AutoInitWorktree creates a local .grepai/ in worktreeRoot by copying config and
 // index files from mainWorktree. This is used by watch to auto-init linked worktrees.
 func AutoInitWorktree(worktreeRoot, mainWorktree string) error {
@@ -620,11 +1065,19 @@ func autoInitFromMainWorktree(worktreeRoot, mainWorktree string) error {
 		return fmt.Errorf("config.yaml not found in main worktree: %s", srcConfig)
 	}
 
-	// Copy index.gob as seed (search works immediately)
+	// Copy index.gob or index.db as seed (search works immediately)
 	_ = copyFileIfExists(
-		filepath.Join(mainGrepai, "index.gob"),
-		filepath.Join(localGrepai, "index.gob"),
+		filepath.Join(mainGrepai, IndexFileName),
+		filepath.Join(localGrepai, IndexFileName),
 	)
+	// SQLite runs in WAL mode, so index.db alone may miss recent writes;
+	// a failed backup leaves no seed rather than a partial one
+	if SQLiteIndexCopier != nil {
+		localDB := filepath.Join(localGrepai, SQLiteIndexFileName)
+		if err := SQLiteIndexCopier(context.Background(), filepath.Join(mainGrepai, SQLiteIndexFileName), localDB); err != nil {
+			os.Remove(localDB)
+		}
+	}
 
 	// Copy symbols.gob as seed (trace works immediately)
 	_ = copyFileIfExists(
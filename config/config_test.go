@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 const (
@@ -566,6 +567,48 @@ func TestValidateRPGConfig_FeatureGroupStrategy(t *testing.T) {
 	}
 }
 
+func TestValidateRPGConfig_Backend(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		wantErr bool
+	}{
+		{"empty defaults to gob", "", false},
+		{"gob is valid", "gob", false},
+		{"postgres is valid", "postgres", false},
+		{"unknown is invalid", "sqlite", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := RPGConfig{
+				Backend:              tt.backend,
+				DriftThreshold:       DefaultRPGDriftThreshold,
+				MaxTraversalDepth:    DefaultRPGMaxTraversalDepth,
+				FeatureMode:          DefaultRPGFeatureMode,
+				FeatureGroupStrategy: DefaultRPGFeatureGroupStrategy,
+			}
+			err := ValidateRPGConfig(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRPGConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyDefaults_EmbeddingCache(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.Embedder.Cache.MaxSizeMB != 0 {
+		t.Errorf("disabled cache should keep max_size_mb unset, got %d", cfg.Embedder.Cache.MaxSizeMB)
+	}
+
+	cfg = &Config{Embedder: EmbedderConfig{Cache: EmbeddingCacheConfig{Enabled: true}}}
+	cfg.applyDefaults()
+	if cfg.Embedder.Cache.MaxSizeMB != DefaultEmbeddingCacheMaxSizeMB {
+		t.Errorf("expected max_size_mb=%d, got %d", DefaultEmbeddingCacheMaxSizeMB, cfg.Embedder.Cache.MaxSizeMB)
+	}
+}
+
 func TestApplyDefaults_FeatureGroupStrategy(t *testing.T) {
 	// When FeatureGroupStrategy is empty, applyDefaults should set it to "sample"
 	cfg := &Config{}
@@ -593,6 +636,24 @@ func TestApplyDefaults_WatchRealtimeFields(t *testing.T) {
 	}
 }
 
+func TestApplyDefaults_HNSW(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.Store.GOB.HNSW.M != 0 {
+		t.Errorf("expected HNSW params untouched when disabled, got M=%d", cfg.Store.GOB.HNSW.M)
+	}
+
+	cfg = &Config{Store: StoreConfig{GOB: GOBConfig{HNSW: HNSWConfig{Enabled: true, EfSearch: 128}}}}
+	cfg.applyDefaults()
+	hnsw := cfg.Store.GOB.HNSW
+	if hnsw.M != DefaultHNSWM || hnsw.EfConstruction != DefaultHNSWEfConstruction || hnsw.MinChunks != DefaultHNSWMinChunks {
+		t.Errorf("expected HNSW defaults, got %+v", hnsw)
+	}
+	if hnsw.EfSearch != 128 {
+		t.Errorf("expected explicit ef_search=128 to be kept, got %d", hnsw.EfSearch)
+	}
+}
+
 func TestValidateWatchConfig(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -649,6 +710,28 @@ func TestValidateWatchConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "gc interval too low",
+			cfg: WatchConfig{
+				RPGPersistIntervalMs:        1000,
+				RPGDerivedDebounceMs:        300,
+				RPGFullReconcileIntervalSec: 300,
+				RPGMaxDirtyFilesPerBatch:    128,
+				GCIntervalSec:               59,
+			},
+			wantErr: true,
+		},
+		{
+			name: "gc interval set",
+			cfg: WatchConfig{
+				RPGPersistIntervalMs:        1000,
+				RPGDerivedDebounceMs:        300,
+				RPGFullReconcileIntervalSec: 300,
+				RPGMaxDirtyFilesPerBatch:    128,
+				GCIntervalSec:               3600,
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -660,3 +743,214 @@ func TestValidateWatchConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateChunkingConfig_Strategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		wantErr  bool
+	}{
+		{"empty defaults to fixed", "", false},
+		{"fixed is valid", "fixed", false},
+		{"ast is valid", "ast", false},
+		{"unknown is invalid", "semantic", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChunkingConfig(ChunkingConfig{Size: 512, Overlap: 50, Strategy: tt.strategy})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateChunkingConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"2048", 2048, false},
+		{"512KB", 512 * 1024, false},
+		{"4 mb", 4 * 1024 * 1024, false},
+		{"1GB", 1 << 30, false},
+		{"10B", 10, false},
+		{"1.5MB", 0, true},
+		{"big", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateIndexConfig_MaxFileSize(t *testing.T) {
+	if err := ValidateIndexConfig(IndexConfig{MaxFileSize: "5MB"}); err != nil {
+		t.Errorf("expected 5MB to be valid, got %v", err)
+	}
+	if err := ValidateIndexConfig(IndexConfig{MaxFileSize: "0"}); err == nil {
+		t.Error("expected an error for a zero size")
+	}
+	if err := ValidateIndexConfig(IndexConfig{MaxFileSize: "lots"}); err == nil {
+		t.Error("expected an error for an invalid size")
+	}
+}
+
+func TestRedactionConfig_IsEnabled(t *testing.T) {
+	var cfg RedactionConfig
+	if !cfg.IsEnabled("openai") {
+		t.Error("expected redaction to default to on for openai")
+	}
+	if cfg.IsEnabled("ollama") {
+		t.Error("expected redaction to default to off for ollama")
+	}
+
+	off, on := false, true
+	if (RedactionConfig{Enabled: &off}).IsEnabled("openai") {
+		t.Error("expected enabled: false to win for openai")
+	}
+	if !(RedactionConfig{Enabled: &on}).IsEnabled("ollama") {
+		t.Error("expected enabled: true to win for ollama")
+	}
+}
+
+func TestValidateRedactionConfig(t *testing.T) {
+	valid := RedactionConfig{Patterns: []RedactionPattern{{Name: "internal", Regex: `itk_[a-z0-9]{12}`}}}
+	if err := ValidateRedactionConfig(valid); err != nil {
+		t.Errorf("expected valid patterns, got %v", err)
+	}
+	if err := ValidateRedactionConfig(RedactionConfig{Patterns: []RedactionPattern{{Regex: `x+`}}}); err == nil {
+		t.Error("expected an error for a pattern without a name")
+	}
+	if err := ValidateRedactionConfig(RedactionConfig{Patterns: []RedactionPattern{{Name: "bad", Regex: `(`}}}); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
+
+func TestValidateRerankConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     RerankConfig
+		wantErr bool
+	}{
+		{"heuristic is valid", RerankConfig{Provider: "heuristic", TopN: DefaultRerankTopN}, false},
+		{"llm is valid", RerankConfig{Provider: "llm", TopN: DefaultRerankTopN}, false},
+		{"http with endpoint is valid", RerankConfig{Provider: "http", Endpoint: "https://api.jina.ai/v1", TopN: 50}, false},
+		{"http without endpoint is invalid", RerankConfig{Provider: "http", TopN: DefaultRerankTopN}, true},
+		{"unknown provider is invalid", RerankConfig{Provider: "cohere", TopN: DefaultRerankTopN}, true},
+		{"top_n below 2 is invalid", RerankConfig{Provider: "heuristic", TopN: 1}, true},
+		{"top_n above 200 is invalid", RerankConfig{Provider: "heuristic", TopN: 500}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRerankConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRerankConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyDefaults_Rerank(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Search.Rerank = RerankConfig{Enabled: true}
+	cfg.applyDefaults()
+	if cfg.Search.Rerank.Provider != DefaultRerankProvider || cfg.Search.Rerank.TopN != DefaultRerankTopN {
+		t.Errorf("expected rerank defaults, got %+v", cfg.Search.Rerank)
+	}
+}
+
+func TestValidateDiversityConfig(t *testing.T) {
+	tests := []struct {
+		lambda  float64
+		wantErr bool
+	}{
+		{DefaultDiversityLambda, false},
+		{1, false},
+		{0.1, false},
+		{0, true},
+		{-0.5, true},
+		{1.5, true},
+	}
+	for _, tt := range tests {
+		err := ValidateDiversityConfig(DiversityConfig{Enabled: true, Lambda: tt.lambda})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateDiversityConfig(lambda=%g) error = %v, wantErr %v", tt.lambda, err, tt.wantErr)
+		}
+	}
+}
+
+func TestApplyDefaults_Diversity(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Search.Diversity = DiversityConfig{Enabled: true}
+	cfg.applyDefaults()
+	if cfg.Search.Diversity.Lambda != DefaultDiversityLambda {
+		t.Errorf("expected default lambda, got %g", cfg.Search.Diversity.Lambda)
+	}
+}
+
+func TestValidateBoostConfig(t *testing.T) {
+	if err := ValidateBoostConfig(DefaultConfig().Search.Boost); err != nil {
+		t.Fatalf("default boost config should be valid: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		rule    BoostRule
+		wantErr bool
+	}{
+		{"substring", BoostRule{Pattern: "/tests/", Factor: 0.5}, false},
+		{"glob", BoostRule{Pattern: "*.md", Match: BoostMatchGlob, Factor: 0.6}, false},
+		{"regex", BoostRule{Pattern: `_test\.go$`, Match: BoostMatchRegex, Factor: 0.5}, false},
+		{"signals only", BoostRule{Language: "go", Kind: "function", ModifiedWithin: "2w", Factor: 1.1}, false},
+		{"size range", BoostRule{MinSize: 1000, MaxSize: 5000, Factor: 1.1}, false},
+		{"zero factor", BoostRule{Pattern: "x", Factor: 0}, true},
+		{"no condition", BoostRule{Factor: 1.1}, true},
+		{"unknown match", BoostRule{Pattern: "x", Match: "fuzzy", Factor: 1.1}, true},
+		{"bad regex", BoostRule{Pattern: "(", Match: BoostMatchRegex, Factor: 1.1}, true},
+		{"match without pattern", BoostRule{Language: "go", Match: BoostMatchGlob, Factor: 1.1}, true},
+		{"inverted size range", BoostRule{MinSize: 5000, MaxSize: 1000, Factor: 1.1}, true},
+		{"bad recency", BoostRule{ModifiedWithin: "soon", Factor: 1.1}, true},
+	}
+	for _, tt := range tests {
+		cfg := BoostConfig{Enabled: true, Bonuses: []BoostRule{tt.rule}}
+		if err := ValidateBoostConfig(cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateBoostConfig() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBoostRule_RecencyWindow(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"0d", 0, true},
+		{"-3d", 0, true},
+		{"month", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := BoostRule{ModifiedWithin: tt.value}.RecencyWindow()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("RecencyWindow(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("RecencyWindow(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
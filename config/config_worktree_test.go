@@ -1,6 +1,8 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -131,6 +133,44 @@ func TestAutoInitFromMainWorktree(t *testing.T) {
 		}
 	})
 
+	t.Run("seeds the sqlite index with the copier", func(t *testing.T) {
+		mainDir := t.TempDir()
+		worktreeDir := t.TempDir()
+
+		mainGrepai := filepath.Join(mainDir, ".grepai")
+		os.MkdirAll(mainGrepai, 0755)
+		os.WriteFile(filepath.Join(mainGrepai, "config.yaml"), []byte("version: 1\n"), 0644)
+
+		var gotSrc, gotDst string
+		old := SQLiteIndexCopier
+		SQLiteIndexCopier = func(ctx context.Context, src, dst string) error {
+			gotSrc, gotDst = src, dst
+			return os.WriteFile(dst, []byte("partial"), 0600)
+		}
+		t.Cleanup(func() { SQLiteIndexCopier = old })
+
+		if err := autoInitFromMainWorktree(worktreeDir, mainDir); err != nil {
+			t.Fatalf("autoInitFromMainWorktree failed: %v", err)
+		}
+		localDB := filepath.Join(worktreeDir, ".grepai", "index.db")
+		if gotSrc != filepath.Join(mainGrepai, "index.db") || gotDst != localDB {
+			t.Errorf("copier called with %q -> %q", gotSrc, gotDst)
+		}
+
+		// A failed copy leaves no partial seed behind.
+		SQLiteIndexCopier = func(ctx context.Context, src, dst string) error {
+			os.WriteFile(dst, []byte("partial"), 0600)
+			return errors.New("backup failed")
+		}
+		os.RemoveAll(filepath.Join(worktreeDir, ".grepai"))
+		if err := autoInitFromMainWorktree(worktreeDir, mainDir); err != nil {
+			t.Fatalf("autoInitFromMainWorktree failed: %v", err)
+		}
+		if _, err := os.Stat(localDB); !os.IsNotExist(err) {
+			t.Errorf("expected no sqlite seed after a failed copy, got %v", err)
+		}
+	})
+
 	t.Run("fails if config.yaml missing in main", func(t *testing.T) {
 		mainDir := t.TempDir()
 		worktreeDir := t.TempDir()
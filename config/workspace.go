@@ -108,6 +108,12 @@ func SaveWorkspaceConfig(cfg *WorkspaceConfig) error {
 	return nil
 }
 
+// SymbolProjectID returns the ID under which a workspace project's symbols
+// are stored in a shared PostgreSQL database.
+func (ws *Workspace) SymbolProjectID(projectName string) string {
+	return "workspace:" + ws.Name + "/" + projectName
+}
+
 // GetWorkspace returns a workspace by name.
 func (c *WorkspaceConfig) GetWorkspace(name string) (*Workspace, error) {
 	if c == nil || c.Workspaces == nil {
@@ -236,12 +242,24 @@ func (c *WorkspaceConfig) ListWorkspaces() []string {
 // FindWorkspaceForPath checks if the given path is within any workspace project.
 // Returns workspace name and workspace config if found, or ("", nil, nil) if no match.
 func FindWorkspaceForPath(targetPath string) (string, *Workspace, error) {
+	name, ws, _, err := findWorkspaceProject(targetPath)
+	return name, ws, err
+}
+
+// FindWorkspaceProject returns the workspace and the project within it that
+// contain the given path, or (nil, nil, nil) if no workspace project does.
+func FindWorkspaceProject(targetPath string) (*Workspace, *ProjectEntry, error) {
+	_, ws, project, err := findWorkspaceProject(targetPath)
+	return ws, project, err
+}
+
+func findWorkspaceProject(targetPath string) (string, *Workspace, *ProjectEntry, error) {
 	cfg, err := LoadWorkspaceConfig()
 	if err != nil {
-		return "", nil, err
+		return "", nil, nil, err
 	}
 	if cfg == nil {
-		return "", nil, nil
+		return "", nil, nil, nil
 	}
 
 	// Resolve symlinks
@@ -260,11 +278,11 @@ func FindWorkspaceForPath(targetPath string) (string, *Workspace, error) {
 				continue
 			}
 			if !strings.HasPrefix(rel, "..") {
-				wsCopy := ws
-				return name, &wsCopy, nil
+				wsCopy, projCopy := ws, proj
+				return name, &wsCopy, &projCopy, nil
 			}
 		}
 	}
 
-	return "", nil, nil
+	return "", nil, nil, nil
 }
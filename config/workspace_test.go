@@ -494,6 +494,19 @@ func TestFindWorkspaceForPath(t *testing.T) {
 			t.Errorf("expected ws2, got %s", name)
 		}
 	})
+
+	t.Run("project_match", func(t *testing.T) {
+		ws, project, err := FindWorkspaceProject(subDir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ws == nil || project == nil {
+			t.Fatal("expected workspace and project, got nil")
+		}
+		if ws.Name != "ws1" || project.Name != "projectA" {
+			t.Errorf("expected ws1/projectA, got %s/%s", ws.Name, project.Name)
+		}
+	})
 }
 
 func TestGetWorkspaceConfigPath(t *testing.T) {
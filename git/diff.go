@@ -0,0 +1,162 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// diffTimeout bounds the git commands listing changed files, which may take
+// a while on large repositories.
+const diffTimeout = 30 * time.Second
+
+// ChangeStatus is the kind of change git reports for a file.
+type ChangeStatus string
+
+const (
+	Added    ChangeStatus = "added"
+	Modified ChangeStatus = "modified"
+	Deleted  ChangeStatus = "deleted"
+	Renamed  ChangeStatus = "renamed"
+)
+
+// Change is a file that differs between two states of a repository. Paths
+// are relative to the directory the changes were listed for and use forward
+// slashes.
+type Change struct {
+	Status  ChangeStatus
+	Path    string
+	OldPath string // previous path of a renamed file
+}
+
+// HeadCommit returns the commit checked out in the repository containing
+// path.
+func HeadCommit(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	out, err := runGit(ctx, path, "rev-parse", "--verify", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// ChangedFiles lists the files under path whose content differs between
+// commit since and the working tree, staged and unstaged changes included,
+// followed by the untracked files that are not ignored. Renames are
+// detected.
+func ChangedFiles(path, since string) ([]Change, error) {
+	if since == "" || strings.HasPrefix(since, "-") {
+		return nil, fmt.Errorf("invalid commit %q", since)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), diffTimeout)
+	defer cancel()
+
+	if _, err := runGit(ctx, path, "rev-parse", "--verify", "--quiet", since+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("commit %s not found: %w", since, err)
+	}
+
+	out, err := runGit(ctx, path, "diff", "--name-status", "-z", "-M", "--relative", "--no-ext-diff", since, "--")
+	if err != nil {
+		return nil, err
+	}
+	changes, err := parseNameStatus(out)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err = runGit(ctx, path, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range strings.Split(string(out), "\x00") {
+		if p != "" {
+			changes = append(changes, Change{Status: Added, Path: p})
+		}
+	}
+	return changes, nil
+}
+
+// HasSubmodules reports whether the repository containing path has
+// submodules under path. ChangedFiles does not list changes inside them.
+func HasSubmodules(path string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), diffTimeout)
+	defer cancel()
+
+	out, err := runGit(ctx, path, "ls-files", "-z", "--stage")
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range strings.Split(string(out), "\x00") {
+		// Submodules are recorded as gitlinks, with mode 160000.
+		if strings.HasPrefix(entry, "160000 ") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseNameStatus parses the output of git diff --name-status -z.
+func parseNameStatus(out []byte) ([]Change, error) {
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	if len(fields) == 1 && fields[0] == "" {
+		return nil, nil
+	}
+
+	var changes []Change
+	for i := 0; i < len(fields); {
+		status := fields[i]
+		i++
+		if status == "" {
+			return nil, fmt.Errorf("unexpected empty status in git diff output")
+		}
+
+		// Renames and copies carry a similarity score and two paths.
+		if status[0] == 'R' || status[0] == 'C' {
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("truncated git diff output for status %s", status)
+			}
+			oldPath, newPath := fields[i], fields[i+1]
+			i += 2
+			if status[0] == 'R' {
+				changes = append(changes, Change{Status: Renamed, Path: newPath, OldPath: oldPath})
+			} else {
+				changes = append(changes, Change{Status: Added, Path: newPath})
+			}
+			continue
+		}
+
+		if i >= len(fields) {
+			return nil, fmt.Errorf("truncated git diff output for status %s", status)
+		}
+		path := fields[i]
+		i++
+		switch status[0] {
+		case 'A':
+			changes = append(changes, Change{Status: Added, Path: path})
+		case 'D':
+			changes = append(changes, Change{Status: Deleted, Path: path})
+		default:
+			// M, T (type change) and U (unmerged) all leave new content.
+			changes = append(changes, Change{Status: Modified, Path: path})
+		}
+	}
+	return changes, nil
+}
+
+// runGit runs git in path and returns its standard output.
+func runGit(ctx context.Context, path string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", path}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("git %s failed: %w (stderr: %s)", args[0], err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to execute git command (is git installed?): %w", err)
+	}
+	return out, nil
+}
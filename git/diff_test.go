@@ -0,0 +1,139 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseNameStatus(t *testing.T) {
+	out := []byte("M\x00a.go\x00R087\x00old.go\x00new.go\x00D\x00gone.go\x00A\x00added.go\x00C100\x00src.go\x00copy.go\x00T\x00link\x00")
+	changes, err := parseNameStatus(out)
+	if err != nil {
+		t.Fatalf("parseNameStatus failed: %v", err)
+	}
+
+	want := []Change{
+		{Status: Modified, Path: "a.go"},
+		{Status: Renamed, Path: "new.go", OldPath: "old.go"},
+		{Status: Deleted, Path: "gone.go"},
+		{Status: Added, Path: "added.go"},
+		{Status: Added, Path: "copy.go"},
+		{Status: Modified, Path: "link"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got %+v, want %+v", changes, want)
+	}
+}
+
+func TestParseNameStatus_Empty(t *testing.T) {
+	changes, err := parseNameStatus(nil)
+	if err != nil || changes != nil {
+		t.Errorf("expected no changes, got %+v, %v", changes, err)
+	}
+}
+
+func TestParseNameStatus_Truncated(t *testing.T) {
+	for _, out := range []string{"M\x00", "R100\x00old.go\x00"} {
+		if _, err := parseNameStatus([]byte(out)); err == nil {
+			t.Errorf("expected error for %q", out)
+		}
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	root := t.TempDir()
+	setupGitRepo(t, root)
+
+	gitRun := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	write := func(path, content string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("sub/edited.go", "package sub\n")
+	write("sub/gone.go", "package sub\n")
+	write("sub/old.go", "package sub\n\nfunc Moved() {\n\treturn\n}\n")
+	write("top.go", "package main\n")
+	gitRun("add", "-A")
+	gitRun("commit", "-m", "initial")
+
+	since, err := HeadCommit(root)
+	if err != nil {
+		t.Fatalf("HeadCommit failed: %v", err)
+	}
+
+	write("sub/edited.go", "package sub\n\nvar x = 1\n")
+	gitRun("rm", "-q", "sub/gone.go")
+	gitRun("mv", "sub/old.go", "sub/new.go")
+	gitRun("commit", "-m", "changes")
+	write("sub/untracked.go", "package sub\n")
+	write("top.go", "package main\n\nvar y = 2\n")
+
+	changes, err := ChangedFiles(filepath.Join(root, "sub"), since)
+	if err != nil {
+		t.Fatalf("ChangedFiles failed: %v", err)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	want := []Change{
+		{Status: Modified, Path: "edited.go"},
+		{Status: Deleted, Path: "gone.go"},
+		{Status: Renamed, Path: "new.go", OldPath: "old.go"},
+		{Status: Added, Path: "untracked.go"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got %+v, want %+v", changes, want)
+	}
+}
+
+func TestChangedFiles_UnknownCommit(t *testing.T) {
+	root := t.TempDir()
+	setupGitRepo(t, root)
+
+	for _, since := range []string{"", "--output=x", "0123456789abcdef0123456789abcdef01234567"} {
+		if _, err := ChangedFiles(root, since); err == nil {
+			t.Errorf("expected error for commit %q", since)
+		}
+	}
+}
+
+func TestHasSubmodules(t *testing.T) {
+	root := t.TempDir()
+	setupGitRepo(t, root)
+	if err := os.MkdirAll(filepath.Join(root, "app"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if has, err := HasSubmodules(root); err != nil || has {
+		t.Fatalf("expected no submodules, got %v, %v", has, err)
+	}
+
+	// Record a gitlink directly, as 'git submodule add' would.
+	cmd := exec.Command("git", "-C", root, "update-index", "--add", "--cacheinfo",
+		"160000,0123456789abcdef0123456789abcdef01234567,vendor/lib")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git update-index failed: %v\n%s", err, out)
+	}
+	if has, err := HasSubmodules(root); err != nil || !has {
+		t.Errorf("expected a submodule, got %v, %v", has, err)
+	}
+	if has, err := HasSubmodules(filepath.Join(root, "app")); err != nil || has {
+		t.Errorf("expected no submodule under app/, got %v, %v", has, err)
+	}
+}
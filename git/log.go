@@ -0,0 +1,74 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commitMarker starts the lines of git log output holding a commit time, so
+// they cannot be mistaken for file names.
+const commitMarker = "\x01"
+
+// LastCommitTimes returns the time of the latest commit since the given time
+// touching each of files, which are relative to path. Files not committed
+// since then are left out of the result.
+func LastCommitTimes(path string, files []string, since time.Time) (map[string]time.Time, error) {
+	times := make(map[string]time.Time, len(files))
+	if len(files) == 0 {
+		return times, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), diffTimeout)
+	defer cancel()
+
+	args := []string{
+		"-c", "core.quotePath=false", "--literal-pathspecs",
+		"log", "--relative", "--name-only", "--no-renames",
+		"--format=" + commitMarker + "%ct",
+		"--since=" + strconv.FormatInt(since.Unix(), 10),
+		"--",
+	}
+	out, err := runGit(ctx, path, append(args, files...)...)
+	if err != nil {
+		return nil, err
+	}
+	if err := parseCommitTimes(out, times); err != nil {
+		return nil, err
+	}
+	return times, nil
+}
+
+// parseCommitTimes reads git log --name-only output where each commit starts
+// with a commitMarker line holding its Unix time. Commits are listed newest
+// first, so the first time seen for a file is its latest.
+func parseCommitTimes(out []byte, times map[string]time.Time) error {
+	var current time.Time
+	for _, line := range strings.Split(string(out), "\n") {
+		if line == "" {
+			continue
+		}
+		if ts, ok := strings.CutPrefix(line, commitMarker); ok {
+			sec, err := strconv.ParseInt(strings.TrimSpace(ts), 10, 64)
+			if err != nil {
+				return fmt.Errorf("unexpected commit time %q in git log output", ts)
+			}
+			current = time.Unix(sec, 0)
+			continue
+		}
+		if current.IsZero() {
+			return fmt.Errorf("unexpected file name before any commit in git log output")
+		}
+		if strings.HasPrefix(line, `"`) {
+			if unquoted, err := strconv.Unquote(line); err == nil {
+				line = unquoted
+			}
+		}
+		if _, seen := times[line]; !seen {
+			times[line] = current
+		}
+	}
+	return nil
+}
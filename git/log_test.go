@@ -0,0 +1,66 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseCommitTimes(t *testing.T) {
+	out := []byte("\x01200\n\na.go\nsub/b.go\n\n\x01100\n\na.go\n\"sp\\303\\251cial.go\"\n")
+	times := make(map[string]time.Time)
+	if err := parseCommitTimes(out, times); err != nil {
+		t.Fatalf("parseCommitTimes failed: %v", err)
+	}
+
+	want := map[string]int64{"a.go": 200, "sub/b.go": 200, "spécial.go": 100}
+	if len(times) != len(want) {
+		t.Fatalf("got %v, want %v", times, want)
+	}
+	for path, sec := range want {
+		if times[path].Unix() != sec {
+			t.Errorf("%s: got %v, want %d", path, times[path], sec)
+		}
+	}
+
+	if err := parseCommitTimes([]byte("a.go\n"), times); err == nil {
+		t.Error("expected error for a file name before any commit")
+	}
+}
+
+func TestLastCommitTimes(t *testing.T) {
+	root := t.TempDir()
+	setupGitRepo(t, root)
+
+	commit := func(path, date string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(date), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", path, "--date", date}} {
+			cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+			cmd.Env = append(os.Environ(), "GIT_COMMITTER_DATE="+date)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v failed: %v\n%s", args, err, out)
+			}
+		}
+	}
+	commit("sub/old.go", "2020-01-01T00:00:00Z")
+	commit("sub/new.go", "2024-06-01T00:00:00Z")
+	commit("top.go", "2024-06-02T00:00:00Z")
+
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	times, err := LastCommitTimes(filepath.Join(root, "sub"), []string{"old.go", "new.go", "missing.go"}, since)
+	if err != nil {
+		t.Fatalf("LastCommitTimes failed: %v", err)
+	}
+	if len(times) != 1 || !times["new.go"].Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected only new.go, committed 2024-06-01, got %v", times)
+	}
+}
@@ -0,0 +1,138 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"time"
+)
+
+// FileChange is a file that may have changed since the last index run, as
+// reported by version control.
+type FileChange struct {
+	Path    string
+	OldPath string // previous path of a renamed file
+}
+
+// IndexChanges brings the index up to date for the given files only, instead
+// of walking the whole project. Files whose hash differs from the indexed one
+// are re-indexed, and files that no longer exist or are excluded are removed.
+// A renamed file reuses the vectors of the chunks indexed for its previous
+// path, so content that did not change is not embedded again.
+func (idx *Indexer) IndexChanges(ctx context.Context, changes []FileChange, onProgress ProgressCallback, onBatchProgress BatchProgressCallback) (*IndexStats, error) {
+	start := time.Now()
+	stats := &IndexStats{}
+
+	var filesToIndex []FileInfo
+	for i, change := range changes {
+		if onProgress != nil {
+			onProgress(ProgressInfo{
+				Current:     i + 1,
+				Total:       len(changes),
+				CurrentFile: change.Path,
+			})
+		}
+
+		if change.OldPath != "" && change.OldPath != change.Path {
+			if err := idx.moveFile(ctx, change.OldPath, change.Path, stats); err != nil {
+				return nil, err
+			}
+			// The old path is usually gone, but may have been recreated.
+			file, err := idx.scanChange(ctx, change.OldPath, stats)
+			if err != nil {
+				return nil, err
+			}
+			if file != nil {
+				filesToIndex = append(filesToIndex, *file)
+			}
+			continue
+		}
+
+		file, err := idx.scanChange(ctx, change.Path, stats)
+		if err != nil {
+			return nil, err
+		}
+		if file != nil {
+			filesToIndex = append(filesToIndex, *file)
+		}
+	}
+
+	if err := idx.indexFiles(ctx, filesToIndex, onBatchProgress, stats); err != nil {
+		return nil, err
+	}
+
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// moveFile indexes the file renamed from oldPath to newPath, reusing the
+// vectors of the chunks indexed for oldPath. Chunks are matched on their raw
+// content, since context headers make ContentHash depend on the path.
+func (idx *Indexer) moveFile(ctx context.Context, oldPath, newPath string, stats *IndexStats) error {
+	file, err := idx.scanChange(ctx, newPath, stats)
+	if err != nil || file == nil {
+		return err
+	}
+
+	// Stores that don't return vectors here still find them through their
+	// EmbeddingCache, as the old chunks are removed only afterwards.
+	chunks, err := idx.store.GetChunksForFile(ctx, oldPath)
+	if err != nil {
+		log.Printf("Warning: failed to read chunks of %s: %v", oldPath, err)
+	}
+	reuse := make(map[string][]float32, len(chunks))
+	for _, chunk := range chunks {
+		if len(chunk.Vector) > 0 {
+			old := ChunkInfo{FilePath: chunk.FilePath, Content: chunk.Content, Headings: chunk.Headings}
+			reuse[old.rawContentHash()] = chunk.Vector
+		}
+	}
+
+	n, err := idx.indexFile(ctx, *file, reuse)
+	if err != nil {
+		log.Printf("Failed to index %s: %v", newPath, err)
+		stats.FilesFailed++
+		return nil
+	}
+	log.Printf("Moved %s to %s (%d chunks)", oldPath, newPath, n)
+	stats.FilesIndexed++
+	stats.ChunksCreated += n
+	return nil
+}
+
+// scanChange returns the file at path if it needs indexing. A file that can
+// no longer be indexed is removed from the index.
+func (idx *Indexer) scanChange(ctx context.Context, path string, stats *IndexStats) (*FileInfo, error) {
+	doc, err := idx.store.GetDocument(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document %s: %w", path, err)
+	}
+
+	var file *FileInfo
+	if idx.scanner.Matches(path) {
+		file, err = idx.scanner.ScanFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Failed to scan %s: %v", path, err)
+			stats.FilesSkipped++
+			return nil, nil
+		}
+	}
+
+	if file == nil {
+		if doc != nil {
+			if err := idx.RemoveFile(ctx, path); err != nil {
+				log.Printf("Failed to remove %s: %v", path, err)
+				return nil, nil
+			}
+			stats.FilesRemoved++
+		}
+		return nil, nil
+	}
+
+	if doc != nil && doc.Hash == file.Hash && len(doc.ChunkIDs) > 0 {
+		return nil, nil // File unchanged and has chunks
+	}
+	return file, nil
+}
@@ -0,0 +1,129 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndexChanges(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, path), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	write("kept.go", "package main\n\nfunc kept() {}\n")
+	write("edited.go", "package main\n\nfunc before() {}\n")
+	write("gone.go", "package main\n\nfunc gone() {}\n")
+	write("old.go", "package main\n\nfunc moved() {}\n")
+
+	ignore, err := NewIgnoreMatcher(root, nil, "")
+	if err != nil {
+		t.Fatalf("NewIgnoreMatcher failed: %v", err)
+	}
+	st := newMockStore()
+	emb := &recordingEmbedder{}
+	idx := NewIndexer(root, st, emb, NewChunker(512, 50), NewScanner(root, ignore), time.Time{})
+	if _, err := idx.IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+
+	write("edited.go", "package main\n\nfunc after() {}\n")
+	if err := os.Remove(filepath.Join(root, "gone.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(root, "old.go"), filepath.Join(root, "new.go")); err != nil {
+		t.Fatal(err)
+	}
+	emb.texts = nil
+
+	stats, err := idx.IndexChanges(ctx, []FileChange{
+		{Path: "kept.go"},
+		{Path: "edited.go"},
+		{Path: "gone.go"},
+		{Path: "new.go", OldPath: "old.go"},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("IndexChanges failed: %v", err)
+	}
+
+	if stats.FilesIndexed != 2 || stats.FilesRemoved != 2 {
+		t.Errorf("expected 2 files indexed and 2 removed, got %+v", stats)
+	}
+	if len(emb.texts) != 1 || !strings.Contains(emb.texts[0], "func after()") {
+		t.Errorf("expected only edited.go to be embedded, got %q", emb.texts)
+	}
+	for _, path := range []string{"gone.go", "old.go"} {
+		if _, ok := st.documents[path]; ok {
+			t.Errorf("%s should have been removed", path)
+		}
+	}
+	chunks, _ := st.GetChunksForFile(ctx, "new.go")
+	if len(chunks) != 1 || chunks[0].FilePath != "new.go" || len(chunks[0].Vector) == 0 {
+		t.Errorf("expected new.go to keep the vector of old.go, got %+v", chunks)
+	}
+}
+
+func TestIndexChanges_RenameWithContextHeaders(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "old.go"), []byte("package main\n\nfunc moved() {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ignore, _ := NewIgnoreMatcher(root, nil, "")
+	emb := &recordingEmbedder{}
+	idx := NewIndexer(root, newMockStore(), emb, NewChunker(512, 50), NewScanner(root, ignore), time.Time{})
+	idx.EnableContextHeaders()
+	if _, err := idx.IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+
+	if err := os.Rename(filepath.Join(root, "old.go"), filepath.Join(root, "new.go")); err != nil {
+		t.Fatal(err)
+	}
+	emb.texts = nil
+	if _, err := idx.IndexChanges(ctx, []FileChange{{Path: "new.go", OldPath: "old.go"}}, nil, nil); err != nil {
+		t.Fatalf("IndexChanges failed: %v", err)
+	}
+	// The header names the file, so the content hash changes with the path,
+	// but the moved chunk still reuses its vector.
+	if len(emb.texts) != 0 {
+		t.Errorf("expected the renamed file not to be embedded again, got %q", emb.texts)
+	}
+}
+
+func TestIndexChanges_DropsIgnoredFiles(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "gen.go"), []byte("package gen\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	st := newMockStore()
+	ignore, _ := NewIgnoreMatcher(root, nil, "")
+	idx := NewIndexer(root, st, newMockEmbedder(), NewChunker(512, 50), NewScanner(root, ignore), time.Time{})
+	if _, err := idx.IndexAll(ctx); err != nil {
+		t.Fatalf("IndexAll failed: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("gen.go\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ignore, _ = NewIgnoreMatcher(root, nil, "")
+	idx = NewIndexer(root, st, newMockEmbedder(), NewChunker(512, 50), NewScanner(root, ignore), time.Time{})
+
+	stats, err := idx.IndexChanges(ctx, []FileChange{{Path: "gen.go"}}, nil, nil)
+	if err != nil {
+		t.Fatalf("IndexChanges failed: %v", err)
+	}
+	if stats.FilesRemoved != 1 {
+		t.Errorf("expected the ignored file to be removed, got %+v", stats)
+	}
+}
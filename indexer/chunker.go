@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/yoanbernabeu/grepai/store"
 )
 
 const (
@@ -21,15 +23,53 @@ type ChunkInfo struct {
 	EndLine     int
 	Content     string
 	Hash        string
-	ContentHash string // SHA256 of raw content text (without file path prefix)
+	ContentHash string   // SHA256 of the embedded text: raw content, plus the context header when set
+	Headings    []string // Heading path of Markdown chunks, outermost first
+
+	// EmbedHeader, when set, replaces the context prefix of Content in the
+	// text sent to the embedder. Content itself is stored unchanged.
+	EmbedHeader string
+}
+
+// embedText returns the text sent to the embedder for the chunk.
+func (c ChunkInfo) embedText() string {
+	if c.EmbedHeader == "" {
+		return c.Content
+	}
+	return c.EmbedHeader + strings.TrimPrefix(c.Content, contextHeader(c))
+}
+
+// rawContentHash returns the SHA256 of the chunk content without the prefix
+// added by ChunkWithContext. Unlike ContentHash it never covers the file
+// path, so it identifies the same content after a rename.
+func (c ChunkInfo) rawContentHash() string {
+	sum := sha256.Sum256([]byte(strings.TrimPrefix(c.Content, contextHeader(c))))
+	return hex.EncodeToString(sum[:])
+}
+
+// setEmbedHeader sets EmbedHeader and makes ContentHash cover the header, so
+// cached vectors are only reused for identical embedded text.
+func (c *ChunkInfo) setEmbedHeader(header string) {
+	c.EmbedHeader = header
+	sum := sha256.Sum256([]byte(c.embedText()))
+	c.ContentHash = hex.EncodeToString(sum[:])
 }
 
 type Chunker struct {
 	chunkSize int
 	overlap   int
+
+	// syntax parses files for AST chunking; nil selects fixed-size chunks.
+	syntax syntaxParser
+
+	// notebookOutputs indexes the text outputs of notebook code cells.
+	notebookOutputs bool
 }
 
-func NewChunker(chunkSize, overlap int) *Chunker {
+// ChunkerOption configures optional Chunker behaviour.
+type ChunkerOption func(*Chunker)
+
+func NewChunker(chunkSize, overlap int, opts ...ChunkerOption) *Chunker {
 	if chunkSize <= 0 {
 		chunkSize = DefaultChunkSize
 	}
@@ -40,10 +80,14 @@ func NewChunker(chunkSize, overlap int) *Chunker {
 		overlap = chunkSize / 10
 	}
 
-	return &Chunker{
+	c := &Chunker{
 		chunkSize: chunkSize,
 		overlap:   overlap,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // alignRuneBoundary adjusts a byte offset forward to the start of the next
@@ -59,62 +103,92 @@ func (c *Chunker) Chunk(filePath string, content string) []ChunkInfo {
 	if len(content) == 0 {
 		return nil
 	}
+	if store.IsNotebook(filePath) {
+		return c.chunkNotebook(filePath, content)
+	}
+
+	lineStarts := buildLineStarts(content)
+
+	var ranges []chunkRange
+	var headings [][]string
+	switch {
+	case isMarkdownFile(filePath):
+		ranges, headings = c.markdownRanges(content, lineStarts)
+	case c.syntax != nil:
+		ranges = c.astRanges(filePath, content, lineStarts)
+	}
+	if ranges == nil {
+		ranges = c.fixedRanges(content, 0, len(content))
+	}
+
+	chunks := make([]ChunkInfo, 0, len(ranges))
+	for i, r := range ranges {
+		chunkContent := content[r.start:r.end]
+
+		// Calculate line numbers
+		startLine := getLineNumber(lineStarts, r.start)
+		endLine := getLineNumber(lineStarts, r.end-1)
+
+		// Generate chunk ID
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d:%s", filePath, r.start, r.end, chunkContent)))
+		contentHash := sha256.Sum256([]byte(chunkContent))
+		chunkID := fmt.Sprintf("%s_%d", filePath, len(chunks))
+
+		chunks = append(chunks, ChunkInfo{
+			ID:          chunkID,
+			FilePath:    filePath,
+			StartLine:   startLine,
+			EndLine:     endLine,
+			Content:     chunkContent,
+			Hash:        hex.EncodeToString(hash[:8]),
+			ContentHash: hex.EncodeToString(contentHash[:]),
+		})
+		if headings != nil {
+			chunks[len(chunks)-1].Headings = headings[i]
+		}
+	}
+
+	return chunks
+}
 
+// chunkRange is the byte range [start, end) of a chunk in the file content.
+type chunkRange struct {
+	start, end int
+}
+
+// fixedRanges splits content[from:to] into ranges of at most chunkSize
+// tokens, breaking at newlines where possible and overlapping consecutive
+// ranges. Whitespace-only ranges are skipped.
+func (c *Chunker) fixedRanges(content string, from, to int) []chunkRange {
 	// Use character-based chunking instead of line-based
 	// This handles minified files with very long lines
 	maxChars := c.chunkSize * CharsPerToken
 	overlapChars := c.overlap * CharsPerToken
 
-	var chunks []ChunkInfo
-	chunkIndex := 0
-
-	// Build line index for position -> line number mapping
-	lineStarts := buildLineStarts(content)
-
-	pos := 0
-	for pos < len(content) {
+	var ranges []chunkRange
+	pos := from
+	for pos < to {
 		end := pos + maxChars
-		if end > len(content) {
-			end = len(content)
+		if end > to {
+			end = to
 		}
 		end = alignRuneBoundary(content, end)
 
 		// Try to break at a newline if possible (cleaner chunks)
-		if end < len(content) {
+		if end < to {
 			lastNewline := strings.LastIndex(content[pos:end], "\n")
 			if lastNewline > 0 {
 				end = pos + lastNewline + 1
 			}
 		}
 
-		chunkContent := content[pos:end]
-
 		// Skip empty chunks
-		if strings.TrimSpace(chunkContent) == "" {
+		if strings.TrimSpace(content[pos:end]) == "" {
 			pos = end
 			continue
 		}
 
-		// Calculate line numbers
-		startLine := getLineNumber(lineStarts, pos)
-		endLine := getLineNumber(lineStarts, end-1)
-
-		// Generate chunk ID
-		hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d:%s", filePath, pos, end, chunkContent)))
-		contentHash := sha256.Sum256([]byte(chunkContent))
-		chunkID := fmt.Sprintf("%s_%d", filePath, chunkIndex)
-
-		chunks = append(chunks, ChunkInfo{
-			ID:          chunkID,
-			FilePath:    filePath,
-			StartLine:   startLine,
-			EndLine:     endLine,
-			Content:     chunkContent,
-			Hash:        hex.EncodeToString(hash[:8]),
-			ContentHash: hex.EncodeToString(contentHash[:]),
-		})
-
-		chunkIndex++
+		ranges = append(ranges, chunkRange{start: pos, end: end})
 
 		// Move to next chunk with overlap
 		nextPos := end - overlapChars
@@ -125,7 +199,7 @@ func (c *Chunker) Chunk(filePath string, content string) []ChunkInfo {
 		pos = nextPos
 	}
 
-	return chunks
+	return ranges
 }
 
 // buildLineStarts returns a slice where lineStarts[i] is the byte offset of line i+1
@@ -160,19 +234,33 @@ func (c *Chunker) ChunkWithContext(filePath string, content string) []ChunkInfo
 
 	// Add file path context to each chunk
 	for i := range chunks {
-		chunks[i].Content = fmt.Sprintf("File: %s\n\n%s", filePath, chunks[i].Content)
+		chunks[i].Content = contextHeader(chunks[i]) + chunks[i].Content
 	}
 
 	return chunks
 }
 
+// contextHeader returns the prefix ChunkWithContext adds to a chunk: the
+// file path, followed by the heading path for Markdown sections.
+func contextHeader(chunk ChunkInfo) string {
+	var b strings.Builder
+	b.WriteString("File: ")
+	b.WriteString(chunk.FilePath)
+	for _, heading := range chunk.Headings {
+		b.WriteString(store.HeadingSeparator)
+		b.WriteString(heading)
+	}
+	b.WriteString("\n\n")
+	return b.String()
+}
+
 // ReChunk splits a single chunk into smaller sub-chunks when it exceeds the embedder's context limit.
 // It uses half the original chunk size to ensure the new chunks fit within limits.
 // The parentIndex is used to generate unique sub-chunk IDs (e.g., "file.go_0_0", "file.go_0_1").
 func (c *Chunker) ReChunk(parent ChunkInfo, parentIndex int) []ChunkInfo {
 	// Strip the file context prefix if present (we'll re-add it later)
 	content := parent.Content
-	filePrefix := fmt.Sprintf("File: %s\n\n", parent.FilePath)
+	filePrefix := contextHeader(parent)
 	hasContext := strings.HasPrefix(content, filePrefix)
 	if hasContext {
 		content = strings.TrimPrefix(content, filePrefix)
@@ -231,6 +319,10 @@ func (c *Chunker) ReChunk(parent ChunkInfo, parentIndex int) []ChunkInfo {
 		// Adjust to absolute line numbers
 		absoluteStartLine := parent.StartLine + subStartLine - 1
 		absoluteEndLine := parent.StartLine + subEndLine - 1
+		if store.IsNotebook(parent.FilePath) {
+			// Notebook chunks are numbered by cell, not by line.
+			absoluteStartLine, absoluteEndLine = parent.StartLine, parent.EndLine
+		}
 
 		// Generate sub-chunk ID: file.go_parentIndex_subIndex
 		hash := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%d:%d:%s", parent.FilePath, parentIndex, subIndex, pos, chunkContent)))
@@ -240,10 +332,10 @@ func (c *Chunker) ReChunk(parent ChunkInfo, parentIndex int) []ChunkInfo {
 		// Re-add file context if it was present in the parent
 		finalContent := chunkContent
 		if hasContext {
-			finalContent = fmt.Sprintf("File: %s\n\n%s", parent.FilePath, chunkContent)
+			finalContent = filePrefix + chunkContent
 		}
 
-		subChunks = append(subChunks, ChunkInfo{
+		sub := ChunkInfo{
 			ID:          subChunkID,
 			FilePath:    parent.FilePath,
 			StartLine:   absoluteStartLine,
@@ -251,7 +343,12 @@ func (c *Chunker) ReChunk(parent ChunkInfo, parentIndex int) []ChunkInfo {
 			Content:     finalContent,
 			Hash:        hex.EncodeToString(hash[:8]),
 			ContentHash: hex.EncodeToString(contentHash[:]),
-		})
+			Headings:    parent.Headings,
+		}
+		if hasContext && parent.EmbedHeader != "" {
+			sub.setEmbedHeader(parent.EmbedHeader)
+		}
+		subChunks = append(subChunks, sub)
 
 		subIndex++
 
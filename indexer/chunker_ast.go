@@ -0,0 +1,143 @@
+package indexer
+
+import (
+	"log"
+	"strings"
+)
+
+// maxASTDepth bounds how far an oversized declaration is descended into
+// before falling back to fixed-size splitting.
+const maxASTDepth = 8
+
+// syntaxNode is the part of a parsed syntax tree AST chunking needs.
+type syntaxNode interface {
+	// Span returns the byte range [start, end) of the node.
+	Span() (start, end int)
+	// IsComment reports whether the node is a comment, which stays attached
+	// to the declaration that follows it.
+	IsComment() bool
+	// Children returns the nodes an oversized node splits into: the members
+	// or statements of its body.
+	Children() []syntaxNode
+}
+
+// syntaxParser parses a file into a syntax tree. It returns ok=false for
+// languages it has no grammar for; release frees the tree.
+type syntaxParser interface {
+	Parse(filePath string, content string) (root syntaxNode, release func(), ok bool)
+}
+
+// WithASTChunking aligns chunks to function, method, class and type
+// boundaries using tree-sitter grammars. Files without a grammar, and builds
+// without the treesitter tag, keep fixed-size chunks.
+func WithASTChunking() ChunkerOption {
+	return func(c *Chunker) {
+		c.syntax = newSyntaxParser()
+		if c.syntax == nil {
+			log.Printf("Warning: chunking.strategy ast requires a build with -tags treesitter; using fixed-size chunks")
+		}
+	}
+}
+
+// astRanges returns chunk ranges aligned to syntax boundaries, or nil if the
+// file can't be parsed.
+func (c *Chunker) astRanges(filePath string, content string, lineStarts []int) []chunkRange {
+	root, release, ok := c.syntax.Parse(filePath, content)
+	if !ok {
+		return nil
+	}
+	defer release()
+
+	p := astPacker{chunker: c, content: content, lineStarts: lineStarts}
+	p.pack(root.Children(), 0, len(content), 0)
+	return p.ranges
+}
+
+// astPacker greedily packs consecutive sibling declarations into chunks of at
+// most chunkSize tokens, descending into declarations too large for one.
+type astPacker struct {
+	chunker    *Chunker
+	content    string
+	lineStarts []int
+	ranges     []chunkRange
+}
+
+// astSegment is a line-aligned slice of the parent range holding one
+// declaration and the comments before it.
+type astSegment struct {
+	start, end int
+	node       syntaxNode
+}
+
+// segments splits [start, end) at the start of the line of each child. The
+// text before the first child (e.g. a function signature) belongs to the
+// first segment and the text after the last child (a closing brace) to the
+// last one.
+func (p *astPacker) segments(children []syntaxNode, start, end int) []astSegment {
+	var segs []astSegment
+	segStart := start
+	var prevComment bool
+	for i, child := range children {
+		childStart, _ := child.Span()
+		boundary := p.lineStart(childStart)
+		if i > 0 && !prevComment && boundary > segStart && boundary < end {
+			segs = append(segs, astSegment{start: segStart, end: boundary, node: children[i-1]})
+			segStart = boundary
+		}
+		prevComment = child.IsComment()
+	}
+	if len(children) > 0 && segStart < end {
+		segs = append(segs, astSegment{start: segStart, end: end, node: children[len(children)-1]})
+	}
+	return segs
+}
+
+func (p *astPacker) lineStart(pos int) int {
+	return p.lineStarts[getLineNumber(p.lineStarts, pos)-1]
+}
+
+func (p *astPacker) pack(children []syntaxNode, start, end, depth int) {
+	maxChars := p.chunker.chunkSize * CharsPerToken
+	segs := p.segments(children, start, end)
+	if len(segs) == 0 {
+		p.emit(p.chunker.fixedRanges(p.content, start, end)...)
+		return
+	}
+
+	curStart, curEnd := -1, -1
+	flush := func() {
+		if curStart >= 0 {
+			p.emit(chunkRange{start: curStart, end: curEnd})
+			curStart = -1
+		}
+	}
+
+	for _, seg := range segs {
+		if seg.end-seg.start > maxChars {
+			flush()
+			if kids := seg.node.Children(); depth < maxASTDepth && len(kids) > 0 {
+				p.pack(kids, seg.start, seg.end, depth+1)
+			} else {
+				p.emit(p.chunker.fixedRanges(p.content, seg.start, seg.end)...)
+			}
+			continue
+		}
+		if curStart >= 0 && seg.end-curStart > maxChars {
+			flush()
+		}
+		if curStart < 0 {
+			curStart = seg.start
+		}
+		curEnd = seg.end
+	}
+	flush()
+}
+
+// emit appends ranges, dropping whitespace-only ones.
+func (p *astPacker) emit(ranges ...chunkRange) {
+	for _, r := range ranges {
+		if strings.TrimSpace(p.content[r.start:r.end]) != "" {
+			p.ranges = append(p.ranges, r)
+		}
+	}
+}
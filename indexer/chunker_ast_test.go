@@ -0,0 +1,158 @@
+package indexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeNode is a syntax node over a fixed byte range.
+type fakeNode struct {
+	start, end int
+	comment    bool
+	children   []syntaxNode
+}
+
+func (n *fakeNode) Span() (int, int)       { return n.start, n.end }
+func (n *fakeNode) IsComment() bool        { return n.comment }
+func (n *fakeNode) Children() []syntaxNode { return n.children }
+
+type fakeParser struct {
+	root *fakeNode
+}
+
+func (p fakeParser) Parse(filePath string, content string) (syntaxNode, func(), bool) {
+	if p.root == nil {
+		return nil, nil, false
+	}
+	return p.root, func() {}, true
+}
+
+// nodeFor returns a fake node spanning the first occurrence of text.
+func nodeFor(t *testing.T, content, text string, children ...syntaxNode) *fakeNode {
+	t.Helper()
+	start := strings.Index(content, text)
+	if start < 0 {
+		t.Fatalf("%q not found in content", text)
+	}
+	return &fakeNode{start: start, end: start + len(text), children: children}
+}
+
+func TestChunker_ASTPacksSiblingsAndKeepsComments(t *testing.T) {
+	content := "package demo\n\n// A does a.\nfunc A() {}\n\nfunc B() {}\n\nfunc C() {\n\treturn\n}\n"
+	comment := nodeFor(t, content, "// A does a.")
+	comment.comment = true
+	root := &fakeNode{end: len(content), children: []syntaxNode{
+		nodeFor(t, content, "package demo"),
+		comment,
+		nodeFor(t, content, "func A() {}"),
+		nodeFor(t, content, "func B() {}"),
+		nodeFor(t, content, "func C() {\n\treturn\n}"),
+	}}
+
+	tests := []struct {
+		name      string
+		chunkSize int
+		want      []string
+	}{
+		{
+			// 7 tokens = 28 chars: the comment stays with func A.
+			name:      "comment attached",
+			chunkSize: 7,
+			want: []string{
+				"package demo\n\n",
+				"// A does a.\nfunc A() {}\n\n",
+				"func B() {}\n\n",
+				"func C() {\n\treturn\n}\n",
+			},
+		},
+		{
+			// 12 tokens = 48 chars: small siblings are packed together.
+			name:      "siblings packed",
+			chunkSize: 12,
+			want: []string{
+				"package demo\n\n// A does a.\nfunc A() {}\n\n",
+				"func B() {}\n\nfunc C() {\n\treturn\n}\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChunker(tt.chunkSize, 0)
+			c.syntax = fakeParser{root: root}
+			chunks := c.Chunk("demo.go", content)
+
+			if len(chunks) != len(tt.want) {
+				t.Fatalf("expected %d chunks, got %d: %+v", len(tt.want), len(chunks), chunks)
+			}
+			for i, w := range tt.want {
+				if chunks[i].Content != w {
+					t.Errorf("chunk %d = %q, want %q", i, chunks[i].Content, w)
+				}
+			}
+		})
+	}
+}
+
+func TestChunker_ASTSplitsOversizedBodyAtStatements(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("func Big() {\n")
+	for i := 0; i < 6; i++ {
+		b.WriteString("\tstatementNumber()\n")
+	}
+	b.WriteString("}\n")
+	content := b.String()
+
+	var stmts []syntaxNode
+	pos := 0
+	for i := 0; i < 6; i++ {
+		start := strings.Index(content[pos:], "statementNumber()") + pos
+		stmts = append(stmts, &fakeNode{start: start, end: start + len("statementNumber()")})
+		pos = start + 1
+	}
+	fn := &fakeNode{start: 0, end: len(content) - 1, children: stmts}
+	root := &fakeNode{end: len(content), children: []syntaxNode{fn}}
+
+	c := NewChunker(12, 0)
+	c.syntax = fakeParser{root: root}
+	chunks := c.Chunk("big.go", content)
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected the body to be split, got %d chunk(s)", len(chunks))
+	}
+	if !strings.HasPrefix(chunks[0].Content, "func Big() {\n") {
+		t.Errorf("first chunk should start with the signature, got %q", chunks[0].Content)
+	}
+	if !strings.HasSuffix(chunks[len(chunks)-1].Content, "}\n") {
+		t.Errorf("last chunk should end with the closing brace, got %q", chunks[len(chunks)-1].Content)
+	}
+	var joined strings.Builder
+	for _, chunk := range chunks {
+		if !strings.HasSuffix(chunk.Content, "\n") {
+			t.Errorf("chunk %q does not end at a line boundary", chunk.Content)
+		}
+		joined.WriteString(chunk.Content)
+	}
+	if joined.String() != content {
+		t.Errorf("chunks should cover the file exactly once")
+	}
+}
+
+func TestChunker_ASTFallsBackWithoutGrammar(t *testing.T) {
+	content := strings.Repeat("line of text\n", 10)
+
+	fixed := NewChunker(8, 2).Chunk("notes.txt", content)
+
+	c := NewChunker(8, 2)
+	c.syntax = fakeParser{}
+	chunks := c.Chunk("notes.txt", content)
+
+	if len(chunks) != len(fixed) {
+		t.Fatalf("expected fallback to fixed-size chunks (%d), got %d", len(fixed), len(chunks))
+	}
+	for i := range chunks {
+		if !reflect.DeepEqual(chunks[i], fixed[i]) {
+			t.Errorf("chunk %d differs from fixed-size chunking", i)
+		}
+	}
+}
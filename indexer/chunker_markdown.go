@@ -0,0 +1,194 @@
+package indexer
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// isMarkdownFile reports whether filePath is chunked along its headings.
+func isMarkdownFile(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), ".md")
+}
+
+// mdNode is a Markdown section (a heading and everything up to the next
+// heading of the same or a higher level) or a block of a section's body:
+// a paragraph or a fenced code block. The children of a code block are the
+// blank-line separated parts of its body, so an oversized block is split
+// between them rather than mid-line. It implements syntaxNode so sections
+// are packed like declarations.
+type mdNode struct {
+	start, end int
+	level      int // heading level for sections, 0 for the root and blocks
+	title      string
+	children   []syntaxNode
+}
+
+func (n *mdNode) Span() (int, int)       { return n.start, n.end }
+func (n *mdNode) IsComment() bool        { return false }
+func (n *mdNode) Children() []syntaxNode { return n.children }
+
+// markdownRanges returns chunk ranges aligned to the heading hierarchy of a
+// Markdown document and, for each range, the headings of the innermost
+// section containing it.
+func (c *Chunker) markdownRanges(content string, lineStarts []int) ([]chunkRange, [][]string) {
+	root := parseMarkdownSections(content, lineStarts)
+
+	p := astPacker{chunker: c, content: content, lineStarts: lineStarts}
+	p.pack(root.children, 0, len(content), 0)
+
+	headings := make([][]string, len(p.ranges))
+	for i, r := range p.ranges {
+		headings[i] = sectionHeadings(root, r)
+	}
+	return p.ranges, headings
+}
+
+// sectionHeadings returns the titles of the nested sections that fully
+// contain r, outermost first.
+func sectionHeadings(root *mdNode, r chunkRange) []string {
+	var titles []string
+	node := root
+	for {
+		var inner *mdNode
+		for _, child := range node.children {
+			section := child.(*mdNode)
+			if section.level > 0 && section.start <= r.start && r.end <= section.end {
+				inner = section
+				break
+			}
+		}
+		if inner == nil {
+			return titles
+		}
+		titles = append(titles, inner.title)
+		node = inner
+	}
+}
+
+// parseMarkdownSections builds the section tree of a Markdown document.
+// Only ATX headings ("## Title") outside fenced code blocks open sections.
+func parseMarkdownSections(content string, lineStarts []int) *mdNode {
+	root := &mdNode{start: 0, end: len(content)}
+	stack := []*mdNode{root}
+	var block *mdNode // paragraph or fence being read
+	var fence string  // opening fence of the code block being read
+	var part *mdNode  // blank-line separated part of the code block body
+
+	closeBlock := func() {
+		if block != nil {
+			top := stack[len(stack)-1]
+			top.children = append(top.children, block)
+			block = nil
+		}
+	}
+
+	for i, lineStart := range lineStarts {
+		lineEnd := len(content)
+		if i+1 < len(lineStarts) {
+			lineEnd = lineStarts[i+1]
+		}
+		line := strings.TrimRight(content[lineStart:lineEnd], "\r\n")
+
+		if fence != "" {
+			block.end = lineEnd
+			switch {
+			case isClosingFence(line, fence):
+				fence = ""
+				closeBlock()
+			case strings.TrimSpace(line) == "":
+				part = nil
+			case part == nil:
+				part = &mdNode{start: lineStart, end: lineEnd}
+				block.children = append(block.children, part)
+			default:
+				part.end = lineEnd
+			}
+			continue
+		}
+
+		if open := openingFence(line); open != "" {
+			closeBlock()
+			fence = open
+			part = nil
+			block = &mdNode{start: lineStart, end: lineEnd}
+			continue
+		}
+
+		if level, title, ok := atxHeading(line); ok {
+			closeBlock()
+			for len(stack) > 1 && stack[len(stack)-1].level >= level {
+				stack[len(stack)-1].end = lineStart
+				stack = stack[:len(stack)-1]
+			}
+			section := &mdNode{start: lineStart, end: len(content), level: level, title: title}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, section)
+			stack = append(stack, section)
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			closeBlock()
+			continue
+		}
+		if block == nil {
+			block = &mdNode{start: lineStart}
+		}
+		block.end = lineEnd
+	}
+	closeBlock()
+	return root
+}
+
+// atxHeading parses an ATX heading line such as "## Installation ##".
+func atxHeading(line string) (level int, title string, ok bool) {
+	line = trimIndent(line)
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	title = strings.TrimSpace(rest)
+	if trimmed := strings.TrimRight(title, "#"); trimmed == "" || strings.HasSuffix(trimmed, " ") || strings.HasSuffix(trimmed, "\t") {
+		title = strings.TrimSpace(trimmed)
+	}
+	return level, title, true
+}
+
+// openingFence returns the fence ("```" or "~~~", possibly longer) opening a
+// code block on line, or "".
+func openingFence(line string) string {
+	line = trimIndent(line)
+	if !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "~~~") {
+		return ""
+	}
+	n := 0
+	for n < len(line) && line[n] == line[0] {
+		n++
+	}
+	// Backtick fences can't have backticks in their info string.
+	if line[0] == '`' && strings.Contains(line[n:], "`") {
+		return ""
+	}
+	return line[:n]
+}
+
+// isClosingFence reports whether line closes a code block opened by fence.
+func isClosingFence(line, fence string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+// trimIndent strips up to three leading spaces, the indentation Markdown
+// allows before headings and fences.
+func trimIndent(line string) string {
+	for i := 0; i < 3 && strings.HasPrefix(line, " "); i++ {
+		line = line[1:]
+	}
+	return line
+}
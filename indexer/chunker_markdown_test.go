@@ -0,0 +1,176 @@
+package indexer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const markdownDoc = `# Project
+
+Intro paragraph for the project.
+
+## Installation
+
+Install the binary from the releases page.
+
+### Docker
+
+Run the image:
+
+` + "```sh" + `
+# not a heading
+docker run --rm -v "$PWD:/src" project/project:latest index /src
+` + "```" + `
+
+### Homebrew
+
+Install with brew install project.
+
+## License
+
+MIT
+`
+
+func TestChunker_MarkdownSplitsOnHeadings(t *testing.T) {
+	// 30 tokens = 120 chars: the Docker section is split before its code
+	// block, which fits whole.
+	chunks := NewChunker(30, 0).Chunk("README.md", markdownDoc)
+
+	var docker *ChunkInfo
+	for i := range chunks {
+		if strings.HasPrefix(chunks[i].Content, "### Docker") {
+			docker = &chunks[i]
+		}
+	}
+	if docker == nil {
+		t.Fatalf("expected a chunk starting at the Docker heading, got %+v", chunks)
+	}
+	if want := []string{"Project", "Installation", "Docker"}; !reflect.DeepEqual(docker.Headings, want) {
+		t.Errorf("Docker headings = %v, want %v", docker.Headings, want)
+	}
+
+	for _, chunk := range chunks {
+		if strings.Contains(chunk.Content, "```sh") != strings.Contains(chunk.Content, "docker run") {
+			t.Errorf("fenced code block was split: %q", chunk.Content)
+		}
+		if strings.HasPrefix(chunk.Content, "# not a heading") {
+			t.Errorf("comment inside a code fence started a section: %q", chunk.Content)
+		}
+	}
+
+	last := chunks[len(chunks)-1]
+	if want := []string{"Project", "License"}; !reflect.DeepEqual(last.Headings, want) {
+		t.Errorf("License headings = %v, want %v", last.Headings, want)
+	}
+}
+
+func TestChunker_MarkdownSplitsOversizedFenceAtBlankLines(t *testing.T) {
+	var body strings.Builder
+	for i := range 6 {
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		for j := range 3 {
+			body.WriteString("fmt.Println(\"block " + string(rune('a'+i)) + " line " + string(rune('0'+j)) + "\")\n")
+		}
+	}
+	doc := "# Example\n\n```go\n" + body.String() + "```\n"
+
+	// 40 tokens = 160 chars: the fence is too large for one chunk, but each
+	// blank-line separated part of its body fits.
+	chunks := NewChunker(40, 0).Chunk("README.md", doc)
+	if len(chunks) < 2 {
+		t.Fatalf("expected the oversized fence to be split, got %d chunks", len(chunks))
+	}
+	for _, chunk := range chunks {
+		lines := strings.Split(strings.TrimSpace(chunk.Content), "\n")
+		first, last := lines[0], lines[len(lines)-1]
+		if strings.HasPrefix(first, "fmt.Println") && !strings.HasSuffix(first, "line 0\")") {
+			t.Errorf("chunk starts inside a part of the fence body: %q", chunk.Content)
+		}
+		if strings.HasPrefix(last, "fmt.Println") && !strings.HasSuffix(last, "line 2\")") {
+			t.Errorf("chunk ends inside a part of the fence body: %q", chunk.Content)
+		}
+	}
+}
+
+func TestChunker_MarkdownPacksSmallSections(t *testing.T) {
+	chunks := NewChunker(512, 50).Chunk("README.md", markdownDoc)
+
+	if len(chunks) != 1 {
+		t.Fatalf("expected the whole document in one chunk, got %d", len(chunks))
+	}
+	if want := []string{"Project"}; !reflect.DeepEqual(chunks[0].Headings, want) {
+		t.Errorf("headings = %v, want %v", chunks[0].Headings, want)
+	}
+	if chunks[0].StartLine != 1 || chunks[0].Content != markdownDoc {
+		t.Errorf("expected the full document from line 1, got line %d", chunks[0].StartLine)
+	}
+}
+
+func TestChunker_MarkdownBreadcrumbInContext(t *testing.T) {
+	chunks := NewChunker(30, 0).ChunkWithContext("docs/README.md", markdownDoc)
+
+	var docker ChunkInfo
+	for _, chunk := range chunks {
+		if strings.Contains(chunk.Content, "### Docker") {
+			docker = chunk
+		}
+	}
+	header := "File: docs/README.md › Project › Installation › Docker\n\n"
+	if !strings.HasPrefix(docker.Content, header+"### Docker") {
+		t.Fatalf("expected breadcrumb header, got %q", docker.Content)
+	}
+}
+
+func TestChunker_ReChunkKeepsBreadcrumb(t *testing.T) {
+	parent := ChunkInfo{
+		FilePath:  "README.md",
+		StartLine: 9,
+		Headings:  []string{"Project", "Installation", "Docker"},
+	}
+	body := strings.Repeat("Run the container with the source directory mounted.\n", 30)
+	parent.Content = contextHeader(parent) + body
+
+	subChunks := NewChunker(512, 0).ReChunk(parent, 0)
+	if len(subChunks) < 2 {
+		t.Fatalf("expected the chunk to be split, got %d sub-chunks", len(subChunks))
+	}
+	header := "File: README.md › Project › Installation › Docker\n\nRun"
+	for _, sub := range subChunks {
+		if !strings.HasPrefix(sub.Content, header) {
+			t.Errorf("sub-chunk lost the breadcrumb: %q", sub.Content)
+		}
+		if !reflect.DeepEqual(sub.Headings, parent.Headings) {
+			t.Errorf("sub-chunk headings = %v, want %v", sub.Headings, parent.Headings)
+		}
+	}
+	if subChunks[0].StartLine != parent.StartLine {
+		t.Errorf("first sub-chunk starts at line %d, want %d", subChunks[0].StartLine, parent.StartLine)
+	}
+}
+
+func TestATXHeading(t *testing.T) {
+	tests := []struct {
+		line      string
+		wantLevel int
+		wantTitle string
+		wantOK    bool
+	}{
+		{"# Title", 1, "Title", true},
+		{"### Docker ###", 3, "Docker", true},
+		{"  ## Indented", 2, "Indented", true},
+		{"## C#", 2, "C#", true},
+		{"#hashtag", 0, "", false},
+		{"####### Too deep", 0, "", false},
+		{"    # Code", 0, "", false},
+	}
+	for _, tt := range tests {
+		level, title, ok := atxHeading(tt.line)
+		if level != tt.wantLevel || title != tt.wantTitle || ok != tt.wantOK {
+			t.Errorf("atxHeading(%q) = %d, %q, %v; want %d, %q, %v",
+				tt.line, level, title, ok, tt.wantLevel, tt.wantTitle, tt.wantOK)
+		}
+	}
+}
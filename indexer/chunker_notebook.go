@@ -0,0 +1,123 @@
+package indexer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// WithNotebookOutputs indexes the text outputs of notebook code cells
+// (stream output, text/plain results and errors) along with their source.
+func WithNotebookOutputs() ChunkerOption {
+	return func(c *Chunker) {
+		c.notebookOutputs = true
+	}
+}
+
+// notebook is the part of the Jupyter notebook format (nbformat 4) the
+// chunker reads.
+type notebook struct {
+	Cells []notebookCell `json:"cells"`
+}
+
+type notebookCell struct {
+	CellType string           `json:"cell_type"`
+	Source   notebookText     `json:"source"`
+	Outputs  []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType string                  `json:"output_type"`
+	Text       notebookText            `json:"text"`
+	Data       map[string]notebookText `json:"data"`
+	Ename      string                  `json:"ename"`
+	Evalue     string                  `json:"evalue"`
+}
+
+// notebookText is a multiline string, stored either as one string or as a
+// list of lines.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = notebookText(s)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		// Non-text data such as application/json outputs.
+		*t = ""
+		return nil
+	}
+	*t = notebookText(strings.Join(lines, ""))
+	return nil
+}
+
+// chunkNotebook returns one chunk per code or Markdown cell of a Jupyter
+// notebook, splitting cells larger than the chunk size. StartLine and
+// EndLine hold the 1-based cell number instead of line numbers.
+func (c *Chunker) chunkNotebook(filePath string, content string) []ChunkInfo {
+	var nb notebook
+	if err := json.Unmarshal([]byte(content), &nb); err != nil {
+		return nil
+	}
+
+	var chunks []ChunkInfo
+	for i, cell := range nb.Cells {
+		if cell.CellType != "code" && cell.CellType != "markdown" {
+			continue
+		}
+		cellNum := i + 1
+		text := c.cellText(cell)
+
+		for _, r := range c.fixedRanges(text, 0, len(text)) {
+			chunkContent := text[r.start:r.end]
+			hash := sha256.Sum256([]byte(fmt.Sprintf("%s#cell-%d:%d:%d:%s", filePath, cellNum, r.start, r.end, chunkContent)))
+			contentHash := sha256.Sum256([]byte(chunkContent))
+
+			chunks = append(chunks, ChunkInfo{
+				ID:          fmt.Sprintf("%s_%d", filePath, len(chunks)),
+				FilePath:    filePath,
+				StartLine:   cellNum,
+				EndLine:     cellNum,
+				Content:     chunkContent,
+				Hash:        hex.EncodeToString(hash[:8]),
+				ContentHash: hex.EncodeToString(contentHash[:]),
+			})
+		}
+	}
+	return chunks
+}
+
+// cellText returns the text indexed for a cell: its source, followed by its
+// text outputs when enabled.
+func (c *Chunker) cellText(cell notebookCell) string {
+	text := string(cell.Source)
+	if !c.notebookOutputs || cell.CellType != "code" {
+		return text
+	}
+
+	var outputs []string
+	for _, out := range cell.Outputs {
+		switch out.OutputType {
+		case "stream":
+			outputs = append(outputs, string(out.Text))
+		case "execute_result", "display_data":
+			if plain := out.Data["text/plain"]; plain != "" {
+				outputs = append(outputs, string(plain))
+			}
+		case "error":
+			outputs = append(outputs, out.Ename+": "+out.Evalue)
+		}
+	}
+	if len(outputs) == 0 {
+		return text
+	}
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text + "\n# Output:\n" + strings.Join(outputs, "\n")
+}
@@ -0,0 +1,136 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testNotebook = `{
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": ["# Churn model\n", "Loads the events table."]},
+  {"cell_type": "code", "execution_count": 1, "metadata": {}, "outputs": [
+    {"output_type": "stream", "name": "stdout", "text": ["loaded 1200 rows\n"]},
+    {"output_type": "display_data", "data": {"image/png": "iVBORw0KGgo=", "text/plain": ["<Figure>"]}, "metadata": {}}
+   ],
+   "source": "df = load_events()\nprint(f'loaded {len(df)} rows')"},
+  {"cell_type": "raw", "metadata": {}, "source": "raw text"},
+  {"cell_type": "code", "execution_count": 2, "metadata": {}, "outputs": [
+    {"output_type": "error", "ename": "KeyError", "evalue": "'churn'", "traceback": []}
+   ],
+   "source": ["model = fit(df['churn'])"]}
+ ],
+ "metadata": {"kernelspec": {"language": "python", "name": "python3"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`
+
+func TestChunker_NotebookCells(t *testing.T) {
+	chunks := NewChunker(512, 50).Chunk("analysis/churn.ipynb", testNotebook)
+
+	want := []struct {
+		cell    int
+		content string
+	}{
+		{1, "# Churn model\nLoads the events table."},
+		{2, "df = load_events()\nprint(f'loaded {len(df)} rows')"},
+		{4, "model = fit(df['churn'])"},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("expected %d chunks, got %d: %+v", len(want), len(chunks), chunks)
+	}
+	for i, w := range want {
+		if chunks[i].StartLine != w.cell || chunks[i].EndLine != w.cell {
+			t.Errorf("chunk %d: cell %d-%d, want %d", i, chunks[i].StartLine, chunks[i].EndLine, w.cell)
+		}
+		if chunks[i].Content != w.content {
+			t.Errorf("chunk %d content = %q, want %q", i, chunks[i].Content, w.content)
+		}
+	}
+}
+
+func TestChunker_NotebookOutputs(t *testing.T) {
+	chunks := NewChunker(512, 50, WithNotebookOutputs()).Chunk("churn.ipynb", testNotebook)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(chunks))
+	}
+
+	if !strings.Contains(chunks[1].Content, "# Output:\nloaded 1200 rows\n\n<Figure>") {
+		t.Errorf("expected stream and text/plain outputs, got %q", chunks[1].Content)
+	}
+	if strings.Contains(chunks[1].Content, "iVBORw0KGgo") {
+		t.Error("image outputs should not be indexed")
+	}
+	if !strings.HasSuffix(chunks[2].Content, "# Output:\nKeyError: 'churn'") {
+		t.Errorf("expected the error output, got %q", chunks[2].Content)
+	}
+}
+
+func TestChunker_NotebookReChunkKeepsCell(t *testing.T) {
+	c := NewChunker(512, 0)
+	parent := ChunkInfo{FilePath: "big.ipynb", StartLine: 7, EndLine: 7}
+	parent.Content = contextHeader(parent) + strings.Repeat("total += compute(row)\n", 150)
+
+	for _, sub := range c.ReChunk(parent, 0) {
+		if sub.StartLine != 7 || sub.EndLine != 7 {
+			t.Errorf("sub-chunk should stay in cell 7, got %d-%d", sub.StartLine, sub.EndLine)
+		}
+	}
+}
+
+func TestScanner_Notebooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "good.ipynb"), []byte(testNotebook), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "broken.ipynb"), []byte(`{"cells": [`), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	files, skipped, err := NewScanner(tmpDir, ignoreMatcher).Scan()
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "good.ipynb" {
+		t.Errorf("expected only good.ipynb, got %+v", files)
+	}
+	if len(skipped) != 1 || skipped[0] != "broken.ipynb (invalid notebook)" {
+		t.Errorf("expected broken.ipynb to be skipped, got %v", skipped)
+	}
+}
+
+func TestIndexAll_CountsInvalidNotebooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "good.ipynb"), []byte(testNotebook), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "broken.ipynb"), []byte(`{"cells": [`), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "image.png"), []byte("png"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	idx := NewIndexer(tmpDir, newMockStore(), newMockEmbedder(), NewChunker(512, 50), NewScanner(tmpDir, ignoreMatcher), time.Time{})
+	stats, err := idx.IndexAllWithProgress(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("IndexAllWithProgress failed: %v", err)
+	}
+
+	want := map[SkipReason]int{SkipInvalidNotebook: 1, SkipUnsupported: 1}
+	if !reflect.DeepEqual(stats.SkipCounts, want) {
+		t.Errorf("SkipCounts = %v, want %v", stats.SkipCounts, want)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build treesitter
+
+package indexer
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// ASTChunkingSupported reports whether this build can chunk along syntax
+// boundaries.
+const ASTChunkingSupported = true
+
+// treeSitterParser parses files with the grammars bundled in the trace
+// package.
+type treeSitterParser struct{}
+
+func newSyntaxParser() syntaxParser {
+	return treeSitterParser{}
+}
+
+// Parse uses a fresh parser per call since tree-sitter parsers are not safe
+// for concurrent use.
+func (treeSitterParser) Parse(filePath string, content string) (syntaxNode, func(), bool) {
+	lang := trace.TreeSitterLanguage(filepath.Ext(filePath))
+	if lang == nil {
+		return nil, nil, false
+	}
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(lang)
+	tree, err := parser.ParseCtx(context.Background(), nil, []byte(content))
+	parser.Close()
+	if err != nil || tree == nil {
+		return nil, nil, false
+	}
+	return treeSitterNode{node: tree.RootNode()}, tree.Close, true
+}
+
+type treeSitterNode struct {
+	node *sitter.Node
+}
+
+func (n treeSitterNode) Span() (int, int) {
+	return int(n.node.StartByte()), int(n.node.EndByte())
+}
+
+func (n treeSitterNode) IsComment() bool {
+	return strings.Contains(n.node.Type(), "comment")
+}
+
+// Children returns the named children of the node's body (class members,
+// function statements) when it has one, otherwise its own named children.
+func (n treeSitterNode) Children() []syntaxNode {
+	target := n.node
+	if body := n.node.ChildByFieldName("body"); body != nil && body.NamedChildCount() > 0 {
+		target = body
+	}
+
+	count := int(target.NamedChildCount())
+	children := make([]syntaxNode, 0, count)
+	for i := 0; i < count; i++ {
+		children = append(children, treeSitterNode{node: target.NamedChild(i)})
+	}
+	return children
+}
@@ -0,0 +1,13 @@
+//go:build !treesitter
+
+package indexer
+
+// ASTChunkingSupported reports whether this build can chunk along syntax
+// boundaries.
+const ASTChunkingSupported = false
+
+// newSyntaxParser returns nil: tree-sitter grammars are only compiled in with
+// the treesitter build tag.
+func newSyntaxParser() syntaxParser {
+	return nil
+}
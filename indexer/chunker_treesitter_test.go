@@ -0,0 +1,36 @@
+//go:build treesitter
+
+package indexer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChunker_ASTGoFunctionsStayWhole(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("package demo\n\n")
+	for _, name := range []string{"Alpha", "Beta", "Gamma", "Delta"} {
+		b.WriteString("// " + name + " computes a value.\n")
+		b.WriteString("func " + name + "(x int) int {\n")
+		b.WriteString("\ty := x * 2\n\tif y > 10 {\n\t\treturn y - 10\n\t}\n\treturn y + 1\n}\n\n")
+	}
+	content := b.String()
+
+	c := NewChunker(40, 5, WithASTChunking())
+	chunks := c.Chunk("demo.go", content)
+	if len(chunks) == 0 {
+		t.Fatal("expected chunks")
+	}
+
+	for _, chunk := range chunks {
+		opens := strings.Count(chunk.Content, "func ")
+		closes := strings.Count(chunk.Content, "\n}\n")
+		if opens != closes {
+			t.Errorf("chunk cuts a function in half:\n%s", chunk.Content)
+		}
+		if strings.Contains(chunk.Content, "func ") && !strings.Contains(chunk.Content, "computes a value") {
+			t.Errorf("doc comment was separated from its function:\n%s", chunk.Content)
+		}
+	}
+}
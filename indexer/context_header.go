@@ -0,0 +1,113 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// maxHeaderSymbols caps the symbols listed in a context header.
+const maxHeaderSymbols = 8
+
+// FeaturePathFunc returns the RPG feature path of a file, or "" if unknown.
+type FeaturePathFunc func(filePath string) string
+
+// EnableContextHeaders makes the indexer embed each chunk behind a header
+// naming its file, language, Markdown section, enclosing symbols and RPG
+// feature path, instead of the plain file path. The stored chunk content is
+// unchanged.
+func (idx *Indexer) EnableContextHeaders() {
+	idx.contextHeaders = true
+}
+
+// SetFeaturePathFunc sets the source of RPG feature paths for context
+// headers.
+func (idx *Indexer) SetFeaturePathFunc(fn FeaturePathFunc) {
+	idx.featurePath = fn
+}
+
+// addContextHeaders sets the embed header of each chunk of file when context
+// headers are enabled.
+func (idx *Indexer) addContextHeaders(ctx context.Context, file FileInfo, chunks []ChunkInfo) {
+	if !idx.contextHeaders {
+		return
+	}
+
+	var symbols []trace.Symbol
+	if idx.symbols != nil {
+		// Extraction errors only leave the symbols out of the header.
+		symbols, _ = idx.symbols.ExtractSymbols(ctx, file.Path, file.Content)
+		sort.SliceStable(symbols, func(i, j int) bool { return symbols[i].Line < symbols[j].Line })
+	}
+	var feature string
+	if idx.featurePath != nil {
+		feature = idx.featurePath(file.Path)
+	}
+	language := store.LanguageForPath(file.Path)
+
+	for i := range chunks {
+		names := enclosingSymbols(symbols, chunks[i].StartLine, chunks[i].EndLine)
+		chunks[i].setEmbedHeader(buildContextHeader(chunks[i], language, names, feature))
+	}
+}
+
+// buildContextHeader formats the header embedded before a chunk's content.
+func buildContextHeader(chunk ChunkInfo, language string, symbols []string, feature string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "File: %s\n", chunk.FilePath)
+	if language != "" {
+		fmt.Fprintf(&b, "Language: %s\n", language)
+	}
+	if len(chunk.Headings) > 0 {
+		fmt.Fprintf(&b, "Section: %s\n", strings.Join(chunk.Headings, store.HeadingSeparator))
+	}
+	if len(symbols) > 0 {
+		fmt.Fprintf(&b, "Symbols: %s\n", strings.Join(symbols, ", "))
+	}
+	if feature != "" {
+		fmt.Fprintf(&b, "Feature: %s\n", feature)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// enclosingSymbols returns the names of the symbols declared within lines
+// [startLine, endLine], preceded by the symbol whose body the chunk starts
+// in, if any. symbols must be sorted by line.
+func enclosingSymbols(symbols []trace.Symbol, startLine, endLine int) []string {
+	var names []string
+	add := func(sym trace.Symbol) {
+		name := sym.Name
+		if sym.Receiver != "" {
+			name = sym.Receiver + "." + sym.Name
+		}
+		if len(names) < maxHeaderSymbols && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	// The last symbol declared before the chunk encloses its first line,
+	// unless its end is known to come earlier.
+	var outer *trace.Symbol
+	for i := range symbols {
+		if symbols[i].Line >= startLine {
+			break
+		}
+		outer = &symbols[i]
+	}
+	if outer != nil && (outer.EndLine == 0 || outer.EndLine >= startLine) {
+		add(*outer)
+	}
+
+	for _, sym := range symbols {
+		if sym.Line >= startLine && sym.Line <= endLine {
+			add(sym)
+		}
+	}
+	return names
+}
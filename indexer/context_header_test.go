@@ -0,0 +1,91 @@
+package indexer
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// recordingEmbedder records the texts it is asked to embed.
+type recordingEmbedder struct {
+	mockEmbedder
+	texts []string
+}
+
+func (m *recordingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	m.texts = append(m.texts, texts...)
+	return m.mockEmbedder.EmbedBatch(ctx, texts)
+}
+
+func TestIndexFile_ContextHeaders(t *testing.T) {
+	content := "package payment\n\n// Charge retries failed requests.\nfunc (c *Client) Charge() error {\n\treturn retry(c.do)\n}\n"
+	emb := &recordingEmbedder{}
+	st := newMockStore()
+	idx := NewIndexer(t.TempDir(), st, emb, NewChunker(512, 50), nil, time.Time{})
+	idx.EnableContextHeaders()
+	idx.SetFeaturePathFunc(func(filePath string) string { return "billing/payments/client" })
+
+	if _, err := idx.IndexFile(context.Background(), FileInfo{Path: "internal/payment/client.go", Content: content}); err != nil {
+		t.Fatalf("IndexFile failed: %v", err)
+	}
+
+	if len(emb.texts) != 1 {
+		t.Fatalf("expected 1 embedded text, got %d", len(emb.texts))
+	}
+	wantHeader := "File: internal/payment/client.go\nLanguage: go\nSymbols: Client.Charge\nFeature: billing/payments/client\n\n"
+	if emb.texts[0] != wantHeader+content {
+		t.Errorf("embedded text = %q, want header %q followed by the content", emb.texts[0], wantHeader)
+	}
+
+	for _, chunk := range st.chunks {
+		if chunk.Content != "File: internal/payment/client.go\n\n"+content {
+			t.Errorf("stored content should not include the header, got %q", chunk.Content)
+		}
+	}
+}
+
+func TestReChunk_KeepsEmbedHeader(t *testing.T) {
+	c := NewChunker(512, 0)
+	parent := c.ChunkWithContext("big.go", strings.Repeat("x := compute(value)\n", 200))[0]
+	parent.setEmbedHeader("File: big.go\nLanguage: go\n\n")
+
+	for _, sub := range c.ReChunk(parent, 0) {
+		if !strings.HasPrefix(sub.embedText(), "File: big.go\nLanguage: go\n\nx := ") {
+			t.Errorf("sub-chunk lost the embed header: %q", sub.embedText()[:40])
+		}
+		if strings.Contains(sub.embedText(), "File: big.go\n\nx") {
+			t.Errorf("sub-chunk embeds the plain file prefix too: %q", sub.embedText()[:40])
+		}
+	}
+}
+
+func TestEnclosingSymbols(t *testing.T) {
+	symbols := []trace.Symbol{
+		{Name: "Open", Line: 3},
+		{Name: "Read", Receiver: "File", Line: 20},
+		{Name: "Close", Line: 40, EndLine: 45},
+		{Name: "helper", Line: 60},
+	}
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       []string
+	}{
+		{"declared inside", 18, 42, []string{"Open", "File.Read", "Close"}},
+		{"starts in a body", 25, 30, []string{"File.Read"}},
+		{"after a known end", 50, 55, nil},
+		{"before any symbol", 1, 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := enclosingSymbols(symbols, tt.start, tt.end); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("enclosingSymbols(%d, %d) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package indexer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// Reasons reported only by Explain: the scans skip these files without
+// listing them.
+const (
+	SkipIgnored SkipReason = "ignored"
+	SkipBinary  SkipReason = "binary"
+)
+
+// Check names used in FileExplanation.
+const (
+	CheckIgnore   = "ignore rules"
+	CheckFileType = "file type"
+	CheckMinified = "minified"
+	CheckSize     = "size"
+	CheckContent  = "content"
+)
+
+// FileCheck is one step of the scanner's decision for a file.
+type FileCheck struct {
+	Name   string      `json:"name"`
+	Passed bool        `json:"passed"`
+	Detail string      `json:"detail"`
+	Rule   *IgnoreRule `json:"rule,omitempty"`
+}
+
+// FileExplanation describes how the scanner treats a file.
+type FileExplanation struct {
+	Path      string      `json:"path"`
+	Indexable bool        `json:"indexable"`
+	Reason    SkipReason  `json:"reason,omitempty"`
+	Checks    []FileCheck `json:"checks"`
+}
+
+func (e *FileExplanation) pass(check FileCheck) {
+	check.Passed = true
+	e.Checks = append(e.Checks, check)
+}
+
+func (e *FileExplanation) fail(check FileCheck, reason SkipReason) *FileExplanation {
+	e.Checks = append(e.Checks, check)
+	e.Reason = reason
+	return e
+}
+
+// Explain runs the scanner's checks on the file at relPath, in the order the
+// scans apply them, and reports each decision up to the first one that
+// excludes the file.
+func (s *Scanner) Explain(relPath string) (*FileExplanation, error) {
+	relPath = filepath.Clean(relPath)
+	absPath := filepath.Join(s.root, relPath)
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", relPath, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", relPath)
+	}
+
+	e := &FileExplanation{Path: filepath.ToSlash(relPath)}
+
+	// The walk never enters skipped directories.
+	if dir := s.skippedDir(relPath); dir != "" {
+		_, rule := s.ignore.Explain(dir)
+		detail := fmt.Sprintf("directory %s/ is ignored%s", dir, byRule(rule))
+		return e.fail(FileCheck{Name: CheckIgnore, Detail: detail, Rule: rule}, SkipIgnored), nil
+	}
+
+	ignored, rule := s.ignore.Explain(relPath)
+	switch {
+	case ignored:
+		detail := "ignored" + byRule(rule)
+		return e.fail(FileCheck{Name: CheckIgnore, Detail: detail, Rule: rule}, SkipIgnored), nil
+	case rule != nil && rule.Negated:
+		e.pass(FileCheck{Name: CheckIgnore, Detail: "re-included" + byRule(rule), Rule: rule})
+	default:
+		e.pass(FileCheck{Name: CheckIgnore, Detail: "no ignore pattern matches"})
+	}
+
+	reason, detail := s.filter.describeType(absPath, relPath)
+	if reason != "" {
+		return e.fail(FileCheck{Name: CheckFileType, Detail: detail}, reason), nil
+	}
+	e.pass(FileCheck{Name: CheckFileType, Detail: detail})
+
+	if isMinifiedFile(relPath) {
+		detail := "name matches a minified file pattern (" + strings.Join(MinifiedPatterns, ", ") + ")"
+		return e.fail(FileCheck{Name: CheckMinified, Detail: detail}, SkipMinified), nil
+	}
+	e.pass(FileCheck{Name: CheckMinified, Detail: "not a minified file name"})
+
+	limit := s.filter.MaxSize(relPath)
+	detail = fmt.Sprintf("%s, limit %s", formatSize(info.Size()), formatSize(limit))
+	if info.Size() > limit {
+		return e.fail(FileCheck{Name: CheckSize, Detail: detail + "; raise index.max_file_size to index it"}, SkipTooLarge), nil
+	}
+	e.pass(FileCheck{Name: CheckSize, Detail: detail})
+
+	content, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", relPath, err)
+	}
+	switch {
+	case !utf8.Valid(content):
+		return e.fail(FileCheck{Name: CheckContent, Detail: "binary file: content is not valid UTF-8"}, SkipBinary), nil
+	case containsNull(content):
+		return e.fail(FileCheck{Name: CheckContent, Detail: "binary file: content contains NUL bytes"}, SkipBinary), nil
+	case store.IsNotebook(relPath) && !json.Valid(content):
+		return e.fail(FileCheck{Name: CheckContent, Detail: "notebook is not valid JSON"}, SkipInvalidNotebook), nil
+	}
+	e.pass(FileCheck{Name: CheckContent, Detail: "UTF-8 text"})
+
+	e.Indexable = true
+	return e, nil
+}
+
+// describeType explains the CheckType decision for a file.
+func (f *FileFilter) describeType(absPath, relPath string) (SkipReason, string) {
+	reason := f.CheckType(absPath, relPath)
+	base := filepath.Base(relPath)
+	ext := strings.ToLower(filepath.Ext(base))
+
+	switch {
+	case f.filenames[base]:
+		return reason, base + " is listed in index.filenames"
+	case f.excluded[ext]:
+		return reason, "extension " + ext + " is listed in index.exclude_extensions"
+	case f.extensions[ext]:
+		return reason, "extension " + ext + " is indexed"
+	case ext == "" && f.shebang && reason == "":
+		return reason, "no extension, starts with a shebang line"
+	case ext == "" && f.shebang:
+		return reason, "no extension and no shebang line; add the name to index.filenames to index it"
+	case ext == "":
+		return reason, "no extension; enable index.shebang or add the name to index.filenames to index it"
+	default:
+		return reason, "extension " + ext + " is not indexed; add it to index.include_extensions to index it"
+	}
+}
+
+// byRule formats " by <rule>", or returns "" for a nil rule.
+func byRule(rule *IgnoreRule) string {
+	if rule == nil {
+		return ""
+	}
+	return " by " + rule.String()
+}
+
+// formatSize formats a byte count for explanations.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,129 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeExplainFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+}
+
+func TestIgnoreMatcher_Explain(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeExplainFiles(t, tmpDir, map[string]string{
+		".gitignore":           "# build output\n*.log\n\ndist/\n",
+		".grepaiignore":        "# keep the debug log\n\n!debug.log\n",
+		"web/.gitignore":       "*.generated.ts\n",
+		"web/api.generated.ts": "",
+	})
+
+	m, err := NewIgnoreMatcher(tmpDir, []string{"node_modules"}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+
+	tests := []struct {
+		path        string
+		wantIgnored bool
+		wantRule    *IgnoreRule
+	}{
+		{"app.log", true, &IgnoreRule{Source: ".gitignore", Line: 2, Pattern: "*.log"}},
+		{"dist/app.js", true, &IgnoreRule{Source: ".gitignore", Line: 4, Pattern: "dist/"}},
+		{"debug.log", false, &IgnoreRule{Source: ".grepaiignore", Line: 3, Pattern: "!debug.log", Negated: true}},
+		{"web/api.generated.ts", true, &IgnoreRule{Source: "web/.gitignore", Line: 1, Pattern: "*.generated.ts"}},
+		{"node_modules", true, &IgnoreRule{Source: ConfigIgnoreSource, Pattern: "node_modules"}},
+		{"main.go", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ignored, rule := m.Explain(tt.path)
+			if ignored != tt.wantIgnored {
+				t.Errorf("Explain(%q) ignored = %v, want %v", tt.path, ignored, tt.wantIgnored)
+			}
+			if ignored != m.ShouldIgnore(tt.path) {
+				t.Errorf("Explain(%q) disagrees with ShouldIgnore", tt.path)
+			}
+			switch {
+			case tt.wantRule == nil && rule != nil:
+				t.Errorf("Explain(%q) rule = %+v, want none", tt.path, *rule)
+			case tt.wantRule != nil && (rule == nil || *rule != *tt.wantRule):
+				t.Errorf("Explain(%q) rule = %+v, want %+v", tt.path, rule, *tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestScanner_Explain(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeExplainFiles(t, tmpDir, map[string]string{
+		".gitignore":       "build/\n",
+		"main.go":          "package main\n",
+		"build/out.go":     "package out\n",
+		"blob.go":          "pack\x00age",
+		"app.min.js":       "x",
+		"schema.graphql":   "type Query {}\n",
+		"big.sql":          string(make([]byte, 2048)),
+		"notes/README.txt": "notes\n",
+	})
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	scanner := NewScanner(tmpDir, ignoreMatcher, WithFileFilter(NewFileFilter(FileFilterConfig{MaxFileSize: 1024})))
+
+	tests := []struct {
+		path       string
+		wantReason SkipReason
+		wantChecks int
+	}{
+		{"main.go", "", 5},
+		{"notes/README.txt", "", 5},
+		{"build/out.go", SkipIgnored, 1},
+		{"schema.graphql", SkipUnsupported, 2},
+		{"app.min.js", SkipMinified, 3},
+		{"big.sql", SkipTooLarge, 4},
+		{"blob.go", SkipBinary, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			e, err := scanner.Explain(tt.path)
+			if err != nil {
+				t.Fatalf("Explain failed: %v", err)
+			}
+			if e.Reason != tt.wantReason || e.Indexable != (tt.wantReason == "") {
+				t.Errorf("Explain(%q) = indexable %v, reason %q; want reason %q", tt.path, e.Indexable, e.Reason, tt.wantReason)
+			}
+			if len(e.Checks) != tt.wantChecks {
+				t.Fatalf("Explain(%q) made %d checks, want %d: %+v", tt.path, len(e.Checks), tt.wantChecks, e.Checks)
+			}
+			last := e.Checks[len(e.Checks)-1]
+			if last.Passed != e.Indexable {
+				t.Errorf("last check %q passed = %v, want %v", last.Name, last.Passed, e.Indexable)
+			}
+		})
+	}
+
+	e, err := scanner.Explain("build/out.go")
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if rule := e.Checks[0].Rule; rule == nil || rule.Source != ".gitignore" || rule.Pattern != "build/" {
+		t.Errorf("expected the build/ rule from .gitignore, got %+v", rule)
+	}
+
+	if _, err := scanner.Explain("missing.go"); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
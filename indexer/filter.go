@@ -0,0 +1,150 @@
+package indexer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// SkipReason explains why a file was not indexed.
+type SkipReason string
+
+const (
+	SkipUnsupported     SkipReason = "unsupported type"
+	SkipExcluded        SkipReason = "excluded extension"
+	SkipMinified        SkipReason = "minified"
+	SkipTooLarge        SkipReason = "too large"
+	SkipInvalidNotebook SkipReason = "invalid notebook"
+)
+
+// FileFilterConfig customizes the files a FileFilter accepts.
+type FileFilterConfig struct {
+	IncludeExtensions []string // indexed in addition to SupportedExtensions
+	ExcludeExtensions []string // never indexed
+	Filenames         []string // exact basenames to index, e.g. Dockerfile
+	Shebang           bool     // index extensionless files starting with #!
+	MaxFileSize       int64    // 0 means maxFileSize
+}
+
+// FileFilter decides which files are indexed from their name, shebang line
+// and size.
+type FileFilter struct {
+	extensions  map[string]bool
+	excluded    map[string]bool
+	filenames   map[string]bool
+	shebang     bool
+	maxFileSize int64
+}
+
+// DefaultFileFilter accepts SupportedExtensions up to 1 MB.
+func DefaultFileFilter() *FileFilter {
+	return NewFileFilter(FileFilterConfig{})
+}
+
+// NewFileFilter builds a filter from cfg. Extensions are matched
+// case-insensitively, with or without their leading dot; exclusions win over
+// inclusions.
+func NewFileFilter(cfg FileFilterConfig) *FileFilter {
+	f := &FileFilter{
+		extensions:  make(map[string]bool, len(SupportedExtensions)+len(cfg.IncludeExtensions)),
+		excluded:    make(map[string]bool, len(cfg.ExcludeExtensions)),
+		filenames:   make(map[string]bool, len(cfg.Filenames)),
+		shebang:     cfg.Shebang,
+		maxFileSize: cfg.MaxFileSize,
+	}
+	for ext := range SupportedExtensions {
+		f.extensions[normalizeExtension(ext)] = true
+	}
+	for _, ext := range cfg.IncludeExtensions {
+		f.extensions[normalizeExtension(ext)] = true
+	}
+	for _, ext := range cfg.ExcludeExtensions {
+		f.excluded[normalizeExtension(ext)] = true
+	}
+	for _, name := range cfg.Filenames {
+		f.filenames[name] = true
+	}
+	if f.maxFileSize <= 0 {
+		f.maxFileSize = maxFileSize
+	}
+	return f
+}
+
+func normalizeExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// MatchName reports whether relPath may be indexed judging by its name
+// alone. Extensionless files match when shebang detection is enabled, since
+// their content decides.
+func (f *FileFilter) MatchName(relPath string) bool {
+	reason, needsContent := f.checkName(relPath)
+	return reason == "" || needsContent
+}
+
+// CheckType returns why the file at absPath would not be indexed based on its
+// name and, for extensionless files, its shebang line, or "" if it is
+// indexable.
+func (f *FileFilter) CheckType(absPath, relPath string) SkipReason {
+	reason, needsContent := f.checkName(relPath)
+	if needsContent {
+		if hasShebang(absPath) {
+			return ""
+		}
+		return SkipUnsupported
+	}
+	return reason
+}
+
+// checkName applies the name rules; needsContent is true when the shebang
+// line decides.
+func (f *FileFilter) checkName(relPath string) (reason SkipReason, needsContent bool) {
+	base := filepath.Base(relPath)
+	if f.filenames[base] {
+		return "", false
+	}
+
+	ext := strings.ToLower(filepath.Ext(base))
+	switch {
+	case f.excluded[ext]:
+		return SkipExcluded, false
+	case f.extensions[ext]:
+		return "", false
+	case ext == "" && f.shebang:
+		return SkipUnsupported, true
+	default:
+		return SkipUnsupported, false
+	}
+}
+
+// MaxSize returns the size limit for the file at relPath. Notebooks get
+// maxNotebookSize unless the configured limit is larger.
+func (f *FileFilter) MaxSize(relPath string) int64 {
+	if store.IsNotebook(relPath) && f.maxFileSize < maxNotebookSize {
+		return maxNotebookSize
+	}
+	return f.maxFileSize
+}
+
+// hasShebang reports whether the file at path starts with "#!".
+func hasShebang(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(file, head); err != nil {
+		return false
+	}
+	return bytes.Equal(head, []byte("#!"))
+}
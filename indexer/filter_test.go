@@ -0,0 +1,119 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileFilter_CheckType(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"deploy":        "#!/usr/bin/env bash\necho deploy\n",
+		"NOTES":         "plain text without a shebang\n",
+		"Dockerfile":    "FROM golang:1.24\n",
+		"schema.prisma": "model User {}\n",
+		"app.GRAPHQL":   "type Query {}\n",
+		"main.go":       "package main\n",
+		"data.json":     "{}\n",
+		"logo.png":      "\x89PNG",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	filter := NewFileFilter(FileFilterConfig{
+		IncludeExtensions: []string{"prisma", ".graphql"},
+		ExcludeExtensions: []string{".json"},
+		Filenames:         []string{"Dockerfile"},
+		Shebang:           true,
+	})
+
+	tests := []struct {
+		path string
+		want SkipReason
+	}{
+		{"deploy", ""},
+		{"NOTES", SkipUnsupported},
+		{"Dockerfile", ""},
+		{"schema.prisma", ""},
+		{"app.GRAPHQL", ""},
+		{"main.go", ""},
+		{"data.json", SkipExcluded},
+		{"logo.png", SkipUnsupported},
+		{"missing-script", SkipUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := filter.CheckType(filepath.Join(tmpDir, tt.path), tt.path); got != tt.want {
+				t.Errorf("CheckType(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if !filter.MatchName("bin/missing-script") {
+		t.Error("MatchName should accept extensionless files when shebang detection is on")
+	}
+	if DefaultFileFilter().CheckType(filepath.Join(tmpDir, "deploy"), "deploy") != SkipUnsupported {
+		t.Error("the default filter should not detect shebangs")
+	}
+}
+
+func TestFileFilter_MaxSize(t *testing.T) {
+	if got := DefaultFileFilter().MaxSize("main.go"); got != maxFileSize {
+		t.Errorf("default MaxSize = %d, want %d", got, maxFileSize)
+	}
+
+	filter := NewFileFilter(FileFilterConfig{MaxFileSize: 4 * 1024 * 1024})
+	if got := filter.MaxSize("migrations/001_init.sql"); got != 4*1024*1024 {
+		t.Errorf("MaxSize = %d, want the configured 4MB", got)
+	}
+	if got := filter.MaxSize("analysis.ipynb"); got != maxNotebookSize {
+		t.Errorf("notebook MaxSize = %d, want %d", got, maxNotebookSize)
+	}
+}
+
+func TestScanner_ScanMetadataSkipCounts(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]int{
+		"main.go":       100,
+		"big.sql":       2 * 1024 * 1024,
+		"app.min.js":    10,
+		"image.png":     10,
+		"ignored.png":   10,
+		"migration.sql": 100,
+	}
+	for name, size := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), make([]byte, size), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{"ignored.png"}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	_, _, counts, err := NewScanner(tmpDir, ignoreMatcher).scanMetadata()
+	if err != nil {
+		t.Fatalf("scanMetadata failed: %v", err)
+	}
+
+	want := map[SkipReason]int{SkipTooLarge: 1, SkipMinified: 1, SkipUnsupported: 1}
+	for reason, n := range want {
+		if counts[reason] != n {
+			t.Errorf("counts[%q] = %d, want %d (all: %v)", reason, counts[reason], n, counts)
+		}
+	}
+
+	// Raising the limit indexes the large migration.
+	filter := NewFileFilter(FileFilterConfig{MaxFileSize: 4 * 1024 * 1024})
+	_, _, counts, err = NewScanner(tmpDir, ignoreMatcher, WithFileFilter(filter)).scanMetadata()
+	if err != nil {
+		t.Fatalf("scanMetadata failed: %v", err)
+	}
+	if counts[SkipTooLarge] != 0 {
+		t.Errorf("expected no large files with a 4MB limit, got %d", counts[SkipTooLarge])
+	}
+}
@@ -2,6 +2,10 @@ package indexer
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
 	"log"
 	"os"
 	"path/filepath"
@@ -28,19 +32,42 @@ func expandTilde(path string) string {
 	return path
 }
 
+// ConfigIgnoreSource is the IgnoreRule source of the ignore patterns from
+// the project configuration.
+const ConfigIgnoreSource = "config.yaml ignore"
+
+// IgnoreRule is the ignore pattern that decided whether a path is ignored.
+type IgnoreRule struct {
+	Source  string // ignore file relative to the project root, external gitignore path or ConfigIgnoreSource
+	Line    int    // 1-based line in Source, 0 for config patterns
+	Pattern string
+	Negated bool // the pattern re-includes the path
+}
+
+func (r IgnoreRule) String() string {
+	if r.Line > 0 {
+		return fmt.Sprintf("%q (%s:%d)", r.Pattern, r.Source, r.Line)
+	}
+	return fmt.Sprintf("%q (%s)", r.Pattern, r.Source)
+}
+
 // nestedMatcher holds a gitignore matcher and its base directory
 type nestedMatcher struct {
 	matcher *ignore.GitIgnore
 	baseDir string // relative path from project root (empty for root .gitignore)
+	source  string // ignore file the patterns come from, for IgnoreRule
 }
 
 // grepaiMatcher holds a pair of matchers for .grepaiignore files.
 // "full" uses original patterns (with negations) for the actual decision.
 // "any" uses all patterns converted to positive for detecting if the file has an opinion.
 type grepaiMatcher struct {
-	full    *ignore.GitIgnore // Matcher with original patterns (including negations)
-	any     *ignore.GitIgnore // Matcher with all patterns as positive (for detection)
-	baseDir string            // relative path from project root
+	full     *ignore.GitIgnore // Matcher with original patterns (including negations)
+	any      *ignore.GitIgnore // Matcher with all patterns as positive (for detection)
+	baseDir  string            // relative path from project root
+	source   string            // .grepaiignore path relative to the project root
+	patterns []string          // original patterns, in compile order
+	lines    []int             // source line of each pattern
 }
 
 type IgnoreMatcher struct {
@@ -49,6 +76,7 @@ type IgnoreMatcher struct {
 	extraDirs          []string        // patterns from config
 	grepaiMatchers     []grepaiMatcher // .grepaiignore matchers
 	hasGrepaiNegations bool            // true if any .grepaiignore has ! patterns
+	fingerprint        string          // digest of every rule source, see Fingerprint
 }
 
 func NewIgnoreMatcher(projectRoot string, extraIgnore []string, externalGitignore string) (*IgnoreMatcher, error) {
@@ -56,6 +84,7 @@ func NewIgnoreMatcher(projectRoot string, extraIgnore []string, externalGitignor
 		projectRoot: projectRoot,
 		extraDirs:   extraIgnore,
 	}
+	digest := sha256.New()
 
 	// Load external gitignore file if specified
 	if externalGitignore != "" {
@@ -71,7 +100,9 @@ func NewIgnoreMatcher(projectRoot string, extraIgnore []string, externalGitignor
 			m.nestedMatchers = append(m.nestedMatchers, nestedMatcher{
 				matcher: gi,
 				baseDir: "", // External gitignore applies from root
+				source:  expandedPath,
 			})
+			addToDigest(digest, expandedPath, expandedPath)
 		}
 	}
 
@@ -112,7 +143,9 @@ func NewIgnoreMatcher(projectRoot string, extraIgnore []string, externalGitignor
 			m.nestedMatchers = append(m.nestedMatchers, nestedMatcher{
 				matcher: gi,
 				baseDir: relPath,
+				source:  filepath.ToSlash(filepath.Join(relPath, ".gitignore")),
 			})
+			addToDigest(digest, path, m.nestedMatchers[len(m.nestedMatchers)-1].source)
 		}
 
 		// Process .grepaiignore files
@@ -131,7 +164,9 @@ func NewIgnoreMatcher(projectRoot string, extraIgnore []string, externalGitignor
 			}
 
 			gm.baseDir = relPath
+			gm.source = filepath.ToSlash(filepath.Join(relPath, ".grepaiignore"))
 			m.grepaiMatchers = append(m.grepaiMatchers, gm)
+			addToDigest(digest, path, gm.source)
 			if hasNegations {
 				m.hasGrepaiNegations = true
 			}
@@ -150,32 +185,93 @@ func NewIgnoreMatcher(projectRoot string, extraIgnore []string, externalGitignor
 		m.nestedMatchers = append(m.nestedMatchers, nestedMatcher{
 			matcher: gi,
 			baseDir: "",
+			source:  ConfigIgnoreSource,
 		})
+		fmt.Fprintf(digest, "%s\n%s\n", ConfigIgnoreSource, strings.Join(extraIgnore, "\n"))
 	}
+	m.fingerprint = hex.EncodeToString(digest.Sum(nil))
 
 	return m, nil
 }
 
+// addToDigest adds the name and content of the ignore file at path to digest.
+func addToDigest(digest hash.Hash, path, source string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return // Unreadable files are left out, like invalid ones
+	}
+	fmt.Fprintf(digest, "%s\n%d\n", source, len(content))
+	digest.Write(content)
+}
+
+// HasNegations reports whether a .grepaiignore re-includes paths with "!"
+// patterns, possibly files that .gitignore excludes.
+func (m *IgnoreMatcher) HasNegations() bool {
+	return m.hasGrepaiNegations
+}
+
+// Fingerprint returns a digest of every ignore file and pattern the matcher
+// was built from, which changes whenever they do.
+func (m *IgnoreMatcher) Fingerprint() string {
+	return m.fingerprint
+}
+
 func (m *IgnoreMatcher) ShouldIgnore(path string) bool {
+	ignored, _ := m.match(path)
+	return ignored
+}
+
+// Explain reports whether path is ignored, like ShouldIgnore, along with the
+// rule that decided it. The rule is nil when no pattern matches the path.
+func (m *IgnoreMatcher) Explain(path string) (bool, *IgnoreRule) {
+	ignored, match := m.match(path)
+	return ignored, match.rule()
+}
+
+// ignoreMatch identifies the pattern that decided a path. Only Explain turns
+// it into an IgnoreRule, so ShouldIgnore, called for every scanned file,
+// doesn't allocate one.
+type ignoreMatch struct {
+	grepai   *grepaiMatcher // deciding .grepaiignore
+	relPath  string         // path relative to the .grepaiignore directory
+	nested   *nestedMatcher // deciding .gitignore or config patterns
+	pattern  *ignore.IgnorePattern
+	extraDir string // matching directory name from the config patterns
+}
+
+func (im ignoreMatch) rule() *IgnoreRule {
+	switch {
+	case im.grepai != nil:
+		return im.grepai.rule(im.relPath)
+	case im.nested != nil:
+		return im.nested.rule(im.pattern)
+	case im.extraDir != "":
+		return &IgnoreRule{Source: ConfigIgnoreSource, Pattern: im.extraDir}
+	}
+	return nil
+}
+
+func (m *IgnoreMatcher) match(path string) (bool, ignoreMatch) {
 	normalizedPath := filepath.ToSlash(path)
 
 	// Phase 1: Check if .grepaiignore has an opinion
-	result, hasOpinion, grepaiBaseDir := m.evalGrepaiIgnore(normalizedPath)
+	result, hasOpinion, grepaiBaseDir, grepaiMatch := m.evalGrepaiIgnore(normalizedPath)
 	if hasOpinion {
 		if result {
-			return true // .grepaiignore says ignore → always respect
+			return true, grepaiMatch // .grepaiignore says ignore → always respect
 		}
 		// .grepaiignore says don't ignore (negation).
 		// Only override .gitignore at the same level or shallower.
 		// If a deeper .gitignore ignores this path, respect the .gitignore.
-		if ignored, gitBaseDir := m.evalGitIgnoreWithLevel(normalizedPath); ignored && len(gitBaseDir) > len(grepaiBaseDir) {
-			return true // Deeper .gitignore wins over shallower .grepaiignore
+		if ignored, gitBaseDir, gitMatch := m.evalGitIgnoreWithLevel(normalizedPath); ignored && len(gitBaseDir) > len(grepaiBaseDir) {
+			return true, gitMatch // Deeper .gitignore wins over shallower .grepaiignore
 		}
-		return false
+		return false, grepaiMatch
 	}
 
 	// Phase 2: No .grepaiignore opinion, delegate to .gitignore + extra patterns
-	return m.evalGitIgnore(normalizedPath)
+	ignored, _, match := m.evalGitIgnoreWithLevel(normalizedPath)
+	return ignored, match
 }
 
 // ShouldSkipDir determines if a directory can be skipped entirely via filepath.SkipDir.
@@ -189,7 +285,7 @@ func (m *IgnoreMatcher) ShouldSkipDir(path string) bool {
 	normalizedPath := filepath.ToSlash(path)
 
 	// If .grepaiignore explicitly says to ignore this dir → safe to skip
-	if result, hasOpinion, _ := m.evalGrepaiIgnore(normalizedPath); hasOpinion {
+	if result, hasOpinion, _, _ := m.evalGrepaiIgnore(normalizedPath); hasOpinion {
 		return result
 	}
 
@@ -203,9 +299,10 @@ func (m *IgnoreMatcher) ShouldSkipDir(path string) bool {
 }
 
 // evalGrepaiIgnore checks .grepaiignore matchers for the path.
-// Returns (result, hasOpinion, baseDir) where baseDir is the directory level of the matching .grepaiignore.
+// Returns (result, hasOpinion, baseDir, match) where baseDir is the directory level of the matching .grepaiignore
+// and match identifies it.
 // The most specific matcher (longest baseDir) wins.
-func (m *IgnoreMatcher) evalGrepaiIgnore(normalizedPath string) (bool, bool, string) {
+func (m *IgnoreMatcher) evalGrepaiIgnore(normalizedPath string) (bool, bool, string, ignoreMatch) {
 	var bestMatch *grepaiMatcher
 	bestBaseLen := -1
 
@@ -226,10 +323,11 @@ func (m *IgnoreMatcher) evalGrepaiIgnore(normalizedPath string) (bool, bool, str
 	}
 
 	if bestMatch == nil {
-		return false, false, "" // No .grepaiignore has an opinion
+		return false, false, "", ignoreMatch{} // No .grepaiignore has an opinion
 	}
 
 	relPath := matcherRelPath(normalizedPath, bestMatch.baseDir)
+	match := ignoreMatch{grepai: bestMatch, relPath: relPath}
 	// The full matcher (with negations) gives the final answer.
 	// Check both with and without trailing slash. The trailing-slash variant
 	// is more specific (matches directory patterns), so if it says "not ignored"
@@ -238,47 +336,94 @@ func (m *IgnoreMatcher) evalGrepaiIgnore(normalizedPath string) (bool, bool, str
 	matchSlash := bestMatch.full.MatchesPath(relPath + "/")
 	if matchPlain && !matchSlash {
 		// The trailing-slash check negated the match → not ignored
-		return false, true, bestMatch.baseDir
+		return false, true, bestMatch.baseDir, match
 	}
-	return matchPlain || matchSlash, true, bestMatch.baseDir
+	return matchPlain || matchSlash, true, bestMatch.baseDir, match
+}
+
+// rule returns the last pattern of the matcher matching relPath, which is
+// the one deciding it.
+func (gm *grepaiMatcher) rule(relPath string) *IgnoreRule {
+	_, plain := gm.any.MatchesPathHow(relPath)
+	_, slash := gm.any.MatchesPathHow(relPath + "/")
+	pattern := plain
+	if pattern == nil || (slash != nil && slash.LineNo > pattern.LineNo) {
+		pattern = slash
+	}
+	if pattern == nil {
+		return nil
+	}
+
+	// Both matchers compile the same patterns, in order, so the index maps
+	// back to the original pattern and its line.
+	rule := &IgnoreRule{Source: gm.source, Pattern: pattern.Line}
+	if i := pattern.LineNo - 1; i >= 0 && i < len(gm.patterns) {
+		rule.Pattern = gm.patterns[i]
+		rule.Line = gm.lines[i]
+		rule.Negated = strings.HasPrefix(rule.Pattern, "!")
+	}
+	return rule
 }
 
 // evalGitIgnore checks extra dirs and .gitignore matchers (original ShouldIgnore logic).
 func (m *IgnoreMatcher) evalGitIgnore(normalizedPath string) bool {
-	ignored, _ := m.evalGitIgnoreWithLevel(normalizedPath)
+	ignored, _, _ := m.evalGitIgnoreWithLevel(normalizedPath)
 	return ignored
 }
 
-// evalGitIgnoreWithLevel checks .gitignore/extra patterns and returns the deepest matching level.
-func (m *IgnoreMatcher) evalGitIgnoreWithLevel(normalizedPath string) (bool, string) {
+// evalGitIgnoreWithLevel checks .gitignore/extra patterns and returns the deepest matching level
+// and the pattern that matched there.
+func (m *IgnoreMatcher) evalGitIgnoreWithLevel(normalizedPath string) (bool, string, ignoreMatch) {
 	found := false
 	deepestBaseDir := ""
+	var match ignoreMatch
 
 	// Check extra directories (root-level, baseDir="")
 	base := filepath.Base(normalizedPath)
 	for _, dir := range m.extraDirs {
 		if base == dir {
 			found = true
+			match = ignoreMatch{extraDir: dir}
 			break
 		}
 	}
 
 	// Check nested gitignore patterns, find the deepest match
-	for _, nm := range m.nestedMatchers {
+	for i := range m.nestedMatchers {
+		nm := &m.nestedMatchers[i]
 		relPath := matcherRelPath(normalizedPath, nm.baseDir)
 		if relPath == "" && nm.baseDir != "" {
 			continue
 		}
 
-		if nm.matcher.MatchesPath(relPath) || nm.matcher.MatchesPath(relPath+"/") {
+		matchPlain, plain := nm.matcher.MatchesPathHow(relPath)
+		matchSlash, slash := nm.matcher.MatchesPathHow(relPath + "/")
+		if matchPlain || matchSlash {
 			if !found || len(nm.baseDir) > len(deepestBaseDir) {
 				deepestBaseDir = nm.baseDir
 				found = true
+				pattern := plain
+				if !matchPlain {
+					pattern = slash
+				}
+				match = ignoreMatch{nested: nm, pattern: pattern}
 			}
 		}
 	}
 
-	return found, deepestBaseDir
+	return found, deepestBaseDir, match
+}
+
+// rule converts a matched pattern of the matcher to an IgnoreRule.
+func (nm nestedMatcher) rule(pattern *ignore.IgnorePattern) *IgnoreRule {
+	if pattern == nil {
+		return nil
+	}
+	rule := &IgnoreRule{Source: nm.source, Pattern: strings.TrimSpace(pattern.Line)}
+	if nm.source != ConfigIgnoreSource {
+		rule.Line = pattern.LineNo
+	}
+	return rule
 }
 
 // matcherRelPath computes the path relative to a matcher's base directory.
@@ -310,14 +455,16 @@ func compileGrepaiIgnoreFile(path string) (grepaiMatcher, bool, error) {
 	lines := strings.Split(string(content), "\n")
 	fullLines := make([]string, 0, len(lines))
 	anyLines := make([]string, 0, len(lines))
+	lineNos := make([]int, 0, len(lines))
 	hasNegations := false
 
-	for _, line := range lines {
+	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
 			continue
 		}
 		fullLines = append(fullLines, trimmed)
+		lineNos = append(lineNos, i+1)
 
 		if strings.HasPrefix(trimmed, "!") {
 			hasNegations = true
@@ -332,8 +479,10 @@ func compileGrepaiIgnoreFile(path string) (grepaiMatcher, bool, error) {
 	anyMatcher := ignore.CompileIgnoreLines(anyLines...)
 
 	return grepaiMatcher{
-		full: fullMatcher,
-		any:  anyMatcher,
+		full:     fullMatcher,
+		any:      anyMatcher,
+		lines:    lineNos,
+		patterns: fullLines,
 	}, hasNegations, nil
 }
 
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
 	"github.com/yoanbernabeu/grepai/embedder"
 	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
 )
 
 type Indexer struct {
@@ -16,7 +18,21 @@ type Indexer struct {
 	embedder      embedder.Embedder
 	chunker       *Chunker
 	scanner       *Scanner
+	symbols       *trace.RegexExtractor
 	lastIndexTime time.Time
+
+	// sharedCache is consulted after the store's own EmbeddingCache and
+	// records fresh embeddings when it implements EmbeddingCacheWriter.
+	sharedCache store.EmbeddingCache
+
+	// contextHeaders enables the header built by addContextHeaders;
+	// featurePath, when set, adds the RPG feature path to it.
+	contextHeaders bool
+	featurePath    FeaturePathFunc
+
+	// redactor, when set, removes secrets from file content before it is
+	// chunked.
+	redactor *Redactor
 }
 
 type IndexStats struct {
@@ -24,7 +40,11 @@ type IndexStats struct {
 	FilesSkipped  int
 	ChunksCreated int
 	FilesRemoved  int
-	Duration      time.Duration
+	FilesFailed   int // files that could not be embedded or stored
+	// SkipCounts counts the files left out of the index by reason. It is
+	// only set by full scans.
+	SkipCounts map[SkipReason]int
+	Duration   time.Duration
 }
 
 // ProgressInfo contains progress information for indexing
@@ -65,10 +85,17 @@ func NewIndexer(
 		embedder:      emb,
 		chunker:       chunker,
 		scanner:       scanner,
+		symbols:       trace.NewRegexExtractor(),
 		lastIndexTime: lastIndexTime,
 	}
 }
 
+// SetEmbeddingCache sets a cache shared across projects, consulted whatever
+// the store backend before calling the embedder.
+func (idx *Indexer) SetEmbeddingCache(cache store.EmbeddingCache) {
+	idx.sharedCache = cache
+}
+
 // IndexAll performs a full index of the project (no progress reporting)
 func (idx *Indexer) IndexAll(ctx context.Context) (*IndexStats, error) {
 	return idx.IndexAllWithProgress(ctx, nil)
@@ -86,11 +113,12 @@ func (idx *Indexer) IndexAllWithBatchProgress(ctx context.Context, onProgress Pr
 	stats := &IndexStats{}
 
 	// Scan all files (metadata-only first pass)
-	fileMetas, skipped, err := idx.scanner.ScanMetadata()
+	fileMetas, skipped, skipCounts, err := idx.scanner.scanMetadata()
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan files: %w", err)
 	}
 	stats.FilesSkipped = len(skipped)
+	stats.SkipCounts = skipCounts
 
 	// Get existing documents
 	existingDocs, err := idx.store.ListDocuments(ctx)
@@ -143,6 +171,11 @@ func (idx *Indexer) IndexAllWithBatchProgress(ctx context.Context, onProgress Pr
 		}
 		if file == nil {
 			stats.FilesSkipped++
+			if store.IsNotebook(fileMeta.Path) {
+				// It passed the metadata checks, so its content is not
+				// valid JSON.
+				skipCounts[SkipInvalidNotebook]++
+			}
 			delete(existingMap, fileMeta.Path)
 			continue
 		}
@@ -156,42 +189,8 @@ func (idx *Indexer) IndexAllWithBatchProgress(ctx context.Context, onProgress Pr
 		delete(existingMap, fileMeta.Path)
 	}
 
-	// Index files using batch processing if available, otherwise sequentially
-	if batchEmbedder, ok := idx.embedder.(embedder.BatchEmbedder); ok && len(filesToIndex) > 0 {
-		indexed, chunks, err := idx.indexFilesBatched(ctx, filesToIndex, batchEmbedder, onBatchProgress)
-		if err != nil {
-			return nil, err
-		}
-		stats.FilesIndexed = indexed
-		stats.ChunksCreated = chunks
-	} else if len(filesToIndex) > 0 {
-		// Sequential indexing for non-batch embedders (e.g., Ollama)
-		total := len(filesToIndex)
-		for i, file := range filesToIndex {
-			if onBatchProgress != nil {
-				onBatchProgress(BatchProgressInfo{
-					BatchIndex:      i,
-					TotalBatches:    total,
-					CompletedChunks: i,
-					TotalChunks:     total,
-				})
-			}
-			chunks, err := idx.IndexFile(ctx, file)
-			if err != nil {
-				log.Printf("Failed to index %s: %v", file.Path, err)
-				continue
-			}
-			stats.FilesIndexed++
-			stats.ChunksCreated += chunks
-		}
-		if onBatchProgress != nil {
-			onBatchProgress(BatchProgressInfo{
-				BatchIndex:      total,
-				TotalBatches:    total,
-				CompletedChunks: total,
-				TotalChunks:     total,
-			})
-		}
+	if err := idx.indexFiles(ctx, filesToIndex, onBatchProgress, stats); err != nil {
+		return nil, err
 	}
 
 	// Remove deleted files
@@ -207,6 +206,50 @@ func (idx *Indexer) IndexAllWithBatchProgress(ctx context.Context, onProgress Pr
 	return stats, nil
 }
 
+// indexFiles indexes files, using cross-file batching when the embedder
+// supports it, and adds the results to stats.
+func (idx *Indexer) indexFiles(ctx context.Context, files []FileInfo, onBatchProgress BatchProgressCallback, stats *IndexStats) error {
+	if len(files) == 0 {
+		return nil
+	}
+	if batchEmbedder, ok := idx.embedder.(embedder.BatchEmbedder); ok {
+		indexed, chunks, err := idx.indexFilesBatched(ctx, files, batchEmbedder, onBatchProgress)
+		stats.FilesIndexed += indexed
+		stats.ChunksCreated += chunks
+		return err
+	}
+
+	// Sequential indexing for non-batch embedders (e.g., Ollama)
+	total := len(files)
+	for i, file := range files {
+		if onBatchProgress != nil {
+			onBatchProgress(BatchProgressInfo{
+				BatchIndex:      i,
+				TotalBatches:    total,
+				CompletedChunks: i,
+				TotalChunks:     total,
+			})
+		}
+		chunks, err := idx.IndexFile(ctx, file)
+		if err != nil {
+			log.Printf("Failed to index %s: %v", file.Path, err)
+			stats.FilesFailed++
+			continue
+		}
+		stats.FilesIndexed++
+		stats.ChunksCreated += chunks
+	}
+	if onBatchProgress != nil {
+		onBatchProgress(BatchProgressInfo{
+			BatchIndex:      total,
+			TotalBatches:    total,
+			CompletedChunks: total,
+			TotalChunks:     total,
+		})
+	}
+	return nil
+}
+
 // fileChunkData holds chunking information for a single file during batch processing.
 type fileChunkData struct {
 	fileIndex  int // Index in the files slice (for result mapping)
@@ -228,14 +271,21 @@ func (idx *Indexer) prepareFileChunks(
 			return nil, nil, fmt.Errorf("failed to delete existing chunks for %s: %w", file.Path, err)
 		}
 
+		file, ok := idx.redact(file)
+		if !ok {
+			continue
+		}
+
 		chunkInfos := idx.chunker.ChunkWithContext(file.Path, file.Content)
 		if len(chunkInfos) == 0 {
 			continue
 		}
 
+		idx.addContextHeaders(ctx, file, chunkInfos)
+
 		contents := make([]string, len(chunkInfos))
 		for j, c := range chunkInfos {
-			contents[j] = c.Content
+			contents[j] = c.embedText()
 		}
 
 		fileData = append(fileData, fileChunkData{
@@ -268,6 +318,7 @@ func createStoreChunks(chunkInfos []ChunkInfo, embeddings [][]float32, now time.
 			Vector:      embeddings[i],
 			Hash:        info.Hash,
 			ContentHash: info.ContentHash,
+			Headings:    info.Headings,
 			UpdatedAt:   now,
 		}
 		chunkIDs[i] = info.ID
@@ -278,6 +329,8 @@ func createStoreChunks(chunkInfos []ChunkInfo, embeddings [][]float32, now time.
 
 // saveFileData saves chunks and document metadata for a single file.
 func (idx *Indexer) saveFileData(ctx context.Context, fd fileChunkData, chunks []store.Chunk, chunkIDs []string) error {
+	idx.annotateSymbolKinds(ctx, chunks, fd.file)
+
 	if err := idx.store.SaveChunks(ctx, chunks); err != nil {
 		return fmt.Errorf("failed to save chunks for %s: %w", fd.file.Path, err)
 	}
@@ -296,6 +349,31 @@ func (idx *Indexer) saveFileData(ctx context.Context, fd fileChunkData, chunks [
 	return nil
 }
 
+// annotateSymbolKinds records on each chunk the kinds of the symbols defined
+// within its line range, so searches can filter by symbol kind.
+func (idx *Indexer) annotateSymbolKinds(ctx context.Context, chunks []store.Chunk, file FileInfo) {
+	if idx.symbols == nil {
+		return
+	}
+	symbols, err := idx.symbols.ExtractSymbols(ctx, file.Path, file.Content)
+	if err != nil || len(symbols) == 0 {
+		return
+	}
+
+	for i := range chunks {
+		var kinds []string
+		for _, sym := range symbols {
+			if sym.Line < chunks[i].StartLine || sym.Line > chunks[i].EndLine {
+				continue
+			}
+			if kind := string(sym.Kind); !slices.Contains(kinds, kind) {
+				kinds = append(kinds, kind)
+			}
+		}
+		chunks[i].SymbolKinds = kinds
+	}
+}
+
 // wrapBatchProgress creates an embedder.BatchProgress callback from BatchProgressCallback.
 func wrapBatchProgress(onProgress BatchProgressCallback) embedder.BatchProgress {
 	if onProgress == nil {
@@ -333,7 +411,7 @@ func (idx *Indexer) indexFilesBatched(
 	}
 
 	// Check embedding cache for content-addressed deduplication
-	cache, hasCache := idx.store.(store.EmbeddingCache)
+	hasCache := idx.hasEmbeddingCache()
 	var totalCacheHits int
 
 	// Pre-fill cached embeddings and filter out fully-cached files
@@ -361,12 +439,7 @@ func (idx *Indexer) indexFilesBatched(
 				allCached = false
 				continue
 			}
-			vec, found, err := cache.LookupByContentHash(ctx, chunk.ContentHash)
-			if err != nil {
-				log.Printf("Warning: cache lookup failed: %v", err)
-				allCached = false
-				continue
-			}
+			vec, found := idx.lookupCachedEmbedding(ctx, chunk.ContentHash)
 			if found {
 				vecs[j] = vec
 				totalCacheHits++
@@ -416,6 +489,7 @@ func (idx *Indexer) indexFilesBatched(
 					fd.file.Path, len(embeddings), len(fd.chunkInfos))
 				continue
 			}
+			idx.rememberEmbeddings(ctx, fd.chunkInfos, embeddings)
 			chunks, chunkIDs := createStoreChunks(fd.chunkInfos, embeddings, now)
 			if err := idx.saveFileData(ctx, fd, chunks, chunkIDs); err != nil {
 				return filesIndexed, chunksCreated, err
@@ -434,19 +508,31 @@ const maxReChunkAttempts = 3
 
 // IndexFile indexes a single file
 func (idx *Indexer) IndexFile(ctx context.Context, file FileInfo) (int, error) {
+	return idx.indexFile(ctx, file, nil)
+}
+
+// indexFile indexes a single file. Chunks whose raw content hash is in reuse
+// take that vector instead of being embedded.
+func (idx *Indexer) indexFile(ctx context.Context, file FileInfo, reuse map[string][]float32) (int, error) {
 	// Remove existing chunks for this file
 	if err := idx.store.DeleteByFile(ctx, file.Path); err != nil {
 		return 0, fmt.Errorf("failed to delete existing chunks: %w", err)
 	}
 
+	file, ok := idx.redact(file)
+	if !ok {
+		return 0, nil
+	}
+
 	// Chunk the file
 	chunkInfos := idx.chunker.ChunkWithContext(file.Path, file.Content)
 	if len(chunkInfos) == 0 {
 		return 0, nil
 	}
+	idx.addContextHeaders(ctx, file, chunkInfos)
 
 	// Check embedding cache for content-addressed deduplication
-	cachedVectors, cacheHits := idx.lookupCachedEmbeddings(ctx, chunkInfos)
+	cachedVectors, cacheHits := idx.lookupCachedEmbeddings(ctx, chunkInfos, reuse)
 	if cacheHits > 0 {
 		log.Printf("Reused %d cached embeddings for %s", cacheHits, file.Path)
 	}
@@ -468,6 +554,7 @@ func (idx *Indexer) IndexFile(ctx context.Context, file FileInfo) (int, error) {
 		if err != nil {
 			return 0, fmt.Errorf("failed to embed chunks: %w", err)
 		}
+		idx.rememberEmbeddings(ctx, finalUncachedChunks, uncachedVectors)
 	}
 
 	// Merge cached and freshly embedded results
@@ -530,11 +617,14 @@ func (idx *Indexer) IndexFile(ctx context.Context, file FileInfo) (int, error) {
 			Vector:      vectors[i],
 			Hash:        info.Hash,
 			ContentHash: info.ContentHash,
+			Headings:    info.Headings,
 			UpdatedAt:   now,
 		}
 		chunkIDs[i] = info.ID
 	}
 
+	idx.annotateSymbolKinds(ctx, chunks, file)
+
 	// Save chunks
 	if err := idx.store.SaveChunks(ctx, chunks); err != nil {
 		return 0, fmt.Errorf("failed to save chunks: %w", err)
@@ -565,7 +655,7 @@ func (idx *Indexer) embedWithReChunking(ctx context.Context, chunks []ChunkInfo)
 	for attempt := 0; attempt < maxReChunkAttempts; attempt++ {
 		contents := make([]string, len(currentChunks))
 		for i, c := range currentChunks {
-			contents[i] = c.Content
+			contents[i] = c.embedText()
 		}
 
 		vectors, err := idx.embedder.EmbedBatch(ctx, contents)
@@ -597,7 +687,7 @@ func (idx *Indexer) embedWithReChunking(ctx context.Context, chunks []ChunkInfo)
 		if failedIndex > 0 {
 			beforeContents := make([]string, failedIndex)
 			for i := 0; i < failedIndex; i++ {
-				beforeContents[i] = currentChunks[i].Content
+				beforeContents[i] = currentChunks[i].embedText()
 			}
 			beforeVectors, err := idx.embedder.EmbedBatch(ctx, beforeContents)
 			if err != nil {
@@ -622,31 +712,79 @@ func (idx *Indexer) embedWithReChunking(ctx context.Context, chunks []ChunkInfo)
 	return nil, nil, fmt.Errorf("exceeded maximum re-chunk attempts (%d) for file", maxReChunkAttempts)
 }
 
-// lookupCachedEmbeddings checks if the store implements EmbeddingCache and returns
-// cached vectors for chunks with matching content hashes. The returned map maps
-// chunk index to cached vector. Chunks not in the map need fresh embedding.
-func (idx *Indexer) lookupCachedEmbeddings(ctx context.Context, chunks []ChunkInfo) (map[int][]float32, int) {
-	cache, ok := idx.store.(store.EmbeddingCache)
-	if !ok {
+// lookupCachedEmbeddings returns cached vectors for chunks with matching
+// content hashes, from reuse (keyed by raw content hash), the store's
+// EmbeddingCache or the shared cache.
+// The returned map maps chunk index to cached vector. Chunks not in the map
+// need fresh embedding.
+func (idx *Indexer) lookupCachedEmbeddings(ctx context.Context, chunks []ChunkInfo, reuse map[string][]float32) (map[int][]float32, int) {
+	if len(reuse) == 0 && !idx.hasEmbeddingCache() {
 		return nil, 0
 	}
 
 	cached := make(map[int][]float32)
 	for i, chunk := range chunks {
-		if chunk.ContentHash == "" {
-			continue
+		if vec, found := reuse[chunk.rawContentHash()]; found {
+			cached[i] = vec
+		} else if vec, found := idx.lookupCachedEmbedding(ctx, chunk.ContentHash); found {
+			cached[i] = vec
 		}
-		vec, found, err := cache.LookupByContentHash(ctx, chunk.ContentHash)
+	}
+
+	return cached, len(cached)
+}
+
+// hasEmbeddingCache reports whether any embedding cache is available.
+func (idx *Indexer) hasEmbeddingCache() bool {
+	_, ok := idx.store.(store.EmbeddingCache)
+	return ok || idx.sharedCache != nil
+}
+
+// lookupCachedEmbedding looks contentHash up in the store's EmbeddingCache,
+// then in the shared cache. Lookup errors are logged and treated as misses.
+func (idx *Indexer) lookupCachedEmbedding(ctx context.Context, contentHash string) ([]float32, bool) {
+	if contentHash == "" {
+		return nil, false
+	}
+
+	caches := make([]store.EmbeddingCache, 0, 2)
+	if cache, ok := idx.store.(store.EmbeddingCache); ok {
+		caches = append(caches, cache)
+	}
+	if idx.sharedCache != nil {
+		caches = append(caches, idx.sharedCache)
+	}
+
+	for _, cache := range caches {
+		vec, found, err := cache.LookupByContentHash(ctx, contentHash)
 		if err != nil {
-			log.Printf("Warning: cache lookup failed for content hash %s: %v", chunk.ContentHash[:8], err)
+			log.Printf("Warning: cache lookup failed for content hash %s: %v", contentHash[:min(8, len(contentHash))], err)
 			continue
 		}
 		if found {
-			cached[i] = vec
+			return vec, true
 		}
 	}
+	return nil, false
+}
 
-	return cached, len(cached)
+// rememberEmbeddings records freshly computed vectors in the shared cache.
+// Failures are logged; the cache is an optimization only.
+func (idx *Indexer) rememberEmbeddings(ctx context.Context, chunks []ChunkInfo, vectors [][]float32) {
+	writer, ok := idx.sharedCache.(store.EmbeddingCacheWriter)
+	if !ok || len(chunks) != len(vectors) {
+		return
+	}
+
+	entries := make(map[string][]float32, len(chunks))
+	for i, chunk := range chunks {
+		if chunk.ContentHash != "" {
+			entries[chunk.ContentHash] = vectors[i]
+		}
+	}
+	if err := writer.StoreEmbeddings(ctx, entries); err != nil {
+		log.Printf("Warning: failed to update shared embedding cache: %v", err)
+	}
 }
 
 // RemoveFile removes a file from the index
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -1165,3 +1166,76 @@ func TestEmbedWithReChunking_ReChunksOnError(t *testing.T) {
 		t.Errorf("vectors count %d != chunks count %d", len(vectors), len(finalChunks))
 	}
 }
+
+func TestIndexFile_RecordsSymbolKinds(t *testing.T) {
+	st := newMockStore()
+	idx := NewIndexer(t.TempDir(), st, newMockEmbedder(), NewChunker(512, 50), nil, time.Time{})
+
+	content := "package demo\n\ntype Server struct{}\n\nfunc (s *Server) Start() {}\n\nfunc helper() {}\n"
+	if _, err := idx.IndexFile(context.Background(), FileInfo{Path: "demo.go", Content: content}); err != nil {
+		t.Fatalf("IndexFile failed: %v", err)
+	}
+
+	chunk, ok := st.chunks["demo.go_0"]
+	if !ok {
+		t.Fatalf("expected chunk demo.go_0, got %v", st.chunks)
+	}
+	for _, kind := range []string{"type", "method", "function"} {
+		if !slices.Contains(chunk.SymbolKinds, kind) {
+			t.Errorf("expected symbol kind %q in %v", kind, chunk.SymbolKinds)
+		}
+	}
+}
+
+// memoryEmbeddingCache is an in-memory shared embedding cache.
+type memoryEmbeddingCache struct {
+	vectors map[string][]float32
+}
+
+func (c *memoryEmbeddingCache) LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error) {
+	vec, ok := c.vectors[contentHash]
+	return vec, ok, nil
+}
+
+func (c *memoryEmbeddingCache) StoreEmbeddings(ctx context.Context, vectors map[string][]float32) error {
+	for hash, vec := range vectors {
+		c.vectors[hash] = vec
+	}
+	return nil
+}
+
+func TestIndexFile_SharedEmbeddingCache(t *testing.T) {
+	cache := &memoryEmbeddingCache{vectors: make(map[string][]float32)}
+	content := "package demo\n\nfunc helper() {}\n"
+
+	// The mock store has no EmbeddingCache of its own; the first project
+	// embeds and fills the shared cache.
+	first := newMockEmbedder()
+	idx := NewIndexer(t.TempDir(), newMockStore(), first, NewChunker(512, 50), nil, time.Time{})
+	idx.SetEmbeddingCache(cache)
+	if _, err := idx.IndexFile(context.Background(), FileInfo{Path: "vendor/lib.go", Content: content}); err != nil {
+		t.Fatalf("IndexFile failed: %v", err)
+	}
+	if !first.embedCalled {
+		t.Fatal("expected the first index to call the embedder")
+	}
+	if len(cache.vectors) == 0 {
+		t.Fatal("expected fresh embeddings to be recorded in the shared cache")
+	}
+
+	// A second project with identical content reuses them.
+	second := newMockEmbedder()
+	st := newMockStore()
+	idx = NewIndexer(t.TempDir(), st, second, NewChunker(512, 50), nil, time.Time{})
+	idx.SetEmbeddingCache(cache)
+	n, err := idx.IndexFile(context.Background(), FileInfo{Path: "vendor/lib.go", Content: content})
+	if err != nil {
+		t.Fatalf("IndexFile failed: %v", err)
+	}
+	if second.embedCalled {
+		t.Error("expected cached embeddings to be reused")
+	}
+	if n == 0 || len(st.chunks) != n {
+		t.Errorf("expected %d chunks saved, got %d", n, len(st.chunks))
+	}
+}
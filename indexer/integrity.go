@@ -0,0 +1,338 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/yoanbernabeu/grepai/rpg"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// IssueKind classifies an inconsistency found by IntegrityChecker.Verify.
+type IssueKind string
+
+const (
+	IssueMissingChunks      IssueKind = "missing_chunks"      // document references chunks absent from the store
+	IssueUnreferencedChunks IssueKind = "unreferenced_chunks" // chunks stored for a file but not listed by its document
+	IssueOrphanChunks       IssueKind = "orphan_chunks"       // chunks whose file has no document
+	IssueStaleFile          IssueKind = "stale_file"          // indexed hash no longer matches the file on disk
+	IssueDeletedFile        IssueKind = "deleted_file"        // document for a file that is gone or no longer indexable
+	IssueUnindexedFile      IssueKind = "unindexed_file"      // indexable file without a document
+	IssueStaleSymbols       IssueKind = "stale_symbols"       // symbol index entry for a changed or deleted file
+	IssueOrphanRPGFile      IssueKind = "orphan_rpg_file"     // RPG nodes for a file that is not indexed
+)
+
+// Issue describes one inconsistency affecting a single file.
+type Issue struct {
+	Kind     IssueKind `json:"kind"`
+	Path     string    `json:"path"`
+	Detail   string    `json:"detail,omitempty"`
+	ChunkIDs []string  `json:"chunk_ids,omitempty"`
+}
+
+// IntegrityReport is the result of IntegrityChecker.Verify.
+type IntegrityReport struct {
+	Documents   int     `json:"documents"`
+	Chunks      int     `json:"chunks"`
+	Files       int     `json:"files"`
+	SymbolFiles int     `json:"symbol_files"`
+	RPGFiles    int     `json:"rpg_files"`
+	Issues      []Issue `json:"issues"`
+}
+
+// OK reports whether no inconsistencies were found.
+func (r *IntegrityReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// RepairStats summarizes the changes made by IntegrityChecker.Repair.
+type RepairStats struct {
+	OrphanChunksDeleted int `json:"orphan_chunks_deleted"`
+	DocumentsRemoved    int `json:"documents_removed"`
+	FilesRequeued       int `json:"files_requeued"`
+	SymbolFilesRemoved  int `json:"symbol_files_removed"`
+	RPGFilesRemoved     int `json:"rpg_files_removed"`
+}
+
+// IntegrityChecker cross-checks the vector store against the files on disk
+// and, when set, against the symbol and RPG indexes.
+type IntegrityChecker struct {
+	Store   store.VectorStore
+	Scanner *Scanner
+	Symbols *trace.GOBSymbolStore // optional
+	RPG     rpg.RPGStore          // optional
+}
+
+// Verify reports inconsistencies without modifying any index.
+func (c *IntegrityChecker) Verify(ctx context.Context) (*IntegrityReport, error) {
+	metas, _, err := c.Scanner.ScanMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan files: %w", err)
+	}
+	onDisk := make(map[string]bool, len(metas))
+	for _, meta := range metas {
+		onDisk[meta.Path] = true
+	}
+
+	// Disk hashes are computed lazily and shared between the vector and
+	// symbol checks. An empty hash means the file is no longer indexable.
+	hashes := make(map[string]string)
+	diskHash := func(path string) string {
+		if hash, ok := hashes[path]; ok {
+			return hash
+		}
+		var hash string
+		if onDisk[path] {
+			if file, err := c.Scanner.ScanFile(path); err == nil && file != nil {
+				hash = file.Hash
+			}
+		}
+		hashes[path] = hash
+		return hash
+	}
+
+	paths, err := c.Store.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	sort.Strings(paths)
+
+	chunks, err := c.Store.GetAllChunks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+	chunkIDs := make(map[string]bool, len(chunks))
+	chunksByFile := make(map[string][]string)
+	for _, chunk := range chunks {
+		chunkIDs[chunk.ID] = true
+		chunksByFile[chunk.FilePath] = append(chunksByFile[chunk.FilePath], chunk.ID)
+	}
+
+	report := &IntegrityReport{
+		Documents: len(paths),
+		Chunks:    len(chunks),
+		Files:     len(metas),
+	}
+
+	// Stores without document metadata only allow checking which files are
+	// indexed at all.
+	tracked := store.TracksDocuments(c.Store)
+
+	documented := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		documented[path] = true
+		if !tracked {
+			if diskHash(path) == "" {
+				report.Issues = append(report.Issues, Issue{
+					Kind:   IssueDeletedFile,
+					Path:   path,
+					Detail: "file is missing or no longer indexable",
+				})
+			}
+			continue
+		}
+
+		doc, err := c.Store.GetDocument(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document %s: %w", path, err)
+		}
+		if doc == nil {
+			continue
+		}
+
+		referenced := make(map[string]bool, len(doc.ChunkIDs))
+		var missing []string
+		for _, id := range doc.ChunkIDs {
+			referenced[id] = true
+			if !chunkIDs[id] {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			report.Issues = append(report.Issues, Issue{
+				Kind:     IssueMissingChunks,
+				Path:     path,
+				Detail:   fmt.Sprintf("%d of %d referenced chunks missing", len(missing), len(doc.ChunkIDs)),
+				ChunkIDs: missing,
+			})
+		}
+
+		var unreferenced []string
+		for _, id := range chunksByFile[path] {
+			if !referenced[id] {
+				unreferenced = append(unreferenced, id)
+			}
+		}
+		if len(unreferenced) > 0 {
+			report.Issues = append(report.Issues, Issue{
+				Kind:     IssueUnreferencedChunks,
+				Path:     path,
+				Detail:   fmt.Sprintf("%d chunks not listed by the document", len(unreferenced)),
+				ChunkIDs: unreferenced,
+			})
+		}
+
+		hash := diskHash(path)
+		switch {
+		case hash == "":
+			report.Issues = append(report.Issues, Issue{
+				Kind:   IssueDeletedFile,
+				Path:   path,
+				Detail: "file is missing or no longer indexable",
+			})
+		case hash != doc.Hash:
+			report.Issues = append(report.Issues, Issue{
+				Kind:   IssueStaleFile,
+				Path:   path,
+				Detail: "content changed since it was indexed",
+			})
+		}
+	}
+
+	orphanPaths := make([]string, 0, len(chunksByFile))
+	for path := range chunksByFile {
+		if tracked && !documented[path] {
+			orphanPaths = append(orphanPaths, path)
+		}
+	}
+	sort.Strings(orphanPaths)
+	for _, path := range orphanPaths {
+		report.Issues = append(report.Issues, Issue{
+			Kind:     IssueOrphanChunks,
+			Path:     path,
+			Detail:   fmt.Sprintf("%d chunks without a document", len(chunksByFile[path])),
+			ChunkIDs: chunksByFile[path],
+		})
+	}
+
+	for _, meta := range metas {
+		if !documented[meta.Path] {
+			report.Issues = append(report.Issues, Issue{
+				Kind:   IssueUnindexedFile,
+				Path:   meta.Path,
+				Detail: "file is not in the index",
+			})
+		}
+	}
+
+	if c.Symbols != nil {
+		symbolFiles := c.Symbols.ListFiles()
+		report.SymbolFiles = len(symbolFiles)
+		for _, path := range symbolFiles {
+			hash := diskHash(path)
+			if hash == "" {
+				report.Issues = append(report.Issues, Issue{
+					Kind:   IssueStaleSymbols,
+					Path:   path,
+					Detail: "symbols recorded for a missing file",
+				})
+				continue
+			}
+			if stored, ok := c.Symbols.GetFileContentHash(path); ok && stored != hash {
+				report.Issues = append(report.Issues, Issue{
+					Kind:   IssueStaleSymbols,
+					Path:   path,
+					Detail: "symbols extracted from an older version of the file",
+				})
+			}
+		}
+	}
+
+	if c.RPG != nil {
+		fileNodes := c.RPG.GetGraph().GetNodesByKind(rpg.KindFile)
+		report.RPGFiles = len(fileNodes)
+		rpgPaths := make([]string, 0, len(fileNodes))
+		for _, node := range fileNodes {
+			if !onDisk[node.Path] || !documented[node.Path] {
+				rpgPaths = append(rpgPaths, node.Path)
+			}
+		}
+		sort.Strings(rpgPaths)
+		for _, path := range rpgPaths {
+			report.Issues = append(report.Issues, Issue{
+				Kind:   IssueOrphanRPGFile,
+				Path:   path,
+				Detail: "graph nodes for a file that is not indexed",
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// Repair fixes the issues in report. Orphan chunks and entries for deleted
+// files are removed; stale or partially stored files are dropped from the
+// index so the next indexing run (grepai watch) re-embeds them. The caller
+// is responsible for persisting the stores afterwards.
+func (c *IntegrityChecker) Repair(ctx context.Context, report *IntegrityReport) (*RepairStats, error) {
+	stats := &RepairStats{}
+
+	// Group chunk-level issues by file so each file is purged once.
+	purge := make(map[string][]string)
+	requeue := make(map[string]bool)
+	deleted := make(map[string]bool)
+	var symbolPaths, rpgPaths []string
+	for _, issue := range report.Issues {
+		switch issue.Kind {
+		case IssueOrphanChunks:
+			purge[issue.Path] = append(purge[issue.Path], issue.ChunkIDs...)
+			stats.OrphanChunksDeleted += len(issue.ChunkIDs)
+		case IssueUnreferencedChunks:
+			purge[issue.Path] = append(purge[issue.Path], issue.ChunkIDs...)
+			stats.OrphanChunksDeleted += len(issue.ChunkIDs)
+			requeue[issue.Path] = true
+		case IssueMissingChunks, IssueStaleFile:
+			if _, ok := purge[issue.Path]; !ok {
+				purge[issue.Path] = nil
+			}
+			requeue[issue.Path] = true
+		case IssueDeletedFile:
+			if _, ok := purge[issue.Path]; !ok {
+				purge[issue.Path] = nil
+			}
+			deleted[issue.Path] = true
+		case IssueStaleSymbols:
+			symbolPaths = append(symbolPaths, issue.Path)
+		case IssueOrphanRPGFile:
+			rpgPaths = append(rpgPaths, issue.Path)
+		}
+	}
+
+	paths := make([]string, 0, len(purge))
+	for path := range purge {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := store.PurgeFile(ctx, c.Store, path, purge[path]); err != nil {
+			return stats, err
+		}
+		switch {
+		case deleted[path]:
+			stats.DocumentsRemoved++
+		case requeue[path]:
+			stats.FilesRequeued++
+		}
+	}
+
+	if c.Symbols != nil {
+		for _, path := range symbolPaths {
+			if err := c.Symbols.DeleteFile(ctx, path); err != nil {
+				return stats, fmt.Errorf("failed to delete symbols for %s: %w", path, err)
+			}
+			stats.SymbolFilesRemoved++
+		}
+	}
+
+	if c.RPG != nil && len(rpgPaths) > 0 {
+		evolver := rpg.NewEvolver(c.RPG.GetGraph(), nil, nil, 0)
+		for _, path := range rpgPaths {
+			evolver.HandleDelete(ctx, path)
+			stats.RPGFilesRemoved++
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,159 @@
+package indexer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/rpg"
+	"github.com/yoanbernabeu/grepai/store"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func issueKindsByPath(report *IntegrityReport) map[string][]IssueKind {
+	kinds := make(map[string][]IssueKind)
+	for _, issue := range report.Issues {
+		kinds[issue.Path] = append(kinds[issue.Path], issue.Kind)
+	}
+	for path := range kinds {
+		sort.Slice(kinds[path], func(i, j int) bool { return kinds[path][i] < kinds[path][j] })
+	}
+	return kinds
+}
+
+func TestIntegrityChecker_VerifyAndRepair(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"ok.go":      "package main\n\nfunc OK() {}\n",
+		"stale.go":   "package main\n\nfunc Stale() {}\n",
+		"partial.go": "package main\n\nfunc Partial() {}\n",
+		"new.go":     "package main\n\nfunc New() {}\n",
+	}
+	hashes := make(map[string]string)
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		hash, err := HashFile(path)
+		if err != nil {
+			t.Fatalf("failed to hash %s: %v", name, err)
+		}
+		hashes[name] = hash
+	}
+
+	st := store.NewGOBStore(filepath.Join(tmpDir, "index.gob"))
+	if err := st.SaveChunks(ctx, []store.Chunk{
+		{ID: "ok-0", FilePath: "ok.go"},
+		{ID: "stale-0", FilePath: "stale.go"},
+		{ID: "partial-0", FilePath: "partial.go"},
+		{ID: "gone-0", FilePath: "gone.go"},
+		{ID: "orphan-0", FilePath: "orphan.go"},
+	}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	for _, doc := range []store.Document{
+		{Path: "ok.go", Hash: hashes["ok.go"], ChunkIDs: []string{"ok-0"}},
+		{Path: "stale.go", Hash: "outdated", ChunkIDs: []string{"stale-0"}},
+		{Path: "partial.go", Hash: hashes["partial.go"], ChunkIDs: []string{"partial-0", "partial-1"}},
+		{Path: "gone.go", Hash: "gone", ChunkIDs: []string{"gone-0"}},
+	} {
+		if err := st.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("SaveDocument failed: %v", err)
+		}
+	}
+
+	symbols := trace.NewGOBSymbolStore(filepath.Join(tmpDir, "symbols.gob"))
+	if err := symbols.SaveFileWithContentHash(ctx, "ok.go", hashes["ok.go"], nil, nil); err != nil {
+		t.Fatalf("SaveFileWithContentHash failed: %v", err)
+	}
+	if err := symbols.SaveFileWithContentHash(ctx, "gone.go", "gone", nil, nil); err != nil {
+		t.Fatalf("SaveFileWithContentHash failed: %v", err)
+	}
+
+	rpgStore := rpg.NewGOBRPGStore(filepath.Join(tmpDir, "rpg.gob"))
+	graph := rpgStore.GetGraph()
+	graph.AddNode(&rpg.Node{ID: rpg.MakeNodeID(rpg.KindFile, "ok.go"), Kind: rpg.KindFile, Path: "ok.go"})
+	graph.AddNode(&rpg.Node{ID: rpg.MakeNodeID(rpg.KindFile, "gone.go"), Kind: rpg.KindFile, Path: "gone.go"})
+
+	ignoreMatcher, err := NewIgnoreMatcher(tmpDir, []string{"*.gob"}, "")
+	if err != nil {
+		t.Fatalf("failed to create ignore matcher: %v", err)
+	}
+	checker := &IntegrityChecker{
+		Store:   st,
+		Scanner: NewScanner(tmpDir, ignoreMatcher),
+		Symbols: symbols,
+		RPG:     rpgStore,
+	}
+
+	report, err := checker.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	got := issueKindsByPath(report)
+	want := map[string][]IssueKind{
+		"stale.go":   {IssueStaleFile},
+		"partial.go": {IssueMissingChunks},
+		"gone.go":    {IssueDeletedFile, IssueOrphanRPGFile, IssueStaleSymbols},
+		"orphan.go":  {IssueOrphanChunks},
+		"new.go":     {IssueUnindexedFile},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("issues = %v, want %v", got, want)
+	}
+	for path, kinds := range want {
+		if len(got[path]) != len(kinds) {
+			t.Errorf("%s: issues = %v, want %v", path, got[path], kinds)
+			continue
+		}
+		for i := range kinds {
+			if got[path][i] != kinds[i] {
+				t.Errorf("%s: issues = %v, want %v", path, got[path], kinds)
+				break
+			}
+		}
+	}
+
+	stats, err := checker.Repair(ctx, report)
+	if err != nil {
+		t.Fatalf("Repair failed: %v", err)
+	}
+	if stats.OrphanChunksDeleted != 1 || stats.DocumentsRemoved != 1 || stats.FilesRequeued != 2 ||
+		stats.SymbolFilesRemoved != 1 || stats.RPGFilesRemoved != 1 {
+		t.Errorf("unexpected repair stats: %+v", stats)
+	}
+
+	chunks, err := st.GetAllChunks(ctx)
+	if err != nil {
+		t.Fatalf("GetAllChunks failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].ID != "ok-0" {
+		t.Errorf("remaining chunks = %v, want only ok-0", chunks)
+	}
+	if symbols.IsFileIndexed("gone.go") {
+		t.Error("symbols for gone.go should be removed")
+	}
+	if len(graph.GetNodesByFile("gone.go")) != 0 {
+		t.Error("RPG nodes for gone.go should be removed")
+	}
+
+	// Requeued files are picked up by the next indexing run as unindexed.
+	report, err = checker.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify after repair failed: %v", err)
+	}
+	for _, issue := range report.Issues {
+		if issue.Kind != IssueUnindexedFile {
+			t.Errorf("unexpected issue after repair: %+v", issue)
+		}
+	}
+	if len(report.Issues) != 3 {
+		t.Errorf("expected stale.go, partial.go and new.go to be unindexed, got %v", report.Issues)
+	}
+}
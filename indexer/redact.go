@@ -0,0 +1,293 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretGroup is the name of the regex group holding the secret when a
+// pattern also matches surrounding context, such as "password = ...".
+const secretGroup = "secret"
+
+// minSecretEntropy is the Shannon entropy, in bits per character, above
+// which the high-entropy rule treats a quoted or assigned token as a secret.
+const minSecretEntropy = 4.0
+
+// SecretRule is a pattern for one kind of secret.
+type SecretRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// HighEntropy keeps only matches that look random: letters of both
+	// cases and digits, with at least minSecretEntropy bits per character.
+	HighEntropy bool
+}
+
+// BuiltinSecretRules lists the secret patterns redacted by default.
+var BuiltinSecretRules = []SecretRule{
+	{Name: "private-key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY( BLOCK)?-----`)},
+	{Name: "aws-access-key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`)},
+	{Name: "aws-secret-key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40})\b`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{Name: "github-token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "slack-token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{Name: "openai-key", Pattern: regexp.MustCompile(`\bsk-(?:proj-|svcacct-)?[A-Za-z0-9_-]{20,}`)},
+	{Name: "google-api-key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{Name: "stripe-key", Pattern: regexp.MustCompile(`\b[rs]k_(?:live|test)_[0-9A-Za-z]{16,}\b`)},
+	{Name: "url-credentials", Pattern: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^:@/\s"']+:(?P<secret>[^@/\s"']+)@`)},
+	{Name: "password-literal", Pattern: regexp.MustCompile(`(?i)(?:password|passwd|secret|api_?key|access_?token|auth_?token|client_?secret)[a-z0-9_]*["']?\s*(?::=|=>|[:=])\s*["'](?P<secret>[^"'\s]{8,})["']`)},
+	{Name: "password-config", Pattern: regexp.MustCompile(`(?im)^[ \t]*(?:export[ \t]+)?[a-z0-9_.-]*(?:password|passwd|secret|api_?key|access_?token|auth_?token|client_?secret)[a-z0-9_]*[ \t]*[:=][ \t]*(?P<secret>[^\s"'#$(){}<>]{8,})[ \t\r]*$`)},
+	{Name: "high-entropy", Pattern: regexp.MustCompile("(?:[\"'`]|[:=]\\s*)(?P<secret>[A-Za-z0-9+/_-]{24,}={0,2})"), HighEntropy: true},
+}
+
+// RedactorConfig configures a Redactor.
+type RedactorConfig struct {
+	Rules     []SecretRule // rules added to the built-in ones
+	Disable   []string     // names of built-in rules to turn off
+	SkipFiles bool         // leave files with secrets out instead of redacting them
+	Audit     io.Writer    // receives one JSON line per redaction, if set
+	// History is the audit log of previous runs. Files it records as
+	// skipped are not scanned, logged or audited again while their content
+	// hash is unchanged.
+	History io.Reader
+}
+
+// Redaction records one secret found in a file. The secret itself is never
+// recorded.
+type Redaction struct {
+	Time   time.Time `json:"time"`
+	File   string    `json:"file"`
+	Line   int       `json:"line"`
+	Rule   string    `json:"rule"`
+	Action string    `json:"action"`         // "redacted" or "skipped_file"
+	Hash   string    `json:"hash,omitempty"` // content hash of the file, when known
+}
+
+// Redactor replaces secrets in file content with placeholders before it is
+// chunked, so they are never embedded or stored.
+type Redactor struct {
+	rules     []SecretRule
+	skipFiles bool
+
+	mu      sync.Mutex
+	audit   io.Writer
+	skipped map[string]string // content hash of each file skipped for its secrets
+}
+
+// NewRedactor builds a redactor from the built-in rules and cfg.
+func NewRedactor(cfg RedactorConfig) (*Redactor, error) {
+	disabled := make(map[string]bool, len(cfg.Disable))
+	for _, name := range cfg.Disable {
+		disabled[name] = true
+	}
+
+	r := &Redactor{skipFiles: cfg.SkipFiles, audit: cfg.Audit, skipped: make(map[string]string)}
+	for _, rule := range BuiltinSecretRules {
+		if disabled[rule.Name] {
+			delete(disabled, rule.Name)
+			continue
+		}
+		r.rules = append(r.rules, rule)
+	}
+	for name := range disabled {
+		return nil, fmt.Errorf("unknown built-in redaction pattern %q", name)
+	}
+	r.rules = append(r.rules, cfg.Rules...)
+	if cfg.History != nil {
+		r.loadSkipped(cfg.History)
+	}
+	return r, nil
+}
+
+// loadSkipped records the files an audit log lists as skipped. Reading
+// stops at the first malformed line.
+func (r *Redactor) loadSkipped(history io.Reader) {
+	dec := json.NewDecoder(history)
+	for {
+		var entry Redaction
+		if err := dec.Decode(&entry); err != nil {
+			return
+		}
+		if entry.Action == "skipped_file" && entry.Hash != "" {
+			r.skipped[entry.File] = entry.Hash
+		}
+	}
+}
+
+// wasSkipped reports whether the file was already skipped for its secrets
+// with the same content hash.
+func (r *Redactor) wasSkipped(filePath, hash string) bool {
+	if hash == "" {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.skipped[filePath] == hash
+}
+
+// Redact returns content with every secret replaced by a placeholder naming
+// its rule, and the redactions made. Placeholders keep the line breaks of
+// the secrets they replace, so line numbers don't change. When the redactor
+// skips files, skip is true if any secret was found and content is returned
+// unchanged.
+func (r *Redactor) Redact(filePath, content string) (redacted string, found []Redaction, skip bool) {
+	return r.redact(filePath, "", content)
+}
+
+// redact implements Redact, recording hash as the content hash of the file.
+func (r *Redactor) redact(filePath, hash, content string) (redacted string, found []Redaction, skip bool) {
+	type span struct {
+		start, end int
+		rule       string
+	}
+	var spans []span
+	for _, rule := range r.rules {
+		secretIdx := rule.Pattern.SubexpIndex(secretGroup)
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[0], m[1]
+			if secretIdx > 0 {
+				start, end = m[2*secretIdx], m[2*secretIdx+1]
+			}
+			if start < 0 || start == end {
+				continue
+			}
+			if rule.HighEntropy && !looksRandom(content[start:end]) {
+				continue
+			}
+			spans = append(spans, span{start, end, rule.Name})
+		}
+	}
+	if len(spans) == 0 {
+		return content, nil, false
+	}
+
+	// Keep the first of overlapping matches; spans were collected in rule
+	// order, so earlier rules win ties.
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	kept := spans[:0]
+	for _, s := range spans {
+		if len(kept) == 0 || s.start >= kept[len(kept)-1].end {
+			kept = append(kept, s)
+		}
+	}
+
+	action := "redacted"
+	if r.skipFiles {
+		action = "skipped_file"
+	}
+	now := time.Now()
+	var b strings.Builder
+	last := 0
+	for _, s := range kept {
+		found = append(found, Redaction{
+			Time:   now,
+			File:   filePath,
+			Line:   strings.Count(content[:s.start], "\n") + 1,
+			Rule:   s.rule,
+			Action: action,
+			Hash:   hash,
+		})
+		b.WriteString(content[last:s.start])
+		b.WriteString("[REDACTED:" + s.rule + "]")
+		b.WriteString(strings.Repeat("\n", strings.Count(content[s.start:s.end], "\n")))
+		last = s.end
+	}
+	b.WriteString(content[last:])
+
+	r.record(found)
+	if r.skipFiles {
+		if hash != "" {
+			r.mu.Lock()
+			r.skipped[filePath] = hash
+			r.mu.Unlock()
+		}
+		return content, found, true
+	}
+	return b.String(), found, false
+}
+
+// SetRedactor makes the indexer redact secrets from file content before
+// chunking, so they are neither embedded nor stored.
+func (idx *Indexer) SetRedactor(r *Redactor) {
+	idx.redactor = r
+}
+
+// redact returns file with its secrets redacted, and false if the file must
+// not be indexed because it contains secrets. A file already skipped with
+// the same content is left out silently.
+func (idx *Indexer) redact(file FileInfo) (FileInfo, bool) {
+	if idx.redactor == nil {
+		return file, true
+	}
+	if idx.redactor.wasSkipped(file.Path, file.Hash) {
+		return file, false
+	}
+	content, found, skip := idx.redactor.redact(file.Path, file.Hash, file.Content)
+	if skip {
+		log.Printf("Skipped %s: contains %d secret(s)", file.Path, len(found))
+		return file, false
+	}
+	if len(found) > 0 {
+		log.Printf("Redacted %d secret(s) in %s", len(found), file.Path)
+	}
+	file.Content = content
+	return file, true
+}
+
+// record appends redactions to the audit log, in a single write.
+func (r *Redactor) record(found []Redaction) {
+	if r.audit == nil {
+		return
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, f := range found {
+		if err := enc.Encode(f); err != nil {
+			return
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.audit.Write(buf.Bytes()); err != nil {
+		// Audit failures must not block indexing.
+		log.Printf("Warning: failed to write redaction audit log: %v", err)
+	}
+}
+
+// looksRandom reports whether s mixes letters of both cases and digits with
+// high Shannon entropy, as generated keys do and identifiers rarely do.
+func looksRandom(s string) bool {
+	var lower, upper, digit bool
+	counts := make(map[rune]int)
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+		counts[c]++
+	}
+	if !lower || !upper || !digit {
+		return false
+	}
+
+	entropy := 0.0
+	n := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= minSecretEntropy
+}
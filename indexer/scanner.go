@@ -3,16 +3,24 @@ package indexer
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"unicode/utf8"
+
+	"github.com/yoanbernabeu/grepai/store"
 )
 
 const (
+	// maxFileSize is the default size limit, see FileFilterConfig.MaxFileSize.
 	maxFileSize = 1 * 1024 * 1024 // 1 MB
+
+	// maxNotebookSize is higher because notebooks embed their outputs,
+	// images included, which are not indexed.
+	maxNotebookSize = 16 * 1024 * 1024 // 16 MB
 )
 
 // MinifiedPatterns lists patterns for minified files to skip by default
@@ -95,6 +103,7 @@ var SupportedExtensions = map[string]bool{
 	".hcl":    true,
 	".pas":    true, // Pascal source file
 	".dpr":    true, // Delphi project file
+	".ipynb":  true, // Jupyter notebook, chunked by cell
 }
 
 type FileInfo struct {
@@ -114,20 +123,94 @@ type FileMeta struct {
 type Scanner struct {
 	root   string
 	ignore *IgnoreMatcher
+	filter *FileFilter
+}
+
+// ScannerOption configures a Scanner.
+type ScannerOption func(*Scanner)
+
+// WithFileFilter replaces the default file filter.
+func WithFileFilter(filter *FileFilter) ScannerOption {
+	return func(s *Scanner) {
+		s.filter = filter
+	}
 }
 
-func NewScanner(root string, ignore *IgnoreMatcher) *Scanner {
-	return &Scanner{
+func NewScanner(root string, ignore *IgnoreMatcher, opts ...ScannerOption) *Scanner {
+	s := &Scanner{
 		root:   root,
 		ignore: ignore,
+		filter: DefaultFileFilter(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// checkFile returns why the file at path would be skipped before reading its
+// content, or "" if it should be read.
+func (s *Scanner) checkFile(path, relPath string, d fs.DirEntry) (fs.FileInfo, SkipReason, error) {
+	if reason := s.filter.CheckType(path, relPath); reason != "" {
+		return nil, reason, nil
+	}
+
+	if isMinifiedFile(relPath) {
+		return nil, SkipMinified, nil
+	}
+
+	info, err := d.Info()
+	if err != nil {
+		return nil, "", err
+	}
+	if info.Size() > s.filter.MaxSize(relPath) {
+		return info, SkipTooLarge, nil
+	}
+	return info, "", nil
+}
+
+// skippedDir returns the first parent directory of relPath that the walk
+// skips, or "" if it reaches relPath.
+func (s *Scanner) skippedDir(relPath string) string {
+	parts := strings.Split(filepath.ToSlash(relPath), "/")
+	for i := 1; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		if s.ignore.ShouldSkipDir(dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// Matches reports whether the scans would consider the file at relPath,
+// judging by the ignore rules and its type. ScanFile applies the remaining
+// checks.
+func (s *Scanner) Matches(relPath string) bool {
+	if s.skippedDir(relPath) != "" || s.ignore.ShouldIgnore(relPath) {
+		return false
 	}
+	return s.filter.CheckType(filepath.Join(s.root, relPath), relPath) == ""
+}
+
+// skippedEntry formats a skipped file for the lists returned by the scans.
+func skippedEntry(relPath string, reason SkipReason) string {
+	return relPath + " (" + string(reason) + ")"
 }
 
 // ScanMetadata scans indexable files and returns only file metadata.
 // It avoids reading file contents and hash computation for a faster first pass.
 func (s *Scanner) ScanMetadata() ([]FileMeta, []string, error) {
+	files, skipped, _, err := s.scanMetadata()
+	return files, skipped, err
+}
+
+// scanMetadata is ScanMetadata, also counting the files that are not indexed
+// by reason. Ignored files are not counted, and files are not read, so
+// binary files and invalid notebooks are not detected.
+func (s *Scanner) scanMetadata() ([]FileMeta, []string, map[SkipReason]int, error) {
 	var files []FileMeta
 	var skipped []string
+	counts := make(map[SkipReason]int)
 
 	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -152,26 +235,19 @@ func (s *Scanner) ScanMetadata() ([]FileMeta, []string, error) {
 			return nil
 		}
 
-		// Check extension
-		ext := strings.ToLower(filepath.Ext(path))
-		if !SupportedExtensions[ext] {
+		// Check type, minified patterns and size
+		info, reason, err := s.checkFile(path, relPath, d)
+		if err != nil {
 			return nil
 		}
-
-		// Skip minified files
-		if isMinifiedFile(relPath) {
-			skipped = append(skipped, relPath+" (minified)")
-			return nil
+		if reason != "" {
+			counts[reason]++
 		}
-
-		info, err := d.Info()
-		if err != nil {
+		if reason == SkipUnsupported || reason == SkipExcluded {
 			return nil
 		}
-
-		// Skip large files
-		if info.Size() > maxFileSize {
-			skipped = append(skipped, relPath+" (too large)")
+		if reason != "" {
+			skipped = append(skipped, skippedEntry(relPath, reason))
 			return nil
 		}
 
@@ -184,7 +260,7 @@ func (s *Scanner) ScanMetadata() ([]FileMeta, []string, error) {
 		return nil
 	})
 
-	return files, skipped, err
+	return files, skipped, counts, err
 }
 
 func (s *Scanner) Scan() ([]FileInfo, []string, error) {
@@ -214,26 +290,16 @@ func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 			return nil
 		}
 
-		// Check extension
-		ext := strings.ToLower(filepath.Ext(path))
-		if !SupportedExtensions[ext] {
-			return nil
-		}
-
-		// Skip minified files
-		if isMinifiedFile(relPath) {
-			skipped = append(skipped, relPath+" (minified)")
+		// Check type, minified patterns and size
+		info, reason, err := s.checkFile(path, relPath, d)
+		if err != nil {
 			return nil
 		}
-
-		info, err := d.Info()
-		if err != nil {
+		if reason == SkipUnsupported || reason == SkipExcluded {
 			return nil
 		}
-
-		// Skip large files
-		if info.Size() > maxFileSize {
-			skipped = append(skipped, relPath+" (too large)")
+		if reason != "" {
+			skipped = append(skipped, skippedEntry(relPath, reason))
 			return nil
 		}
 
@@ -248,6 +314,12 @@ func (s *Scanner) Scan() ([]FileInfo, []string, error) {
 			return nil
 		}
 
+		// Skip notebooks that aren't valid JSON
+		if store.IsNotebook(relPath) && !json.Valid(content) {
+			skipped = append(skipped, skippedEntry(relPath, SkipInvalidNotebook))
+			return nil
+		}
+
 		// Calculate hash
 		hash := sha256.Sum256(content)
 
@@ -278,7 +350,7 @@ func (s *Scanner) ScanFile(relPath string) (*FileInfo, error) {
 		return nil, err
 	}
 
-	if info.Size() > maxFileSize {
+	if info.Size() > s.filter.MaxSize(relPath) {
 		return nil, nil // Skip large files
 	}
 
@@ -291,6 +363,10 @@ func (s *Scanner) ScanFile(relPath string) (*FileInfo, error) {
 		return nil, nil // Skip binary files
 	}
 
+	if store.IsNotebook(relPath) && !json.Valid(content) {
+		return nil, nil // Skip notebooks that aren't valid JSON
+	}
+
 	hash := sha256.Sum256(content)
 
 	return &FileInfo{
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/alpkeskin/gotoon"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -37,8 +38,10 @@ type SearchResult struct {
 	FilePath    string  `json:"file_path"`
 	StartLine   int     `json:"start_line"`
 	EndLine     int     `json:"end_line"`
+	Cell        int     `json:"cell,omitempty"` // notebook cell number; start and end lines hold it too
 	Score       float32 `json:"score"`
 	Content     string  `json:"content"`
+	Section     string  `json:"section,omitempty"`
 	FeaturePath string  `json:"feature_path,omitempty"`
 	SymbolName  string  `json:"symbol_name,omitempty"`
 }
@@ -48,7 +51,29 @@ type SearchResultCompact struct {
 	FilePath    string  `json:"file_path"`
 	StartLine   int     `json:"start_line"`
 	EndLine     int     `json:"end_line"`
+	Cell        int     `json:"cell,omitempty"` // notebook cell number; start and end lines hold it too
 	Score       float32 `json:"score"`
+	Section     string  `json:"section,omitempty"`
+	FeaturePath string  `json:"feature_path,omitempty"`
+	SymbolName  string  `json:"symbol_name,omitempty"`
+}
+
+// SearchFileResult is one file of grepai_search output grouped by file.
+type SearchFileResult struct {
+	FilePath string          `json:"file_path"`
+	Score    float32         `json:"score"`
+	Snippets []SearchSnippet `json:"snippets"`
+}
+
+// SearchSnippet is one snippet of a file in grouped output. Content is left
+// out in compact mode.
+type SearchSnippet struct {
+	StartLine   int     `json:"start_line"`
+	EndLine     int     `json:"end_line"`
+	Cell        int     `json:"cell,omitempty"` // notebook cell number; start and end lines hold it too
+	Score       float32 `json:"score"`
+	Content     string  `json:"content,omitempty"`
+	Section     string  `json:"section,omitempty"`
 	FeaturePath string  `json:"feature_path,omitempty"`
 	SymbolName  string  `json:"symbol_name,omitempty"`
 }
@@ -160,6 +185,12 @@ func (s *Server) registerTools() {
 		mcp.WithBoolean("compact",
 			mcp.Description("Return minimal output without content (default: false)"),
 		),
+		mcp.WithBoolean("group_by_file",
+			mcp.Description("Return one entry per file with up to 3 of its best snippets; limit then counts files (default: false)"),
+		),
+		mcp.WithBoolean("diverse",
+			mcp.Description("Spread results across files and directories with maximal marginal relevance instead of returning near-duplicates (default: false, or search.diversity.enabled)"),
+		),
 		mcp.WithString("format",
 			mcp.Description("Output format: 'json' (default) or 'toon' (token-efficient)"),
 		),
@@ -172,6 +203,21 @@ func (s *Server) registerTools() {
 		mcp.WithString("projects",
 			mcp.Description("Comma-separated list of project names to search within workspace (requires workspace)"),
 		),
+		mcp.WithString("lang",
+			mcp.Description("Comma-separated languages to search (optional, e.g. 'go,python')"),
+		),
+		mcp.WithString("glob",
+			mcp.Description("Comma-separated path globs; only matching files are searched (optional, e.g. 'services/**'). Globs without a slash match file names in any directory."),
+		),
+		mcp.WithString("exclude",
+			mcp.Description("Comma-separated path globs to skip (optional, e.g. '*_test.go')"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only search files modified within this period or since a date (optional, e.g. '30d', '2w', '2024-06-01')"),
+		),
+		mcp.WithString("kind",
+			mcp.Description("Comma-separated symbol kinds the chunk must define (optional: function, method, class, interface, type)"),
+		),
 	)
 	s.mcpServer.AddTool(searchTool, s.handleSearch)
 
@@ -349,10 +395,13 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 	}
 
 	compact := request.GetBool("compact", false)
+	groupByFile := request.GetBool("group_by_file", false)
+	diverse := request.GetBool("diverse", false)
 	format := request.GetString("format", "json")
 	path := request.GetString("path", "")
 	workspace := request.GetString("workspace", "")
 	projects := request.GetString("projects", "")
+	filters := searchFiltersFromRequest(request)
 
 	// Auto-inject workspace when server is in workspace mode
 	if workspace == "" && s.workspaceName != "" {
@@ -366,7 +415,7 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 
 	// Workspace mode
 	if workspace != "" {
-		return s.handleWorkspaceSearch(ctx, query, limit, compact, format, path, workspace, projects)
+		return s.handleWorkspaceSearch(ctx, query, limit, compact, groupByFile, diverse, format, path, workspace, projects, filters)
 	}
 
 	// Load configuration
@@ -397,13 +446,41 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 	}
 	defer st.Close()
 
+	reranker, err := search.NewRerankerFromConfig(cfg)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to initialize reranker: %v", err)), nil
+	}
+
+	signals := search.NewProjectSignals(s.projectRoot)
+	defer signals.Close()
+
 	// Create searcher and search
-	searcher := search.NewSearcher(st, emb, cfg.Search)
+	searcherOpts := []search.SearcherOption{
+		search.WithReranker(reranker, cfg.Search.Rerank.TopN),
+		search.WithBoostSignals(signals),
+	}
+	if diverse {
+		searcherOpts = append(searcherOpts, search.WithDiversity(cfg.Search.Diversity.Lambda))
+	}
+	searcher := search.NewSearcher(st, emb, cfg.Search, searcherOpts...)
 	normalizedPath, err := search.NormalizeProjectPathPrefix(path, s.projectRoot)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("invalid path parameter: %v", err)), nil
 	}
-	results, err := searcher.Search(ctx, query, limit, normalizedPath)
+	opts, err := filters.Options(normalizedPath, time.Now())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid search filter: %v", err)), nil
+	}
+	var results []store.SearchResult
+	var groups []search.FileResult
+	if groupByFile {
+		groups, err = searcher.SearchByFile(ctx, query, limit, opts)
+		for _, g := range groups {
+			results = append(results, g.Snippets...)
+		}
+	} else {
+		results, err = searcher.SearchWithOptions(ctx, query, limit, opts)
+	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
@@ -436,14 +513,29 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 	}
 
 	var data any
-	if compact {
+	if groupByFile {
+		fileResults := fileSearchResults(groups, compact)
+		i := 0
+		for _, fr := range fileResults {
+			for j := range fr.Snippets {
+				if info, ok := rpgData[i]; ok {
+					fr.Snippets[j].FeaturePath = info.featurePath
+					fr.Snippets[j].SymbolName = info.symbolName
+				}
+				i++
+			}
+		}
+		data = fileResults
+	} else if compact {
 		searchResultsCompact := make([]SearchResultCompact, len(results))
 		for i, r := range results {
 			searchResultsCompact[i] = SearchResultCompact{
 				FilePath:  r.Chunk.FilePath,
 				StartLine: r.Chunk.StartLine,
 				EndLine:   r.Chunk.EndLine,
+				Cell:      r.Chunk.Cell(),
 				Score:     r.Score,
+				Section:   r.Chunk.SectionPath(),
 			}
 			if info, ok := rpgData[i]; ok {
 				searchResultsCompact[i].FeaturePath = info.featurePath
@@ -458,7 +550,9 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 				FilePath:  r.Chunk.FilePath,
 				StartLine: r.Chunk.StartLine,
 				EndLine:   r.Chunk.EndLine,
+				Cell:      r.Chunk.Cell(),
 				Score:     r.Score,
+				Section:   r.Chunk.SectionPath(),
 				Content:   r.Chunk.Content,
 			}
 			if info, ok := rpgData[i]; ok {
@@ -477,8 +571,25 @@ func (s *Server) handleSearch(ctx context.Context, request mcp.CallToolRequest)
 	return mcp.NewToolResultText(output), nil
 }
 
+// searchFiltersFromRequest reads the optional metadata filters of grepai_search.
+func searchFiltersFromRequest(request mcp.CallToolRequest) search.Filters {
+	list := func(name string) []string {
+		if value := request.GetString(name, ""); value != "" {
+			return []string{value}
+		}
+		return nil
+	}
+	return search.Filters{
+		Languages: list("lang"),
+		Include:   list("glob"),
+		Exclude:   list("exclude"),
+		Kinds:     list("kind"),
+		Since:     request.GetString("since", ""),
+	}
+}
+
 // handleWorkspaceSearch handles workspace-level search via MCP.
-func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit int, compact bool, format, pathPrefix, workspaceName, projectsStr string) (*mcp.CallToolResult, error) {
+func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit int, compact, groupByFile, diverse bool, format, pathPrefix, workspaceName, projectsStr string, filters search.Filters) (*mcp.CallToolResult, error) {
 	// Load workspace config
 	wsCfg, err := config.LoadWorkspaceConfig()
 	if err != nil {
@@ -530,7 +641,11 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 		Hybrid: config.HybridConfig{Enabled: false, K: 60},
 		Boost:  config.DefaultConfig().Search.Boost,
 	}
-	searcher := search.NewSearcher(st, emb, searchCfg)
+	var searcherOpts []search.SearcherOption
+	if diverse {
+		searcherOpts = append(searcherOpts, search.WithDiversity(0))
+	}
+	searcher := search.NewSearcher(st, emb, searchCfg, searcherOpts...)
 
 	// Construct full path prefix for database query. Database stores paths as:
 	// workspaceName/projectName/relativePath. When a single project is specified,
@@ -550,9 +665,18 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 		fullPathPrefix += normalizedPath
 	}
 
-	// Search
+	opts, err := filters.WithWorkspaceRoot(ws.Name).Options(fullPathPrefix, time.Now())
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid search filter: %v", err)), nil
+	}
+
+	// Search, fetching several snippets per file when grouping
+	searchLimit := limit
+	if groupByFile {
+		searchLimit = limit * search.DefaultSnippetsPerFile
+	}
 	var results []store.SearchResult
-	results, err = searcher.Search(ctx, query, limit, fullPathPrefix)
+	results, err = searcher.SearchWithOptions(ctx, query, searchLimit, opts)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
@@ -615,14 +739,22 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 	}
 
 	var data any
-	if compact {
+	if groupByFile {
+		groups := search.GroupByFile(results, search.DefaultSnippetsPerFile)
+		if len(groups) > limit {
+			groups = groups[:limit]
+		}
+		data = fileSearchResults(groups, compact)
+	} else if compact {
 		searchResultsCompact := make([]SearchResultCompact, len(results))
 		for i, r := range results {
 			searchResultsCompact[i] = SearchResultCompact{
 				FilePath:  r.Chunk.FilePath,
 				StartLine: r.Chunk.StartLine,
 				EndLine:   r.Chunk.EndLine,
+				Cell:      r.Chunk.Cell(),
 				Score:     r.Score,
+				Section:   r.Chunk.SectionPath(),
 			}
 		}
 		data = searchResultsCompact
@@ -633,7 +765,9 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 				FilePath:  r.Chunk.FilePath,
 				StartLine: r.Chunk.StartLine,
 				EndLine:   r.Chunk.EndLine,
+				Cell:      r.Chunk.Cell(),
 				Score:     r.Score,
+				Section:   r.Chunk.SectionPath(),
 				Content:   r.Chunk.Content,
 			}
 		}
@@ -648,6 +782,29 @@ func (s *Server) handleWorkspaceSearch(ctx context.Context, query string, limit
 	return mcp.NewToolResultText(output), nil
 }
 
+// fileSearchResults converts grouped results for output, leaving snippet
+// content out in compact mode.
+func fileSearchResults(groups []search.FileResult, compact bool) []SearchFileResult {
+	out := make([]SearchFileResult, len(groups))
+	for i, g := range groups {
+		snippets := make([]SearchSnippet, len(g.Snippets))
+		for j, r := range g.Snippets {
+			snippets[j] = SearchSnippet{
+				StartLine: r.Chunk.StartLine,
+				EndLine:   r.Chunk.EndLine,
+				Cell:      r.Chunk.Cell(),
+				Score:     r.Score,
+				Section:   r.Chunk.SectionPath(),
+			}
+			if !compact {
+				snippets[j].Content = r.Chunk.Content
+			}
+		}
+		out[i] = SearchFileResult{FilePath: g.FilePath, Score: g.Score, Snippets: snippets}
+	}
+	return out
+}
+
 func parseProjectNames(projectsStr string) []string {
 	if projectsStr == "" {
 		return nil
@@ -922,7 +1079,7 @@ func (s *Server) createWorkspaceStore(ctx context.Context, ws *config.Workspace)
 	}
 }
 
-// loadWorkspaceSymbolStores loads GOBSymbolStores for workspace projects.
+// loadWorkspaceSymbolStores loads the symbol stores of workspace projects.
 func (s *Server) loadWorkspaceSymbolStores(ctx context.Context, workspaceName, projectName string) ([]trace.SymbolStore, error) {
 	wsCfg, err := config.LoadWorkspaceConfig()
 	if err != nil {
@@ -954,6 +1111,20 @@ func (s *Server) loadWorkspaceSymbolStores(ctx context.Context, workspaceName, p
 		projects = ws.Projects
 	}
 
+	// Postgres workspaces share one symbol database: a single store spanning
+	// the selected projects answers every query in SQL.
+	if ws.Store.Backend == "postgres" {
+		projectIDs := make([]string, 0, len(projects))
+		for _, p := range projects {
+			projectIDs = append(projectIDs, ws.SymbolProjectID(p.Name))
+		}
+		ss, err := trace.NewPostgresSymbolStore(ctx, ws.Store.Postgres.DSN, projectIDs...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open workspace symbol store: %v", err)
+		}
+		return []trace.SymbolStore{ss}, nil
+	}
+
 	stores := make([]trace.SymbolStore, 0, len(projects))
 	for _, p := range projects {
 		ss := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(p.Path))
@@ -994,8 +1165,20 @@ func (s *Server) enrichTraceSymbols(ctx context.Context, symbols ...*trace.Symbo
 	if err != nil || !cfg.RPG.Enabled {
 		return
 	}
-	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(s.projectRoot))
-	if err := rpgStore.Load(ctx); err != nil {
+	rpgStore, err := rpg.NewStoreFromConfig(ctx, cfg, s.projectRoot)
+	if err != nil {
+		log.Printf("Warning: RPG enrichment unavailable for trace: %v", err)
+		return
+	}
+
+	files := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		if sym != nil && sym.File != "" {
+			files = append(files, sym.File)
+		}
+	}
+	if err := rpg.LoadFiles(ctx, rpgStore, files); err != nil {
+		rpg.Discard(rpgStore)
 		log.Printf("Warning: RPG enrichment unavailable for trace: %v", err)
 		return
 	}
@@ -1513,13 +1696,12 @@ func (s *Server) handleIndexStatus(ctx context.Context, request mcp.CallToolRequ
 				Name: p.Name,
 				Path: p.Path,
 			}
-			ss := trace.NewGOBSymbolStore(config.GetSymbolIndexPath(p.Path))
-			if loadErr := ss.Load(ctx); loadErr == nil {
-				if symbolStats, statsErr := ss.GetStats(ctx); statsErr == nil && symbolStats.TotalSymbols > 0 {
+			if stores, loadErr := s.loadWorkspaceSymbolStores(ctx, ws.Name, p.Name); loadErr == nil {
+				if symbolStats, statsErr := stores[0].GetStats(ctx); statsErr == nil && symbolStats.TotalSymbols > 0 {
 					ps.SymbolsReady = true
 					ps.TotalSymbols = symbolStats.TotalSymbols
 				}
-				ss.Close()
+				closeSymbolStores(stores)
 			}
 			wsStatus.Projects = append(wsStatus.Projects, ps)
 		}
@@ -1688,27 +1870,41 @@ func (s *Server) createEmbedder(cfg *config.Config) (embedder.Embedder, error) {
 	return embedder.NewFromConfig(cfg)
 }
 
-// createStore creates a vector store based on configuration.
+// createStore creates a vector store based on configuration and refuses
+// indexes built with a different embedder.
 func (s *Server) createStore(ctx context.Context, cfg *config.Config) (store.VectorStore, error) {
-	switch cfg.Store.Backend {
-	case "gob":
-		indexPath := config.GetIndexPath(s.projectRoot)
-		gobStore := store.NewGOBStore(indexPath)
-		if err := gobStore.Load(ctx); err != nil {
-			return nil, fmt.Errorf("failed to load index: %w", err)
-		}
-		return gobStore, nil
-	case "postgres":
-		return store.NewPostgresStore(ctx, cfg.Store.Postgres.DSN, s.projectRoot, cfg.Embedder.GetDimensions())
-	case "qdrant":
-		collectionName := cfg.Store.Qdrant.Collection
-		if collectionName == "" {
-			collectionName = store.SanitizeCollectionName(s.projectRoot)
-		}
-		return store.NewQdrantStore(ctx, cfg.Store.Qdrant.Endpoint, cfg.Store.Qdrant.Port, cfg.Store.Qdrant.UseTLS, collectionName, cfg.Store.Qdrant.APIKey, cfg.Embedder.GetDimensions())
-	default:
-		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Store.Backend)
+	st, err := store.NewFromConfig(ctx, cfg, s.projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.CheckManifest(ctx, st, store.NewManifest(cfg.Embedder, cfg.Chunking, "")); err != nil {
+		st.Close()
+		return nil, err
+	}
+	return st, nil
+}
+
+// CheckIndexManifest verifies at startup that the project index matches the
+// configured embedder. Only a mismatch is reported; other failures (e.g. an
+// unreachable database) surface later from the individual tools, as before.
+// It is a no-op in workspace-only mode.
+func (s *Server) CheckIndexManifest(ctx context.Context) error {
+	if s.projectRoot == "" {
+		return nil
+	}
+	cfg, err := config.Load(s.projectRoot)
+	if err != nil {
+		return nil
 	}
+	st, err := s.createStore(ctx, cfg)
+	var mismatch *store.ManifestMismatchError
+	if errors.As(err, &mismatch) {
+		return err
+	}
+	if err == nil {
+		st.Close()
+	}
+	return nil
 }
 
 // Serve starts the MCP server using stdio transport.
@@ -1803,8 +1999,12 @@ func (s *Server) tryLoadRPG(ctx context.Context) (rpg.RPGStore, *rpg.QueryEngine
 	if !cfg.RPG.Enabled {
 		return nil, nil, nil
 	}
-	rpgStore := rpg.NewGOBRPGStore(config.GetRPGIndexPath(s.projectRoot))
+	rpgStore, err := rpg.NewStoreFromConfig(ctx, cfg, s.projectRoot)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open RPG store: %w", err)
+	}
 	if err := rpgStore.Load(ctx); err != nil {
+		rpg.Discard(rpgStore)
 		if errors.Is(err, rpg.ErrRPGIndexOutdated) {
 			return nil, nil, rpg.ErrRPGIndexOutdated
 		}
@@ -19,6 +19,8 @@ type LLMExtractorConfig struct {
 	Endpoint string
 	APIKey   string
 	Timeout  time.Duration
+	// MaxTokens bounds the length of replies (default: 100).
+	MaxTokens int
 }
 
 // LLMExtractor generates feature labels using an LLM API.
@@ -34,6 +36,9 @@ func NewLLMExtractor(cfg LLMExtractorConfig) *LLMExtractor {
 	if cfg.Timeout <= 0 {
 		cfg.Timeout = 8 * time.Second
 	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = 100
+	}
 	return &LLMExtractor{
 		cfg:      cfg,
 		client:   &http.Client{Timeout: cfg.Timeout},
@@ -81,6 +86,14 @@ func (e *LLMExtractor) GenerateSummary(ctx context.Context, name, contextStr str
 	return e.callCompletion(ctx, systemPrompt, userPrompt)
 }
 
+// Complete sends a chat completion request to the configured endpoint and
+// returns the reply, for uses of the LLM other than feature extraction.
+func (e *LLMExtractor) Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.Timeout)
+	defer cancel()
+	return e.callCompletion(ctx, systemPrompt, userPrompt)
+}
+
 // callCompletion makes an OpenAI-compatible chat completion API call.
 func (e *LLMExtractor) callCompletion(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
 	// Build request body (OpenAI chat completion format)
@@ -90,7 +103,7 @@ func (e *LLMExtractor) callCompletion(ctx context.Context, systemPrompt, userPro
 			{"role": "system", "content": systemPrompt},
 			{"role": "user", "content": userPrompt},
 		},
-		"max_tokens":  100, // Increased for summaries
+		"max_tokens":  e.cfg.MaxTokens,
 		"temperature": 0,
 	}
 
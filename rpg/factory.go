@@ -0,0 +1,24 @@
+package rpg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// NewStoreFromConfig creates the RPGStore configured for the project. The
+// store is returned unloaded; call Load or LoadFiles before reading the graph.
+func NewStoreFromConfig(ctx context.Context, cfg *config.Config, projectRoot string) (RPGStore, error) {
+	switch cfg.RPG.Backend {
+	case "", "gob":
+		return NewGOBRPGStore(config.GetRPGIndexPath(projectRoot)), nil
+	case "postgres":
+		if cfg.Store.Postgres.DSN == "" {
+			return nil, fmt.Errorf("rpg.backend postgres requires store.postgres.dsn to be set")
+		}
+		return NewPostgresRPGStore(ctx, cfg.Store.Postgres.DSN, projectRoot)
+	default:
+		return nil, fmt.Errorf("unknown rpg backend: %s", cfg.RPG.Backend)
+	}
+}
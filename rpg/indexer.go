@@ -377,6 +377,15 @@ func (idx *RPGEncoder) Stats() GraphStats {
 	return idx.store.GetGraph().Stats()
 }
 
+// FeaturePathForFile returns the feature path of a file in the graph, or ""
+// if the file has none yet, in a concurrency-safe manner.
+func (idx *RPGEncoder) FeaturePathForFile(filePath string) string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	qe := NewQueryEngine(idx.store.GetGraph())
+	return qe.getFeaturePath(MakeNodeID(KindFile, filePath))
+}
+
 // GetEvolver returns the evolver for direct use.
 func (idx *RPGEncoder) GetEvolver() *Evolver {
 	return idx.evolver
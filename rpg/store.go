@@ -15,3 +15,46 @@ type RPGStore interface {
 	// GetStats returns graph statistics.
 	GetStats(ctx context.Context) (*GraphStats, error)
 }
+
+// FileLoader is implemented by stores that can load only the part of the
+// graph around a set of files, so readers that enrich a handful of results
+// don't have to read the whole graph.
+type FileLoader interface {
+	// LoadFiles reads the nodes of the given files, the edges touching them
+	// and the nodes at the other end of those edges.
+	LoadFiles(ctx context.Context, paths []string) error
+}
+
+// LoadFiles loads at least the part of the graph around the given files,
+// falling back to a full Load for stores that don't implement FileLoader.
+func LoadFiles(ctx context.Context, s RPGStore, paths []string) error {
+	if loader, ok := s.(FileLoader); ok {
+		return loader.LoadFiles(ctx, paths)
+	}
+	return s.Load(ctx)
+}
+
+// Discard releases the resources held by a store whose graph must not be
+// persisted, e.g. after a failed Load. Close can't be used there since the
+// GOB store persists on Close and would overwrite the index with an empty
+// graph.
+func Discard(s RPGStore) {
+	if pg, ok := s.(*PostgresRPGStore); ok {
+		_ = pg.Close()
+	}
+}
+
+// CopyGraph replaces the graph of dst with the one loaded in src and
+// persists it. Both stores must be fully loaded, since dst persists the
+// difference against the graph it loaded.
+func CopyGraph(ctx context.Context, dst, src RPGStore) error {
+	from := src.GetGraph()
+	to := dst.GetGraph()
+	to.Nodes = make(map[string]*Node, len(from.Nodes))
+	for id, n := range from.Nodes {
+		to.Nodes[id] = n
+	}
+	to.Edges = append(make([]*Edge, 0, len(from.Edges)), from.Edges...)
+	to.RebuildIndexes()
+	return dst.Persist(ctx)
+}
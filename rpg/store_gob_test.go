@@ -492,3 +492,36 @@ func TestGOBRPGStore_LoadLegacyEmptyVersionlessFile(t *testing.T) {
 		t.Fatalf("expected empty graph edges, got %d", len(store.GetGraph().Edges))
 	}
 }
+
+func TestCopyGraph(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	src := NewGOBRPGStore(filepath.Join(tmpDir, "src.gob"))
+	src.GetGraph().AddNode(&Node{ID: "file:a.go", Kind: KindFile, Path: "a.go"})
+	src.GetGraph().AddNode(&Node{ID: "sym:a.go:A", Kind: KindSymbol, Path: "a.go", SymbolName: "A"})
+	src.GetGraph().AddEdge(&Edge{From: "file:a.go", To: "sym:a.go:A", Type: EdgeContains})
+
+	dstPath := filepath.Join(tmpDir, "dst.gob")
+	dst := NewGOBRPGStore(dstPath)
+	dst.GetGraph().AddNode(&Node{ID: "file:stale.go", Kind: KindFile, Path: "stale.go"})
+
+	if err := CopyGraph(ctx, dst, src); err != nil {
+		t.Fatalf("CopyGraph failed: %v", err)
+	}
+
+	loaded := NewGOBRPGStore(dstPath)
+	if err := loaded.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	g := loaded.GetGraph()
+	if len(g.Nodes) != 2 || g.GetNode("file:stale.go") != nil {
+		t.Fatalf("expected the copied nodes only, got %v", g.Nodes)
+	}
+	if got := g.GetOutgoing("file:a.go"); len(got) != 1 || got[0].To != "sym:a.go:A" {
+		t.Fatalf("expected the copied edge, got %v", got)
+	}
+	if nodes := g.GetNodesByFile("a.go"); len(nodes) != 2 {
+		t.Fatalf("expected indexes to be rebuilt, got %d nodes for a.go", len(nodes))
+	}
+}
@@ -0,0 +1,636 @@
+package rpg
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRPGStore implements RPGStore on top of PostgreSQL. Nodes and edges
+// are stored as rows keyed by project ID, so Persist only writes the rows that
+// changed since the graph was loaded or last persisted, and readers can load
+// the part of the graph they need with LoadFiles.
+type PostgresRPGStore struct {
+	pool      *pgxpool.Pool
+	projectID string
+	graph     *Graph
+	mu        sync.RWMutex
+
+	// Fingerprints of the rows as last loaded or written. Persist compares
+	// the in-memory graph against them to find what changed.
+	nodeSums map[string]uint64
+	edgeSums map[edgeRowKey]uint64
+
+	// loaded is set once the full graph has been read. partial is set when
+	// only a subgraph was read; such a store is read-only, since the rows
+	// it did not load would otherwise look deleted.
+	loaded  bool
+	partial bool
+
+	// reset is set when the stored graph has an outdated version, so the
+	// next Persist replaces it instead of diffing against it.
+	reset bool
+}
+
+// edgeRowKey identifies an edge row. Seq numbers edges sharing the same
+// endpoints and type, in graph order, so duplicate edges round-trip.
+type edgeRowKey struct {
+	From string
+	To   string
+	Type EdgeType
+	Seq  int
+}
+
+type edgeRow struct {
+	key  edgeRowKey
+	edge *Edge
+}
+
+// rpgDelta holds the rows Persist must write or delete, and the fingerprints
+// of the graph once they are applied.
+type rpgDelta struct {
+	upsertNodes []*Node
+	deleteNodes []string
+	upsertEdges []edgeRow
+	deleteEdges []edgeRowKey
+
+	nodeSums map[string]uint64
+	edgeSums map[edgeRowKey]uint64
+}
+
+func (d *rpgDelta) empty() bool {
+	return len(d.upsertNodes) == 0 && len(d.deleteNodes) == 0 &&
+		len(d.upsertEdges) == 0 && len(d.deleteEdges) == 0
+}
+
+// NewPostgresRPGStore connects to the database at dsn and ensures the RPG
+// tables exist. The graph is empty until Load or LoadFiles is called.
+func NewPostgresRPGStore(ctx context.Context, dsn string, projectID string) (*PostgresRPGStore, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("postgres rpg store requires a project ID")
+	}
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	s := &PostgresRPGStore{
+		pool:      pool,
+		projectID: projectID,
+		graph:     NewGraph(),
+		nodeSums:  make(map[string]uint64),
+		edgeSums:  make(map[edgeRowKey]uint64),
+	}
+	if err := s.ensureSchema(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *PostgresRPGStore) ensureSchema(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS rpg_meta (
+			project_id TEXT PRIMARY KEY,
+			version INTEGER NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS rpg_nodes (
+			project_id TEXT NOT NULL,
+			id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			feature TEXT NOT NULL,
+			features TEXT[] NOT NULL,
+			path TEXT NOT NULL,
+			symbol_name TEXT NOT NULL,
+			receiver TEXT NOT NULL,
+			language TEXT NOT NULL,
+			start_line INTEGER NOT NULL,
+			end_line INTEGER NOT NULL,
+			signature TEXT NOT NULL,
+			chunk_id TEXT NOT NULL,
+			semantic_label TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (project_id, id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_rpg_nodes_path ON rpg_nodes(project_id, path)`,
+		`CREATE INDEX IF NOT EXISTS idx_rpg_nodes_kind ON rpg_nodes(project_id, kind)`,
+		`CREATE INDEX IF NOT EXISTS idx_rpg_nodes_symbol ON rpg_nodes(project_id, symbol_name)`,
+		`CREATE TABLE IF NOT EXISTS rpg_edges (
+			project_id TEXT NOT NULL,
+			from_id TEXT NOT NULL,
+			to_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			seq INTEGER NOT NULL,
+			weight DOUBLE PRECISION NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (project_id, from_id, to_id, type, seq)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_rpg_edges_to ON rpg_edges(project_id, to_id)`,
+	}
+
+	for _, q := range queries {
+		if _, err := s.pool.Exec(ctx, q); err != nil {
+			return fmt.Errorf("failed to create rpg schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkVersion reports whether the stored graph can be read. An outdated
+// graph marks the store for reset and yields ErrRPGIndexOutdated if it has
+// any rows.
+func (s *PostgresRPGStore) checkVersion(ctx context.Context) error {
+	var version int
+	err := s.pool.QueryRow(ctx, `SELECT version FROM rpg_meta WHERE project_id = $1`, s.projectID).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read rpg version: %w", err)
+	}
+	if version == CurrentRPGIndexVersion {
+		return nil
+	}
+
+	s.reset = true
+	var hasData bool
+	err = s.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM rpg_nodes WHERE project_id = $1)`, s.projectID).Scan(&hasData)
+	if err != nil {
+		return fmt.Errorf("failed to read rpg nodes: %w", err)
+	}
+	if hasData {
+		return ErrRPGIndexOutdated
+	}
+	return nil
+}
+
+// Load reads the whole graph from the database.
+func (s *PostgresRPGStore) Load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setGraph(nil, nil)
+	s.loaded = false
+	s.partial = false
+	if err := s.checkVersion(ctx); err != nil {
+		return err
+	}
+
+	nodes, err := s.queryNodes(ctx, `WHERE project_id = $1`, s.projectID)
+	if err != nil {
+		return err
+	}
+	edges, err := s.queryEdges(ctx, `WHERE project_id = $1`, s.projectID)
+	if err != nil {
+		return err
+	}
+
+	s.setGraph(nodes, edges)
+	s.loaded = true
+	return nil
+}
+
+// LoadFiles reads the nodes of the given files, every edge touching them and
+// the nodes at the other end of those edges. This is enough to resolve the
+// feature path of the files and their symbols without loading the whole
+// graph. A store loaded this way is read-only.
+func (s *PostgresRPGStore) LoadFiles(ctx context.Context, paths []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setGraph(nil, nil)
+	s.loaded = false
+	s.partial = true
+	if err := s.checkVersion(ctx); err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	nodes, err := s.queryNodes(ctx, `WHERE project_id = $1 AND path = ANY($2)`, s.projectID, paths)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		ids = append(ids, n.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	edges, err := s.queryEdges(ctx, `WHERE project_id = $1 AND (from_id = ANY($2) OR to_id = ANY($2))`, s.projectID, ids)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		known[n.ID] = true
+	}
+	var missing []string
+	for _, e := range edges {
+		for _, id := range []string{e.From, e.To} {
+			if !known[id] {
+				known[id] = true
+				missing = append(missing, id)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		neighbors, err := s.queryNodes(ctx, `WHERE project_id = $1 AND id = ANY($2)`, s.projectID, missing)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, neighbors...)
+	}
+
+	s.setGraph(nodes, edges)
+	return nil
+}
+
+// setGraph replaces the in-memory graph contents and records the
+// fingerprints of the rows it was built from.
+func (s *PostgresRPGStore) setGraph(nodes []*Node, edges []*Edge) {
+	s.graph.Nodes = make(map[string]*Node, len(nodes))
+	for _, n := range nodes {
+		s.graph.Nodes[n.ID] = n
+	}
+	s.graph.Edges = make([]*Edge, 0, len(edges))
+	s.graph.Edges = append(s.graph.Edges, edges...)
+	s.graph.RebuildIndexes()
+
+	delta := diffGraph(s.graph, nil, nil)
+	s.nodeSums = delta.nodeSums
+	s.edgeSums = delta.edgeSums
+}
+
+const rpgNodeColumns = `id, kind, feature, features, path, symbol_name, receiver, language,
+	start_line, end_line, signature, chunk_id, semantic_label, summary, updated_at`
+
+func (s *PostgresRPGStore) queryNodes(ctx context.Context, where string, args ...any) ([]*Node, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+rpgNodeColumns+` FROM rpg_nodes `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rpg nodes: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*Node
+	for rows.Next() {
+		var n Node
+		var kind string
+		if err := rows.Scan(&n.ID, &kind, &n.Feature, &n.Features, &n.Path, &n.SymbolName, &n.Receiver, &n.Language,
+			&n.StartLine, &n.EndLine, &n.Signature, &n.ChunkID, &n.SemanticLabel, &n.Summary, &n.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rpg node: %w", err)
+		}
+		n.Kind = NodeKind(kind)
+		if len(n.Features) == 0 {
+			n.Features = nil
+		}
+		nodes = append(nodes, &n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rpg nodes: %w", err)
+	}
+	return nodes, nil
+}
+
+// queryEdges returns edges in key order, so duplicate edges keep the
+// sequence numbers Persist assigned them.
+func (s *PostgresRPGStore) queryEdges(ctx context.Context, where string, args ...any) ([]*Edge, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT from_id, to_id, type, weight, updated_at FROM rpg_edges `+where+`
+		ORDER BY from_id, to_id, type, seq`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rpg edges: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []*Edge
+	for rows.Next() {
+		var e Edge
+		var edgeType string
+		if err := rows.Scan(&e.From, &e.To, &edgeType, &e.Weight, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rpg edge: %w", err)
+		}
+		e.Type = EdgeType(edgeType)
+		edges = append(edges, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rpg edges: %w", err)
+	}
+	return edges, nil
+}
+
+// Persist writes the nodes and edges that changed since the last Load or
+// Persist in a single transaction.
+func (s *PostgresRPGStore) Persist(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.persistUnlocked(ctx)
+}
+
+func (s *PostgresRPGStore) persistUnlocked(ctx context.Context) error {
+	if s.partial {
+		return fmt.Errorf("rpg store was loaded for a subset of files and is read-only")
+	}
+
+	delta := diffGraph(s.graph, s.nodeSums, s.edgeSums)
+	if delta.empty() && !s.reset {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	batch := &pgx.Batch{}
+	if s.reset {
+		batch.Queue(`DELETE FROM rpg_edges WHERE project_id = $1`, s.projectID)
+		batch.Queue(`DELETE FROM rpg_nodes WHERE project_id = $1`, s.projectID)
+	}
+	if len(delta.deleteEdges) > 0 {
+		from := make([]string, len(delta.deleteEdges))
+		to := make([]string, len(delta.deleteEdges))
+		types := make([]string, len(delta.deleteEdges))
+		seqs := make([]int32, len(delta.deleteEdges))
+		for i, k := range delta.deleteEdges {
+			from[i], to[i], types[i], seqs[i] = k.From, k.To, string(k.Type), int32(k.Seq) // #nosec G115 -- edge ordinals are small
+		}
+		batch.Queue(
+			`DELETE FROM rpg_edges e
+			USING unnest($2::text[], $3::text[], $4::text[], $5::int[]) AS d(from_id, to_id, type, seq)
+			WHERE e.project_id = $1 AND e.from_id = d.from_id AND e.to_id = d.to_id
+				AND e.type = d.type AND e.seq = d.seq`,
+			s.projectID, from, to, types, seqs)
+	}
+	if len(delta.deleteNodes) > 0 {
+		batch.Queue(`DELETE FROM rpg_nodes WHERE project_id = $1 AND id = ANY($2)`, s.projectID, delta.deleteNodes)
+	}
+	for _, n := range delta.upsertNodes {
+		features := n.Features
+		if features == nil {
+			features = []string{}
+		}
+		batch.Queue(
+			`INSERT INTO rpg_nodes (project_id, `+rpgNodeColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+			ON CONFLICT (project_id, id) DO UPDATE SET
+				kind = EXCLUDED.kind, feature = EXCLUDED.feature, features = EXCLUDED.features,
+				path = EXCLUDED.path, symbol_name = EXCLUDED.symbol_name, receiver = EXCLUDED.receiver,
+				language = EXCLUDED.language, start_line = EXCLUDED.start_line, end_line = EXCLUDED.end_line,
+				signature = EXCLUDED.signature, chunk_id = EXCLUDED.chunk_id,
+				semantic_label = EXCLUDED.semantic_label, summary = EXCLUDED.summary,
+				updated_at = EXCLUDED.updated_at`,
+			s.projectID, n.ID, string(n.Kind), n.Feature, features, n.Path, n.SymbolName, n.Receiver, n.Language,
+			n.StartLine, n.EndLine, n.Signature, n.ChunkID, n.SemanticLabel, n.Summary, n.UpdatedAt)
+	}
+	for _, r := range delta.upsertEdges {
+		batch.Queue(
+			`INSERT INTO rpg_edges (project_id, from_id, to_id, type, seq, weight, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (project_id, from_id, to_id, type, seq) DO UPDATE SET
+				weight = EXCLUDED.weight, updated_at = EXCLUDED.updated_at`,
+			s.projectID, r.key.From, r.key.To, string(r.key.Type), r.key.Seq, r.edge.Weight, r.edge.UpdatedAt)
+	}
+	batch.Queue(
+		`INSERT INTO rpg_meta (project_id, version) VALUES ($1, $2)
+		ON CONFLICT (project_id) DO UPDATE SET version = EXCLUDED.version`,
+		s.projectID, CurrentRPGIndexVersion)
+
+	results := tx.SendBatch(ctx, batch)
+	for i := 0; i < batch.Len(); i++ {
+		if _, err := results.Exec(); err != nil {
+			_ = results.Close()
+			return fmt.Errorf("failed to persist rpg graph: %w", err)
+		}
+	}
+	if err := results.Close(); err != nil {
+		return fmt.Errorf("failed to persist rpg graph: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit rpg graph: %w", err)
+	}
+
+	s.nodeSums = delta.nodeSums
+	s.edgeSums = delta.edgeSums
+	s.reset = false
+	return nil
+}
+
+// Close persists pending changes of a fully loaded graph and closes the
+// connection pool.
+func (s *PostgresRPGStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if s.loaded {
+		err = s.persistUnlocked(context.Background())
+	}
+	s.pool.Close()
+	return err
+}
+
+// GetGraph returns the in-memory graph.
+func (s *PostgresRPGStore) GetGraph() *Graph {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.graph
+}
+
+// GetStats returns graph statistics. When the full graph is not loaded they
+// are computed in the database.
+func (s *PostgresRPGStore) GetStats(ctx context.Context) (*GraphStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.loaded {
+		stats := s.graph.Stats()
+		return &stats, nil
+	}
+
+	stats := GraphStats{
+		NodesByKind: make(map[NodeKind]int),
+		EdgesByType: make(map[EdgeType]int),
+	}
+
+	rows, err := s.pool.Query(ctx, `SELECT kind, COUNT(*) FROM rpg_nodes WHERE project_id = $1 GROUP BY kind`, s.projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rpg stats: %w", err)
+	}
+	for rows.Next() {
+		var kind string
+		var count int
+		if err := rows.Scan(&kind, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan rpg stats: %w", err)
+		}
+		stats.NodesByKind[NodeKind(kind)] = count
+		stats.TotalNodes += count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get rpg stats: %w", err)
+	}
+
+	rows, err = s.pool.Query(ctx, `SELECT type, COUNT(*) FROM rpg_edges WHERE project_id = $1 GROUP BY type`, s.projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rpg stats: %w", err)
+	}
+	for rows.Next() {
+		var edgeType string
+		var count int
+		if err := rows.Scan(&edgeType, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan rpg stats: %w", err)
+		}
+		stats.EdgesByType[EdgeType(edgeType)] = count
+		stats.TotalEdges += count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get rpg stats: %w", err)
+	}
+
+	var lastUpdated *time.Time
+	err = s.pool.QueryRow(ctx,
+		`SELECT GREATEST(
+			(SELECT MAX(updated_at) FROM rpg_nodes WHERE project_id = $1),
+			(SELECT MAX(updated_at) FROM rpg_edges WHERE project_id = $1))`,
+		s.projectID,
+	).Scan(&lastUpdated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rpg stats: %w", err)
+	}
+	if lastUpdated != nil {
+		stats.LastUpdated = *lastUpdated
+	}
+	return &stats, nil
+}
+
+// diffGraph compares the graph against previously recorded fingerprints and
+// returns the rows to write and delete. Nil fingerprint maps mean nothing is
+// stored yet.
+func diffGraph(g *Graph, nodeSums map[string]uint64, edgeSums map[edgeRowKey]uint64) *rpgDelta {
+	delta := &rpgDelta{
+		nodeSums: make(map[string]uint64, len(g.Nodes)),
+		edgeSums: make(map[edgeRowKey]uint64, len(g.Edges)),
+	}
+
+	for id, n := range g.Nodes {
+		sum := nodeFingerprint(n)
+		delta.nodeSums[id] = sum
+		if prev, ok := nodeSums[id]; !ok || prev != sum {
+			delta.upsertNodes = append(delta.upsertNodes, n)
+		}
+	}
+	for id := range nodeSums {
+		if _, ok := delta.nodeSums[id]; !ok {
+			delta.deleteNodes = append(delta.deleteNodes, id)
+		}
+	}
+
+	seen := make(map[edgeRowKey]int)
+	for _, e := range g.Edges {
+		base := edgeRowKey{From: e.From, To: e.To, Type: e.Type}
+		key := base
+		key.Seq = seen[base]
+		seen[base]++
+
+		sum := edgeFingerprint(e)
+		delta.edgeSums[key] = sum
+		if prev, ok := edgeSums[key]; !ok || prev != sum {
+			delta.upsertEdges = append(delta.upsertEdges, edgeRow{key: key, edge: e})
+		}
+	}
+	for key := range edgeSums {
+		if _, ok := delta.edgeSums[key]; !ok {
+			delta.deleteEdges = append(delta.deleteEdges, key)
+		}
+	}
+
+	sort.Strings(delta.deleteNodes)
+	sort.Slice(delta.deleteEdges, func(i, j int) bool {
+		a, b := delta.deleteEdges[i], delta.deleteEdges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Seq < b.Seq
+	})
+	return delta
+}
+
+// fingerprintWriter hashes length-prefixed fields so adjacent values cannot
+// collide by shifting bytes between them.
+type fingerprintWriter struct {
+	buf []byte
+}
+
+func (w *fingerprintWriter) str(v string) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+func (w *fingerprintWriter) int(v int64) {
+	w.buf = binary.AppendVarint(w.buf, v)
+}
+
+func (w *fingerprintWriter) sum() uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(w.buf)
+	return h.Sum64()
+}
+
+func nodeFingerprint(n *Node) uint64 {
+	var w fingerprintWriter
+	w.str(n.ID)
+	w.str(string(n.Kind))
+	w.str(n.Feature)
+	w.int(int64(len(n.Features)))
+	for _, f := range n.Features {
+		w.str(f)
+	}
+	w.str(n.Path)
+	w.str(n.SymbolName)
+	w.str(n.Receiver)
+	w.str(n.Language)
+	w.int(int64(n.StartLine))
+	w.int(int64(n.EndLine))
+	w.str(n.Signature)
+	w.str(n.ChunkID)
+	w.str(n.SemanticLabel)
+	w.str(n.Summary)
+	w.int(n.UpdatedAt.UnixMicro())
+	return w.sum()
+}
+
+func edgeFingerprint(e *Edge) uint64 {
+	var w fingerprintWriter
+	w.int(int64(math.Float64bits(e.Weight)))
+	w.int(e.UpdatedAt.UnixMicro())
+	return w.sum()
+}
@@ -0,0 +1,137 @@
+package rpg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// These tests don't require a real database connection.
+
+func newDiffTestGraph() *Graph {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	g := NewGraph()
+	g.AddNode(&Node{ID: "file:a.go", Kind: KindFile, Path: "a.go", UpdatedAt: now})
+	g.AddNode(&Node{ID: "sym:a.go:Run", Kind: KindSymbol, Path: "a.go", SymbolName: "Run", UpdatedAt: now})
+	g.AddEdge(&Edge{From: "file:a.go", To: "sym:a.go:Run", Type: EdgeContains, Weight: 1, UpdatedAt: now})
+	return g
+}
+
+func TestDiffGraph_WritesOnlyChanges(t *testing.T) {
+	g := newDiffTestGraph()
+
+	initial := diffGraph(g, nil, nil)
+	if len(initial.upsertNodes) != 2 || len(initial.upsertEdges) != 1 {
+		t.Fatalf("initial diff: got %d nodes, %d edges; want 2, 1", len(initial.upsertNodes), len(initial.upsertEdges))
+	}
+
+	unchanged := diffGraph(g, initial.nodeSums, initial.edgeSums)
+	if !unchanged.empty() {
+		t.Fatalf("unchanged graph should produce an empty diff, got %+v", unchanged)
+	}
+
+	g.GetNode("sym:a.go:Run").Summary = "runs the command"
+	g.AddNode(&Node{ID: "file:b.go", Kind: KindFile, Path: "b.go"})
+	g.RemoveEdgesBetween("file:a.go", "sym:a.go:Run")
+
+	changed := diffGraph(g, initial.nodeSums, initial.edgeSums)
+	if len(changed.upsertNodes) != 2 {
+		t.Errorf("expected 2 node upserts, got %d", len(changed.upsertNodes))
+	}
+	for _, n := range changed.upsertNodes {
+		if n.ID == "file:a.go" {
+			t.Error("unchanged node file:a.go should not be written")
+		}
+	}
+	if len(changed.deleteEdges) != 1 || changed.deleteEdges[0].From != "file:a.go" {
+		t.Errorf("expected the contains edge to be deleted, got %+v", changed.deleteEdges)
+	}
+
+	g.RemoveNode("file:b.go")
+	removed := diffGraph(g, changed.nodeSums, changed.edgeSums)
+	if len(removed.deleteNodes) != 1 || removed.deleteNodes[0] != "file:b.go" {
+		t.Errorf("expected file:b.go to be deleted, got %v", removed.deleteNodes)
+	}
+}
+
+func TestDiffGraph_DuplicateEdgesGetDistinctKeys(t *testing.T) {
+	g := NewGraph()
+	g.AddEdge(&Edge{From: "a", To: "b", Type: EdgeInvokes})
+	g.AddEdge(&Edge{From: "a", To: "b", Type: EdgeInvokes})
+
+	delta := diffGraph(g, nil, nil)
+	if len(delta.upsertEdges) != 2 {
+		t.Fatalf("expected 2 edge upserts, got %d", len(delta.upsertEdges))
+	}
+	if delta.upsertEdges[0].key.Seq == delta.upsertEdges[1].key.Seq {
+		t.Errorf("duplicate edges share seq %d", delta.upsertEdges[0].key.Seq)
+	}
+}
+
+func TestNodeFingerprint_IgnoresSubMicrosecondTime(t *testing.T) {
+	// PostgreSQL keeps microseconds, so a node read back must match the one
+	// that was written.
+	base := time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	written := &Node{ID: "n", Kind: KindFile, UpdatedAt: base.Add(789 * time.Nanosecond)}
+	read := &Node{ID: "n", Kind: KindFile, UpdatedAt: base}
+	if nodeFingerprint(written) != nodeFingerprint(read) {
+		t.Error("fingerprints differ below microsecond precision")
+	}
+
+	read.Features = []string{"a", "b"}
+	shifted := &Node{ID: "n", Kind: KindFile, UpdatedAt: base, Features: []string{"ab"}}
+	if nodeFingerprint(read) == nodeFingerprint(shifted) {
+		t.Error("fingerprint should distinguish feature boundaries")
+	}
+}
+
+func TestPostgresRPGStore_PartialLoadIsReadOnly(t *testing.T) {
+	s := &PostgresRPGStore{projectID: "/repo", graph: NewGraph(), partial: true}
+	err := s.Persist(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+}
+
+func TestNewStoreFromConfig(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+
+	cfg := config.DefaultConfig()
+	st, err := NewStoreFromConfig(ctx, cfg, root)
+	if err != nil {
+		t.Fatalf("default backend: %v", err)
+	}
+	if _, ok := st.(*GOBRPGStore); !ok {
+		t.Errorf("default backend should be GOB, got %T", st)
+	}
+
+	cfg.RPG.Backend = "postgres"
+	cfg.Store.Postgres.DSN = ""
+	if _, err := NewStoreFromConfig(ctx, cfg, root); err == nil || !strings.Contains(err.Error(), "store.postgres.dsn") {
+		t.Errorf("expected missing DSN error, got %v", err)
+	}
+
+	cfg.RPG.Backend = "bolt"
+	if _, err := NewStoreFromConfig(ctx, cfg, root); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestDiscard_DoesNotPersistGOB(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "rpg.gob")
+	st := NewGOBRPGStore(indexPath)
+	if err := LoadFiles(context.Background(), st, []string{"a.go"}); err != nil {
+		t.Fatalf("LoadFiles: %v", err)
+	}
+
+	Discard(st)
+	if _, err := os.Stat(indexPath); !os.IsNotExist(err) {
+		t.Errorf("Discard should not write the GOB index, stat err = %v", err)
+	}
+}
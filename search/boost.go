@@ -1,55 +1,272 @@
 package search
 
 import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/store"
 )
 
+// BoostSignals supplies the facts about a result that boost rules test
+// beyond its path and chunk. A rule needing a fact the signals cannot
+// provide does not match.
+type BoostSignals interface {
+	// FileSize returns the size in bytes of the file at path.
+	FileSize(path string) (int64, bool)
+
+	// LastModified returns when each of paths last changed, leaving out
+	// those unchanged since the given time.
+	LastModified(ctx context.Context, paths []string, since time.Time) (map[string]time.Time, error)
+
+	// SymbolKinds returns the kinds of the symbols defined in the file at
+	// path between startLine and endLine.
+	SymbolKinds(ctx context.Context, path string, startLine, endLine int) ([]string, bool)
+}
+
+// BoostMatch is a boost rule that matched a result.
+type BoostMatch struct {
+	Rule    config.BoostRule
+	Penalty bool
+}
+
+// String describes the rule and its factor, e.g.
+// `penalty glob "*.md" ×0.6`.
+func (m BoostMatch) String() string {
+	parts := []string{"bonus"}
+	if m.Penalty {
+		parts[0] = "penalty"
+	}
+
+	rule := m.Rule
+	if rule.Pattern != "" {
+		match := rule.Match
+		if match == "" {
+			match = config.BoostMatchSubstring
+		}
+		parts = append(parts, fmt.Sprintf("%s %q", match, rule.Pattern))
+	}
+	if rule.Language != "" {
+		parts = append(parts, "language="+rule.Language)
+	}
+	if rule.Kind != "" {
+		parts = append(parts, "kind="+rule.Kind)
+	}
+	if rule.MinSize > 0 {
+		parts = append(parts, fmt.Sprintf("min_size=%d", rule.MinSize))
+	}
+	if rule.MaxSize > 0 {
+		parts = append(parts, fmt.Sprintf("max_size=%d", rule.MaxSize))
+	}
+	if rule.ModifiedWithin != "" {
+		parts = append(parts, "modified_within="+rule.ModifiedWithin)
+	}
+	return strings.Join(parts, " ") + fmt.Sprintf(" ×%g", rule.Factor)
+}
+
+// Booster applies the rules of a config.BoostConfig to search results.
+type Booster struct {
+	enabled bool
+	rules   []boostRule
+	signals BoostSignals
+	now     func() time.Time
+}
+
+// boostRule is a config.BoostRule prepared for matching.
+type boostRule struct {
+	config.BoostRule
+	penalty  bool
+	pattern  *regexp.Regexp // glob and regex patterns
+	language string         // normalized language
+	window   time.Duration
+}
+
+// NewBooster prepares the rules of cfg. Signals may be nil, in which case
+// size and recency rules never match and kind rules only see the symbol
+// kinds recorded on chunks. Invalid rules are skipped with a warning.
+func NewBooster(cfg config.BoostConfig, signals BoostSignals) *Booster {
+	b := &Booster{enabled: cfg.Enabled, signals: signals, now: time.Now}
+	if !cfg.Enabled {
+		return b
+	}
+	add := func(rules []config.BoostRule, penalty bool) {
+		for _, rule := range rules {
+			prepared, err := prepareBoostRule(rule, penalty)
+			if err != nil {
+				log.Printf("Warning: ignoring boost rule %s: %v", BoostMatch{Rule: rule, Penalty: penalty}, err)
+				continue
+			}
+			b.rules = append(b.rules, prepared)
+		}
+	}
+	add(cfg.Penalties, true)
+	add(cfg.Bonuses, false)
+	return b
+}
+
+func prepareBoostRule(rule config.BoostRule, penalty bool) (boostRule, error) {
+	prepared := boostRule{BoostRule: rule, penalty: penalty}
+	var err error
+	switch rule.Match {
+	case "", config.BoostMatchSubstring:
+	case config.BoostMatchGlob:
+		prepared.pattern, err = store.CompileGlob(rule.Pattern)
+	case config.BoostMatchRegex:
+		prepared.pattern, err = regexp.Compile(rule.Pattern)
+	default:
+		err = fmt.Errorf("unknown match type %q", rule.Match)
+	}
+	if err != nil {
+		return boostRule{}, err
+	}
+	if rule.Language != "" {
+		if prepared.language, err = store.NormalizeLanguage(rule.Language); err != nil {
+			return boostRule{}, err
+		}
+	}
+	prepared.Kind = strings.ToLower(rule.Kind)
+	if prepared.window, err = rule.RecencyWindow(); err != nil {
+		return boostRule{}, err
+	}
+	return prepared, nil
+}
+
 // ApplyBoost applies structural boosting to search results based on file path patterns.
 // Penalties reduce scores (factor < 1), bonuses increase scores (factor > 1).
 // Results are re-sorted by adjusted score after boosting.
 func ApplyBoost(results []store.SearchResult, boostCfg config.BoostConfig) []store.SearchResult {
-	if !boostCfg.Enabled || len(results) == 0 {
+	return NewBooster(boostCfg, nil).Apply(context.Background(), results)
+}
+
+// Apply multiplies the score of each result by the factors of the rules it
+// matches and re-sorts the results by adjusted score.
+func (b *Booster) Apply(ctx context.Context, results []store.SearchResult) []store.SearchResult {
+	if !b.enabled || len(results) == 0 {
 		return results
 	}
 
-	for i := range results {
-		boost := computeBoostFactor(results[i].Chunk.FilePath, boostCfg)
-		results[i].Score *= boost
+	for i, matches := range b.Explain(ctx, results) {
+		results[i].Score *= boostFactor(matches)
 	}
 
-	sort.Slice(results, func(i, j int) bool {
+	sort.SliceStable(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
 	})
 
 	return results
 }
 
-// computeBoostFactor calculates the combined boost factor for a file path.
-// Multiple matching rules are multiplied together.
-func computeBoostFactor(filePath string, boostCfg config.BoostConfig) float32 {
+// Explain returns the rules matching each result.
+func (b *Booster) Explain(ctx context.Context, results []store.SearchResult) [][]BoostMatch {
+	explanations := make([][]BoostMatch, len(results))
+	if !b.enabled || len(b.rules) == 0 {
+		return explanations
+	}
+
+	facts := b.collectFacts(ctx, results)
+	now := b.now()
+	for i, r := range results {
+		for _, rule := range b.rules {
+			if rule.matches(r.Chunk, facts[i], now) {
+				explanations[i] = append(explanations[i], BoostMatch{Rule: rule.BoostRule, Penalty: rule.penalty})
+			}
+		}
+	}
+	return explanations
+}
+
+// boostFactor returns the combined factor of matching rules. Multiple
+// matching rules are multiplied together.
+func boostFactor(matches []BoostMatch) float32 {
 	factor := float32(1.0)
+	for _, m := range matches {
+		factor *= m.Rule.Factor
+	}
+	return factor
+}
+
+// resultFacts are the signals known about one result.
+type resultFacts struct {
+	kinds    []string
+	size     int64
+	hasSize  bool
+	modified time.Time
+}
+
+// collectFacts gathers the signals the rules need for each result.
+func (b *Booster) collectFacts(ctx context.Context, results []store.SearchResult) []resultFacts {
+	var needKinds, needSize bool
+	var window time.Duration
+	for _, rule := range b.rules {
+		needKinds = needKinds || rule.Kind != ""
+		needSize = needSize || rule.MinSize > 0 || rule.MaxSize > 0
+		window = max(window, rule.window)
+	}
 
-	for _, rule := range boostCfg.Penalties {
-		if matchesPattern(filePath, rule.Pattern) {
-			factor *= rule.Factor
+	facts := make([]resultFacts, len(results))
+	for i, r := range results {
+		if needKinds {
+			facts[i].kinds = r.Chunk.SymbolKinds
+			if len(facts[i].kinds) == 0 && b.signals != nil {
+				facts[i].kinds, _ = b.signals.SymbolKinds(ctx, r.Chunk.FilePath, r.Chunk.StartLine, r.Chunk.EndLine)
+			}
+		}
+		if needSize && b.signals != nil {
+			facts[i].size, facts[i].hasSize = b.signals.FileSize(r.Chunk.FilePath)
 		}
 	}
 
-	for _, rule := range boostCfg.Bonuses {
-		if matchesPattern(filePath, rule.Pattern) {
-			factor *= rule.Factor
+	if window > 0 && b.signals != nil {
+		var paths []string
+		for _, r := range results {
+			if !slices.Contains(paths, r.Chunk.FilePath) {
+				paths = append(paths, r.Chunk.FilePath)
+			}
+		}
+		modified, err := b.signals.LastModified(ctx, paths, b.now().Add(-window))
+		if err != nil {
+			log.Printf("Warning: recency boost unavailable: %v", err)
+		}
+		for i, r := range results {
+			facts[i].modified = modified[r.Chunk.FilePath]
 		}
 	}
+	return facts
+}
 
-	return factor
+// matches reports whether a chunk meets every condition of the rule.
+func (r boostRule) matches(chunk store.Chunk, facts resultFacts, now time.Time) bool {
+	if r.Pattern != "" && !r.matchesPath(chunk.FilePath) {
+		return false
+	}
+	if r.language != "" && store.LanguageForPath(chunk.FilePath) != r.language {
+		return false
+	}
+	if r.Kind != "" && !slices.Contains(facts.kinds, r.Kind) {
+		return false
+	}
+	if r.MinSize > 0 || r.MaxSize > 0 {
+		if !facts.hasSize || facts.size < r.MinSize || (r.MaxSize > 0 && facts.size > r.MaxSize) {
+			return false
+		}
+	}
+	if r.window > 0 && (facts.modified.IsZero() || facts.modified.Before(now.Add(-r.window))) {
+		return false
+	}
+	return true
 }
 
-// matchesPattern checks if a file path contains the given pattern.
-// Patterns are simple substring matches (case-sensitive).
-func matchesPattern(filePath, pattern string) bool {
-	return strings.Contains(filePath, pattern)
+// matchesPath matches the rule pattern against a file path. Substring
+// patterns are case-sensitive.
+func (r boostRule) matchesPath(filePath string) bool {
+	if r.pattern != nil {
+		return r.pattern.MatchString(filePath)
+	}
+	return strings.Contains(filePath, r.Pattern)
 }
@@ -1,7 +1,10 @@
 package search
 
 import (
+	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/yoanbernabeu/grepai/config"
 	"github.com/yoanbernabeu/grepai/store"
@@ -109,7 +112,7 @@ func TestApplyBoost_EmptyResults(t *testing.T) {
 	}
 }
 
-func TestComputeBoostFactor(t *testing.T) {
+func TestBoostFactor(t *testing.T) {
 	boostCfg := config.BoostConfig{
 		Enabled: true,
 		Penalties: []config.BoostRule{
@@ -133,10 +136,123 @@ func TestComputeBoostFactor(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.path, func(t *testing.T) {
-			factor := computeBoostFactor(tt.path, boostCfg)
-			if factor != tt.expected {
-				t.Errorf("computeBoostFactor(%s) = %f, want %f", tt.path, factor, tt.expected)
+			matches := NewBooster(boostCfg, nil).Explain(context.Background(), []store.SearchResult{{Chunk: store.Chunk{FilePath: tt.path}}})
+			if factor := boostFactor(matches[0]); factor != tt.expected {
+				t.Errorf("boostFactor(%s) = %f, want %f", tt.path, factor, tt.expected)
 			}
 		})
 	}
 }
+
+func TestBooster_MatchTypes(t *testing.T) {
+	booster := NewBooster(config.BoostConfig{
+		Enabled: true,
+		Penalties: []config.BoostRule{
+			{Pattern: "*.md", Match: config.BoostMatchGlob, Factor: 0.6},
+			{Pattern: "test_*", Match: config.BoostMatchGlob, Factor: 0.5},
+			{Pattern: `(^|/)vendor/`, Match: config.BoostMatchRegex, Factor: 0.3},
+		},
+	}, nil)
+
+	tests := []struct {
+		path     string
+		expected float32
+	}{
+		{"README.md", 0.6},
+		{"docs/guide.md", 0.6},
+		{"cmd/main.go", 1.0},
+		{"notes.mdx", 1.0},
+		{"tests/test_auth.py", 0.5},
+		{"config/latest_config.go", 1.0},
+		{"vendor/lib/x.go", 0.3},
+		{"pkg/myvendor/x.go", 1.0},
+	}
+	for _, tt := range tests {
+		matches := booster.Explain(context.Background(), []store.SearchResult{{Chunk: store.Chunk{FilePath: tt.path}}})
+		if factor := boostFactor(matches[0]); factor != tt.expected {
+			t.Errorf("factor(%s) = %f, want %f", tt.path, factor, tt.expected)
+		}
+	}
+}
+
+type fakeSignals struct {
+	sizes    map[string]int64
+	modified map[string]time.Time
+	kinds    map[string][]string
+}
+
+func (f fakeSignals) FileSize(path string) (int64, bool) {
+	size, ok := f.sizes[path]
+	return size, ok
+}
+
+func (f fakeSignals) LastModified(ctx context.Context, paths []string, since time.Time) (map[string]time.Time, error) {
+	return f.modified, nil
+}
+
+func (f fakeSignals) SymbolKinds(ctx context.Context, path string, startLine, endLine int) ([]string, bool) {
+	kinds, ok := f.kinds[path]
+	return kinds, ok
+}
+
+func TestBooster_Signals(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	signals := fakeSignals{
+		sizes:    map[string]int64{"big.go": 500_000, "small.go": 2_000},
+		modified: map[string]time.Time{"small.go": now.Add(-48 * time.Hour), "big.go": now.AddDate(-1, 0, 0)},
+		kinds:    map[string][]string{"small.go": {"function"}},
+	}
+	cfg := config.BoostConfig{
+		Enabled: true,
+		Penalties: []config.BoostRule{
+			{MinSize: 100_000, Factor: 0.5},
+		},
+		Bonuses: []config.BoostRule{
+			{Language: "go", Kind: "function", Factor: 1.2},
+			{ModifiedWithin: "7d", Factor: 1.1},
+			{Language: "python", Factor: 2},
+		},
+	}
+	booster := NewBooster(cfg, signals)
+	booster.now = func() time.Time { return now }
+
+	results := []store.SearchResult{
+		{Chunk: store.Chunk{FilePath: "big.go", SymbolKinds: []string{"function"}}},
+		{Chunk: store.Chunk{FilePath: "small.go"}},
+	}
+	explanations := booster.Explain(context.Background(), results)
+
+	var big []string
+	for _, m := range explanations[0] {
+		big = append(big, m.String())
+	}
+	want := []string{"penalty min_size=100000 ×0.5", "bonus language=go kind=function ×1.2"}
+	if !reflect.DeepEqual(big, want) {
+		t.Errorf("big.go matched %v, want %v", big, want)
+	}
+	if factor := boostFactor(explanations[1]); factor != float32(1.2)*float32(1.1) {
+		t.Errorf("expected small.go to get the kind and recency bonuses, got %v", explanations[1])
+	}
+
+	// Without signals, size and recency rules never match.
+	explanations = NewBooster(cfg, nil).Explain(context.Background(), results)
+	if len(explanations[0]) != 1 || len(explanations[1]) != 0 {
+		t.Errorf("expected only the kind rule on big.go without signals, got %v", explanations)
+	}
+}
+
+func TestBoostMatch_String(t *testing.T) {
+	tests := []struct {
+		match BoostMatch
+		want  string
+	}{
+		{BoostMatch{Rule: config.BoostRule{Pattern: "_test.", Factor: 0.5}, Penalty: true}, `penalty substring "_test." ×0.5`},
+		{BoostMatch{Rule: config.BoostRule{Pattern: "*.md", Match: "glob", Factor: 0.6}, Penalty: true}, `penalty glob "*.md" ×0.6`},
+		{BoostMatch{Rule: config.BoostRule{ModifiedWithin: "30d", Factor: 1.1}}, `bonus modified_within=30d ×1.1`},
+	}
+	for _, tt := range tests {
+		if got := tt.match.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
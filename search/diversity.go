@@ -0,0 +1,131 @@
+package search
+
+import (
+	"math"
+	"path/filepath"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// Weights of the similarity between two results used by MMR: the cosine
+// similarity of their vectors, and how close they are in the file tree.
+// Results without vectors are compared by location only.
+const (
+	diversityVectorWeight   = 0.7
+	diversityLocationWeight = 0.3
+)
+
+// WithDiversity orders results with maximal marginal relevance, where lambda
+// in (0, 1] weighs relevance against similarity to the results already
+// picked. Zero selects config.DefaultDiversityLambda.
+func WithDiversity(lambda float64) SearcherOption {
+	return func(s *Searcher) {
+		if lambda <= 0 {
+			lambda = config.DefaultDiversityLambda
+		}
+		s.diversityLambda = lambda
+	}
+}
+
+// Diversify reorders results with maximal marginal relevance (MMR): each
+// position goes to the result maximizing
+//
+//	lambda * relevance - (1 - lambda) * max similarity to the results before it
+//
+// where relevance is the score rescaled to [0, 1] and similarity combines
+// vector similarity with sharing a file or directory. A lambda of 1 keeps
+// the ranking; lower values push near-duplicates down. Scores are kept.
+func Diversify(results []store.SearchResult, lambda float64) []store.SearchResult {
+	n := len(results)
+	if n < 3 || lambda >= 1 {
+		return results
+	}
+
+	relevance := normalizedScores(results)
+	// maxSim[i] is the highest similarity of candidate i to a picked result.
+	maxSim := make([]float64, n)
+	picked := make([]bool, n)
+	out := make([]store.SearchResult, 0, n)
+
+	last := 0 // the most relevant result always comes first
+	for len(out) < n {
+		if len(out) > 0 {
+			last = -1
+			best := math.Inf(-1)
+			for i := range results {
+				if picked[i] {
+					continue
+				}
+				if mmr := lambda*relevance[i] - (1-lambda)*maxSim[i]; mmr > best {
+					best, last = mmr, i
+				}
+			}
+		}
+
+		picked[last] = true
+		out = append(out, results[last])
+		for i := range results {
+			if !picked[i] {
+				maxSim[i] = max(maxSim[i], resultSimilarity(results[i].Chunk, results[last].Chunk))
+			}
+		}
+	}
+	return out
+}
+
+// normalizedScores rescales the scores of results, in rank order, to [0, 1].
+func normalizedScores(results []store.SearchResult) []float64 {
+	lo, hi := float64(results[0].Score), float64(results[0].Score)
+	for _, r := range results {
+		lo = min(lo, float64(r.Score))
+		hi = max(hi, float64(r.Score))
+	}
+
+	scores := make([]float64, len(results))
+	for i, r := range results {
+		if hi > lo {
+			scores[i] = (float64(r.Score) - lo) / (hi - lo)
+		} else {
+			scores[i] = 1
+		}
+	}
+	return scores
+}
+
+// resultSimilarity returns how redundant two results are, from 0 to 1.
+func resultSimilarity(a, b store.Chunk) float64 {
+	location := locationSimilarity(a.FilePath, b.FilePath)
+	if len(a.Vector) == 0 || len(a.Vector) != len(b.Vector) {
+		return location
+	}
+	return diversityVectorWeight*max(vectorSimilarity(a.Vector, b.Vector), 0) + diversityLocationWeight*location
+}
+
+// locationSimilarity is 1 for the same file, 0.5 for files of the same
+// directory and 0 otherwise.
+func locationSimilarity(a, b string) float64 {
+	switch {
+	case a == b:
+		return 1
+	case filepath.Dir(a) == filepath.Dir(b):
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// vectorSimilarity returns the cosine similarity of two vectors of the same
+// length.
+func vectorSimilarity(a, b []float32) float64 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,67 @@
+package search
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func vectorHit(id, path string, score float32, vector ...float32) store.SearchResult {
+	return store.SearchResult{
+		Chunk: store.Chunk{ID: id, FilePath: path, Vector: vector},
+		Score: score,
+	}
+}
+
+func TestDiversify_DemotesNearDuplicates(t *testing.T) {
+	results := []store.SearchResult{
+		vectorHit("auth_0", "auth/login.go", 0.90, 1, 0, 0),
+		vectorHit("auth_1", "auth/login.go", 0.89, 0.99, 0.1, 0),
+		vectorHit("auth_2", "auth/session.go", 0.88, 0.98, 0.15, 0),
+		vectorHit("api_0", "api/handler.go", 0.80, 0, 1, 0),
+		vectorHit("db_0", "db/users.go", 0.75, 0, 0, 1),
+	}
+
+	got := resultIDs(Diversify(results, 0.3))
+	want := []string{"auth_0", "api_0", "db_0", "auth_2", "auth_1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diversify() = %v, want %v", got, want)
+	}
+
+	if got := resultIDs(Diversify(results, 1)); !reflect.DeepEqual(got, resultIDs(results)) {
+		t.Errorf("expected lambda 1 to keep the ranking, got %v", got)
+	}
+}
+
+func TestDiversify_WithoutVectors(t *testing.T) {
+	results := []store.SearchResult{
+		vectorHit("a_0", "pkg/a.go", 0.9),
+		vectorHit("a_1", "pkg/a.go", 0.88),
+		vectorHit("b_0", "pkg/b.go", 0.87),
+		vectorHit("c_0", "other/c.go", 0.7),
+	}
+
+	got := resultIDs(Diversify(results, 0.5))
+	want := []string{"a_0", "b_0", "c_0", "a_1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diversify() = %v, want %v", got, want)
+	}
+}
+
+func TestResultSimilarity(t *testing.T) {
+	a := store.Chunk{FilePath: "pkg/a.go", Vector: []float32{1, 0}}
+	b := store.Chunk{FilePath: "pkg/b.go", Vector: []float32{1, 0}}
+	c := store.Chunk{FilePath: "other/c.go", Vector: []float32{0, 1}}
+
+	if got := resultSimilarity(a, a); math.Abs(got-1) > 1e-9 {
+		t.Errorf("expected identical chunks to have similarity 1, got %g", got)
+	}
+	if got := resultSimilarity(a, b); math.Abs(got-(diversityVectorWeight+diversityLocationWeight*0.5)) > 1e-9 {
+		t.Errorf("unexpected similarity for same-directory chunks: %g", got)
+	}
+	if got := resultSimilarity(a, c); got != 0 {
+		t.Errorf("expected unrelated chunks to have similarity 0, got %g", got)
+	}
+}
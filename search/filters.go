@@ -0,0 +1,141 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// Filters are the user-facing search filters shared by the CLI and the MCP
+// server. Use Options to turn them into store.SearchOptions.
+type Filters struct {
+	Languages []string // language names or aliases, e.g. "go", "ts"
+	Include   []string // path globs to keep
+	Exclude   []string // path globs to drop
+	Kinds     []string // symbol kinds, e.g. "function", "method"
+	Since     string   // relative age ("30d", "2w", "12h") or date ("2024-06-01")
+}
+
+// Options validates the filters and builds store.SearchOptions for a search
+// restricted to pathPrefix.
+func (f Filters) Options(pathPrefix string, now time.Time) (store.SearchOptions, error) {
+	opts := store.SearchOptions{
+		PathPrefix:   pathPrefix,
+		Languages:    splitList(f.Languages),
+		IncludeGlobs: splitGlobList(f.Include),
+		ExcludeGlobs: splitGlobList(f.Exclude),
+		SymbolKinds:  splitList(f.Kinds),
+	}
+	for i, kind := range opts.SymbolKinds {
+		opts.SymbolKinds[i] = strings.ToLower(kind)
+	}
+
+	if f.Since != "" {
+		since, err := ParseSince(f.Since, now)
+		if err != nil {
+			return store.SearchOptions{}, err
+		}
+		opts.ModifiedAfter = since
+	}
+
+	return opts.Validate()
+}
+
+// WithWorkspaceRoot rewrites anchored globs (those containing a slash) so they
+// apply relative to each project root in a workspace store, where paths are
+// stored as workspace/project/relative/path.
+func (f Filters) WithWorkspaceRoot(workspaceName string) Filters {
+	anchor := func(globs []string) []string {
+		out := make([]string, 0, len(globs))
+		for _, glob := range splitGlobList(globs) {
+			if strings.Contains(glob, "/") {
+				glob = workspaceName + "/*/" + strings.TrimPrefix(glob, "/")
+			}
+			out = append(out, glob)
+		}
+		return out
+	}
+	f.Include = anchor(f.Include)
+	f.Exclude = anchor(f.Exclude)
+	return f
+}
+
+// ParseSince converts a --since value into the earliest modification time to
+// keep. It accepts a relative age with a d (days), w (weeks), h, m or s
+// suffix, a date (2006-01-02) or an RFC 3339 timestamp.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty since value")
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return t, nil
+	}
+
+	unit := value[len(value)-1]
+	switch unit {
+	case 'd', 'w':
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid since value %q (expected e.g. 30d, 2w, 12h or 2024-06-01)", value)
+		}
+		days := n
+		if unit == 'w' {
+			days = n * 7
+		}
+		return now.AddDate(0, 0, -days), nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("invalid since value %q (expected e.g. 30d, 2w, 12h or 2024-06-01)", value)
+	}
+	return now.Add(-d), nil
+}
+
+// splitList flattens comma-separated entries and drops empty ones.
+func splitList(values []string) []string {
+	var out []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// splitGlobList is splitList for globs: commas inside {a,b} alternations do
+// not separate patterns.
+func splitGlobList(values []string) []string {
+	var out []string
+	for _, value := range values {
+		depth, start := 0, 0
+		for i := 0; i <= len(value); i++ {
+			if i < len(value) {
+				switch value[i] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+				}
+				if value[i] != ',' || depth > 0 {
+					continue
+				}
+			}
+			if part := strings.TrimSpace(value[start:i]); part != "" {
+				out = append(out, part)
+			}
+			start = i + 1
+		}
+	}
+	return out
+}
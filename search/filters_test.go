@@ -0,0 +1,84 @@
+package search
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"30d", now.AddDate(0, 0, -30)},
+		{"2w", now.AddDate(0, 0, -14)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2025-06-01", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"2025-06-01T08:00:00Z", time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.value, now)
+		if err != nil {
+			t.Fatalf("ParseSince(%q) failed: %v", tt.value, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "soon", "-3d", "3x"} {
+		if _, err := ParseSince(value, now); err == nil {
+			t.Errorf("ParseSince(%q) expected error", value)
+		}
+	}
+}
+
+func TestFiltersOptions(t *testing.T) {
+	now := time.Now()
+	opts, err := Filters{
+		Languages: []string{"go,py"},
+		Include:   []string{"services/**,*.{ts,tsx}"},
+		Exclude:   []string{"*_test.go"},
+		Kinds:     []string{"Function"},
+		Since:     "7d",
+	}.Options("src/", now)
+	if err != nil {
+		t.Fatalf("Options failed: %v", err)
+	}
+
+	if opts.PathPrefix != "src/" {
+		t.Errorf("expected path prefix to be kept, got %q", opts.PathPrefix)
+	}
+	if len(opts.Languages) != 2 || opts.Languages[1] != "python" {
+		t.Errorf("unexpected languages: %v", opts.Languages)
+	}
+	if len(opts.IncludeGlobs) != 2 || opts.IncludeGlobs[1] != "*.{ts,tsx}" {
+		t.Errorf("expected brace alternation to survive comma splitting, got %v", opts.IncludeGlobs)
+	}
+	if len(opts.SymbolKinds) != 1 || opts.SymbolKinds[0] != "function" {
+		t.Errorf("unexpected symbol kinds: %v", opts.SymbolKinds)
+	}
+	if !opts.ModifiedAfter.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("unexpected modified-after: %v", opts.ModifiedAfter)
+	}
+
+	if _, err := (Filters{Languages: []string{"klingon"}}).Options("", now); err == nil {
+		t.Error("expected error for unknown language")
+	}
+}
+
+func TestFiltersWithWorkspaceRoot(t *testing.T) {
+	f := Filters{
+		Include: []string{"services/**", "*.go"},
+		Exclude: []string{"/vendor/**"},
+	}.WithWorkspaceRoot("acme")
+
+	if f.Include[0] != "acme/*/services/**" || f.Include[1] != "*.go" {
+		t.Errorf("unexpected include globs: %v", f.Include)
+	}
+	if f.Exclude[0] != "acme/*/vendor/**" {
+		t.Errorf("unexpected exclude globs: %v", f.Exclude)
+	}
+}
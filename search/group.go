@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// DefaultSnippetsPerFile is the number of snippets kept per file when
+// results are grouped by file.
+const DefaultSnippetsPerFile = 3
+
+// FileResult groups the hits of one file.
+type FileResult struct {
+	FilePath string
+	Score    float32              // score of the best snippet
+	Snippets []store.SearchResult // best first
+}
+
+// GroupByFile groups results by file, keeping the best maxSnippets hits of
+// each file, or all of them when maxSnippets is zero. Files are ordered by
+// their best hit and results are expected in rank order.
+func GroupByFile(results []store.SearchResult, maxSnippets int) []FileResult {
+	var groups []FileResult
+	positions := make(map[string]int)
+	for _, r := range results {
+		pos, ok := positions[r.Chunk.FilePath]
+		if !ok {
+			pos = len(groups)
+			positions[r.Chunk.FilePath] = pos
+			groups = append(groups, FileResult{FilePath: r.Chunk.FilePath, Score: r.Score})
+		}
+		if maxSnippets <= 0 || len(groups[pos].Snippets) < maxSnippets {
+			groups[pos].Snippets = append(groups[pos].Snippets, r)
+		}
+	}
+	return groups
+}
+
+// SearchByFile searches like SearchWithOptions and returns the best limit
+// files, each with up to DefaultSnippetsPerFile snippets.
+func (s *Searcher) SearchByFile(ctx context.Context, query string, limit int, opts store.SearchOptions) ([]FileResult, error) {
+	// Fetch enough candidates to fill several snippets per file
+	results, err := s.rankedResults(ctx, query, limit*DefaultSnippetsPerFile*2, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := GroupByFile(results, DefaultSnippetsPerFile)
+	if len(groups) > limit {
+		groups = groups[:limit]
+	}
+	return groups, nil
+}
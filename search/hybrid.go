@@ -8,49 +8,26 @@ import (
 	"github.com/yoanbernabeu/grepai/store"
 )
 
-// TextSearch performs a simple text-based search on chunks.
-// It scores chunks based on the number of query words they contain.
-// If pathPrefix is provided, only chunks from files starting with that prefix are included.
+// TextSearch ranks chunks against the query with BM25, building a
+// full-text index in memory. It serves stores without a persistent text
+// index (see store.TextSearcher). If pathPrefix is provided, only chunks from
+// files starting with that prefix are included.
 func TextSearch(ctx context.Context, chunks []store.Chunk, query string, limit int, pathPrefix string) []store.SearchResult {
-	words := tokenize(query)
-	if len(words) == 0 {
-		return nil
-	}
-
-	var results []store.SearchResult
-
+	index := store.NewTextIndex()
+	byID := make(map[string]store.Chunk, len(chunks))
 	for _, chunk := range chunks {
-		// Filter by path prefix if provided
 		if pathPrefix != "" && !strings.HasPrefix(chunk.FilePath, pathPrefix) {
 			continue
 		}
-
-		contentLower := strings.ToLower(chunk.Content)
-		matchCount := 0
-
-		for _, word := range words {
-			if strings.Contains(contentLower, word) {
-				matchCount++
-			}
-		}
-
-		if matchCount > 0 {
-			score := float32(matchCount) / float32(len(words))
-			results = append(results, store.SearchResult{
-				Chunk: chunk,
-				Score: score,
-			})
-		}
+		index.Add(chunk)
+		byID[chunk.ID] = chunk
 	}
 
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
-
-	if limit > 0 && len(results) > limit {
-		results = results[:limit]
+	hits := index.Search(query, limit, nil)
+	results := make([]store.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, store.SearchResult{Chunk: byID[hit.ID], Score: hit.Score})
 	}
-
 	return results
 }
 
@@ -87,16 +64,3 @@ func ReciprocalRankFusion(k float32, limit int, lists ...[]store.SearchResult) [
 
 	return results
 }
-
-// tokenize splits a query into lowercase words, filtering out short words.
-func tokenize(query string) []string {
-	fields := strings.Fields(strings.ToLower(query))
-	var words []string
-	for _, f := range fields {
-		// Skip very short words (articles, prepositions)
-		if len(f) >= 2 {
-			words = append(words, f)
-		}
-	}
-	return words
-}
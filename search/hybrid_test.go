@@ -38,9 +38,9 @@ func TestTextSearch(t *testing.T) {
 		if results[0].Chunk.ID != "3" {
 			t.Errorf("expected first result to be '3' (has both words), got '%s'", results[0].Chunk.ID)
 		}
-		// Chunks 1 and 4 each have one word -> score 0.5
-		if results[0].Score != 1.0 {
-			t.Errorf("expected first result score 1.0, got %f", results[0].Score)
+		// Chunks 1 and 4 each have one word and score lower
+		if results[0].Score <= results[1].Score {
+			t.Errorf("expected first result to score higher, got %f and %f", results[0].Score, results[1].Score)
 		}
 	})
 
@@ -52,6 +52,35 @@ func TestTextSearch(t *testing.T) {
 	})
 }
 
+func TestTextSearch_RareTermsWeighMore(t *testing.T) {
+	chunks := []store.Chunk{
+		{ID: "1", Content: "func parseConfig() error { return nil }"},
+		{ID: "2", Content: "func loadConfig() error { return nil }"},
+		{ID: "3", Content: "func saveConfig() error { return nil }"},
+		{ID: "4", Content: "func retryRequest() error { return nil }"},
+	}
+
+	results := TextSearch(context.Background(), chunks, "config retry", 10, "")
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	if results[0].Chunk.ID != "4" {
+		t.Errorf("expected the chunk with the rare term first, got %q", results[0].Chunk.ID)
+	}
+}
+
+func TestTextSearch_PathPrefix(t *testing.T) {
+	chunks := []store.Chunk{
+		{ID: "1", FilePath: "api/user.go", Content: "type User struct{}"},
+		{ID: "2", FilePath: "web/user.ts", Content: "interface User {}"},
+	}
+
+	results := TextSearch(context.Background(), chunks, "user", 10, "api/")
+	if len(results) != 1 || results[0].Chunk.ID != "1" {
+		t.Errorf("expected only the chunk under api/, got %+v", results)
+	}
+}
+
 func TestTextSearch_EmptyQuery(t *testing.T) {
 	chunks := []store.Chunk{
 		{ID: "1", Content: "some content"},
@@ -118,25 +147,3 @@ func TestReciprocalRankFusion_EmptyLists(t *testing.T) {
 		t.Errorf("expected 0 results for empty lists, got %d", len(results))
 	}
 }
-
-func TestTokenize(t *testing.T) {
-	tests := []struct {
-		query    string
-		expected []string
-	}{
-		{"hello world", []string{"hello", "world"}},
-		{"UPPER CASE", []string{"upper", "case"}},
-		{"a b c", []string{}}, // single letters filtered
-		{"the user login", []string{"the", "user", "login"}},
-		{"", []string{}},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.query, func(t *testing.T) {
-			result := tokenize(tt.query)
-			if len(result) != len(tt.expected) {
-				t.Errorf("tokenize(%q) = %v, want %v", tt.query, result, tt.expected)
-			}
-		})
-	}
-}
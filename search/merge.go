@@ -0,0 +1,184 @@
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// minContentOverlap is the smallest shared text, in bytes, accepted as the
+// overlap between two chunks. Shorter matches, such as a closing brace, are
+// likely coincidental.
+const minContentOverlap = 16
+
+// MergeAdjacent merges results from the same file whose line ranges overlap
+// or touch into a single result covering both ranges. Chunks overlap, so one
+// region of a file often matches several times; merging returns it once.
+//
+// A merged result takes the score, ID and position of its best hit. Results
+// are expected in rank order. Notebook cells, and hits whose content cannot
+// be joined, are left as they are.
+func MergeAdjacent(results []store.SearchResult) []store.SearchResult {
+	if len(results) < 2 {
+		return results
+	}
+
+	byFile := make(map[string][]int)
+	for i, r := range results {
+		if !store.IsNotebook(r.Chunk.FilePath) {
+			byFile[r.Chunk.FilePath] = append(byFile[r.Chunk.FilePath], i)
+		}
+	}
+
+	// merged[i] holds the result replacing results[i], the best hit of its
+	// group; dropped[i] marks hits merged into a better one.
+	merged := make(map[int]store.SearchResult)
+	dropped := make(map[int]bool)
+	for _, indexes := range byFile {
+		if len(indexes) < 2 {
+			continue
+		}
+		sort.SliceStable(indexes, func(a, b int) bool {
+			return results[indexes[a]].Chunk.StartLine < results[indexes[b]].Chunk.StartLine
+		})
+
+		best, current, size := indexes[0], results[indexes[0]], 1
+		flush := func() {
+			if size > 1 {
+				merged[best] = current
+			}
+		}
+		for _, i := range indexes[1:] {
+			next := results[i]
+			if next.Chunk.StartLine <= current.Chunk.EndLine+1 {
+				if content, ok := joinContent(current.Chunk, next.Chunk); ok {
+					if i < best {
+						// The better hit gives the merged result its identity.
+						dropped[best] = true
+						best, current = i, mergeInto(next, current, content)
+					} else {
+						dropped[i] = true
+						current = mergeInto(current, next, content)
+					}
+					size++
+					continue
+				}
+			}
+			flush()
+			best, current, size = i, next, 1
+		}
+		flush()
+	}
+	if len(dropped) == 0 {
+		return results
+	}
+
+	out := make([]store.SearchResult, 0, len(results)-len(dropped))
+	for i, r := range results {
+		if dropped[i] {
+			continue
+		}
+		if m, ok := merged[i]; ok {
+			r = m
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// mergeInto returns best extended with the range of other and the joined
+// content. Content hashes no longer describe the merged text and are
+// cleared.
+func mergeInto(best, other store.SearchResult, content string) store.SearchResult {
+	chunk := best.Chunk
+	chunk.StartLine = min(best.Chunk.StartLine, other.Chunk.StartLine)
+	chunk.EndLine = max(best.Chunk.EndLine, other.Chunk.EndLine)
+	chunk.Content = content
+	chunk.Hash = ""
+	chunk.ContentHash = ""
+	for _, kind := range other.Chunk.SymbolKinds {
+		if !slices.Contains(chunk.SymbolKinds, kind) {
+			chunk.SymbolKinds = append(chunk.SymbolKinds[:len(chunk.SymbolKinds):len(chunk.SymbolKinds)], kind)
+		}
+	}
+	return store.SearchResult{Chunk: chunk, Score: max(best.Score, other.Score)}
+}
+
+// joinContent returns the text covering first and second, where second does
+// not start before first. The context header of first is kept. It reports
+// false when the two texts cannot be joined reliably.
+func joinContent(first, second store.Chunk) (string, bool) {
+	header, a := splitContextHeader(first)
+	_, b := splitContextHeader(second)
+
+	switch {
+	case strings.Contains(a, b):
+		return header + a, true
+	case second.EndLine <= first.EndLine && strings.Contains(b, a):
+		return header + b, true
+	}
+	if k := suffixPrefixOverlap(a, b); k >= minContentOverlap {
+		return header + a + b[k:], true
+	}
+	switch {
+	case second.StartLine == first.EndLine+1:
+		if !strings.HasSuffix(a, "\n") {
+			a += "\n"
+		}
+		return header + a + b, true
+	case second.StartLine == first.EndLine && !strings.HasSuffix(a, "\n"):
+		// Chunks split mid-line share their boundary line.
+		return header + a + b, true
+	}
+	return "", false
+}
+
+// splitContextHeader splits the "File: path" header the indexer stores
+// before chunk content from the content itself.
+func splitContextHeader(chunk store.Chunk) (string, string) {
+	if !strings.HasPrefix(chunk.Content, "File: "+chunk.FilePath) {
+		return "", chunk.Content
+	}
+	i := strings.Index(chunk.Content, "\n\n")
+	if i < 0 {
+		return "", chunk.Content
+	}
+	return chunk.Content[:i+2], chunk.Content[i+2:]
+}
+
+// suffixPrefixOverlap returns the length of the longest suffix of a that is
+// also a prefix of b.
+func suffixPrefixOverlap(a, b string) int {
+	n := min(len(a), len(b))
+	if n == 0 {
+		return 0
+	}
+	// Match the tail of a against b[:n] with the KMP failure function.
+	pattern := b[:n]
+	fail := make([]int, n)
+	for i, k := 1, 0; i < n; i++ {
+		for k > 0 && pattern[i] != pattern[k] {
+			k = fail[k-1]
+		}
+		if pattern[i] == pattern[k] {
+			k++
+		}
+		fail[i] = k
+	}
+
+	k := 0
+	for i := len(a) - n; i < len(a); i++ {
+		for k > 0 && a[i] != pattern[k] {
+			k = fail[k-1]
+		}
+		if a[i] == pattern[k] {
+			k++
+		}
+		if k == n && i < len(a)-1 {
+			k = fail[k-1]
+		}
+	}
+	return k
+}
@@ -0,0 +1,132 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func hit(id, path string, start, end int, content string, score float32) store.SearchResult {
+	return store.SearchResult{
+		Chunk: store.Chunk{ID: id, FilePath: path, StartLine: start, EndLine: end, Content: content, Hash: "h-" + id},
+		Score: score,
+	}
+}
+
+func TestMergeAdjacent_OverlappingChunks(t *testing.T) {
+	results := []store.SearchResult{
+		hit("a_1", "a.go", 8, 14, "File: a.go\n\n\treturn validateToken(token)\n}\n\nfunc logout() {}\n", 0.9),
+		hit("b_0", "b.go", 1, 3, "package b\n", 0.8),
+		hit("a_0", "a.go", 1, 9, "File: a.go\n\nfunc login(token string) error {\n\tcheck(token)\n\treturn validateToken(token)\n}\n", 0.7),
+	}
+
+	merged := MergeAdjacent(results)
+	if len(merged) != 2 {
+		t.Fatalf("expected 2 results, got %d: %+v", len(merged), merged)
+	}
+
+	got := merged[0]
+	if got.Chunk.ID != "a_1" || got.Score != 0.9 {
+		t.Errorf("expected the best hit to give its ID and score, got %s %.2f", got.Chunk.ID, got.Score)
+	}
+	if got.Chunk.StartLine != 1 || got.Chunk.EndLine != 14 {
+		t.Errorf("expected lines 1-14, got %d-%d", got.Chunk.StartLine, got.Chunk.EndLine)
+	}
+	want := "File: a.go\n\nfunc login(token string) error {\n\tcheck(token)\n\treturn validateToken(token)\n}\n\nfunc logout() {}\n"
+	if got.Chunk.Content != want {
+		t.Errorf("unexpected merged content:\n%q\nwant\n%q", got.Chunk.Content, want)
+	}
+	if got.Chunk.Hash != "" {
+		t.Errorf("expected the hash of a merged chunk to be cleared, got %q", got.Chunk.Hash)
+	}
+	if merged[1].Chunk.ID != "b_0" {
+		t.Errorf("expected b.go second, got %s", merged[1].Chunk.ID)
+	}
+}
+
+func TestMergeAdjacent_TouchingChunks(t *testing.T) {
+	results := []store.SearchResult{
+		hit("a_0", "a.go", 1, 2, "line one\nline two", 0.5),
+		hit("a_1", "a.go", 3, 4, "line three\nline four\n", 0.6),
+		hit("a_2", "a.go", 5, 5, "line five\n", 0.4),
+	}
+
+	merged := MergeAdjacent(results)
+	if len(merged) != 1 {
+		t.Fatalf("expected one merged result, got %+v", merged)
+	}
+	if merged[0].Score != 0.6 {
+		t.Errorf("expected the max score, got %.2f", merged[0].Score)
+	}
+	want := "line one\nline two\nline three\nline four\nline five\n"
+	if merged[0].Chunk.Content != want {
+		t.Errorf("unexpected merged content %q", merged[0].Chunk.Content)
+	}
+	if merged[0].Chunk.StartLine != 1 || merged[0].Chunk.EndLine != 5 {
+		t.Errorf("expected lines 1-5, got %d-%d", merged[0].Chunk.StartLine, merged[0].Chunk.EndLine)
+	}
+}
+
+func TestMergeAdjacent_KeepsSeparateHits(t *testing.T) {
+	results := []store.SearchResult{
+		hit("a_0", "a.go", 1, 5, "first\n", 0.9),
+		hit("a_3", "a.go", 20, 25, "second\n", 0.8),
+		hit("n_0", "n.ipynb", 1, 1, "cell one", 0.7),
+		hit("n_1", "n.ipynb", 2, 2, "cell two", 0.6),
+		// Overlapping range whose content does not line up (stale index).
+		hit("a_1", "a.go", 4, 8, "unrelated text\n", 0.5),
+	}
+
+	merged := MergeAdjacent(results)
+	if !reflect.DeepEqual(resultIDs(merged), resultIDs(results)) {
+		t.Errorf("expected results unchanged, got %v", resultIDs(merged))
+	}
+}
+
+func TestSuffixPrefixOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"abcdef", "defgh", 3},
+		{"abc", "xyz", 0},
+		{"aaaa", "aaab", 3},
+		{"abab", "ababx", 4},
+		{"", "abc", 0},
+		{"xabcabc", "abcabcd", 6},
+	}
+	for _, tt := range tests {
+		if got := suffixPrefixOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("suffixPrefixOverlap(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestGroupByFile(t *testing.T) {
+	results := []store.SearchResult{
+		hit("a_0", "a.go", 1, 5, "", 0.9),
+		hit("b_0", "b.go", 1, 5, "", 0.8),
+		hit("a_4", "a.go", 40, 45, "", 0.7),
+		hit("a_8", "a.go", 80, 85, "", 0.6),
+		hit("c_0", "c.go", 1, 5, "", 0.5),
+	}
+
+	groups := GroupByFile(results, 2)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(groups))
+	}
+	if groups[0].FilePath != "a.go" || groups[0].Score != 0.9 {
+		t.Errorf("expected a.go first with its best score, got %s %.2f", groups[0].FilePath, groups[0].Score)
+	}
+	if got := resultIDs(groups[0].Snippets); !reflect.DeepEqual(got, []string{"a_0", "a_4"}) {
+		t.Errorf("expected the best 2 snippets of a.go, got %v", got)
+	}
+	if groups[1].FilePath != "b.go" || groups[2].FilePath != "c.go" {
+		t.Errorf("expected b.go then c.go, got %s then %s", groups[1].FilePath, groups[2].FilePath)
+	}
+
+	if all := GroupByFile(results, 0); len(all[0].Snippets) != 3 {
+		t.Errorf("expected every snippet without a cap, got %d", len(all[0].Snippets))
+	}
+}
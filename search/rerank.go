@@ -0,0 +1,161 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/rpg"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// maxRerankDocumentChars truncates the chunk text sent to remote rerankers,
+// keeping requests bounded for large chunks.
+const maxRerankDocumentChars = 4000
+
+// Reranker re-scores search candidates against the query.
+type Reranker interface {
+	// Rerank returns the candidates ordered by relevance to the query, with
+	// the reranker's scores. Every candidate is returned exactly once.
+	Rerank(ctx context.Context, query string, candidates []store.SearchResult) ([]store.SearchResult, error)
+}
+
+// SearcherOption configures optional Searcher behaviour.
+type SearcherOption func(*Searcher)
+
+// WithReranker re-scores the best topN results of each search with r
+// before they are truncated to the requested limit.
+func WithReranker(r Reranker, topN int) SearcherOption {
+	return func(s *Searcher) {
+		s.reranker = r
+		s.rerankTopN = topN
+	}
+}
+
+// NewRerankerFromConfig creates the reranker configured in
+// cfg.Search.Rerank, or returns nil when re-ranking is disabled. The llm
+// provider falls back to the rpg.llm_* settings for its endpoint, model, API
+// key and timeout.
+func NewRerankerFromConfig(cfg *config.Config) (Reranker, error) {
+	rc := cfg.Search.Rerank
+	if !rc.Enabled {
+		return nil, nil
+	}
+
+	switch rc.Provider {
+	case "", "heuristic":
+		return NewHeuristicReranker(), nil
+	case "http":
+		if rc.Endpoint == "" {
+			return nil, fmt.Errorf("search.rerank.endpoint is required for the http provider")
+		}
+		timeout := time.Duration(rc.TimeoutMs) * time.Millisecond
+		if timeout <= 0 {
+			timeout = config.DefaultRerankTimeoutMs * time.Millisecond
+		}
+		return NewHTTPReranker(rc.Endpoint, rc.Model, rc.APIKey, timeout), nil
+	case "llm":
+		llmCfg := rpg.LLMExtractorConfig{
+			Provider: cfg.RPG.LLMProvider,
+			Model:    firstNonEmpty(rc.Model, cfg.RPG.LLMModel),
+			Endpoint: firstNonEmpty(rc.Endpoint, cfg.RPG.LLMEndpoint),
+			APIKey:   firstNonEmpty(rc.APIKey, cfg.RPG.LLMAPIKey),
+			Timeout:  time.Duration(cfg.RPG.LLMTimeoutMs) * time.Millisecond,
+		}
+		if rc.TimeoutMs > 0 {
+			llmCfg.Timeout = time.Duration(rc.TimeoutMs) * time.Millisecond
+		}
+		if llmCfg.Endpoint == "" || llmCfg.Model == "" {
+			return nil, fmt.Errorf("the llm reranker needs a model and endpoint: set search.rerank.model and endpoint, or rpg.llm_model and llm_endpoint")
+		}
+		llmCfg.MaxTokens = llmRerankMaxTokens(rc.TopN)
+		return NewLLMReranker(rpg.NewLLMExtractor(llmCfg)), nil
+	default:
+		return nil, fmt.Errorf("unknown rerank provider: %s", rc.Provider)
+	}
+}
+
+// rerank re-scores the best s.rerankTopN results and puts them first. The
+// original order is kept when the reranker fails, so a reranking outage
+// degrades results instead of failing the search.
+func (s *Searcher) rerank(ctx context.Context, query string, results []store.SearchResult) []store.SearchResult {
+	n := min(s.rerankTopN, len(results))
+	if s.reranker == nil || n < 2 {
+		return results
+	}
+
+	reranked, err := s.reranker.Rerank(ctx, query, results[:n])
+	if err != nil {
+		log.Printf("Warning: re-ranking failed, keeping the original order: %v", err)
+		return results
+	}
+	return append(reranked, belowScore(results[n:], minScore(reranked))...)
+}
+
+// minScore returns the lowest score of results, which must not be empty.
+func minScore(results []store.SearchResult) float32 {
+	lowest := results[0].Score
+	for _, r := range results[1:] {
+		lowest = min(lowest, r.Score)
+	}
+	return lowest
+}
+
+// belowScore returns a copy of tail, ordered best first, with its scores
+// shifted so they all fall strictly below limit. The reranked head and the
+// tail are scored on different scales, and later stages must still rank
+// every tail result after the head.
+func belowScore(tail []store.SearchResult, limit float32) []store.SearchResult {
+	shifted := make([]store.SearchResult, len(tail))
+	copy(shifted, tail)
+	if len(shifted) == 0 {
+		return shifted
+	}
+	top := math.Nextafter32(limit, float32(math.Inf(-1)))
+	if shifted[0].Score <= top {
+		return shifted
+	}
+	shift := float64(shifted[0].Score) - float64(top)
+	for i := range shifted {
+		// Clamping keeps the order when rounding lands on limit.
+		shifted[i].Score = min(float32(float64(shifted[i].Score)-shift), top)
+	}
+	return shifted
+}
+
+// rerankDocument returns the text a reranker judges for a candidate: its
+// location followed by its content, truncated to maxChars.
+func rerankDocument(chunk store.Chunk, maxChars int) string {
+	content := chunk.Content
+	if len(content) > maxChars {
+		content = strings.ToValidUTF8(content[:maxChars], "")
+	}
+	return chunk.Location() + "\n" + content
+}
+
+// reorderByScores returns the candidates with the given scores, highest
+// first. Candidates with equal scores keep their original order.
+func reorderByScores(candidates []store.SearchResult, scores []float32) []store.SearchResult {
+	reranked := make([]store.SearchResult, len(candidates))
+	for i, c := range candidates {
+		reranked[i] = store.SearchResult{Chunk: c.Chunk, Score: scores[i]}
+	}
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return reranked[i].Score > reranked[j].Score
+	})
+	return reranked
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// Weights of the signals combined by HeuristicReranker; they add up to 1.
+const (
+	heuristicRetrievalWeight = 0.5  // score from retrieval, relative to the best candidate
+	heuristicContentWeight   = 0.3  // share of query terms found in the chunk
+	heuristicPathWeight      = 0.15 // share of query terms found in the file path
+	heuristicPhraseWeight    = 0.05 // the whole query appears verbatim
+)
+
+// HeuristicReranker re-scores candidates locally, without network calls, by
+// blending their retrieval score with how many query terms, identifier parts
+// included, they and their file paths contain.
+type HeuristicReranker struct{}
+
+// NewHeuristicReranker creates a local reranker.
+func NewHeuristicReranker() *HeuristicReranker {
+	return &HeuristicReranker{}
+}
+
+// Rerank orders the candidates by their blended score.
+func (r *HeuristicReranker) Rerank(ctx context.Context, query string, candidates []store.SearchResult) ([]store.SearchResult, error) {
+	terms := distinctTerms(query)
+	if len(terms) == 0 {
+		return candidates, nil
+	}
+	phrase := strings.ToLower(strings.TrimSpace(query))
+
+	var best float32
+	for _, c := range candidates {
+		best = max(best, c.Score)
+	}
+
+	scores := make([]float32, len(candidates))
+	for i, c := range candidates {
+		var retrieval float32
+		if best > 0 {
+			retrieval = c.Score / best
+		}
+		content := termSet(c.Chunk.Content)
+		path := termSet(c.Chunk.FilePath)
+		score := heuristicRetrievalWeight*retrieval +
+			heuristicContentWeight*coverage(terms, content) +
+			heuristicPathWeight*coverage(terms, path)
+		if strings.Contains(phrase, " ") && strings.Contains(strings.ToLower(c.Chunk.Content), phrase) {
+			score += heuristicPhraseWeight
+		}
+		scores[i] = score
+	}
+	return reorderByScores(candidates, scores), nil
+}
+
+// distinctTerms returns the distinct index terms of text.
+func distinctTerms(text string) []string {
+	set := termSet(text)
+	terms := make([]string, 0, len(set))
+	for _, term := range store.TextTerms(text) {
+		if set[term] {
+			terms = append(terms, term)
+			delete(set, term)
+		}
+	}
+	return terms
+}
+
+// termSet returns the index terms of text as a set.
+func termSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, term := range store.TextTerms(text) {
+		set[term] = true
+	}
+	return set
+}
+
+// coverage returns the share of terms found in set.
+func coverage(terms []string, set map[string]bool) float32 {
+	found := 0
+	for _, term := range terms {
+		if set[term] {
+			found++
+		}
+	}
+	return float32(found) / float32(len(terms))
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// HTTPReranker calls a /rerank endpoint in the format shared by Cohere, Jina,
+// Voyage and OpenAI-compatible servers such as vLLM and Infinity.
+type HTTPReranker struct {
+	endpoint string
+	model    string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewHTTPReranker creates a reranker posting to endpoint + "/rerank", for
+// example https://api.jina.ai/v1 or https://api.cohere.com/v2.
+func NewHTTPReranker(endpoint, model, apiKey string, timeout time.Duration) *HTTPReranker {
+	return &HTTPReranker{
+		endpoint: strings.TrimRight(endpoint, "/") + "/rerank",
+		model:    model,
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+type rerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+}
+
+type rerankResult struct {
+	Index          int     `json:"index"`
+	RelevanceScore float32 `json:"relevance_score"`
+}
+
+// rerankResponse accepts both the "results" field of Cohere and Jina and the
+// "data" field of Voyage.
+type rerankResponse struct {
+	Results []rerankResult `json:"results"`
+	Data    []rerankResult `json:"data"`
+}
+
+// Rerank sends the candidates to the endpoint and orders them by the
+// returned relevance scores. Candidates the endpoint leaves out follow, in
+// their original order.
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, candidates []store.SearchResult) ([]store.SearchResult, error) {
+	reqBody := rerankRequest{Model: r.model, Query: query, Documents: make([]string, len(candidates))}
+	for i, c := range candidates {
+		reqBody.Documents[i] = rerankDocument(c.Chunk, maxRerankDocumentChars)
+	}
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rerank request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rerank request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.apiKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send rerank request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rerank response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rerank API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result rerankResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse rerank response: %w", err)
+	}
+	ranked := result.Results
+	if len(ranked) == 0 {
+		ranked = result.Data
+	}
+
+	reranked := make([]store.SearchResult, 0, len(candidates))
+	seen := make([]bool, len(candidates))
+	for _, res := range ranked {
+		if res.Index < 0 || res.Index >= len(candidates) || seen[res.Index] {
+			return nil, fmt.Errorf("rerank response has invalid index %d", res.Index)
+		}
+		seen[res.Index] = true
+		reranked = append(reranked, store.SearchResult{Chunk: candidates[res.Index].Chunk, Score: res.RelevanceScore})
+	}
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return reranked[i].Score > reranked[j].Score
+	})
+	for i, c := range candidates {
+		if !seen[i] {
+			reranked = append(reranked, store.SearchResult{Chunk: c.Chunk})
+		}
+	}
+	return reranked, nil
+}
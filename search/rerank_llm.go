@@ -0,0 +1,84 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+// maxLLMRerankDocumentChars truncates each candidate in the judge prompt,
+// which holds all of them at once.
+const maxLLMRerankDocumentChars = 1500
+
+const llmRerankSystemPrompt = "You are a code search relevance judge. Rate how well each numbered code snippet answers the search query, from 0 (unrelated) to 10 (exactly what was asked for). Output ONLY a JSON array of numbers, one per snippet, in snippet order."
+
+// Completer sends a prompt to a chat model and returns its reply.
+// rpg.LLMExtractor implements it.
+type Completer interface {
+	Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error)
+}
+
+// LLMReranker asks a chat model to grade every candidate against the query
+// in a single request.
+type LLMReranker struct {
+	llm Completer
+}
+
+// NewLLMReranker creates a reranker grading candidates with llm.
+func NewLLMReranker(llm Completer) *LLMReranker {
+	return &LLMReranker{llm: llm}
+}
+
+// llmRerankMaxTokens returns a reply budget for grading n candidates, a few
+// tokens per grade plus room for formatting.
+func llmRerankMaxTokens(n int) int {
+	return 32 + 6*n
+}
+
+// Rerank orders the candidates by the grades the model returns.
+func (r *LLMReranker) Rerank(ctx context.Context, query string, candidates []store.SearchResult) ([]store.SearchResult, error) {
+	reply, err := r.llm.Complete(ctx, llmRerankSystemPrompt, buildRerankPrompt(query, candidates))
+	if err != nil {
+		return nil, fmt.Errorf("failed to grade candidates: %w", err)
+	}
+	scores, err := parseRerankScores(reply, len(candidates))
+	if err != nil {
+		return nil, err
+	}
+	return reorderByScores(candidates, scores), nil
+}
+
+// buildRerankPrompt lists the query and the numbered candidates.
+func buildRerankPrompt(query string, candidates []store.SearchResult) string {
+	var sb strings.Builder
+	sb.WriteString("Query: " + query + "\n")
+	for i, c := range candidates {
+		fmt.Fprintf(&sb, "\n[%d] %s\n", i, rerankDocument(c.Chunk, maxLLMRerankDocumentChars))
+	}
+	fmt.Fprintf(&sb, "\nReturn a JSON array of %d numbers.", len(candidates))
+	return sb.String()
+}
+
+// parseRerankScores reads the JSON array of n grades in reply, which may be
+// wrapped in a Markdown code fence, and scales them to [0, 1].
+func parseRerankScores(reply string, n int) ([]float32, error) {
+	reply = strings.TrimSpace(reply)
+	if start, end := strings.Index(reply, "["), strings.LastIndex(reply, "]"); start >= 0 && end > start {
+		reply = reply[start : end+1]
+	}
+
+	var grades []float32
+	if err := json.Unmarshal([]byte(reply), &grades); err != nil {
+		return nil, fmt.Errorf("failed to parse grades: %w", err)
+	}
+	if len(grades) != n {
+		return nil, fmt.Errorf("expected %d grades, got %d", n, len(grades))
+	}
+	for i, g := range grades {
+		grades[i] = min(max(g, 0), 10) / 10
+	}
+	return grades, nil
+}
@@ -0,0 +1,225 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/store"
+)
+
+func rerankCandidates() []store.SearchResult {
+	return []store.SearchResult{
+		{Chunk: store.Chunk{ID: "a", FilePath: "util/strings.go", StartLine: 1, EndLine: 5, Content: "func padLeft(s string) string"}, Score: 0.9},
+		{Chunk: store.Chunk{ID: "b", FilePath: "auth/session.go", StartLine: 10, EndLine: 20, Content: "func refreshSession(token string) error"}, Score: 0.8},
+		{Chunk: store.Chunk{ID: "c", FilePath: "auth/login.go", StartLine: 3, EndLine: 9, Content: "func validatePassword(user, password string) bool"}, Score: 0.7},
+	}
+}
+
+func resultIDs(results []store.SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Chunk.ID
+	}
+	return ids
+}
+
+func TestHTTPReranker(t *testing.T) {
+	var got rerankRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/rerank" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("unexpected authorization header %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		// Index 0 is left out, as with a top_n smaller than the candidates.
+		_, _ = w.Write([]byte(`{"results":[{"index":2,"relevance_score":0.95},{"index":1,"relevance_score":0.4}]}`))
+	}))
+	defer server.Close()
+
+	r := NewHTTPReranker(server.URL+"/v1/", "rerank-v1", "secret", 0)
+	results, err := r.Rerank(context.Background(), "check user password", rerankCandidates())
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+
+	if got.Query != "check user password" || got.Model != "rerank-v1" || len(got.Documents) != 3 {
+		t.Errorf("unexpected request %+v", got)
+	}
+	if !strings.HasPrefix(got.Documents[2], "auth/login.go:3-9\n") {
+		t.Errorf("expected documents to start with the chunk location, got %q", got.Documents[2])
+	}
+	if ids := resultIDs(results); !reflect.DeepEqual(ids, []string{"c", "b", "a"}) {
+		t.Errorf("unexpected order %v", ids)
+	}
+	if results[0].Score != 0.95 {
+		t.Errorf("expected the reranker score, got %f", results[0].Score)
+	}
+}
+
+func TestHTTPReranker_Errors(t *testing.T) {
+	for name, handler := range map[string]http.HandlerFunc{
+		"status": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad model", http.StatusBadRequest)
+		},
+		"index": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"data":[{"index":7,"relevance_score":1}]}`))
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(handler)
+			defer server.Close()
+
+			if _, err := NewHTTPReranker(server.URL, "", "", 0).Rerank(context.Background(), "q", rerankCandidates()); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+type fakeCompleter struct {
+	reply  string
+	err    error
+	prompt string
+}
+
+func (f *fakeCompleter) Complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	f.prompt = userPrompt
+	return f.reply, f.err
+}
+
+func TestLLMReranker(t *testing.T) {
+	llm := &fakeCompleter{reply: "```json\n[1, 4, 9.5]\n```"}
+	results, err := NewLLMReranker(llm).Rerank(context.Background(), "check user password", rerankCandidates())
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if ids := resultIDs(results); !reflect.DeepEqual(ids, []string{"c", "b", "a"}) {
+		t.Errorf("unexpected order %v", ids)
+	}
+	if results[0].Score != 0.95 {
+		t.Errorf("expected grades scaled to [0, 1], got %f", results[0].Score)
+	}
+	if !strings.Contains(llm.prompt, "[2] auth/login.go:3-9") {
+		t.Errorf("expected numbered candidates in the prompt, got %q", llm.prompt)
+	}
+}
+
+func TestLLMReranker_BadReply(t *testing.T) {
+	for _, reply := range []string{"[1, 2]", "the second one", ""} {
+		if _, err := NewLLMReranker(&fakeCompleter{reply: reply}).Rerank(context.Background(), "q", rerankCandidates()); err == nil {
+			t.Errorf("expected an error for reply %q", reply)
+		}
+	}
+}
+
+func TestHeuristicReranker(t *testing.T) {
+	results, err := NewHeuristicReranker().Rerank(context.Background(), "validate password", rerankCandidates())
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if results[0].Chunk.ID != "c" {
+		t.Errorf("expected the chunk naming the query terms first, got %v", resultIDs(results))
+	}
+	if len(results) != 3 {
+		t.Errorf("expected every candidate back, got %d", len(results))
+	}
+}
+
+type failingReranker struct{}
+
+func (failingReranker) Rerank(ctx context.Context, query string, candidates []store.SearchResult) ([]store.SearchResult, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestSearcherRerank(t *testing.T) {
+	candidates := rerankCandidates()
+
+	s := NewSearcher(nil, nil, config.SearchConfig{}, WithReranker(NewLLMReranker(&fakeCompleter{reply: "[0, 10]"}), 2))
+	reranked := s.rerank(context.Background(), "q", candidates)
+	if ids := resultIDs(reranked); !reflect.DeepEqual(ids, []string{"b", "a", "c"}) {
+		t.Errorf("expected only the top 2 to be reordered, got %v", ids)
+	}
+	if reranked[2].Score >= reranked[1].Score {
+		t.Errorf("expected the unreranked tail to score below the reranked results, got %v then %v", reranked[1].Score, reranked[2].Score)
+	}
+	if candidates[2].Score != 0.7 {
+		t.Errorf("expected the input results to be left untouched, got score %v", candidates[2].Score)
+	}
+
+	s = NewSearcher(nil, nil, config.SearchConfig{}, WithReranker(failingReranker{}, 10))
+	if ids := resultIDs(s.rerank(context.Background(), "q", candidates)); !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+		t.Errorf("expected the original order when the reranker fails, got %v", ids)
+	}
+}
+
+func TestBelowScore(t *testing.T) {
+	tail := []store.SearchResult{{Score: 0.8}, {Score: 0.5}, {Score: 0.5}, {Score: 0.1}}
+
+	shifted := belowScore(tail, 0.2)
+	for i, r := range shifted {
+		if r.Score >= 0.2 {
+			t.Errorf("result %d: expected a score below 0.2, got %v", i, r.Score)
+		}
+		if i > 0 && r.Score > shifted[i-1].Score {
+			t.Errorf("result %d: expected the order to be kept, got %v after %v", i, r.Score, shifted[i-1].Score)
+		}
+	}
+	if shifted[1].Score != shifted[2].Score {
+		t.Errorf("expected ties to stay tied, got %v and %v", shifted[1].Score, shifted[2].Score)
+	}
+
+	if got := belowScore(tail, 1); !reflect.DeepEqual(got, tail) {
+		t.Errorf("expected a tail already below the limit to be unchanged, got %v", got)
+	}
+}
+
+func TestNewRerankerFromConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if r, err := NewRerankerFromConfig(cfg); r != nil || err != nil {
+		t.Errorf("expected no reranker when disabled, got %v, %v", r, err)
+	}
+
+	var path, model string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		model = body.Model
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"[2, 8]"}}]}`))
+	}))
+	defer server.Close()
+
+	// The llm provider reuses the RPG LLM settings.
+	cfg.Search.Rerank = config.RerankConfig{Enabled: true, Provider: "llm", TopN: 2}
+	cfg.RPG.LLMEndpoint = server.URL
+	cfg.RPG.LLMModel = "judge"
+	r, err := NewRerankerFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewRerankerFromConfig failed: %v", err)
+	}
+	results, err := r.Rerank(context.Background(), "q", rerankCandidates()[:2])
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if path != "/chat/completions" || model != "judge" || results[0].Chunk.ID != "b" {
+		t.Errorf("unexpected call to %s with model %q, order %v", path, model, resultIDs(results))
+	}
+
+	cfg.RPG.LLMModel = ""
+	if _, err := NewRerankerFromConfig(cfg); err == nil {
+		t.Error("expected an error without a model")
+	}
+}
@@ -13,35 +13,96 @@ type Searcher struct {
 	embedder  embedder.Embedder
 	boostCfg  config.BoostConfig
 	hybridCfg config.HybridConfig
+	booster   *Booster
+
+	// Optional file facts for boost rules beyond the path (nil when unset)
+	boostSignals BoostSignals
+
+	// Optional re-ranking stage (nil when disabled)
+	reranker   Reranker
+	rerankTopN int
+
+	// MMR relevance weight (0 when diversification is disabled)
+	diversityLambda float64
 }
 
-func NewSearcher(st store.VectorStore, emb embedder.Embedder, searchCfg config.SearchConfig) *Searcher {
-	return &Searcher{
+func NewSearcher(st store.VectorStore, emb embedder.Embedder, searchCfg config.SearchConfig, opts ...SearcherOption) *Searcher {
+	s := &Searcher{
 		store:     st,
 		embedder:  emb,
 		boostCfg:  searchCfg.Boost,
 		hybridCfg: searchCfg.Hybrid,
 	}
+	if searchCfg.Diversity.Enabled {
+		WithDiversity(searchCfg.Diversity.Lambda)(s)
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.booster = NewBooster(s.boostCfg, s.boostSignals)
+	return s
+}
+
+// WithBoostSignals lets boost rules test file sizes, recency and symbol
+// kinds through signals.
+func WithBoostSignals(signals BoostSignals) SearcherOption {
+	return func(s *Searcher) {
+		s.boostSignals = signals
+	}
+}
+
+// ExplainBoost returns the boost rules matching each result.
+func (s *Searcher) ExplainBoost(ctx context.Context, results []store.SearchResult) [][]BoostMatch {
+	return s.booster.Explain(ctx, results)
 }
 
 func (s *Searcher) Search(ctx context.Context, query string, limit int, pathPrefix string) ([]store.SearchResult, error) {
+	return s.SearchWithOptions(ctx, query, limit, store.SearchOptions{PathPrefix: pathPrefix})
+}
+
+// SearchWithOptions searches like Search, restricting results with the
+// metadata filters in opts.
+func (s *Searcher) SearchWithOptions(ctx context.Context, query string, limit int, opts store.SearchOptions) ([]store.SearchResult, error) {
+	// Fetch more results to allow re-ranking
+	results, err := s.rankedResults(ctx, query, limit*2, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Trim to requested limit
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// rankedResults returns up to fetchLimit candidates for the query, boosted,
+// re-ranked, with overlapping hits of a file merged and, when enabled,
+// diversified.
+func (s *Searcher) rankedResults(ctx context.Context, query string, fetchLimit int, opts store.SearchOptions) ([]store.SearchResult, error) {
 	// Embed the query
 	queryVector, err := s.embedder.Embed(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	// Fetch more results to allow re-ranking
-	fetchLimit := limit * 2
+	if s.diversityLambda > 0 {
+		// Give diversification alternatives to the near-duplicates it demotes
+		fetchLimit *= 2
+	}
+	if s.reranker != nil {
+		fetchLimit = max(fetchLimit, s.rerankTopN)
+	}
 
 	var results []store.SearchResult
 
 	if s.hybridCfg.Enabled {
 		// Hybrid search: combine vector + text search with RRF
-		results, err = s.hybridSearch(ctx, query, queryVector, fetchLimit, pathPrefix)
+		results, err = s.hybridSearch(ctx, query, queryVector, fetchLimit, opts)
 	} else {
 		// Vector-only search
-		results, err = s.store.Search(ctx, queryVector, fetchLimit, store.SearchOptions{PathPrefix: pathPrefix})
+		results, err = s.store.Search(ctx, queryVector, fetchLimit, opts)
 	}
 
 	if err != nil {
@@ -49,32 +110,35 @@ func (s *Searcher) Search(ctx context.Context, query string, limit int, pathPref
 	}
 
 	// Apply structural boosting
-	results = ApplyBoost(results, s.boostCfg)
+	results = s.booster.Apply(ctx, results)
 
-	// Trim to requested limit
-	if len(results) > limit {
-		results = results[:limit]
+	// Re-score the best candidates
+	results = s.rerank(ctx, query, results)
+
+	// Return each region of a file once
+	results = MergeAdjacent(results)
+
+	if s.diversityLambda > 0 {
+		results = Diversify(results, s.diversityLambda)
 	}
 
 	return results, nil
 }
 
 // hybridSearch combines vector search and text search using RRF.
-func (s *Searcher) hybridSearch(ctx context.Context, query string, queryVector []float32, limit int, pathPrefix string) ([]store.SearchResult, error) {
+func (s *Searcher) hybridSearch(ctx context.Context, query string, queryVector []float32, limit int, opts store.SearchOptions) ([]store.SearchResult, error) {
 	// Vector search
-	vectorResults, err := s.store.Search(ctx, queryVector, limit, store.SearchOptions{PathPrefix: pathPrefix})
+	vectorResults, err := s.store.Search(ctx, queryVector, limit, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	// Text search (get all chunks first)
-	allChunks, err := s.store.GetAllChunks(ctx)
+	// Text search
+	textResults, err := s.textSearch(ctx, query, limit, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	textResults := TextSearch(ctx, allChunks, query, limit, pathPrefix)
-
 	// Combine with RRF
 	k := s.hybridCfg.K
 	if k <= 0 {
@@ -83,3 +147,23 @@ func (s *Searcher) hybridSearch(ctx context.Context, query string, queryVector [
 
 	return ReciprocalRankFusion(k, limit, vectorResults, textResults), nil
 }
+
+// textSearch ranks chunks against the query with BM25, using the store's
+// full-text index when it keeps one and loading every chunk otherwise.
+func (s *Searcher) textSearch(ctx context.Context, query string, limit int, opts store.SearchOptions) ([]store.SearchResult, error) {
+	if ts, ok := s.store.(store.TextSearcher); ok {
+		return ts.TextSearch(ctx, query, limit, opts)
+	}
+
+	allChunks, err := s.store.GetAllChunks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if opts.HasFilters() {
+		allChunks, err = store.FilterChunks(ctx, s.store, allChunks, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return TextSearch(ctx, allChunks, query, limit, opts.PathPrefix), nil
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/git"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+// ProjectSignals provides boost signals for a local project: file sizes from
+// disk, recency from git history and symbol kinds from the trace index, in
+// symbols.gob or the PostgreSQL workspace store like grepai trace reads it.
+// The trace index is loaded on first use.
+type ProjectSignals struct {
+	root string
+
+	mu          sync.Mutex
+	symbols     trace.SymbolStore
+	symbolsDone bool
+	fileSymbols map[string][]trace.Symbol
+}
+
+// NewProjectSignals returns the boost signals of the project at root.
+func NewProjectSignals(root string) *ProjectSignals {
+	return &ProjectSignals{root: root, fileSymbols: make(map[string][]trace.Symbol)}
+}
+
+// FileSize implements BoostSignals.
+func (p *ProjectSignals) FileSize(path string) (int64, bool) {
+	info, err := os.Stat(filepath.Join(p.root, filepath.FromSlash(path)))
+	if err != nil || !info.Mode().IsRegular() {
+		return 0, false
+	}
+	return info.Size(), true
+}
+
+// LastModified implements BoostSignals with the last commit touching each
+// file. Files with uncommitted changes count as modified now. Outside a git
+// repository no file is reported.
+func (p *ProjectSignals) LastModified(ctx context.Context, paths []string, since time.Time) (map[string]time.Time, error) {
+	if !git.IsGitRepo(p.root) {
+		return nil, nil
+	}
+
+	gitPaths := make([]string, len(paths))
+	for i, path := range paths {
+		gitPaths[i] = filepath.ToSlash(path)
+	}
+	committed, err := git.LastCommitTimes(p.root, gitPaths, since)
+	if err != nil {
+		return nil, err
+	}
+
+	modified := make(map[string]time.Time, len(committed))
+	for i, path := range paths {
+		if t, ok := committed[gitPaths[i]]; ok {
+			modified[path] = t
+		}
+	}
+
+	// Uncommitted changes are the most recent of all.
+	if head, err := git.HeadCommit(p.root); err == nil {
+		if changes, err := git.ChangedFiles(p.root, head); err == nil {
+			now := time.Now()
+			for _, c := range changes {
+				if i := slices.Index(gitPaths, c.Path); i >= 0 {
+					modified[paths[i]] = now
+				}
+			}
+		}
+	}
+	return modified, nil
+}
+
+// SymbolKinds implements BoostSignals with the symbols of the trace index.
+func (p *ProjectSignals) SymbolKinds(ctx context.Context, path string, startLine, endLine int) ([]string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	symbols, ok := p.fileSymbols[path]
+	if !ok {
+		st := p.loadSymbols(ctx)
+		if st == nil {
+			return nil, false
+		}
+		var err error
+		if symbols, err = st.GetSymbolsForFile(ctx, path); err != nil {
+			return nil, false
+		}
+		p.fileSymbols[path] = symbols
+	}
+
+	var kinds []string
+	for _, sym := range symbols {
+		if sym.Line < startLine || sym.Line > endLine {
+			continue
+		}
+		if kind := string(sym.Kind); !slices.Contains(kinds, kind) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, true
+}
+
+// loadSymbols opens the trace index once. Caller must hold p.mu.
+func (p *ProjectSignals) loadSymbols(ctx context.Context) trace.SymbolStore {
+	if !p.symbolsDone {
+		p.symbolsDone = true
+		st, err := trace.NewProjectSymbolStore(ctx, p.root)
+		if err != nil {
+			log.Printf("Warning: symbol kind boosts unavailable: %v", err)
+			return nil
+		}
+		if st == nil {
+			return nil
+		}
+		if err := st.Load(ctx); err != nil {
+			trace.Discard(st)
+			log.Printf("Warning: symbol kind boosts unavailable: %v", err)
+			return nil
+		}
+		p.symbols = st
+	}
+	return p.symbols
+}
+
+// Close releases the trace index. It is only read, so it is discarded
+// rather than closed, which would rewrite a GOB index.
+func (p *ProjectSignals) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.symbols != nil {
+		trace.Discard(p.symbols)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/trace"
+)
+
+func TestProjectSignals_SymbolKinds(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	indexPath := config.GetSymbolIndexPath(root)
+
+	st := trace.NewGOBSymbolStore(indexPath)
+	if err := st.SaveFile(ctx, "main.go", []trace.Symbol{
+		{Name: "main", Kind: trace.KindFunction, File: "main.go", Line: 3},
+		{Name: "Server", Kind: trace.KindClass, File: "main.go", Line: 20},
+	}, nil); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+	if err := st.Persist(ctx); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	before, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signals := NewProjectSignals(root)
+	kinds, ok := signals.SymbolKinds(ctx, "main.go", 1, 10)
+	if !ok || !reflect.DeepEqual(kinds, []string{"function"}) {
+		t.Errorf("expected [function], got %v (ok=%v)", kinds, ok)
+	}
+	if err := signals.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	after, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("expected Close to leave the symbol index untouched")
+	}
+}
+
+func TestProjectSignals_NoSymbolIndex(t *testing.T) {
+	signals := NewProjectSignals(t.TempDir())
+	defer signals.Close()
+
+	if kinds, ok := signals.SymbolKinds(context.Background(), "main.go", 1, 10); ok {
+		t.Errorf("expected no symbol kinds without a trace index, got %v", kinds)
+	}
+}
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+)
+
+// EmbeddingCacheWriter is an optional interface for EmbeddingCache
+// implementations that record the embeddings the indexer computes.
+type EmbeddingCacheWriter interface {
+	// StoreEmbeddings records vectors keyed by content hash.
+	StoreEmbeddings(ctx context.Context, vectors map[string][]float32) error
+}
+
+// embedCacheEntryOverhead approximates the per-row cost of keys and
+// bookkeeping columns on top of the vector bytes.
+const embedCacheEntryOverhead = 128
+
+// evictionTarget is the fraction of the size cap eviction shrinks the cache
+// to, so a full cache does not evict on every write.
+const evictionTarget = 0.9
+
+// DiskEmbeddingCache is a content-addressed embedding cache shared by every
+// project on the machine. Vectors are keyed by the embedder (provider, model
+// and dimensions) and the SHA256 of the chunk content, and are kept in a
+// SQLite database whose size is capped by evicting the least recently used
+// entries.
+type DiskEmbeddingCache struct {
+	db       *sql.DB
+	modelKey string
+	dims     int
+	maxBytes int64
+
+	// size tracks the approximate cache size between evictions. Other
+	// processes write to the same database, so it is re-read from the
+	// database before evicting.
+	mu   sync.Mutex
+	size int64
+}
+
+// NewDiskEmbeddingCache opens (or creates) the cache database at path for
+// vectors produced by emb. maxBytes caps the total size of all entries,
+// across embedders; zero or less disables eviction.
+func NewDiskEmbeddingCache(ctx context.Context, path string, emb config.EmbedderConfig, maxBytes int64) (*DiskEmbeddingCache, error) {
+	if err := fileutil.EnsureParentDir(path); err != nil {
+		return nil, fmt.Errorf("failed to prepare embedding cache directory: %w", err)
+	}
+
+	// Several watchers share the cache, so wait on locks instead of failing.
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedding cache: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	c := &DiskEmbeddingCache{
+		db:       db,
+		modelKey: embeddingModelKey(emb),
+		dims:     emb.GetDimensions(),
+		maxBytes: maxBytes,
+	}
+	if err := c.ensureSchema(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(SUM(size), 0) FROM embeddings`).Scan(&c.size); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to read embedding cache size: %w", err)
+	}
+	return c, nil
+}
+
+// embeddingModelKey identifies the vector space of an embedder. The endpoint
+// is left out so the same model served from different hosts shares entries.
+func embeddingModelKey(emb config.EmbedderConfig) string {
+	sum := sha256.Sum256([]byte(emb.Provider + "\x00" + emb.Model + "\x00" + strconv.Itoa(emb.GetDimensions())))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (c *DiskEmbeddingCache) ensureSchema(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS embeddings (
+			model_key TEXT NOT NULL,
+			content_hash TEXT NOT NULL,
+			vector BLOB NOT NULL,
+			size INTEGER NOT NULL,
+			last_used INTEGER NOT NULL,
+			PRIMARY KEY (model_key, content_hash)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_embeddings_last_used ON embeddings(last_used)`,
+	}
+
+	for _, query := range queries {
+		if _, err := c.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to create embedding cache schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// LookupByContentHash returns the cached vector for contentHash and marks the
+// entry as recently used.
+func (c *DiskEmbeddingCache) LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error) {
+	if contentHash == "" {
+		return nil, false, nil
+	}
+
+	var buf []byte
+	err := c.db.QueryRowContext(ctx,
+		`UPDATE embeddings SET last_used = ? WHERE model_key = ? AND content_hash = ? RETURNING vector`,
+		time.Now().UnixNano(), c.modelKey, contentHash,
+	).Scan(&buf)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lookup cached embedding: %w", err)
+	}
+
+	vec := decodeVector(buf)
+	if len(vec) != c.dims {
+		return nil, false, nil
+	}
+	return vec, true, nil
+}
+
+// StoreEmbeddings records vectors keyed by content hash, then evicts the
+// least recently used entries if the cache grew past its size cap. Vectors
+// whose length does not match the embedder dimensions are skipped.
+func (c *DiskEmbeddingCache) StoreEmbeddings(ctx context.Context, vectors map[string][]float32) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO embeddings (model_key, content_hash, vector, size, last_used) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (model_key, content_hash) DO UPDATE SET last_used = excluded.last_used`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare embedding cache insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().UnixNano()
+	var added int64
+	for hash, vec := range vectors {
+		if hash == "" || len(vec) != c.dims {
+			continue
+		}
+		buf := encodeVector(vec)
+		size := int64(len(buf)) + embedCacheEntryOverhead
+		if _, err := stmt.ExecContext(ctx, c.modelKey, hash, buf, size, now); err != nil {
+			return fmt.Errorf("failed to store cached embedding: %w", err)
+		}
+		added += size
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit embedding cache: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size += added
+	if c.maxBytes > 0 && c.size > c.maxBytes {
+		return c.evictLocked(ctx)
+	}
+	return nil
+}
+
+// evictLocked removes the least recently used entries until the cache is
+// back under evictionTarget of its cap.
+func (c *DiskEmbeddingCache) evictLocked(ctx context.Context) error {
+	var total int64
+	if err := c.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(size), 0) FROM embeddings`).Scan(&total); err != nil {
+		return fmt.Errorf("failed to read embedding cache size: %w", err)
+	}
+	c.size = total
+	if total <= c.maxBytes {
+		return nil
+	}
+
+	target := int64(float64(c.maxBytes) * evictionTarget)
+	rows, err := c.db.QueryContext(ctx, `SELECT rowid, size FROM embeddings ORDER BY last_used`)
+	if err != nil {
+		return fmt.Errorf("failed to list embedding cache entries: %w", err)
+	}
+	var victims []int64
+	for rows.Next() && total > target {
+		var rowid, size int64
+		if err := rows.Scan(&rowid, &size); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan embedding cache entry: %w", err)
+		}
+		victims = append(victims, rowid)
+		total -= size
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to list embedding cache entries: %w", err)
+	}
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	stmt, err := tx.PrepareContext(ctx, `DELETE FROM embeddings WHERE rowid = ?`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare embedding cache eviction: %w", err)
+	}
+	defer stmt.Close()
+	for _, rowid := range victims {
+		if _, err := stmt.ExecContext(ctx, rowid); err != nil {
+			return fmt.Errorf("failed to evict cached embedding: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit embedding cache eviction: %w", err)
+	}
+
+	c.size = total
+	return nil
+}
+
+// Size returns the approximate size of all cached entries in bytes.
+func (c *DiskEmbeddingCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// Close closes the cache database.
+func (c *DiskEmbeddingCache) Close() error {
+	return c.db.Close()
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func testEmbedderConfig(model string, dims int) config.EmbedderConfig {
+	return config.EmbedderConfig{Provider: "ollama", Model: model, Dimensions: &dims}
+}
+
+func TestDiskEmbeddingCache_StoreAndLookup(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache", "embeddings.db")
+
+	cache, err := NewDiskEmbeddingCache(ctx, path, testEmbedderConfig("nomic", 3), 0)
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+	if err := cache.StoreEmbeddings(ctx, map[string][]float32{
+		"hash-a": {0.1, 0.2, 0.3},
+		"hash-b": {1, 2}, // wrong dimensions, skipped
+	}); err != nil {
+		t.Fatalf("StoreEmbeddings failed: %v", err)
+	}
+
+	vec, found, err := cache.LookupByContentHash(ctx, "hash-a")
+	if err != nil || !found {
+		t.Fatalf("expected hash-a to be cached, found=%v err=%v", found, err)
+	}
+	if len(vec) != 3 || vec[2] != 0.3 {
+		t.Errorf("unexpected vector %v", vec)
+	}
+	if _, found, _ := cache.LookupByContentHash(ctx, "hash-b"); found {
+		t.Error("vector with wrong dimensions should not be cached")
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// Another project with the same embedder shares entries; another model
+	// does not.
+	same, err := NewDiskEmbeddingCache(ctx, path, testEmbedderConfig("nomic", 3), 0)
+	if err != nil {
+		t.Fatalf("failed to reopen cache: %v", err)
+	}
+	defer same.Close()
+	if _, found, _ := same.LookupByContentHash(ctx, "hash-a"); !found {
+		t.Error("reopened cache should find hash-a")
+	}
+
+	other, err := NewDiskEmbeddingCache(ctx, path, testEmbedderConfig("mxbai", 3), 0)
+	if err != nil {
+		t.Fatalf("failed to open cache for other model: %v", err)
+	}
+	defer other.Close()
+	if _, found, _ := other.LookupByContentHash(ctx, "hash-a"); found {
+		t.Error("cache entries must not leak across models")
+	}
+}
+
+func TestDiskEmbeddingCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "embeddings.db")
+
+	vec := []float32{0.1, 0.2, 0.3, 0.4}
+	entrySize := int64(4*len(vec)) + embedCacheEntryOverhead
+	cache, err := NewDiskEmbeddingCache(ctx, path, testEmbedderConfig("nomic", len(vec)), 3*entrySize)
+	if err != nil {
+		t.Fatalf("failed to open cache: %v", err)
+	}
+	defer cache.Close()
+
+	for _, hash := range []string{"old", "used", "mid"} {
+		if err := cache.StoreEmbeddings(ctx, map[string][]float32{hash: vec}); err != nil {
+			t.Fatalf("StoreEmbeddings(%s) failed: %v", hash, err)
+		}
+	}
+	// Touching "used" makes "old" the least recently used entry.
+	if _, found, _ := cache.LookupByContentHash(ctx, "used"); !found {
+		t.Fatal("expected used to be cached")
+	}
+
+	if err := cache.StoreEmbeddings(ctx, map[string][]float32{"new": vec}); err != nil {
+		t.Fatalf("StoreEmbeddings(new) failed: %v", err)
+	}
+
+	if _, found, _ := cache.LookupByContentHash(ctx, "old"); found {
+		t.Error("least recently used entry should have been evicted")
+	}
+	for _, hash := range []string{"used", "new"} {
+		if _, found, _ := cache.LookupByContentHash(ctx, hash); !found {
+			t.Errorf("%s should still be cached", hash)
+		}
+	}
+	if size := cache.Size(); size > 3*entrySize {
+		t.Errorf("cache size %d exceeds cap %d", size, 3*entrySize)
+	}
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// NewFromConfig creates a VectorStore for the project based on the provided
+// configuration. Local stores are loaded before being returned so callers
+// can search immediately.
+func NewFromConfig(ctx context.Context, cfg *config.Config, projectRoot string) (VectorStore, error) {
+	switch cfg.Store.Backend {
+	case "gob":
+		quantization, err := ParseQuantization(cfg.Store.Quantization)
+		if err != nil {
+			return nil, err
+		}
+		opts := []GOBOption{WithQuantization(quantization)}
+		if hnsw := cfg.Store.GOB.HNSW; hnsw.Enabled {
+			opts = append(opts, WithHNSW(HNSWParams{
+				M:              hnsw.M,
+				EfConstruction: hnsw.EfConstruction,
+				EfSearch:       hnsw.EfSearch,
+				MinChunks:      hnsw.MinChunks,
+			}))
+		}
+		gobStore := NewGOBStore(config.GetIndexPath(projectRoot), opts...)
+		if err := gobStore.Load(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load index: %w", err)
+		}
+		return gobStore, nil
+	case "sqlite":
+		quantization, err := ParseQuantization(cfg.Store.Quantization)
+		if err != nil {
+			return nil, err
+		}
+		sqliteStore, err := NewSQLiteStore(ctx, config.GetSQLiteIndexPath(projectRoot), WithSQLiteQuantization(quantization))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sqlite index: %w", err)
+		}
+		return sqliteStore, nil
+	case "postgres":
+		return NewPostgresStore(ctx, cfg.Store.Postgres.DSN, projectRoot, cfg.Embedder.GetDimensions())
+	case "qdrant":
+		collectionName := cfg.Store.Qdrant.Collection
+		if collectionName == "" {
+			collectionName = SanitizeCollectionName(projectRoot)
+		}
+		return NewQdrantStore(ctx, cfg.Store.Qdrant.Endpoint, cfg.Store.Qdrant.Port, cfg.Store.Qdrant.UseTLS, collectionName, cfg.Store.Qdrant.APIKey, cfg.Embedder.GetDimensions())
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", cfg.Store.Backend)
+	}
+}
@@ -0,0 +1,395 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// languageExtensions maps the language names accepted by SearchOptions.Languages
+// to the file extensions they cover.
+var languageExtensions = map[string][]string{
+	"c":          {".c", ".h"},
+	"clojure":    {".clj"},
+	"cpp":        {".cpp", ".cc", ".cxx", ".hpp", ".hxx"},
+	"csharp":     {".cs"},
+	"css":        {".css", ".scss", ".less"},
+	"dart":       {".dart"},
+	"elixir":     {".ex", ".exs"},
+	"elm":        {".elm"},
+	"erlang":     {".erl"},
+	"fsharp":     {".fs", ".fsx", ".fsi"},
+	"go":         {".go"},
+	"haskell":    {".hs"},
+	"hcl":        {".tf", ".hcl"},
+	"html":       {".html"},
+	"java":       {".java"},
+	"javascript": {".js", ".jsx", ".mjs", ".cjs"},
+	"json":       {".json"},
+	"kotlin":     {".kt"},
+	"lua":        {".lua"},
+	"markdown":   {".md"},
+	"nim":        {".nim"},
+	"ocaml":      {".ml"},
+	"pascal":     {".pas", ".dpr"},
+	"php":        {".php"},
+	"protobuf":   {".proto"},
+	"python":     {".py"},
+	"r":          {".r"},
+	"ruby":       {".rb"},
+	"rust":       {".rs"},
+	"scala":      {".scala"},
+	"shell":      {".sh", ".bash", ".zsh"},
+	"sql":        {".sql"},
+	"svelte":     {".svelte"},
+	"swift":      {".swift"},
+	"toml":       {".toml"},
+	"typescript": {".ts", ".tsx"},
+	"vue":        {".vue"},
+	"xml":        {".xml"},
+	"yaml":       {".yaml", ".yml"},
+	"zig":        {".zig"},
+}
+
+// languageAliases lets users type common short names.
+var languageAliases = map[string]string{
+	"golang": "go",
+	"js":     "javascript",
+	"ts":     "typescript",
+	"py":     "python",
+	"rb":     "ruby",
+	"rs":     "rust",
+	"c++":    "cpp",
+	"cs":     "csharp",
+	"c#":     "csharp",
+	"f#":     "fsharp",
+	"kt":     "kotlin",
+	"sh":     "shell",
+	"bash":   "shell",
+	"md":     "markdown",
+	"yml":    "yaml",
+}
+
+var extensionLanguages = func() map[string]string {
+	m := make(map[string]string)
+	for lang, exts := range languageExtensions {
+		for _, ext := range exts {
+			m[ext] = lang
+		}
+	}
+	return m
+}()
+
+// LanguageForPath returns the language name for a file path based on its
+// extension, or "" if the extension is not recognized.
+func LanguageForPath(path string) string {
+	return extensionLanguages[strings.ToLower(filepath.Ext(path))]
+}
+
+// NormalizeLanguage resolves a user-supplied language name or alias.
+func NormalizeLanguage(name string) (string, error) {
+	lang := strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := languageAliases[lang]; ok {
+		lang = alias
+	}
+	if _, ok := languageExtensions[lang]; !ok {
+		return "", fmt.Errorf("unknown language %q (supported: %s)", name, strings.Join(SupportedLanguages(), ", "))
+	}
+	return lang, nil
+}
+
+// SupportedLanguages returns the sorted list of language names accepted by
+// SearchOptions.Languages.
+func SupportedLanguages() []string {
+	langs := make([]string, 0, len(languageExtensions))
+	for lang := range languageExtensions {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// SymbolKinds lists the values accepted by SearchOptions.SymbolKinds.
+var SymbolKinds = []string{"function", "method", "class", "interface", "type"}
+
+// globToRegexp translates a path glob into an anchored regular expression
+// that both Go's regexp package and PostgreSQL's ~ operator understand.
+//
+// Supported syntax: * (any run of characters except /), ** (any run of
+// characters including /), ? (one character except /) and {a,b}
+// alternation. A pattern without a slash matches the file name in any
+// directory, like .gitignore.
+func globToRegexp(pattern string) (string, error) {
+	if pattern == "" {
+		return "", fmt.Errorf("empty glob pattern")
+	}
+
+	var b strings.Builder
+	if strings.Contains(pattern, "/") {
+		b.WriteString("^")
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else {
+		b.WriteString("(^|/)")
+	}
+
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more directories
+					i++
+					b.WriteString("(.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '{':
+			depth++
+			b.WriteString("(")
+		case '}':
+			if depth == 0 {
+				return "", fmt.Errorf("invalid glob %q: unmatched '}'", pattern)
+			}
+			depth--
+			b.WriteString(")")
+		case ',':
+			if depth > 0 {
+				b.WriteString("|")
+			} else {
+				b.WriteByte(c)
+			}
+		default:
+			if strings.ContainsRune(`\.+()|[]^$`, rune(c)) {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		}
+	}
+	if depth != 0 {
+		return "", fmt.Errorf("invalid glob %q: unmatched '{'", pattern)
+	}
+
+	b.WriteString("$")
+	return b.String(), nil
+}
+
+// CompileGlob compiles a path glob with the syntax of
+// SearchOptions.IncludeGlobs.
+func CompileGlob(pattern string) (*regexp.Regexp, error) {
+	expr, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile(expr)
+}
+
+// globsToRegexp combines several globs into a single alternation.
+func globsToRegexp(patterns []string) (string, error) {
+	parts := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := globToRegexp(pattern)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "("+re+")")
+	}
+	return strings.Join(parts, "|"), nil
+}
+
+// languagesToRegexp builds a regexp matching the file extensions of the
+// given (normalized) languages. Callers match it case-insensitively.
+func languagesToRegexp(languages []string) string {
+	var exts []string
+	for _, lang := range languages {
+		for _, ext := range languageExtensions[lang] {
+			exts = append(exts, regexp.QuoteMeta(strings.TrimPrefix(ext, ".")))
+		}
+	}
+	return `\.(` + strings.Join(exts, "|") + `)$`
+}
+
+// HasFilters reports whether any filter besides PathPrefix is set.
+func (o SearchOptions) HasFilters() bool {
+	return len(o.Languages) > 0 || len(o.IncludeGlobs) > 0 || len(o.ExcludeGlobs) > 0 ||
+		!o.ModifiedAfter.IsZero() || !o.ModifiedBefore.IsZero() || len(o.SymbolKinds) > 0
+}
+
+// Validate normalizes language names and checks globs and symbol kinds.
+func (o SearchOptions) Validate() (SearchOptions, error) {
+	if len(o.Languages) > 0 {
+		langs := make([]string, 0, len(o.Languages))
+		for _, name := range o.Languages {
+			lang, err := NormalizeLanguage(name)
+			if err != nil {
+				return o, err
+			}
+			langs = append(langs, lang)
+		}
+		o.Languages = langs
+	}
+	for _, pattern := range append(append([]string{}, o.IncludeGlobs...), o.ExcludeGlobs...) {
+		if _, err := globToRegexp(pattern); err != nil {
+			return o, err
+		}
+	}
+	for _, kind := range o.SymbolKinds {
+		if !slices.Contains(SymbolKinds, kind) {
+			return o, fmt.Errorf("unknown symbol kind %q (supported: %s)", kind, strings.Join(SymbolKinds, ", "))
+		}
+	}
+	if !o.ModifiedAfter.IsZero() && !o.ModifiedBefore.IsZero() && !o.ModifiedAfter.Before(o.ModifiedBefore) {
+		return o, fmt.Errorf("modified-after must be earlier than modified-before")
+	}
+	return o, nil
+}
+
+// chunkFilter evaluates SearchOptions in memory for stores that cannot push
+// the filters down to a query engine.
+type chunkFilter struct {
+	opts      SearchOptions
+	languages map[string]bool
+	include   *regexp.Regexp
+	exclude   *regexp.Regexp
+}
+
+func newChunkFilter(opts SearchOptions) (*chunkFilter, error) {
+	opts, err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	f := &chunkFilter{opts: opts}
+	if len(opts.Languages) > 0 {
+		f.languages = make(map[string]bool, len(opts.Languages))
+		for _, lang := range opts.Languages {
+			f.languages[lang] = true
+		}
+	}
+	if len(opts.IncludeGlobs) > 0 {
+		expr, err := globsToRegexp(opts.IncludeGlobs)
+		if err != nil {
+			return nil, err
+		}
+		f.include = regexp.MustCompile(expr)
+	}
+	if len(opts.ExcludeGlobs) > 0 {
+		expr, err := globsToRegexp(opts.ExcludeGlobs)
+		if err != nil {
+			return nil, err
+		}
+		f.exclude = regexp.MustCompile(expr)
+	}
+	return f, nil
+}
+
+// matchPath applies the path prefix, language and glob filters.
+func (f *chunkFilter) matchPath(path string) bool {
+	if f.opts.PathPrefix != "" && !strings.HasPrefix(path, f.opts.PathPrefix) {
+		return false
+	}
+	if f.languages != nil && !f.languages[LanguageForPath(path)] {
+		return false
+	}
+	if f.include != nil && !f.include.MatchString(path) {
+		return false
+	}
+	if f.exclude != nil && f.exclude.MatchString(path) {
+		return false
+	}
+	return true
+}
+
+// hasTimeFilter reports whether matchModTime needs the file modification time.
+func (f *chunkFilter) hasTimeFilter() bool {
+	return !f.opts.ModifiedAfter.IsZero() || !f.opts.ModifiedBefore.IsZero()
+}
+
+// matchModTime applies the modified-after/before range. Files with an
+// unknown modification time never match a time filter.
+func (f *chunkFilter) matchModTime(modTime time.Time) bool {
+	if !f.hasTimeFilter() {
+		return true
+	}
+	if modTime.IsZero() {
+		return false
+	}
+	if !f.opts.ModifiedAfter.IsZero() && modTime.Before(f.opts.ModifiedAfter) {
+		return false
+	}
+	if !f.opts.ModifiedBefore.IsZero() && !modTime.Before(f.opts.ModifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// matchSymbolKinds keeps chunks defining at least one symbol of a requested kind.
+func (f *chunkFilter) matchSymbolKinds(kinds []string) bool {
+	if len(f.opts.SymbolKinds) == 0 {
+		return true
+	}
+	for _, kind := range kinds {
+		if slices.Contains(f.opts.SymbolKinds, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterChunks returns the chunks matching opts. It serves callers that rank
+// chunks outside the store, such as text search over GetAllChunks. File
+// modification times are read from st's documents; chunks whose document has
+// no modification time fall back to their index time.
+func FilterChunks(ctx context.Context, st VectorStore, chunks []Chunk, opts SearchOptions) ([]Chunk, error) {
+	filter, err := newChunkFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	modTimes := make(map[string]time.Time)
+	modTime := func(chunk Chunk) (time.Time, error) {
+		if t, ok := modTimes[chunk.FilePath]; ok {
+			return t, nil
+		}
+		doc, err := st.GetDocument(ctx, chunk.FilePath)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to read document %s: %w", chunk.FilePath, err)
+		}
+		t := chunk.UpdatedAt
+		if doc != nil && !doc.ModTime.IsZero() {
+			t = doc.ModTime
+		}
+		modTimes[chunk.FilePath] = t
+		return t, nil
+	}
+
+	filtered := make([]Chunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if !filter.matchPath(chunk.FilePath) || !filter.matchSymbolKinds(chunk.SymbolKinds) {
+			continue
+		}
+		if filter.hasTimeFilter() {
+			t, err := modTime(chunk)
+			if err != nil {
+				return nil, err
+			}
+			if !filter.matchModTime(t) {
+				continue
+			}
+		}
+		filtered = append(filtered, chunk)
+	}
+	return filtered, nil
+}
@@ -0,0 +1,202 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*_test.go", "pkg/store/gob_test.go", true},
+		{"*_test.go", "pkg/store/gob.go", false},
+		{"**/handlers/**", "services/api/handlers/user.go", true},
+		{"**/handlers/**", "handlers/user.go", true},
+		{"**/handlers/**", "services/api/handler.go", false},
+		{"services/*.go", "services/main.go", true},
+		{"services/*.go", "services/api/main.go", false},
+		{"services/**/*.go", "services/main.go", true},
+		{"services/**/*.go", "services/api/v1/main.go", true},
+		{"/cmd/**", "cmd/grepai/main.go", true},
+		{"*.{ts,tsx}", "web/app.tsx", true},
+		{"*.{ts,tsx}", "web/app.js", false},
+		{"file?.go", "file1.go", true},
+		{"a+b.go", "a+b.go", true},
+		{"a+b.go", "aab.go", false},
+	}
+	for _, tt := range tests {
+		expr, err := globToRegexp(tt.glob)
+		if err != nil {
+			t.Fatalf("globToRegexp(%q) failed: %v", tt.glob, err)
+		}
+		if got := regexp.MustCompile(expr).MatchString(tt.path); got != tt.match {
+			t.Errorf("glob %q on %q = %v, want %v (regexp %s)", tt.glob, tt.path, got, tt.match, expr)
+		}
+	}
+
+	if _, err := globToRegexp("*.{go"); err == nil {
+		t.Error("expected error for unbalanced brace")
+	}
+}
+
+func TestSearchOptionsValidate(t *testing.T) {
+	opts, err := SearchOptions{Languages: []string{"Golang", "ts"}}.Validate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Languages[0] != "go" || opts.Languages[1] != "typescript" {
+		t.Errorf("expected normalized languages, got %v", opts.Languages)
+	}
+
+	invalid := []SearchOptions{
+		{Languages: []string{"klingon"}},
+		{SymbolKinds: []string{"module"}},
+		{IncludeGlobs: []string{"{a"}},
+		{ModifiedAfter: time.Now(), ModifiedBefore: time.Now().Add(-time.Hour)},
+	}
+	for _, opts := range invalid {
+		if _, err := opts.Validate(); err == nil {
+			t.Errorf("expected validation error for %+v", opts)
+		}
+	}
+}
+
+// filterFixture is a small index with varied languages, paths, ages and symbols.
+func filterFixture() ([]Chunk, []Document) {
+	now := time.Now()
+	chunks := []Chunk{
+		{ID: "1", FilePath: "services/api/handlers/user.go", Vector: []float32{1, 0}, SymbolKinds: []string{"function"}},
+		{ID: "2", FilePath: "services/api/handlers/user_test.go", Vector: []float32{0.9, 0.1}, SymbolKinds: []string{"function"}},
+		{ID: "3", FilePath: "services/api/models.go", Vector: []float32{0.8, 0.2}, SymbolKinds: []string{"type", "method"}},
+		{ID: "4", FilePath: "web/app.ts", Vector: []float32{0.7, 0.3}, SymbolKinds: []string{"class"}},
+		{ID: "5", FilePath: "legacy/old.go", Vector: []float32{0.6, 0.4}},
+	}
+	docs := []Document{
+		{Path: "services/api/handlers/user.go", ModTime: now.Add(-24 * time.Hour), ChunkIDs: []string{"1"}},
+		{Path: "services/api/handlers/user_test.go", ModTime: now.Add(-24 * time.Hour), ChunkIDs: []string{"2"}},
+		{Path: "services/api/models.go", ModTime: now.Add(-10 * 24 * time.Hour), ChunkIDs: []string{"3"}},
+		{Path: "web/app.ts", ModTime: now.Add(-2 * time.Hour), ChunkIDs: []string{"4"}},
+		{Path: "legacy/old.go", ModTime: now.Add(-400 * 24 * time.Hour), ChunkIDs: []string{"5"}},
+	}
+	return chunks, docs
+}
+
+var filterCases = []struct {
+	name string
+	opts SearchOptions
+	want []string
+}{
+	{"language", SearchOptions{Languages: []string{"go"}}, []string{"1", "2", "3", "5"}},
+	{"include and exclude", SearchOptions{Languages: []string{"go"}, IncludeGlobs: []string{"services/**"}, ExcludeGlobs: []string{"*_test.go"}}, []string{"1", "3"}},
+	{"modified after", SearchOptions{ModifiedAfter: time.Now().Add(-48 * time.Hour)}, []string{"1", "2", "4"}},
+	{"modified before", SearchOptions{ModifiedBefore: time.Now().Add(-48 * time.Hour)}, []string{"3", "5"}},
+	{"symbol kinds", SearchOptions{SymbolKinds: []string{"method", "class"}}, []string{"3", "4"}},
+	{"prefix and kind", SearchOptions{PathPrefix: "services/", SymbolKinds: []string{"function"}}, []string{"1", "2"}},
+}
+
+func resultIDs(results []SearchResult) map[string]bool {
+	ids := make(map[string]bool, len(results))
+	for _, r := range results {
+		ids[r.Chunk.ID] = true
+	}
+	return ids
+}
+
+func assertResultIDs(t *testing.T, results []SearchResult, want []string) {
+	t.Helper()
+	ids := resultIDs(results)
+	if len(ids) != len(want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	for _, id := range want {
+		if !ids[id] {
+			t.Fatalf("expected %v, got %v", want, ids)
+		}
+	}
+}
+
+func TestGOBStore_SearchFilters(t *testing.T) {
+	ctx := context.Background()
+	s := NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	chunks, docs := filterFixture()
+	if err := s.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	for _, doc := range docs {
+		if err := s.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+
+	for _, tt := range filterCases {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := s.Search(ctx, []float32{1, 0}, 10, tt.opts)
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			assertResultIDs(t, results, tt.want)
+		})
+	}
+
+	if _, err := s.Search(ctx, []float32{1, 0}, 10, SearchOptions{Languages: []string{"klingon"}}); err == nil {
+		t.Error("expected error for unknown language")
+	}
+}
+
+func TestSQLiteStore_SearchFilters(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLiteStore(t)
+	chunks, docs := filterFixture()
+	if err := s.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	for _, doc := range docs {
+		if err := s.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+
+	for _, tt := range filterCases {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := s.Search(ctx, []float32{1, 0}, 10, tt.opts)
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			assertResultIDs(t, results, tt.want)
+		})
+	}
+
+	stored, err := s.GetChunksForFile(ctx, "services/api/models.go")
+	if err != nil || len(stored) != 1 || len(stored[0].SymbolKinds) != 2 {
+		t.Errorf("expected symbol kinds to round trip, got %+v, %v", stored, err)
+	}
+}
+
+func TestFilterChunks(t *testing.T) {
+	ctx := context.Background()
+	s := NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	chunks, docs := filterFixture()
+	for _, doc := range docs {
+		if err := s.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+
+	filtered, err := FilterChunks(ctx, s, chunks, SearchOptions{
+		Languages:     []string{"go"},
+		ModifiedAfter: time.Now().Add(-48 * time.Hour),
+		ExcludeGlobs:  []string{"*_test.go"},
+	})
+	if err != nil {
+		t.Fatalf("FilterChunks failed: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].ID != "1" {
+		t.Errorf("expected only chunk 1, got %+v", filtered)
+	}
+}
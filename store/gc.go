@@ -0,0 +1,215 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// ChunkDeleter is an optional interface for stores that can delete chunks by
+// ID. Documents referencing the deleted chunks are not updated.
+type ChunkDeleter interface {
+	DeleteChunks(ctx context.Context, ids []string) error
+}
+
+// Compactor is an optional interface for stores that can reclaim the space
+// left behind by deleted chunks (rewriting a file, VACUUM, ...).
+type Compactor interface {
+	Compact(ctx context.Context) error
+}
+
+// GCOptions configures GarbageCollect.
+type GCOptions struct {
+	// FileExists reports whether an indexed file still belongs to the
+	// project. Documents for files it rejects are removed with their chunks.
+	// When nil, only chunks no document references are collected.
+	FileExists func(path string) bool
+	// Compact reclaims storage space after collection when the store
+	// implements Compactor.
+	Compact bool
+	// CompactIfCollected limits Compact to runs that removed chunks, so
+	// periodic collections don't rewrite an index that did not change.
+	CompactIfCollected bool
+	// DryRun reports what would be collected without deleting anything.
+	DryRun bool
+}
+
+// GCResult summarizes a garbage collection run.
+type GCResult struct {
+	ChunksRemoved    int   `json:"chunks_removed"`
+	DocumentsRemoved int   `json:"documents_removed"`
+	BytesFreed       int64 `json:"bytes_freed"` // estimated size of the removed chunks
+	SizeBefore       int64 `json:"size_before"` // on-disk index size, 0 if unknown
+	SizeAfter        int64 `json:"size_after"`
+	Compacted        bool  `json:"compacted"`
+}
+
+// Reclaimed returns the on-disk space reclaimed, falling back to the
+// estimated size of the removed chunks for dry runs and for stores that do
+// not report their size.
+func (r *GCResult) Reclaimed() int64 {
+	if r.SizeBefore > 0 && r.SizeAfter > 0 {
+		return max(r.SizeBefore-r.SizeAfter, 0)
+	}
+	return r.BytesFreed
+}
+
+// GarbageCollect removes chunks that no document references and documents
+// (with their chunks) for files that no longer exist, then optionally
+// compacts the store. Vectors shared through EmbeddingCache live on chunks,
+// so this also drops embeddings whose content is no longer indexed. The
+// store is persisted unless opts.DryRun is set.
+func GarbageCollect(ctx context.Context, st VectorStore, opts GCOptions) (*GCResult, error) {
+	result := &GCResult{}
+
+	before, err := st.GetStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index stats: %w", err)
+	}
+	result.SizeBefore = before.IndexSize
+
+	paths, err := st.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	chunks, err := st.GetAllChunks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+	chunksByFile := make(map[string][]Chunk)
+	for _, chunk := range chunks {
+		chunksByFile[chunk.FilePath] = append(chunksByFile[chunk.FilePath], chunk)
+	}
+
+	tracked := TracksDocuments(st)
+	referenced := make(map[string]bool, len(chunks))
+	var stale []string
+	for _, path := range paths {
+		if opts.FileExists != nil && !opts.FileExists(path) {
+			stale = append(stale, path)
+			continue
+		}
+		if !tracked {
+			continue
+		}
+		doc, err := st.GetDocument(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document %s: %w", path, err)
+		}
+		if doc == nil {
+			continue
+		}
+		for _, id := range doc.ChunkIDs {
+			referenced[id] = true
+		}
+	}
+	sort.Strings(stale)
+
+	// Every chunk stored for a stale file goes with its document, whether
+	// the document references it or not.
+	staleChunkIDs := make(map[string][]string, len(stale))
+	for _, path := range stale {
+		result.DocumentsRemoved++
+		for _, chunk := range chunksByFile[path] {
+			result.ChunksRemoved++
+			result.BytesFreed += estimateChunkSize(chunk)
+			if tracked {
+				staleChunkIDs[path] = append(staleChunkIDs[path], chunk.ID)
+			}
+		}
+		delete(chunksByFile, path)
+	}
+
+	// Stores without document metadata cannot tell which chunks are
+	// referenced, so only stale files are collected there.
+	var orphans []Chunk
+	if tracked {
+		for _, fileChunks := range chunksByFile {
+			for _, chunk := range fileChunks {
+				if !referenced[chunk.ID] {
+					orphans = append(orphans, chunk)
+					result.ChunksRemoved++
+					result.BytesFreed += estimateChunkSize(chunk)
+				}
+			}
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	for _, path := range stale {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := PurgeFile(ctx, st, path, staleChunkIDs[path]); err != nil {
+			return nil, err
+		}
+	}
+	if err := deleteOrphanChunks(ctx, st, orphans); err != nil {
+		return nil, err
+	}
+
+	if err := st.Persist(ctx); err != nil {
+		return nil, fmt.Errorf("failed to persist index: %w", err)
+	}
+
+	compact := opts.Compact && (!opts.CompactIfCollected || result.ChunksRemoved > 0)
+	if c, ok := st.(Compactor); ok && compact {
+		if err := c.Compact(ctx); err != nil {
+			return nil, fmt.Errorf("failed to compact index: %w", err)
+		}
+		result.Compacted = true
+	}
+
+	after, err := st.GetStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index stats: %w", err)
+	}
+	result.SizeAfter = after.IndexSize
+
+	return result, nil
+}
+
+// deleteOrphanChunks removes chunks no document references. Stores without
+// ChunkDeleter can only drop orphans whose file has no document at all.
+func deleteOrphanChunks(ctx context.Context, st VectorStore, orphans []Chunk) error {
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(orphans))
+	for i, chunk := range orphans {
+		ids[i] = chunk.ID
+	}
+	if deleter, ok := st.(ChunkDeleter); ok {
+		if err := deleter.DeleteChunks(ctx, ids); err != nil {
+			return fmt.Errorf("failed to delete orphaned chunks: %w", err)
+		}
+		return nil
+	}
+
+	byFile := make(map[string][]string)
+	for _, chunk := range orphans {
+		byFile[chunk.FilePath] = append(byFile[chunk.FilePath], chunk.ID)
+	}
+	for path, fileIDs := range byFile {
+		doc, err := st.GetDocument(ctx, path)
+		if err != nil {
+			return fmt.Errorf("failed to read document %s: %w", path, err)
+		}
+		if doc != nil {
+			continue
+		}
+		if err := PurgeFile(ctx, st, path, fileIDs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// estimateChunkSize approximates the storage used by a chunk.
+func estimateChunkSize(chunk Chunk) int64 {
+	return int64(len(chunk.ID) + len(chunk.FilePath) + len(chunk.Content) + len(chunk.Hash) + len(chunk.ContentHash) + 4*len(chunk.Vector))
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestGarbageCollect(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		open func(t *testing.T, dir string) VectorStore
+	}{
+		{
+			name: "gob",
+			open: func(t *testing.T, dir string) VectorStore {
+				return NewGOBStore(filepath.Join(dir, "index.gob"), WithHNSW(HNSWParams{}))
+			},
+		},
+		{
+			name: "sqlite",
+			open: func(t *testing.T, dir string) VectorStore {
+				st, err := NewSQLiteStore(ctx, filepath.Join(dir, "index.db"))
+				if err != nil {
+					t.Fatalf("failed to open sqlite store: %v", err)
+				}
+				t.Cleanup(func() { _ = st.Close() })
+				return st
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := tt.open(t, t.TempDir())
+			if err := st.SaveChunks(ctx, []Chunk{
+				{ID: "keep_0", FilePath: "keep.go", Content: "func Keep() {}", Vector: []float32{1, 0}},
+				{ID: "keep_old", FilePath: "keep.go", Content: "func Old() {}", Vector: []float32{1, 1}},
+				{ID: "gone_0", FilePath: "gone.go", Content: "func Gone() {}", Vector: []float32{0, 1}},
+				{ID: "orphan_0", FilePath: "orphan.go", Content: "func Orphan() {}", Vector: []float32{0.5, 0.5}},
+			}); err != nil {
+				t.Fatalf("SaveChunks failed: %v", err)
+			}
+			for _, doc := range []Document{
+				{Path: "keep.go", ChunkIDs: []string{"keep_0"}},
+				{Path: "gone.go", ChunkIDs: []string{"gone_0"}},
+			} {
+				if err := st.SaveDocument(ctx, doc); err != nil {
+					t.Fatalf("SaveDocument failed: %v", err)
+				}
+			}
+
+			opts := GCOptions{
+				FileExists: func(path string) bool { return path != "gone.go" },
+				Compact:    true,
+				DryRun:     true,
+			}
+			result, err := GarbageCollect(ctx, st, opts)
+			if err != nil {
+				t.Fatalf("dry run failed: %v", err)
+			}
+			if result.ChunksRemoved != 3 || result.DocumentsRemoved != 1 || result.Reclaimed() == 0 {
+				t.Errorf("unexpected dry run result: %+v", result)
+			}
+			if chunks, _ := st.GetAllChunks(ctx); len(chunks) != 4 {
+				t.Fatalf("dry run deleted chunks: %d left", len(chunks))
+			}
+
+			opts.DryRun = false
+			result, err = GarbageCollect(ctx, st, opts)
+			if err != nil {
+				t.Fatalf("GarbageCollect failed: %v", err)
+			}
+			if result.ChunksRemoved != 3 || result.DocumentsRemoved != 1 || !result.Compacted {
+				t.Errorf("unexpected result: %+v", result)
+			}
+
+			chunks, err := st.GetAllChunks(ctx)
+			if err != nil {
+				t.Fatalf("GetAllChunks failed: %v", err)
+			}
+			if len(chunks) != 1 || chunks[0].ID != "keep_0" {
+				t.Errorf("remaining chunks = %+v, want only keep_0", chunks)
+			}
+			paths, err := st.ListDocuments(ctx)
+			if err != nil {
+				t.Fatalf("ListDocuments failed: %v", err)
+			}
+			sort.Strings(paths)
+			if len(paths) != 1 || paths[0] != "keep.go" {
+				t.Errorf("documents = %v, want [keep.go]", paths)
+			}
+			results, err := st.Search(ctx, []float32{0, 1}, 5, SearchOptions{})
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if len(results) != 1 || results[0].Chunk.ID != "keep_0" {
+				t.Errorf("search after gc = %+v, want only keep_0", results)
+			}
+
+			// A second run finds nothing left to collect, and skips compaction
+			// when asked to compact only after collecting.
+			opts.CompactIfCollected = true
+			result, err = GarbageCollect(ctx, st, opts)
+			if err != nil {
+				t.Fatalf("second GarbageCollect failed: %v", err)
+			}
+			if result.ChunksRemoved != 0 || result.DocumentsRemoved != 0 || result.Compacted {
+				t.Errorf("expected nothing to collect or compact, got %+v", result)
+			}
+		})
+	}
+}
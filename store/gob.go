@@ -7,7 +7,6 @@ import (
 	"math"
 	"os"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 
@@ -20,33 +19,152 @@ type GOBStore struct {
 	chunks    map[string]Chunk    // id -> chunk
 	documents map[string]Document // path -> document
 	mu        sync.RWMutex
+
+	// Quantized vectors keyed by chunk ID; Chunk.Vector is nil in s.chunks
+	// when quantization is enabled.
+	quantization Quantization
+	vectors      map[string]quantizedVector
+
+	// Optional approximate nearest neighbour index (nil when disabled)
+	hnswParams *HNSWParams
+	ann        *hnswIndex
+
+	// Full-text index of chunk content for hybrid search
+	text *TextIndex
+
+	manifest *IndexManifest
 }
 
 type gobData struct {
+	Header    gobHeader
 	Chunks    map[string]Chunk
 	Documents map[string]Document
+	Vectors   map[string]quantizedVector
+}
+
+// gobHeader records how the index was encoded. Indexes written before the
+// header existed decode to the zero value, meaning unquantized floats.
+type gobHeader struct {
+	Quantization string
+	Manifest     *IndexManifest
+}
+
+// GOBOption configures optional GOBStore behaviour.
+type GOBOption func(*GOBStore)
+
+// WithQuantization stores vectors in a compact quantized encoding. Searches
+// rank candidates on the quantized codes, then rescore the best ones against
+// their dequantized vectors. The mode is recorded in the index header and
+// loading an index written with another mode fails.
+func WithQuantization(mode Quantization) GOBOption {
+	return func(s *GOBStore) {
+		s.quantization = mode
+	}
 }
 
-func NewGOBStore(indexPath string) *GOBStore {
-	return &GOBStore{
-		indexPath: indexPath,
-		lockPath:  indexPath + ".lock",
-		chunks:    make(map[string]Chunk),
-		documents: make(map[string]Document),
+// WithHNSW enables an HNSW approximate nearest neighbour index. The graph is
+// built on Load, maintained by SaveChunks/DeleteByFile, and persisted next to
+// the index file. Exact search is still used when the index holds fewer than
+// params.MinChunks chunks.
+func WithHNSW(params HNSWParams) GOBOption {
+	return func(s *GOBStore) {
+		p := params.withDefaults()
+		s.hnswParams = &p
+		s.ann = newHNSWIndex(p)
 	}
 }
 
+func NewGOBStore(indexPath string, opts ...GOBOption) *GOBStore {
+	s := &GOBStore{
+		indexPath:    indexPath,
+		lockPath:     indexPath + ".lock",
+		chunks:       make(map[string]Chunk),
+		documents:    make(map[string]Document),
+		quantization: QuantizationNone,
+		vectors:      make(map[string]quantizedVector),
+		text:         NewTextIndex(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// hnswPath returns the path of the persisted HNSW graph.
+func (s *GOBStore) hnswPath() string {
+	return s.indexPath + ".hnsw"
+}
+
+// textIndexPath returns the path of the persisted full-text index.
+func (s *GOBStore) textIndexPath() string {
+	return s.indexPath + ".bm25"
+}
+
 func (s *GOBStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	for _, chunk := range chunks {
+		if s.quantization != QuantizationNone {
+			s.saveQuantizedVector(chunk.ID, chunk.Vector)
+			chunk.Vector = nil
+		} else if s.ann != nil {
+			// Skip re-inserting unchanged vectors to avoid churning the graph
+			prev, exists := s.chunks[chunk.ID]
+			if !exists || !equalVectors(prev.Vector, chunk.Vector) {
+				s.ann.Add(chunk.ID, chunk.Vector)
+			}
+		}
+		if prev, exists := s.chunks[chunk.ID]; !exists || prev.FilePath != chunk.FilePath || prev.Content != chunk.Content {
+			s.text.Add(chunk)
+		}
 		s.chunks[chunk.ID] = chunk
 	}
 
 	return nil
 }
 
+// saveQuantizedVector encodes and stores a chunk vector. Caller must hold s.mu.
+func (s *GOBStore) saveQuantizedVector(id string, vec []float32) {
+	if len(vec) == 0 {
+		delete(s.vectors, id)
+		if s.ann != nil {
+			s.ann.Remove(id)
+		}
+		return
+	}
+
+	qv := quantizeVector(s.quantization, vec)
+	prev, exists := s.vectors[id]
+	s.vectors[id] = qv
+	if s.ann != nil && (!exists || !prev.equal(qv)) {
+		// Index the dequantized vector so the graph matches what a reload sees
+		s.ann.Add(id, qv.dequantize(s.quantization))
+	}
+}
+
+// withVector returns chunk with its float vector populated. Caller must hold s.mu.
+func (s *GOBStore) withVector(chunk Chunk) Chunk {
+	if s.quantization != QuantizationNone {
+		if qv, ok := s.vectors[chunk.ID]; ok {
+			chunk.Vector = qv.dequantize(s.quantization)
+		}
+	}
+	return chunk
+}
+
+// floatVectors returns the float vector of every chunk, dequantizing if needed.
+// Caller must hold s.mu.
+func (s *GOBStore) floatVectors() map[string][]float32 {
+	vectors := make(map[string][]float32, len(s.chunks))
+	for id, chunk := range s.chunks {
+		if vec := s.withVector(chunk).Vector; len(vec) > 0 {
+			vectors[id] = vec
+		}
+	}
+	return vectors
+}
+
 func (s *GOBStore) DeleteByFile(ctx context.Context, filePath string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -58,20 +176,82 @@ func (s *GOBStore) DeleteByFile(ctx context.Context, filePath string) error {
 
 	for _, chunkID := range doc.ChunkIDs {
 		delete(s.chunks, chunkID)
+		delete(s.vectors, chunkID)
+		s.text.Remove(chunkID)
+		if s.ann != nil {
+			s.ann.Remove(chunkID)
+		}
+	}
+
+	if s.ann != nil && s.ann.NeedsCompaction() {
+		s.ann = buildHNSWIndex(*s.hnswParams, s.floatVectors())
 	}
 
 	return nil
 }
 
+// DeleteChunks removes chunks by ID, leaving documents untouched.
+func (s *GOBStore) DeleteChunks(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.chunks, id)
+		delete(s.vectors, id)
+		s.text.Remove(id)
+		if s.ann != nil {
+			s.ann.Remove(id)
+		}
+	}
+
+	if s.ann != nil && s.ann.NeedsCompaction() {
+		s.ann = buildHNSWIndex(*s.hnswParams, s.floatVectors())
+	}
+
+	return nil
+}
+
+// Compact drops quantized vectors without a chunk, rebuilds the HNSW graph
+// without its tombstones and rewrites the index files.
+func (s *GOBStore) Compact(ctx context.Context) error {
+	s.mu.Lock()
+	for id := range s.vectors {
+		if _, ok := s.chunks[id]; !ok {
+			delete(s.vectors, id)
+		}
+	}
+	if s.ann != nil && s.ann.Tombstones() > 0 {
+		s.ann = buildHNSWIndex(*s.hnswParams, s.floatVectors())
+	}
+	s.mu.Unlock()
+
+	return s.Persist(ctx)
+}
+
 func (s *GOBStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
+	filter, err := newChunkFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if s.ann != nil && limit > 0 && s.ann.Len() >= s.hnswParams.MinChunks {
+		if results := s.searchANN(queryVector, limit, filter); len(results) >= limit {
+			return results, nil
+		}
+		// Not enough approximate hits (e.g. narrow filters): fall back to exact search
+	}
+
+	if s.quantization != QuantizationNone {
+		return s.searchQuantized(queryVector, limit, filter), nil
+	}
+
 	results := make([]SearchResult, 0, len(s.chunks))
 
 	for _, chunk := range s.chunks {
-		// Filter by path prefix if provided
-		if opts.PathPrefix != "" && !strings.HasPrefix(chunk.FilePath, opts.PathPrefix) {
+		if !s.matches(filter, chunk) {
 			continue
 		}
 		score := cosineSimilarity(queryVector, chunk.Vector)
@@ -93,6 +273,71 @@ func (s *GOBStore) Search(ctx context.Context, queryVector []float32, limit int,
 	return results, nil
 }
 
+// searchQuantized ranks chunks with integer scores on the quantized codes, then
+// rescores the best candidates against their dequantized vectors.
+// Caller must hold s.mu.
+func (s *GOBStore) searchQuantized(queryVector []float32, limit int, filter *chunkFilter) []SearchResult {
+	query := quantizeVector(s.quantization, queryVector)
+	depth := s.quantization.rescoreDepth(limit)
+
+	h := &resultHeap{}
+	for id, qv := range s.vectors {
+		chunk, ok := s.chunks[id]
+		if !ok {
+			continue
+		}
+		if !s.matches(filter, chunk) {
+			continue
+		}
+		score := approxSimilarity(s.quantization, query, qv)
+		if !h.accepts(score, depth) {
+			continue
+		}
+		chunk.Vector = qv.dequantize(s.quantization)
+		h.pushBounded(SearchResult{Chunk: chunk, Score: score}, depth)
+	}
+
+	return rescoreResults(queryVector, h.sorted(), limit)
+}
+
+// searchANN queries the HNSW graph. Caller must hold s.mu.
+func (s *GOBStore) searchANN(queryVector []float32, limit int, filter *chunkFilter) []SearchResult {
+	ef := max(s.hnswParams.EfSearch, limit)
+	if filter.opts.PathPrefix != "" || filter.opts.HasFilters() {
+		// Over-fetch so post-filtering still fills the limit
+		ef *= 4
+	}
+
+	hits := s.ann.Search(queryVector, ef)
+	results := make([]SearchResult, 0, limit)
+	for _, hit := range hits {
+		chunk, ok := s.chunks[hit.ID]
+		if !ok {
+			continue
+		}
+		if !s.matches(filter, chunk) {
+			continue
+		}
+		results = append(results, SearchResult{Chunk: s.withVector(chunk), Score: hit.Score})
+		if len(results) == limit {
+			break
+		}
+	}
+	return results
+}
+
+// matches reports whether chunk passes the search filters. The modification
+// time comes from the chunk's document. Caller must hold s.mu.
+func (s *GOBStore) matches(filter *chunkFilter, chunk Chunk) bool {
+	if !filter.matchPath(chunk.FilePath) || !filter.matchSymbolKinds(chunk.SymbolKinds) {
+		return false
+	}
+	if filter.hasTimeFilter() {
+		return filter.matchModTime(s.documents[chunk.FilePath].ModTime)
+	}
+	return true
+}
+
 func (s *GOBStore) GetDocument(ctx context.Context, filePath string) (*Document, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -173,8 +418,14 @@ func (s *GOBStore) loadUnlocked() error {
 		return fmt.Errorf("failed to decode index: %w", err)
 	}
 
+	if stored := storedQuantization(data.Header.Quantization); stored != s.quantization && len(data.Chunks) > 0 {
+		return quantizationMismatchError(s.indexPath, stored, s.quantization)
+	}
+
 	s.chunks = data.Chunks
 	s.documents = data.Documents
+	s.vectors = data.Vectors
+	s.manifest = data.Header.Manifest
 
 	if s.chunks == nil {
 		s.chunks = make(map[string]Chunk)
@@ -182,6 +433,37 @@ func (s *GOBStore) loadUnlocked() error {
 	if s.documents == nil {
 		s.documents = make(map[string]Document)
 	}
+	if s.vectors == nil {
+		s.vectors = make(map[string]quantizedVector)
+	}
+	// Drop corrupt codes so search cannot index past them; their chunks are
+	// left out of results until the file is re-indexed
+	for id, qv := range s.vectors {
+		if qv.Dims > 0 && !qv.valid(s.quantization) {
+			delete(s.vectors, id)
+		}
+	}
+
+	if s.hnswParams != nil {
+		vectors := s.floatVectors()
+		ann, err := loadHNSWIndex(s.hnswPath(), *s.hnswParams, vectors)
+		if err != nil {
+			return err
+		}
+		if ann == nil {
+			ann = buildHNSWIndex(*s.hnswParams, vectors)
+		}
+		s.ann = ann
+	}
+
+	text, err := loadTextIndex(s.textIndexPath(), s.chunks)
+	if err != nil {
+		return err
+	}
+	if text == nil {
+		text = buildTextIndex(s.chunks)
+	}
+	s.text = text
 
 	return nil
 }
@@ -222,15 +504,32 @@ func (s *GOBStore) persistUnlocked() error {
 	defer file.Close()
 
 	data := gobData{
+		Header:    gobHeader{Quantization: string(s.quantization), Manifest: s.manifest},
 		Chunks:    s.chunks,
 		Documents: s.documents,
 	}
+	if s.quantization != QuantizationNone {
+		data.Vectors = s.vectors
+	}
 
 	encoder := gob.NewEncoder(file)
 	if err := encoder.Encode(data); err != nil {
 		return fmt.Errorf("failed to encode index: %w", err)
 	}
 
+	if s.ann != nil {
+		if err := s.ann.save(s.hnswPath()); err != nil {
+			return fmt.Errorf("failed to persist ann index: %w", err)
+		}
+	} else {
+		// Drop a graph left over from when HNSW was enabled; it would go stale
+		_ = os.Remove(s.hnswPath())
+	}
+
+	if err := s.text.save(s.textIndexPath()); err != nil {
+		return fmt.Errorf("failed to persist text index: %w", err)
+	}
+
 	return nil
 }
 
@@ -260,6 +559,14 @@ func (s *GOBStore) GetStats(ctx context.Context) (*IndexStats, error) {
 	if info, err := os.Stat(s.indexPath); err == nil {
 		size = info.Size()
 	}
+	if s.ann != nil {
+		if info, err := os.Stat(s.hnswPath()); err == nil {
+			size += info.Size()
+		}
+	}
+	if info, err := os.Stat(s.textIndexPath()); err == nil {
+		size += info.Size()
+	}
 
 	return &IndexStats{
 		TotalFiles:  len(s.documents),
@@ -296,7 +603,7 @@ func (s *GOBStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chu
 	chunks := make([]Chunk, 0, len(doc.ChunkIDs))
 	for _, id := range doc.ChunkIDs {
 		if chunk, ok := s.chunks[id]; ok {
-			chunks = append(chunks, chunk)
+			chunks = append(chunks, s.withVector(chunk))
 		}
 	}
 	return chunks, nil
@@ -308,18 +615,79 @@ func (s *GOBStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
 
 	chunks := make([]Chunk, 0, len(s.chunks))
 	for _, chunk := range s.chunks {
-		chunks = append(chunks, chunk)
+		chunks = append(chunks, s.withVector(chunk))
 	}
 	return chunks, nil
 }
 
+// IterateChunks implements ChunkIterator. Each batch is copied under the
+// read lock, which is released while fn runs.
+func (s *GOBStore) IterateChunks(ctx context.Context, batchSize int, fn func([]Chunk) error) error {
+	s.mu.RLock()
+	ids := make([]string, 0, len(s.chunks))
+	for id := range s.chunks {
+		ids = append(ids, id)
+	}
+	s.mu.RUnlock()
+	sort.Strings(ids)
+
+	for start := 0; start < len(ids); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+batchSize, len(ids))
+		batch := make([]Chunk, 0, end-start)
+		s.mu.RLock()
+		for _, id := range ids[start:end] {
+			if chunk, ok := s.chunks[id]; ok {
+				batch = append(batch, s.withVector(chunk))
+			}
+		}
+		s.mu.RUnlock()
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TextSearch ranks chunks against the query terms with the full-text index.
+func (s *GOBStore) TextSearch(ctx context.Context, query string, limit int, opts SearchOptions) ([]SearchResult, error) {
+	filter, err := newChunkFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hits := s.text.Search(query, limit, func(id string) bool {
+		chunk, ok := s.chunks[id]
+		return ok && s.matches(filter, chunk)
+	})
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, SearchResult{Chunk: s.withVector(s.chunks[hit.ID]), Score: hit.Score})
+	}
+	return results, nil
+}
+
 // LookupByContentHash searches in-memory chunks for a matching content hash.
 func (s *GOBStore) LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	for _, chunk := range s.chunks {
-		if chunk.ContentHash == contentHash && len(chunk.Vector) > 0 {
+		if chunk.ContentHash != contentHash {
+			continue
+		}
+		if s.quantization != QuantizationNone {
+			if qv, ok := s.vectors[chunk.ID]; ok && qv.Dims > 0 {
+				return qv.dequantize(s.quantization), true, nil
+			}
+			continue
+		}
+		if len(chunk.Vector) > 0 {
 			vec := make([]float32, len(chunk.Vector))
 			copy(vec, chunk.Vector)
 			return vec, true, nil
@@ -329,6 +697,40 @@ func (s *GOBStore) LookupByContentHash(ctx context.Context, contentHash string)
 	return nil, false, nil
 }
 
+// equalVectors reports whether two vectors are identical.
+func equalVectors(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetManifest returns the manifest recorded in the index header.
+func (s *GOBStore) GetManifest(ctx context.Context) (*IndexManifest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.manifest == nil {
+		return nil, nil
+	}
+	manifest := *s.manifest
+	return &manifest, nil
+}
+
+// SaveManifest sets the manifest written to the index header on the next Persist.
+func (s *GOBStore) SaveManifest(ctx context.Context, manifest IndexManifest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.manifest = &manifest
+	return nil
+}
+
 // cosineSimilarity calculates the cosine similarity between two vectors
 func cosineSimilarity(a, b []float32) float32 {
 	if len(a) != len(b) {
@@ -0,0 +1,516 @@
+package store
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/yoanbernabeu/grepai/config"
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+)
+
+// hnswFileVersion is bumped whenever the on-disk graph layout changes.
+const hnswFileVersion = 2
+
+// HNSWParams configures the approximate nearest neighbour index used by GOBStore.
+type HNSWParams struct {
+	M              int // Max neighbours per node on upper layers (layer 0 uses 2*M)
+	EfConstruction int // Candidate list size while inserting
+	EfSearch       int // Candidate list size while searching
+	MinChunks      int // Below this many live chunks, exact search is used instead
+}
+
+// withDefaults fills unset parameters with sensible defaults.
+func (p HNSWParams) withDefaults() HNSWParams {
+	if p.M <= 1 {
+		p.M = config.DefaultHNSWM
+	}
+	if p.EfConstruction <= 0 {
+		p.EfConstruction = config.DefaultHNSWEfConstruction
+	}
+	if p.EfSearch <= 0 {
+		p.EfSearch = config.DefaultHNSWEfSearch
+	}
+	if p.MinChunks < 0 {
+		p.MinChunks = config.DefaultHNSWMinChunks
+	}
+	return p
+}
+
+type hnswNode struct {
+	id      string
+	vector  []float32
+	norm    float64
+	level   int
+	friends [][]int32 // friends[layer] = neighbour node indexes
+	deleted bool
+}
+
+// hnswIndex is an in-process Hierarchical Navigable Small World graph over
+// chunk vectors using cosine distance. Deletions are lazy: removed nodes stay
+// in the graph as routing points but are never returned, and the graph is
+// rebuilt once tombstones outnumber live nodes.
+//
+// hnswIndex is not safe for concurrent mutation; GOBStore guards it with its mutex.
+type hnswIndex struct {
+	params   HNSWParams
+	nodes    []*hnswNode
+	byID     map[string]int32
+	entry    int32
+	maxLevel int
+	levelMul float64
+	rng      *rand.Rand
+	deleted  int
+}
+
+func newHNSWIndex(params HNSWParams) *hnswIndex {
+	params = params.withDefaults()
+	return &hnswIndex{
+		params:   params,
+		byID:     make(map[string]int32),
+		entry:    -1,
+		levelMul: 1 / math.Log(float64(params.M)),
+		//nolint:gosec // Level sampling does not need cryptographic randomness.
+		rng: rand.New(rand.NewSource(42)),
+	}
+}
+
+// Len returns the number of live (non-deleted) vectors in the index.
+func (h *hnswIndex) Len() int {
+	return len(h.byID)
+}
+
+func vectorNorm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
+
+// distance returns the cosine distance between a query and a node.
+// vectorChecksum fingerprints a vector so a persisted graph can tell whether
+// a reused chunk ID still points at the vector its edges were built for.
+func vectorChecksum(v []float32) uint64 {
+	hash := fnv.New64a()
+	var buf [4]byte
+	for _, x := range v {
+		binary.LittleEndian.PutUint32(buf[:], math.Float32bits(x))
+		hash.Write(buf[:])
+	}
+	return hash.Sum64()
+}
+
+func (h *hnswIndex) distance(q []float32, qNorm float64, n *hnswNode) float64 {
+	if len(q) != len(n.vector) || qNorm == 0 || n.norm == 0 {
+		return 2
+	}
+	var dot float64
+	for i := range q {
+		dot += float64(q[i]) * float64(n.vector[i])
+	}
+	return 1 - dot/(qNorm*n.norm)
+}
+
+func (h *hnswIndex) maxFriends(layer int) int {
+	if layer == 0 {
+		return 2 * h.params.M
+	}
+	return h.params.M
+}
+
+func (h *hnswIndex) randomLevel() int {
+	return int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelMul))
+}
+
+// Add inserts or replaces the vector for a chunk ID.
+func (h *hnswIndex) Add(id string, vector []float32) {
+	if len(vector) == 0 {
+		h.Remove(id)
+		return
+	}
+	if existing, ok := h.byID[id]; ok {
+		// Replacing in place would leave stale links tuned to the old vector.
+		h.nodes[existing].deleted = true
+		h.deleted++
+		delete(h.byID, id)
+	}
+
+	node := &hnswNode{
+		id:     id,
+		vector: vector,
+		norm:   vectorNorm(vector),
+		level:  h.randomLevel(),
+	}
+	node.friends = make([][]int32, node.level+1)
+	nodeIdx := int32(len(h.nodes))
+	h.nodes = append(h.nodes, node)
+	h.byID[id] = nodeIdx
+
+	if h.entry < 0 {
+		h.entry = nodeIdx
+		h.maxLevel = node.level
+		return
+	}
+
+	ep := h.entry
+	for layer := h.maxLevel; layer > node.level; layer-- {
+		ep = h.greedyClosest(vector, node.norm, ep, layer)
+	}
+
+	eps := []int32{ep}
+	for layer := min(node.level, h.maxLevel); layer >= 0; layer-- {
+		candidates := h.searchLayer(vector, node.norm, eps, h.params.EfConstruction, layer)
+		neighbours := h.selectNeighbours(candidates, h.params.M)
+		node.friends[layer] = neighbours
+		for _, nb := range neighbours {
+			h.link(nb, nodeIdx, layer)
+		}
+		eps = eps[:0]
+		for _, c := range candidates {
+			eps = append(eps, c.idx)
+		}
+	}
+
+	if node.level > h.maxLevel {
+		h.maxLevel = node.level
+		h.entry = nodeIdx
+	}
+}
+
+// link adds a directed edge from -> to on a layer, shrinking the friend list
+// with the neighbour-selection heuristic when it overflows.
+func (h *hnswIndex) link(from, to int32, layer int) {
+	n := h.nodes[from]
+	if layer >= len(n.friends) {
+		return
+	}
+	n.friends[layer] = append(n.friends[layer], to)
+	limit := h.maxFriends(layer)
+	if len(n.friends[layer]) <= limit {
+		return
+	}
+	candidates := make([]hnswCandidate, 0, len(n.friends[layer]))
+	for _, f := range n.friends[layer] {
+		candidates = append(candidates, hnswCandidate{idx: f, dist: h.distance(n.vector, n.norm, h.nodes[f])})
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].dist < candidates[j].dist })
+	n.friends[layer] = h.selectNeighbours(candidates, limit)
+}
+
+// selectNeighbours implements the HNSW neighbour heuristic: a candidate is
+// kept only if it is closer to the base than to any already selected
+// neighbour, then the list is topped up with the nearest pruned candidates.
+// candidates must be sorted by ascending distance.
+func (h *hnswIndex) selectNeighbours(candidates []hnswCandidate, m int) []int32 {
+	selected := make([]int32, 0, m)
+	var pruned []int32
+	for _, c := range candidates {
+		if len(selected) >= m {
+			break
+		}
+		cn := h.nodes[c.idx]
+		good := true
+		for _, s := range selected {
+			if h.distance(cn.vector, cn.norm, h.nodes[s]) < c.dist {
+				good = false
+				break
+			}
+		}
+		if good {
+			selected = append(selected, c.idx)
+		} else {
+			pruned = append(pruned, c.idx)
+		}
+	}
+	for _, p := range pruned {
+		if len(selected) >= m {
+			break
+		}
+		selected = append(selected, p)
+	}
+	return selected
+}
+
+// Remove marks a chunk ID as deleted.
+func (h *hnswIndex) Remove(id string) {
+	idx, ok := h.byID[id]
+	if !ok {
+		return
+	}
+	h.nodes[idx].deleted = true
+	h.deleted++
+	delete(h.byID, id)
+}
+
+// NeedsCompaction reports whether tombstones dominate the graph.
+func (h *hnswIndex) NeedsCompaction() bool {
+	return h.deleted > 1000 && h.deleted > len(h.byID)
+}
+
+// Tombstones returns the number of deleted nodes still kept for routing.
+func (h *hnswIndex) Tombstones() int {
+	return h.deleted
+}
+
+func (h *hnswIndex) greedyClosest(q []float32, qNorm float64, ep int32, layer int) int32 {
+	cur := ep
+	curDist := h.distance(q, qNorm, h.nodes[cur])
+	for changed := true; changed; {
+		changed = false
+		node := h.nodes[cur]
+		if layer >= len(node.friends) {
+			break
+		}
+		for _, f := range node.friends[layer] {
+			if d := h.distance(q, qNorm, h.nodes[f]); d < curDist {
+				cur, curDist = f, d
+				changed = true
+			}
+		}
+	}
+	return cur
+}
+
+type hnswCandidate struct {
+	idx  int32
+	dist float64
+}
+
+// candidateMinHeap pops the closest candidate first.
+type candidateMinHeap []hnswCandidate
+
+func (c candidateMinHeap) Len() int            { return len(c) }
+func (c candidateMinHeap) Less(i, j int) bool  { return c[i].dist < c[j].dist }
+func (c candidateMinHeap) Swap(i, j int)       { c[i], c[j] = c[j], c[i] }
+func (c *candidateMinHeap) Push(x interface{}) { *c = append(*c, x.(hnswCandidate)) }
+func (c *candidateMinHeap) Pop() interface{} {
+	old := *c
+	item := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return item
+}
+
+// candidateMaxHeap pops the furthest candidate first.
+type candidateMaxHeap struct{ candidateMinHeap }
+
+func (c candidateMaxHeap) Less(i, j int) bool {
+	return c.candidateMinHeap[i].dist > c.candidateMinHeap[j].dist
+}
+
+// searchLayer returns up to ef nearest nodes on a layer, sorted by ascending distance.
+func (h *hnswIndex) searchLayer(q []float32, qNorm float64, eps []int32, ef int, layer int) []hnswCandidate {
+	visited := make(map[int32]struct{}, ef*4)
+	candidates := &candidateMinHeap{}
+	results := &candidateMaxHeap{}
+
+	for _, ep := range eps {
+		if _, seen := visited[ep]; seen {
+			continue
+		}
+		visited[ep] = struct{}{}
+		c := hnswCandidate{idx: ep, dist: h.distance(q, qNorm, h.nodes[ep])}
+		heap.Push(candidates, c)
+		heap.Push(results, c)
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && c.dist > results.candidateMinHeap[0].dist {
+			break
+		}
+		node := h.nodes[c.idx]
+		if layer >= len(node.friends) {
+			continue
+		}
+		for _, f := range node.friends[layer] {
+			if _, seen := visited[f]; seen {
+				continue
+			}
+			visited[f] = struct{}{}
+			d := h.distance(q, qNorm, h.nodes[f])
+			if results.Len() < ef || d < results.candidateMinHeap[0].dist {
+				heap.Push(candidates, hnswCandidate{idx: f, dist: d})
+				heap.Push(results, hnswCandidate{idx: f, dist: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	out := make([]hnswCandidate, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(hnswCandidate)
+	}
+	return out
+}
+
+// Search returns up to ef live chunk IDs closest to q with their cosine similarity.
+func (h *hnswIndex) Search(q []float32, ef int) []hnswHit {
+	if h.entry < 0 || len(h.byID) == 0 {
+		return nil
+	}
+	qNorm := vectorNorm(q)
+	ep := h.entry
+	for layer := h.maxLevel; layer > 0; layer-- {
+		ep = h.greedyClosest(q, qNorm, ep, layer)
+	}
+	candidates := h.searchLayer(q, qNorm, []int32{ep}, ef, 0)
+
+	hits := make([]hnswHit, 0, len(candidates))
+	for _, c := range candidates {
+		node := h.nodes[c.idx]
+		if node.deleted {
+			continue
+		}
+		hits = append(hits, hnswHit{ID: node.id, Score: float32(1 - c.dist)})
+	}
+	return hits
+}
+
+type hnswHit struct {
+	ID    string
+	Score float32
+}
+
+// hnswFile is the on-disk representation of the graph. Vectors of live
+// nodes are not duplicated; they are re-attached from the chunk map on load
+// and checked against the checksum recorded at save time.
+type hnswFile struct {
+	Version  int
+	Params   HNSWParams
+	Entry    int32
+	MaxLevel int
+	Nodes    []hnswFileNode
+}
+
+type hnswFileNode struct {
+	ID       string
+	Level    int
+	Friends  [][]int32
+	Deleted  bool
+	Vector   []float32 // only set for deleted nodes (routing still needs them)
+	Checksum uint64    // vectorChecksum of live nodes
+}
+
+// save writes the graph to path atomically.
+func (h *hnswIndex) save(path string) error {
+	data := hnswFile{
+		Version:  hnswFileVersion,
+		Params:   h.params,
+		Entry:    h.entry,
+		MaxLevel: h.maxLevel,
+		Nodes:    make([]hnswFileNode, len(h.nodes)),
+	}
+	for i, n := range h.nodes {
+		data.Nodes[i] = hnswFileNode{ID: n.id, Level: n.level, Friends: n.friends, Deleted: n.deleted}
+		if n.deleted {
+			data.Nodes[i].Vector = n.vector
+		} else {
+			data.Nodes[i].Checksum = vectorChecksum(n.vector)
+		}
+	}
+
+	if err := fileutil.EnsureParentDir(path); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create ann index file: %w", err)
+	}
+	if err := gob.NewEncoder(file).Encode(data); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to encode ann index: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close ann index file: %w", err)
+	}
+	return fileutil.ReplaceFileAtomically(tmpPath, path)
+}
+
+// loadHNSWIndex reads a persisted graph and re-attaches live vectors.
+// It returns (nil, nil) when the file is missing, stale, built with
+// different parameters, or built for vectors the chunk map no longer holds,
+// in which case the caller should rebuild.
+func loadHNSWIndex(path string, params HNSWParams, vectors map[string][]float32) (*hnswIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open ann index file: %w", err)
+	}
+	defer file.Close()
+
+	var data hnswFile
+	if err := gob.NewDecoder(file).Decode(&data); err != nil {
+		return nil, nil //nolint:nilerr // A corrupt graph is rebuilt from chunks.
+	}
+
+	params = params.withDefaults()
+	if data.Version != hnswFileVersion || data.Params.M != params.M || data.Params.EfConstruction != params.EfConstruction {
+		return nil, nil
+	}
+
+	h := newHNSWIndex(params)
+	h.entry = data.Entry
+	h.maxLevel = data.MaxLevel
+	h.nodes = make([]*hnswNode, len(data.Nodes))
+	for i, fn := range data.Nodes {
+		n := &hnswNode{id: fn.ID, level: fn.Level, friends: fn.Friends, deleted: fn.Deleted}
+		if fn.Deleted {
+			n.vector = fn.Vector
+			h.deleted++
+		} else {
+			vec, ok := vectors[fn.ID]
+			if !ok || vectorChecksum(vec) != fn.Checksum {
+				// Graph and chunk map disagree (e.g. crash between writes,
+				// after a re-index reused the chunk ID for a new vector).
+				return nil, nil
+			}
+			n.vector = vec
+			h.byID[fn.ID] = int32(i)
+		}
+		n.norm = vectorNorm(n.vector)
+		for len(n.friends) <= n.level {
+			n.friends = append(n.friends, nil)
+		}
+		h.nodes[i] = n
+	}
+	if h.entry >= int32(len(h.nodes)) {
+		return nil, nil
+	}
+
+	// Catch up on chunks saved after the graph was last persisted.
+	for id, vec := range vectors {
+		if _, ok := h.byID[id]; !ok {
+			h.Add(id, vec)
+		}
+	}
+
+	return h, nil
+}
+
+// buildHNSWIndex builds a fresh graph from all vectors in deterministic order.
+func buildHNSWIndex(params HNSWParams, vectors map[string][]float32) *hnswIndex {
+	h := newHNSWIndex(params)
+	ids := make([]string, 0, len(vectors))
+	for id := range vectors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		h.Add(id, vectors[id])
+	}
+	return h
+}
@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func randomChunks(n, dims int, seed int64) []Chunk {
+	rng := rand.New(rand.NewSource(seed))
+	chunks := make([]Chunk, n)
+	for i := range chunks {
+		vec := make([]float32, dims)
+		for j := range vec {
+			vec[j] = rng.Float32()*2 - 1
+		}
+		chunks[i] = Chunk{
+			ID:       fmt.Sprintf("file%d.go_%d", i%50, i),
+			FilePath: fmt.Sprintf("pkg%d/file%d.go", i%5, i%50),
+			Vector:   vec,
+		}
+	}
+	return chunks
+}
+
+func TestHNSWIndex_RecallAgainstExactSearch(t *testing.T) {
+	ctx := context.Background()
+	chunks := randomChunks(2000, 32, 1)
+
+	exact := NewGOBStore(filepath.Join(t.TempDir(), "exact.gob"))
+	approx := NewGOBStore(filepath.Join(t.TempDir(), "approx.gob"), WithHNSW(HNSWParams{MinChunks: 0}))
+	for _, s := range []*GOBStore{exact, approx} {
+		if err := s.SaveChunks(ctx, chunks); err != nil {
+			t.Fatalf("failed to save chunks: %v", err)
+		}
+	}
+
+	queries := randomChunks(20, 32, 2)
+	const k = 10
+	hits, total := 0, 0
+	for _, q := range queries {
+		want, _ := exact.Search(ctx, q.Vector, k, SearchOptions{})
+		got, _ := approx.Search(ctx, q.Vector, k, SearchOptions{})
+		wantIDs := make(map[string]bool, len(want))
+		for _, r := range want {
+			wantIDs[r.Chunk.ID] = true
+		}
+		for _, r := range got {
+			if wantIDs[r.Chunk.ID] {
+				hits++
+			}
+		}
+		total += len(want)
+	}
+
+	if recall := float64(hits) / float64(total); recall < 0.9 {
+		t.Errorf("expected recall >= 0.9, got %.2f", recall)
+	}
+}
+
+func TestHNSWIndex_DeleteAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := NewGOBStore(filepath.Join(t.TempDir(), "index.gob"), WithHNSW(HNSWParams{MinChunks: 0}))
+
+	if err := store.SaveChunks(ctx, []Chunk{
+		{ID: "a_0", FilePath: "a.go", Vector: []float32{1, 0, 0}},
+		{ID: "b_0", FilePath: "b.go", Vector: []float32{0, 1, 0}},
+		{ID: "c_0", FilePath: "c.go", Vector: []float32{0, 0, 1}},
+	}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := store.SaveDocument(ctx, Document{Path: "a.go", ChunkIDs: []string{"a_0"}}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	if err := store.DeleteByFile(ctx, "a.go"); err != nil {
+		t.Fatalf("DeleteByFile failed: %v", err)
+	}
+	results, err := store.Search(ctx, []float32{1, 0, 0}, 1, SearchOptions{})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.ID == "a_0" {
+		t.Fatalf("deleted chunk should not be returned, got %+v", results)
+	}
+
+	// Updating a chunk's vector must be reflected in the graph
+	if err := store.SaveChunks(ctx, []Chunk{{ID: "b_0", FilePath: "b.go", Vector: []float32{1, 0.1, 0}}}); err != nil {
+		t.Fatalf("failed to update chunk: %v", err)
+	}
+	results, err = store.Search(ctx, []float32{1, 0, 0}, 1, SearchOptions{})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.ID != "b_0" {
+		t.Errorf("expected updated chunk b_0 first, got %+v", results)
+	}
+}
+
+func TestHNSWIndex_PersistAndReload(t *testing.T) {
+	ctx := context.Background()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+	chunks := randomChunks(300, 16, 3)
+
+	store1 := NewGOBStore(indexPath, WithHNSW(HNSWParams{MinChunks: 0}))
+	if err := store1.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := store1.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist: %v", err)
+	}
+	if _, err := os.Stat(indexPath + ".hnsw"); err != nil {
+		t.Fatalf("expected hnsw graph next to index: %v", err)
+	}
+
+	store2 := NewGOBStore(indexPath, WithHNSW(HNSWParams{MinChunks: 0}))
+	if err := store2.Load(ctx); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if store2.ann.Len() != len(chunks) {
+		t.Fatalf("expected %d nodes after reload, got %d", len(chunks), store2.ann.Len())
+	}
+
+	results, err := store2.Search(ctx, chunks[42].Vector, 1, SearchOptions{})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.ID != chunks[42].ID {
+		t.Errorf("expected exact match %s, got %+v", chunks[42].ID, results)
+	}
+
+	// Disabling HNSW removes the stale graph on the next persist
+	store3 := NewGOBStore(indexPath)
+	if err := store3.Load(ctx); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	if err := store3.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist: %v", err)
+	}
+	if _, err := os.Stat(indexPath + ".hnsw"); !os.IsNotExist(err) {
+		t.Errorf("expected stale hnsw graph to be removed, got err=%v", err)
+	}
+}
+
+func TestHNSWIndex_ReloadRejectsReusedIDWithNewVector(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.gob.hnsw")
+	vectors := make(map[string][]float32)
+	for _, c := range randomChunks(50, 8, 4) {
+		vectors[c.ID] = c.Vector
+	}
+	if err := buildHNSWIndex(HNSWParams{}, vectors).save(path); err != nil {
+		t.Fatalf("failed to save graph: %v", err)
+	}
+
+	h, err := loadHNSWIndex(path, HNSWParams{}, vectors)
+	if err != nil || h == nil {
+		t.Fatalf("expected graph to reload, got %v (err=%v)", h, err)
+	}
+
+	// A re-index reused the chunk ID for a different vector but the graph
+	// was not rewritten before the crash.
+	reused := randomChunks(1, 8, 5)[0]
+	for id := range vectors {
+		vectors[id] = reused.Vector
+		break
+	}
+	h, err = loadHNSWIndex(path, HNSWParams{}, vectors)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if h != nil {
+		t.Error("expected a graph built for an old vector to be rejected")
+	}
+}
+
+func TestHNSWIndex_PathPrefixFallsBackToExact(t *testing.T) {
+	ctx := context.Background()
+	store := NewGOBStore(filepath.Join(t.TempDir(), "index.gob"), WithHNSW(HNSWParams{MinChunks: 0, EfSearch: 4}))
+
+	chunks := randomChunks(500, 8, 4)
+	if err := store.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	results, err := store.Search(ctx, chunks[0].Vector, 20, SearchOptions{PathPrefix: "pkg3/"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 20 {
+		t.Fatalf("expected 20 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Chunk.FilePath[:5] != "pkg3/" {
+			t.Errorf("unexpected path %s outside prefix", r.Chunk.FilePath)
+		}
+	}
+}
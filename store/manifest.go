@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// IndexManifest records how the vectors in an index were produced, so a
+// changed embedder configuration is detected instead of silently returning
+// meaningless scores.
+type IndexManifest struct {
+	Provider       string    `json:"provider"`
+	Model          string    `json:"model"`
+	Dimensions     int       `json:"dimensions"`
+	ChunkSize      int       `json:"chunk_size"`
+	ChunkOverlap   int       `json:"chunk_overlap"`
+	ChunkStrategy  string    `json:"chunk_strategy,omitempty"` // empty for manifests written before strategies existed
+	ContextHeaders bool      `json:"context_headers,omitempty"`
+	Version        string    `json:"version"` // grepai version that last wrote the manifest
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ManifestStore is an optional interface for VectorStore implementations
+// that can persist an IndexManifest alongside the index.
+type ManifestStore interface {
+	// GetManifest returns the stored manifest, or nil if none was recorded.
+	GetManifest(ctx context.Context) (*IndexManifest, error)
+
+	// SaveManifest records the manifest for the index.
+	SaveManifest(ctx context.Context, manifest IndexManifest) error
+}
+
+// NewManifest builds the manifest describing the active configuration.
+func NewManifest(emb config.EmbedderConfig, chunking config.ChunkingConfig, version string) IndexManifest {
+	return IndexManifest{
+		Provider:       emb.Provider,
+		Model:          emb.Model,
+		Dimensions:     emb.GetDimensions(),
+		ChunkSize:      chunking.Size,
+		ChunkOverlap:   chunking.Overlap,
+		ChunkStrategy:  chunkingStrategy(chunking.Strategy),
+		ContextHeaders: chunking.ContextHeaders,
+		Version:        version,
+	}
+}
+
+// EmbedderMatches reports whether both manifests describe vectors from the
+// same embedding space.
+func (m IndexManifest) EmbedderMatches(other IndexManifest) bool {
+	return m.Provider == other.Provider && m.Model == other.Model && m.Dimensions == other.Dimensions
+}
+
+// ChunkingMatches reports whether both manifests use the same chunking settings.
+func (m IndexManifest) ChunkingMatches(other IndexManifest) bool {
+	return m.ChunkSize == other.ChunkSize && m.ChunkOverlap == other.ChunkOverlap &&
+		chunkingStrategy(m.ChunkStrategy) == chunkingStrategy(other.ChunkStrategy) &&
+		m.ContextHeaders == other.ContextHeaders
+}
+
+// ChunkingString returns a short human-readable description of the chunking
+// settings.
+func (m IndexManifest) ChunkingString() string {
+	s := fmt.Sprintf("size=%d overlap=%d strategy=%s", m.ChunkSize, m.ChunkOverlap, chunkingStrategy(m.ChunkStrategy))
+	if m.ContextHeaders {
+		s += " context_headers"
+	}
+	return s
+}
+
+// chunkingStrategy maps the empty strategy to the default fixed-size one.
+func chunkingStrategy(s string) string {
+	if s == "" {
+		return config.ChunkingStrategyFixed
+	}
+	return s
+}
+
+// EmbedderString returns a short human-readable description of the embedder.
+func (m IndexManifest) EmbedderString() string {
+	return fmt.Sprintf("%s/%s (%d dims)", m.Provider, m.Model, m.Dimensions)
+}
+
+// ManifestMismatchError is returned when an index was built with a different
+// embedder than the one currently configured.
+type ManifestMismatchError struct {
+	Stored IndexManifest
+	Active IndexManifest
+}
+
+func (e *ManifestMismatchError) Error() string {
+	return fmt.Sprintf("index was built with embedder %s but the configuration uses %s; run 'grepai watch --reindex' to rebuild the index, or restore the previous embedder settings",
+		e.Stored.EmbedderString(), e.Active.EmbedderString())
+}
+
+// CheckManifest compares the manifest stored in st with active. It returns
+// the stored manifest (nil if the store has none or cannot record one) and a
+// *ManifestMismatchError if the embedders differ.
+func CheckManifest(ctx context.Context, st VectorStore, active IndexManifest) (*IndexManifest, error) {
+	ms, ok := st.(ManifestStore)
+	if !ok {
+		return nil, nil
+	}
+
+	stored, err := ms.GetManifest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index manifest: %w", err)
+	}
+	if stored == nil {
+		return nil, nil
+	}
+	if !stored.EmbedderMatches(active) {
+		return stored, &ManifestMismatchError{Stored: *stored, Active: active}
+	}
+	return stored, nil
+}
+
+// SaveManifest records manifest in st if it supports manifests.
+func SaveManifest(ctx context.Context, st VectorStore, manifest IndexManifest) error {
+	ms, ok := st.(ManifestStore)
+	if !ok {
+		return nil
+	}
+	if manifest.UpdatedAt.IsZero() {
+		manifest.UpdatedAt = time.Now()
+	}
+	if err := ms.SaveManifest(ctx, manifest); err != nil {
+		return fmt.Errorf("failed to save index manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+func testManifest(model string) IndexManifest {
+	dims := 768
+	return NewManifest(
+		config.EmbedderConfig{Provider: "ollama", Model: model, Dimensions: &dims},
+		config.ChunkingConfig{Size: 512, Overlap: 50},
+		"test",
+	)
+}
+
+func TestCheckManifest(t *testing.T) {
+	ctx := context.Background()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+	s := NewGOBStore(indexPath)
+
+	stored, err := CheckManifest(ctx, s, testManifest("nomic-embed-text"))
+	if err != nil || stored != nil {
+		t.Fatalf("expected no manifest and no error for a new index, got %+v, %v", stored, err)
+	}
+
+	if err := SaveManifest(ctx, s, testManifest("nomic-embed-text")); err != nil {
+		t.Fatalf("SaveManifest failed: %v", err)
+	}
+	if err := s.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist: %v", err)
+	}
+
+	reloaded := NewGOBStore(indexPath)
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("failed to load: %v", err)
+	}
+	stored, err = CheckManifest(ctx, reloaded, testManifest("nomic-embed-text"))
+	if err != nil {
+		t.Fatalf("unexpected mismatch: %v", err)
+	}
+	if stored == nil || stored.ChunkSize != 512 || stored.UpdatedAt.IsZero() {
+		t.Errorf("expected persisted manifest, got %+v", stored)
+	}
+
+	_, err = CheckManifest(ctx, reloaded, testManifest("mxbai-embed-large"))
+	var mismatch *ManifestMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected ManifestMismatchError, got %v", err)
+	}
+	if mismatch.Stored.Model != "nomic-embed-text" || mismatch.Active.Model != "mxbai-embed-large" {
+		t.Errorf("unexpected mismatch details: %+v", mismatch)
+	}
+}
+
+func TestSQLiteStore_Manifest(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLiteStore(t)
+
+	if m, err := s.GetManifest(ctx); err != nil || m != nil {
+		t.Fatalf("expected no manifest, got %+v, %v", m, err)
+	}
+	if err := s.SaveManifest(ctx, testManifest("nomic-embed-text")); err != nil {
+		t.Fatalf("SaveManifest failed: %v", err)
+	}
+	m, err := s.GetManifest(ctx)
+	if err != nil {
+		t.Fatalf("GetManifest failed: %v", err)
+	}
+	if m == nil || m.Model != "nomic-embed-text" || m.Dimensions != 768 {
+		t.Errorf("unexpected manifest: %+v", m)
+	}
+}
+
+func TestClearStore_RemovesOrphanedChunks(t *testing.T) {
+	ctx := context.Background()
+	s := NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := s.SaveChunks(ctx, []Chunk{
+		{ID: "a_0", FilePath: "a.go", Vector: []float32{1, 0}},
+		{ID: "orphan", FilePath: "gone.go", Vector: []float32{0, 1}},
+	}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := s.SaveDocument(ctx, Document{Path: "a.go", ChunkIDs: []string{"a_0"}}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	if err := ClearStore(ctx, s); err != nil {
+		t.Fatalf("ClearStore failed: %v", err)
+	}
+	if docs, chunks := s.Stats(); docs != 0 || chunks != 0 {
+		t.Errorf("expected empty store, got %d docs and %d chunks", docs, chunks)
+	}
+}
+
+func TestManifest_ChunkingMatchesStrategy(t *testing.T) {
+	legacy := testManifest("nomic-embed-text")
+	legacy.ChunkStrategy = ""
+
+	fixed := testManifest("nomic-embed-text")
+	if !legacy.ChunkingMatches(fixed) {
+		t.Error("manifest without a strategy should match the fixed strategy")
+	}
+
+	ast := NewManifest(config.EmbedderConfig{Provider: "ollama", Model: "nomic-embed-text"},
+		config.ChunkingConfig{Size: 512, Overlap: 50, Strategy: config.ChunkingStrategyAST}, "test")
+	if legacy.ChunkingMatches(ast) {
+		t.Error("fixed and ast strategies should not match")
+	}
+}
@@ -0,0 +1,205 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultMigrateBatchSize is the number of chunks written per SaveChunks call.
+const DefaultMigrateBatchSize = 500
+
+// MigrateOptions configures Migrate.
+type MigrateOptions struct {
+	// BatchSize is the number of chunks written per SaveChunks call.
+	BatchSize int
+	// Progress, if set, is called after each chunk batch with the number of
+	// chunks copied so far and the total.
+	Progress func(done, total int)
+}
+
+// ChunkIterator is implemented by stores that can stream their chunks,
+// vectors included, without loading the whole index. Migrate requires it of
+// the source store.
+type ChunkIterator interface {
+	// IterateChunks calls fn with successive batches of at most batchSize
+	// chunks ordered by ID, stopping at the first error fn returns.
+	IterateChunks(ctx context.Context, batchSize int, fn func([]Chunk) error) error
+}
+
+// MigrateResult summarizes a completed migration.
+type MigrateResult struct {
+	Chunks    int
+	Documents int
+}
+
+// Migrate copies every chunk (vectors included) and document from src into
+// dst, persists dst, and verifies that dst holds the same number of chunks
+// and documents. Chunks are copied in batches, so only one batch is held in
+// memory at a time. dst is expected to be empty.
+func Migrate(ctx context.Context, src, dst VectorStore, opts MigrateOptions) (*MigrateResult, error) {
+	if !TracksDocuments(src) {
+		return nil, fmt.Errorf("migrating from qdrant is not supported: it does not keep chunk IDs or document metadata")
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultMigrateBatchSize
+	}
+
+	iter, ok := src.(ChunkIterator)
+	if !ok {
+		return nil, fmt.Errorf("migrating from %T is not supported: it cannot stream its chunks", src)
+	}
+
+	stats, err := src.GetStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source stats: %w", err)
+	}
+	paths, err := src.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source documents: %w", err)
+	}
+
+	copied := 0
+	err = iter.IterateChunks(ctx, batchSize, func(batch []Chunk) error {
+		for _, chunk := range batch {
+			if len(chunk.Vector) == 0 {
+				return fmt.Errorf("source chunk %s has no vector", chunk.ID)
+			}
+		}
+		if err := dst.SaveChunks(ctx, batch); err != nil {
+			return fmt.Errorf("failed to write chunks: %w", err)
+		}
+		copied += len(batch)
+		if opts.Progress != nil {
+			opts.Progress(copied, stats.TotalChunks)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		doc, err := src.GetDocument(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source document %s: %w", path, err)
+		}
+		if doc == nil {
+			continue
+		}
+		if err := dst.SaveDocument(ctx, *doc); err != nil {
+			return nil, fmt.Errorf("failed to write document %s: %w", path, err)
+		}
+	}
+
+	if srcManifests, ok := src.(ManifestStore); ok {
+		manifest, err := srcManifests.GetManifest(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source manifest: %w", err)
+		}
+		if manifest != nil {
+			if err := SaveManifest(ctx, dst, *manifest); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := dst.Persist(ctx); err != nil {
+		return nil, fmt.Errorf("failed to persist target store: %w", err)
+	}
+
+	if err := verifyMigration(ctx, dst, copied, len(paths)); err != nil {
+		return nil, err
+	}
+
+	return &MigrateResult{Chunks: copied, Documents: len(paths)}, nil
+}
+
+func verifyMigration(ctx context.Context, dst VectorStore, wantChunks, wantDocs int) error {
+	stats, err := dst.GetStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read target stats: %w", err)
+	}
+	if stats.TotalChunks != wantChunks {
+		return fmt.Errorf("verification failed: target has %d chunks, expected %d", stats.TotalChunks, wantChunks)
+	}
+
+	// Qdrant has no document table; documents are derived from chunk payloads.
+	if _, ok := dst.(*QdrantStore); ok {
+		return nil
+	}
+	docs, err := dst.ListDocuments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list target documents: %w", err)
+	}
+	if len(docs) != wantDocs {
+		return fmt.Errorf("verification failed: target has %d documents, expected %d", len(docs), wantDocs)
+	}
+	return nil
+}
+
+// ClearStore removes every document and chunk from s.
+func ClearStore(ctx context.Context, s VectorStore) error {
+	paths, err := s.ListDocuments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list documents: %w", err)
+	}
+	for _, path := range paths {
+		if err := s.DeleteByFile(ctx, path); err != nil {
+			return fmt.Errorf("failed to delete chunks for %s: %w", path, err)
+		}
+		if err := s.DeleteDocument(ctx, path); err != nil {
+			return fmt.Errorf("failed to delete document %s: %w", path, err)
+		}
+	}
+
+	// Chunks no document points at survive DeleteByFile in stores that
+	// resolve chunks through the document, so attach them to a temporary
+	// document and delete that.
+	remaining, err := s.GetAllChunks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list remaining chunks: %w", err)
+	}
+	orphans := make(map[string][]string)
+	for _, chunk := range remaining {
+		orphans[chunk.FilePath] = append(orphans[chunk.FilePath], chunk.ID)
+	}
+	for path, ids := range orphans {
+		if err := PurgeFile(ctx, s, path, ids); err != nil {
+			return err
+		}
+	}
+
+	return s.Persist(ctx)
+}
+
+// PurgeFile removes the document for path along with its chunks and the
+// given extra chunk IDs, which the document may not reference (for example
+// chunks left behind by an interrupted write).
+func PurgeFile(ctx context.Context, s VectorStore, path string, extraChunkIDs []string) error {
+	ids := append([]string{}, extraChunkIDs...)
+	doc, err := s.GetDocument(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to read document %s: %w", path, err)
+	}
+	if doc != nil {
+		ids = append(ids, doc.ChunkIDs...)
+	}
+
+	if len(extraChunkIDs) > 0 {
+		if err := s.SaveDocument(ctx, Document{Path: path, ChunkIDs: ids}); err != nil {
+			return fmt.Errorf("failed to track orphaned chunks for %s: %w", path, err)
+		}
+	}
+	if err := s.DeleteByFile(ctx, path); err != nil {
+		return fmt.Errorf("failed to delete chunks for %s: %w", path, err)
+	}
+	if err := s.DeleteDocument(ctx, path); err != nil {
+		return fmt.Errorf("failed to delete document %s: %w", path, err)
+	}
+	return nil
+}
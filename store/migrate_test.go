@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrate_GOBToSQLite(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	src := NewGOBStore(filepath.Join(dir, "index.gob"))
+	chunks := randomChunks(120, 8, 9)
+	if err := src.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	docs := make(map[string][]string)
+	for _, c := range chunks {
+		docs[c.FilePath] = append(docs[c.FilePath], c.ID)
+	}
+	for path, ids := range docs {
+		if err := src.SaveDocument(ctx, Document{Path: path, Hash: "h-" + path, ChunkIDs: ids}); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+
+	dst, err := NewSQLiteStore(ctx, filepath.Join(dir, "index.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite store: %v", err)
+	}
+	defer dst.Close()
+
+	var progressCalls int
+	result, err := Migrate(ctx, src, dst, MigrateOptions{BatchSize: 50, Progress: func(done, total int) { progressCalls++ }})
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if result.Chunks != len(chunks) || result.Documents != len(docs) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if progressCalls != 3 {
+		t.Errorf("expected 3 progress calls for 120 chunks in batches of 50, got %d", progressCalls)
+	}
+
+	doc, err := dst.GetDocument(ctx, chunks[0].FilePath)
+	if err != nil || doc == nil || doc.Hash != "h-"+chunks[0].FilePath {
+		t.Fatalf("expected migrated document, got %+v (err=%v)", doc, err)
+	}
+	results, err := dst.Search(ctx, chunks[3].Vector, 1, SearchOptions{})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.ID != chunks[3].ID {
+		t.Errorf("expected vectors to be migrated, got %+v", results)
+	}
+}
+
+func TestIterateChunks(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	sqliteStore, err := NewSQLiteStore(ctx, filepath.Join(dir, "index.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite store: %v", err)
+	}
+	defer sqliteStore.Close()
+
+	chunks := randomChunks(25, 4, 3)
+	stores := map[string]interface {
+		VectorStore
+		ChunkIterator
+	}{
+		"gob":    NewGOBStore(filepath.Join(dir, "index.gob")),
+		"sqlite": sqliteStore,
+	}
+	for name, st := range stores {
+		if err := st.SaveChunks(ctx, chunks); err != nil {
+			t.Fatalf("%s: failed to save chunks: %v", name, err)
+		}
+
+		var sizes []int
+		seen := make(map[string]bool)
+		lastID := ""
+		err := st.IterateChunks(ctx, 10, func(batch []Chunk) error {
+			sizes = append(sizes, len(batch))
+			for _, c := range batch {
+				if c.ID <= lastID {
+					t.Errorf("%s: chunk %s out of order after %s", name, c.ID, lastID)
+				}
+				if len(c.Vector) == 0 {
+					t.Errorf("%s: chunk %s has no vector", name, c.ID)
+				}
+				lastID = c.ID
+				seen[c.ID] = true
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: IterateChunks failed: %v", name, err)
+		}
+		if len(seen) != len(chunks) || len(sizes) != 3 || sizes[0] != 10 || sizes[2] != 5 {
+			t.Errorf("%s: expected 25 chunks in batches of 10, 10, 5; got %d chunks in %v", name, len(seen), sizes)
+		}
+	}
+}
+
+func TestMigrate_VerifyDetectsExtraTargetData(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	src := NewGOBStore(filepath.Join(dir, "src.gob"))
+	if err := src.SaveChunks(ctx, []Chunk{{ID: "a_0", FilePath: "a.go", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	dst := NewGOBStore(filepath.Join(dir, "dst.gob"))
+	if err := dst.SaveChunks(ctx, []Chunk{{ID: "stale", FilePath: "old.go", Vector: []float32{0, 1}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	if _, err := Migrate(ctx, src, dst, MigrateOptions{}); err == nil {
+		t.Fatal("expected verification to fail when target has extra chunks")
+	}
+}
+
+func TestClearStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+	if err := s.SaveChunks(ctx, []Chunk{{ID: "a_0", FilePath: "a.go", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := s.SaveDocument(ctx, Document{Path: "a.go", ChunkIDs: []string{"a_0"}}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	if err := ClearStore(ctx, s); err != nil {
+		t.Fatalf("ClearStore failed: %v", err)
+	}
+	if docs, chunks := s.Stats(); docs != 0 || chunks != 0 {
+		t.Errorf("expected empty store, got %d docs and %d chunks", docs, chunks)
+	}
+}
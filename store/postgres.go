@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -32,6 +33,10 @@ func NewPostgresStore(ctx context.Context, dsn string, projectID string, vectorD
 		pool.Close()
 		return nil, err
 	}
+	if err := store.backfillTextIndex(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
 
 	return store, nil
 }
@@ -61,7 +66,16 @@ func (s *PostgresStore) ensureSchema(ctx context.Context) error {
 			PRIMARY KEY (project_id, path)
 		)`,
 		`ALTER TABLE chunks ADD COLUMN IF NOT EXISTS content_hash TEXT DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS index_manifests (
+			project_id TEXT PRIMARY KEY,
+			manifest JSONB NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
 		`CREATE INDEX IF NOT EXISTS idx_chunks_content_hash ON chunks(content_hash) WHERE content_hash != ''`,
+		`ALTER TABLE chunks ADD COLUMN IF NOT EXISTS symbol_kinds TEXT[] NOT NULL DEFAULT '{}'`,
+		`CREATE INDEX IF NOT EXISTS idx_chunks_symbol_kinds ON chunks USING GIN (symbol_kinds)`,
+		`ALTER TABLE chunks ADD COLUMN IF NOT EXISTS headings TEXT[] NOT NULL DEFAULT '{}'`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_mod_time ON documents(project_id, mod_time)`,
 		buildEnsureVectorSQL(s.dimensions),
 		// Migrate chunks primary key from (id) to (project_id, id) so that
 		// worktrees sharing the same database get their own chunk rows instead
@@ -78,6 +92,24 @@ func (s *PostgresStore) ensureSchema(ctx context.Context) error {
 				ALTER TABLE chunks ADD PRIMARY KEY (project_id, id);
 			END IF;
 		END $$`,
+		// Full-text index: chunk lengths and term frequencies, removed with
+		// their chunk. Created after the key migration they reference.
+		`CREATE TABLE IF NOT EXISTS text_docs (
+			project_id TEXT NOT NULL,
+			chunk_id TEXT NOT NULL,
+			length INTEGER NOT NULL,
+			PRIMARY KEY (project_id, chunk_id),
+			FOREIGN KEY (project_id, chunk_id) REFERENCES chunks(project_id, id) ON DELETE CASCADE
+		)`,
+		`CREATE TABLE IF NOT EXISTS text_terms (
+			project_id TEXT NOT NULL,
+			term TEXT NOT NULL,
+			chunk_id TEXT NOT NULL,
+			tf INTEGER NOT NULL,
+			PRIMARY KEY (project_id, term, chunk_id),
+			FOREIGN KEY (project_id, chunk_id) REFERENCES chunks(project_id, id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_text_terms_chunk ON text_terms(project_id, chunk_id)`,
 	}
 
 	for _, query := range queries {
@@ -99,8 +131,8 @@ func (s *PostgresStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
 	for _, chunk := range chunks {
 		vec := pgvector.NewVector(chunk.Vector)
 		batch.Queue(
-			`INSERT INTO chunks (id, project_id, file_path, start_line, end_line, content, vector, hash, content_hash, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`INSERT INTO chunks (id, project_id, file_path, start_line, end_line, content, vector, hash, content_hash, updated_at, symbol_kinds, headings)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 			ON CONFLICT (project_id, id) DO UPDATE SET
 				file_path = EXCLUDED.file_path,
 				start_line = EXCLUDED.start_line,
@@ -109,16 +141,23 @@ func (s *PostgresStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
 				vector = EXCLUDED.vector,
 				hash = EXCLUDED.hash,
 				content_hash = EXCLUDED.content_hash,
-				updated_at = EXCLUDED.updated_at`,
+				updated_at = EXCLUDED.updated_at,
+				symbol_kinds = EXCLUDED.symbol_kinds,
+				headings = EXCLUDED.headings`,
 			chunk.ID, s.projectID, chunk.FilePath, chunk.StartLine, chunk.EndLine,
 			chunk.Content, vec, chunk.Hash, chunk.ContentHash, chunk.UpdatedAt,
+			textArray(chunk.SymbolKinds), textArray(chunk.Headings),
 		)
 	}
 
+	queuePostgresChunkText(batch, s.projectID, chunks)
+
+	// A batch runs in a single implicit transaction, so the text index
+	// never lags the chunks.
 	results := s.pool.SendBatch(ctx, batch)
 	defer results.Close()
 
-	for range chunks {
+	for range batch.Len() {
 		if _, err := results.Exec(); err != nil {
 			return fmt.Errorf("failed to save chunk: %w", err)
 		}
@@ -138,27 +177,40 @@ func (s *PostgresStore) DeleteByFile(ctx context.Context, filePath string) error
 	return nil
 }
 
+// DeleteChunks removes chunks by ID, leaving documents untouched.
+func (s *PostgresStore) DeleteChunks(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := s.pool.Exec(ctx,
+		`DELETE FROM chunks WHERE project_id = $1 AND id = ANY($2)`,
+		s.projectID, ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
 func (s *PostgresStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
+	opts, err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	vec := pgvector.NewVector(queryVector)
 
-	query := `SELECT id, file_path, start_line, end_line, content, vector, hash, updated_at,
+	query := `SELECT id, file_path, start_line, end_line, content, vector, hash, updated_at, symbol_kinds, headings,
 		1 - (vector <=> $1) as score
 	FROM chunks
 	WHERE project_id = $2`
 
 	args := []interface{}{vec, s.projectID}
-	nextParam := 3
-
-	// Add path prefix filter if provided
-	if opts.PathPrefix != "" {
-		query += ` AND file_path LIKE $` + fmt.Sprintf("%d", nextParam)
-		args = append(args, opts.PathPrefix+"%")
-		nextParam++
-	}
+	query += postgresSearchConditions(opts, &args)
 
-	query += ` ORDER BY vector <=> $1
-	LIMIT $` + fmt.Sprintf("%d", nextParam)
 	args = append(args, limit)
+	query += fmt.Sprintf(` ORDER BY vector <=> $1
+	LIMIT $%d`, len(args))
 
 	rows, err := s.pool.Query(ctx, query, args...)
 	if err != nil {
@@ -174,7 +226,7 @@ func (s *PostgresStore) Search(ctx context.Context, queryVector []float32, limit
 
 		if err := rows.Scan(
 			&chunk.ID, &chunk.FilePath, &chunk.StartLine, &chunk.EndLine,
-			&chunk.Content, &vec, &chunk.Hash, &chunk.UpdatedAt, &score,
+			&chunk.Content, &vec, &chunk.Hash, &chunk.UpdatedAt, &chunk.SymbolKinds, &chunk.Headings, &score,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
@@ -189,6 +241,57 @@ func (s *PostgresStore) Search(ctx context.Context, queryVector []float32, limit
 	return results, rows.Err()
 }
 
+// postgresSearchConditions returns the SQL conditions, each starting with
+// AND, applying opts to the chunks table. Their arguments are appended to
+// args.
+func postgresSearchConditions(opts SearchOptions, args *[]interface{}) string {
+	// param appends a query argument and returns its placeholder.
+	param := func(value interface{}) string {
+		*args = append(*args, value)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+
+	var query string
+	// Add path prefix filter if provided
+	if opts.PathPrefix != "" {
+		query += ` AND file_path LIKE ` + param(opts.PathPrefix+"%")
+	}
+	if len(opts.Languages) > 0 {
+		query += ` AND file_path ~* ` + param(languagesToRegexp(opts.Languages))
+	}
+	if len(opts.IncludeGlobs) > 0 {
+		// Validate already checked the patterns.
+		expr, _ := globsToRegexp(opts.IncludeGlobs)
+		query += ` AND file_path ~ ` + param(expr)
+	}
+	if len(opts.ExcludeGlobs) > 0 {
+		expr, _ := globsToRegexp(opts.ExcludeGlobs)
+		query += ` AND file_path !~ ` + param(expr)
+	}
+	if !opts.ModifiedAfter.IsZero() || !opts.ModifiedBefore.IsZero() {
+		query += ` AND EXISTS (SELECT 1 FROM documents d WHERE d.project_id = chunks.project_id AND d.path = chunks.file_path`
+		if !opts.ModifiedAfter.IsZero() {
+			query += ` AND d.mod_time >= ` + param(opts.ModifiedAfter)
+		}
+		if !opts.ModifiedBefore.IsZero() {
+			query += ` AND d.mod_time < ` + param(opts.ModifiedBefore)
+		}
+		query += `)`
+	}
+	if len(opts.SymbolKinds) > 0 {
+		query += ` AND symbol_kinds && ` + param(opts.SymbolKinds)
+	}
+	return query
+}
+
+// textArray returns a non-nil slice so NULL never reaches the NOT NULL column.
+func textArray(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 func (s *PostgresStore) GetDocument(ctx context.Context, filePath string) (*Document, error) {
 	var doc Document
 	var modTime time.Time
@@ -268,6 +371,17 @@ func (s *PostgresStore) Persist(ctx context.Context) error {
 	return nil
 }
 
+// Compact vacuums the tables so dead rows left by deletions can be reused
+// and refreshes planner statistics. The tables are shared by all projects.
+func (s *PostgresStore) Compact(ctx context.Context) error {
+	for _, table := range []string{"chunks", "documents", "text_docs", "text_terms"} {
+		if _, err := s.pool.Exec(ctx, `VACUUM ANALYZE `+table); err != nil {
+			return fmt.Errorf("failed to vacuum %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
 func (s *PostgresStore) Close() error {
 	s.pool.Close()
 	return nil
@@ -352,7 +466,7 @@ func (s *PostgresStore) GetChunksForFile(ctx context.Context, filePath string) (
 
 func (s *PostgresStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
 	rows, err := s.pool.Query(ctx,
-		`SELECT id, file_path, start_line, end_line, content, hash, updated_at
+		`SELECT id, file_path, start_line, end_line, content, hash, updated_at, symbol_kinds, headings
 		FROM chunks WHERE project_id = $1`,
 		s.projectID,
 	)
@@ -364,9 +478,54 @@ func (s *PostgresStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
 	var chunks []Chunk
 	for rows.Next() {
 		var c Chunk
-		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Hash, &c.UpdatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Hash, &c.UpdatedAt, &c.SymbolKinds, &c.Headings); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		chunks = append(chunks, c)
+	}
+
+	return chunks, rows.Err()
+}
+
+// IterateChunks implements ChunkIterator with keyset pagination on the
+// chunk ID. Unlike GetAllChunks it returns vectors.
+func (s *PostgresStore) IterateChunks(ctx context.Context, batchSize int, fn func([]Chunk) error) error {
+	after := ""
+	for {
+		batch, err := s.chunksAfter(ctx, after, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
+func (s *PostgresStore) chunksAfter(ctx context.Context, after string, limit int) ([]Chunk, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT id, file_path, start_line, end_line, content, vector, hash, COALESCE(content_hash, ''), updated_at, symbol_kinds, headings
+		FROM chunks WHERE project_id = $1 AND id > $2
+		ORDER BY id LIMIT $3`,
+		s.projectID, after, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []Chunk
+	for rows.Next() {
+		var c Chunk
+		var vec pgvector.Vector
+		if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &vec, &c.Hash, &c.ContentHash, &c.UpdatedAt, &c.SymbolKinds, &c.Headings); err != nil {
 			return nil, fmt.Errorf("failed to scan chunk: %w", err)
 		}
+		c.Vector = vec.Slice()
 		chunks = append(chunks, c)
 	}
 
@@ -395,6 +554,47 @@ func (s *PostgresStore) LookupByContentHash(ctx context.Context, contentHash str
 	return vec.Slice(), true, nil
 }
 
+// GetManifest reads the manifest recorded for this project.
+func (s *PostgresStore) GetManifest(ctx context.Context) (*IndexManifest, error) {
+	var raw []byte
+	err := s.pool.QueryRow(ctx,
+		`SELECT manifest FROM index_manifests WHERE project_id = $1`,
+		s.projectID,
+	).Scan(&raw)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest IndexManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// SaveManifest records the manifest for this project.
+func (s *PostgresStore) SaveManifest(ctx context.Context, manifest IndexManifest) error {
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	_, err = s.pool.Exec(ctx,
+		`INSERT INTO index_manifests (project_id, manifest, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (project_id) DO UPDATE SET
+			manifest = EXCLUDED.manifest,
+			updated_at = EXCLUDED.updated_at`,
+		s.projectID, encoded, manifest.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+	return nil
+}
+
 // buildEnsureVectorSQL returns a SQL block that alters the "chunks.vector" column
 // only if its current dimension differs from the specified one.
 func buildEnsureVectorSQL(dim int) string {
@@ -418,3 +618,183 @@ BEGIN
 END$$;
 `, dim, dim, dim, dim)
 }
+
+// postgresTextBatchSize is the number of chunks indexed per transaction by
+// backfillTextIndex and of ranked chunks loaded per query by TextSearch.
+const postgresTextBatchSize = 200
+
+// queuePostgresChunkText queues the statements replacing the full-text index
+// entries of chunks.
+func queuePostgresChunkText(batch *pgx.Batch, projectID string, chunks []Chunk) {
+	ids := make([]string, 0, len(chunks))
+	lengths := make([]int32, 0, len(chunks))
+	var terms, termIDs []string
+	var tfs []int32
+	for _, chunk := range chunks {
+		freqs, length := termFrequencies(chunk)
+		ids = append(ids, chunk.ID)
+		lengths = append(lengths, int32(length))
+		for term, tf := range freqs {
+			terms = append(terms, term)
+			termIDs = append(termIDs, chunk.ID)
+			tfs = append(tfs, int32(tf))
+		}
+	}
+
+	batch.Queue(
+		`DELETE FROM text_terms WHERE project_id = $1 AND chunk_id = ANY($2)`,
+		projectID, ids,
+	)
+	batch.Queue(
+		`INSERT INTO text_terms (project_id, term, chunk_id, tf)
+		SELECT $1, t.term, t.chunk_id, t.tf FROM unnest($2::text[], $3::text[], $4::int[]) AS t(term, chunk_id, tf)`,
+		projectID, textArray(terms), textArray(termIDs), tfs,
+	)
+	batch.Queue(
+		`INSERT INTO text_docs (project_id, chunk_id, length)
+		SELECT $1, d.chunk_id, d.length FROM unnest($2::text[], $3::int[]) AS d(chunk_id, length)
+		ON CONFLICT (project_id, chunk_id) DO UPDATE SET length = EXCLUDED.length`,
+		projectID, ids, lengths,
+	)
+}
+
+// backfillTextIndex indexes the text of chunks saved before the full-text
+// index existed, a batch per transaction.
+func (s *PostgresStore) backfillTextIndex(ctx context.Context) error {
+	for {
+		rows, err := s.pool.Query(ctx,
+			`SELECT id, file_path, content FROM chunks c
+			WHERE project_id = $1 AND NOT EXISTS (
+				SELECT 1 FROM text_docs d WHERE d.project_id = c.project_id AND d.chunk_id = c.id
+			) LIMIT $2`,
+			s.projectID, postgresTextBatchSize,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to list chunks missing from the text index: %w", err)
+		}
+		var chunks []Chunk
+		for rows.Next() {
+			var chunk Chunk
+			if err := rows.Scan(&chunk.ID, &chunk.FilePath, &chunk.Content); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan chunk: %w", err)
+			}
+			chunks = append(chunks, chunk)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to iterate chunks: %w", err)
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+
+		batch := &pgx.Batch{}
+		queuePostgresChunkText(batch, s.projectID, chunks)
+		if err := s.pool.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to index chunk text: %w", err)
+		}
+	}
+}
+
+// TextSearch ranks chunks against the query terms with the full-text index.
+// Only the postings of the query terms are read; chunks are then loaded best
+// first until limit of them pass the filters.
+func (s *PostgresStore) TextSearch(ctx context.Context, query string, limit int, opts SearchOptions) ([]SearchResult, error) {
+	opts, err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var n int
+	var avgLen float64
+	if err := s.pool.QueryRow(ctx,
+		`SELECT COUNT(*), COALESCE(AVG(length), 0) FROM text_docs WHERE project_id = $1`,
+		s.projectID,
+	).Scan(&n, &avgLen); err != nil {
+		return nil, fmt.Errorf("failed to read text index statistics: %w", err)
+	}
+
+	type posting struct {
+		id         string
+		tf, docLen int
+	}
+	rows, err := s.pool.Query(ctx,
+		`SELECT t.term, t.chunk_id, t.tf, d.length FROM text_terms t
+		JOIN text_docs d ON d.project_id = t.project_id AND d.chunk_id = t.chunk_id
+		WHERE t.project_id = $1 AND t.term = ANY($2)`,
+		s.projectID, terms,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read text index: %w", err)
+	}
+	postings := make(map[string][]posting, len(terms))
+	for rows.Next() {
+		var term string
+		var p posting
+		if err := rows.Scan(&term, &p.id, &p.tf, &p.docLen); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan text index entry: %w", err)
+		}
+		postings[term] = append(postings[term], p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate text index: %w", err)
+	}
+
+	scores := make(map[string]float64)
+	for _, termPostings := range postings {
+		for _, p := range termPostings {
+			scores[p.id] += bm25(p.tf, p.docLen, avgLen, len(termPostings), n)
+		}
+	}
+
+	hits := rankHits(scores, 0, nil)
+	var results []SearchResult
+	for start := 0; start < len(hits) && (limit <= 0 || len(results) < limit); start += postgresTextBatchSize {
+		batch := hits[start:min(start+postgresTextBatchSize, len(hits))]
+		ids := make([]string, len(batch))
+		for i, hit := range batch {
+			ids[i] = hit.ID
+		}
+		args := []interface{}{s.projectID, ids}
+		rows, err := s.pool.Query(ctx,
+			`SELECT id, file_path, start_line, end_line, content, hash, updated_at, symbol_kinds, headings
+			FROM chunks WHERE project_id = $1 AND id = ANY($2)`+postgresSearchConditions(opts, &args),
+			args...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load text search results: %w", err)
+		}
+		chunks := make(map[string]Chunk, len(batch))
+		for rows.Next() {
+			var c Chunk
+			if err := rows.Scan(&c.ID, &c.FilePath, &c.StartLine, &c.EndLine, &c.Content, &c.Hash, &c.UpdatedAt, &c.SymbolKinds, &c.Headings); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan chunk: %w", err)
+			}
+			chunks[c.ID] = c
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to iterate text search results: %w", err)
+		}
+
+		for _, hit := range batch {
+			chunk, ok := chunks[hit.ID]
+			if !ok {
+				continue
+			}
+			results = append(results, SearchResult{Chunk: chunk, Score: hit.Score})
+			if limit > 0 && len(results) == limit {
+				break
+			}
+		}
+	}
+	return results, nil
+}
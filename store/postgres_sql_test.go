@@ -82,3 +82,20 @@ func strconvItoa(n int) string {
 		return string(buf[i:])
 	}
 }
+
+// TestPostgresSearchConditions verifies that the filter placeholders continue
+// the numbering of the arguments already bound by the caller.
+func TestPostgresSearchConditions(t *testing.T) {
+	args := []interface{}{"project", []string{"id"}}
+	opts := SearchOptions{PathPrefix: "src/", SymbolKinds: []string{"function"}}
+
+	got := postgresSearchConditions(opts, &args)
+
+	want := ` AND file_path LIKE $3 AND symbol_kinds && $4`
+	if got != want {
+		t.Fatalf("postgresSearchConditions() = %q, want %q", got, want)
+	}
+	if len(args) != 4 || args[2] != "src/%" {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
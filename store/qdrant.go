@@ -2,8 +2,12 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -25,6 +29,10 @@ type QdrantStore struct {
 	collectionName string
 	dimensions     int
 	apiKey         string
+
+	// textMu serializes updates of the text length total in the collection
+	// metadata.
+	textMu sync.Mutex
 }
 
 func parseHost(endpoint string) string {
@@ -95,13 +103,22 @@ func (s *QdrantStore) ensureCollection(ctx context.Context) error {
 		}
 	}
 
-	// Create field index for content_hash to enable efficient lookups.
-	// Error is intentionally ignored because the index may already exist.
-	_, _ = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
-		CollectionName: s.collectionName,
-		FieldName:      "content_hash",
-		FieldType:      qdrant.PtrOf(qdrant.FieldType_FieldTypeKeyword),
-	})
+	// Create field indexes for content_hash lookups and search filters.
+	// Errors are intentionally ignored because the indexes may already exist.
+	fieldIndexes := map[string]qdrant.FieldType{
+		"content_hash": qdrant.FieldType_FieldTypeKeyword,
+		"language":     qdrant.FieldType_FieldTypeKeyword,
+		"symbol_kinds": qdrant.FieldType_FieldTypeKeyword,
+		"mod_time":     qdrant.FieldType_FieldTypeInteger,
+		"text_terms":   qdrant.FieldType_FieldTypeKeyword,
+	}
+	for field, fieldType := range fieldIndexes {
+		_, _ = s.client.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+			CollectionName: s.collectionName,
+			FieldName:      field,
+			FieldType:      qdrant.PtrOf(fieldType),
+		})
+	}
 
 	return nil
 }
@@ -125,22 +142,38 @@ func (s *QdrantStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
 	}
 
 	points := make([]*qdrant.PointStruct, 0, len(chunks))
+	ids := make([]*qdrant.PointId, 0, len(chunks))
+	var added int64
 	for _, chunk := range chunks {
 		payload, err := s.buildChunkPayload(chunk)
 		if err != nil {
 			return fmt.Errorf("failed to build payload: %w", err)
 		}
 
-		pointID := s.getUUIDForChunk(chunk.ID)
+		pointID := qdrant.NewID(s.getUUIDForChunk(chunk.ID).String())
+		ids = append(ids, pointID)
+		added += payload["text_length"].GetIntegerValue()
 
 		points = append(points, &qdrant.PointStruct{
-			Id:      qdrant.NewID(pointID.String()),
+			Id:      pointID,
 			Vectors: qdrant.NewVectors(chunk.Vector...),
 			Payload: payload,
 		})
 	}
 
-	_, err := s.client.Upsert(ctx, &qdrant.UpsertPoints{
+	s.textMu.Lock()
+	defer s.textMu.Unlock()
+
+	replaced, err := s.client.Get(ctx, &qdrant.GetPoints{
+		CollectionName: s.collectionName,
+		Ids:            ids,
+		WithPayload:    qdrant.NewWithPayloadInclude("text_length"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read replaced points: %w", err)
+	}
+
+	_, err = s.client.Upsert(ctx, &qdrant.UpsertPoints{
 		CollectionName: s.collectionName,
 		Points:         points,
 	})
@@ -148,7 +181,7 @@ func (s *QdrantStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
 		return fmt.Errorf("failed to upsert points: %w", err)
 	}
 
-	return nil
+	return s.addTextLength(ctx, added-sumTextLength(replaced))
 }
 
 func (s *QdrantStore) buildChunkPayload(chunk Chunk) (map[string]*qdrant.Value, error) {
@@ -199,6 +232,39 @@ func (s *QdrantStore) buildChunkPayload(chunk Chunk) (map[string]*qdrant.Value,
 		payload["content_hash"] = contentHashVal
 	}
 
+	if lang := LanguageForPath(chunk.FilePath); lang != "" {
+		payload["language"] = qdrant.NewValueString(lang)
+	}
+
+	if len(chunk.SymbolKinds) > 0 {
+		kinds := make([]*qdrant.Value, 0, len(chunk.SymbolKinds))
+		for _, kind := range chunk.SymbolKinds {
+			kinds = append(kinds, qdrant.NewValueString(kind))
+		}
+		payload["symbol_kinds"] = qdrant.NewValueFromList(kinds...)
+	}
+
+	if len(chunk.Headings) > 0 {
+		headings := make([]*qdrant.Value, 0, len(chunk.Headings))
+		for _, heading := range chunk.Headings {
+			headings = append(headings, qdrant.NewValueString(heading))
+		}
+		payload["headings"] = qdrant.NewValueFromList(headings...)
+	}
+
+	// The full-text index entry of the chunk: its distinct terms, keyword
+	// indexed, their frequencies in the same order and its length in terms.
+	freqs, length := termFrequencies(chunk)
+	terms := make([]*qdrant.Value, 0, len(freqs))
+	tfs := make([]*qdrant.Value, 0, len(freqs))
+	for term, tf := range freqs {
+		terms = append(terms, qdrant.NewValueString(term))
+		tfs = append(tfs, qdrant.NewValueInt(int64(tf)))
+	}
+	payload["text_terms"] = qdrant.NewValueFromList(terms...)
+	payload["text_tfs"] = qdrant.NewValueFromList(tfs...)
+	payload["text_length"] = qdrant.NewValueInt(int64(length))
+
 	return payload, nil
 }
 
@@ -209,7 +275,20 @@ func (s *QdrantStore) DeleteByFile(ctx context.Context, filePath string) error {
 		},
 	}
 
-	_, err := s.client.Delete(ctx, &qdrant.DeletePoints{
+	s.textMu.Lock()
+	defer s.textMu.Unlock()
+
+	deleted, err := s.client.Scroll(ctx, &qdrant.ScrollPoints{
+		CollectionName: s.collectionName,
+		Filter:         filter,
+		Limit:          qdrant.PtrOf(uint32(10000)),
+		WithPayload:    qdrant.NewWithPayloadInclude("text_length"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read deleted points: %w", err)
+	}
+
+	_, err = s.client.Delete(ctx, &qdrant.DeletePoints{
 		CollectionName: s.collectionName,
 		Points:         qdrant.NewPointsSelectorFilter(filter),
 	})
@@ -217,7 +296,7 @@ func (s *QdrantStore) DeleteByFile(ctx context.Context, filePath string) error {
 		return fmt.Errorf("failed to delete points: %w", err)
 	}
 
-	return nil
+	return s.addTextLength(ctx, -sumTextLength(deleted))
 }
 
 func (s *QdrantStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
@@ -225,15 +304,25 @@ func (s *QdrantStore) Search(ctx context.Context, queryVector []float32, limit i
 		return nil, fmt.Errorf("limit must be positive, got: %d", limit)
 	}
 
-	// Fetch more results to account for filtering by path prefix
+	filter, err := newChunkFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	opts = filter.opts
+
+	// Path prefix and globs have no payload index, so they are applied to
+	// the results; fetch more to account for them.
 	fetchLimit := limit
-	if opts.PathPrefix != "" {
-		// Fetch 2x the limit to allow for filtering
+	if opts.PathPrefix != "" || len(opts.IncludeGlobs) > 0 || len(opts.ExcludeGlobs) > 0 {
+		factor := 2
+		if len(opts.IncludeGlobs) > 0 || len(opts.ExcludeGlobs) > 0 {
+			factor = 4
+		}
 		maxInt := int(^uint(0) >> 1)
-		if limit > maxInt/2 {
+		if limit > maxInt/factor {
 			fetchLimit = maxInt
 		} else {
-			fetchLimit = limit * 2
+			fetchLimit = limit * factor
 		}
 	}
 	if fetchLimit < 0 {
@@ -244,8 +333,9 @@ func (s *QdrantStore) Search(ctx context.Context, queryVector []float32, limit i
 	searchResult, err := s.client.Query(ctx, &qdrant.QueryPoints{
 		CollectionName: s.collectionName,
 		Query:          qdrant.NewQuery(queryVector...),
+		Filter:         qdrantSearchFilter(opts),
 		Limit:          qdrant.PtrOf(fetchLimitU64),
-		WithPayload:    qdrant.NewWithPayloadInclude("file_path", "start_line", "end_line", "content", "hash", "updated_at"),
+		WithPayload:    qdrant.NewWithPayloadInclude("file_path", "start_line", "end_line", "content", "hash", "updated_at", "symbol_kinds", "headings"),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
@@ -255,8 +345,8 @@ func (s *QdrantStore) Search(ctx context.Context, queryVector []float32, limit i
 	for _, point := range searchResult {
 		chunk := s.parseChunkPayload(point.Payload)
 
-		// Filter by path prefix if provided
-		if opts.PathPrefix != "" && !strings.HasPrefix(chunk.FilePath, opts.PathPrefix) {
+		// Filter by path prefix and globs if provided
+		if !filter.matchPath(chunk.FilePath) {
 			continue
 		}
 
@@ -274,6 +364,33 @@ func (s *QdrantStore) Search(ctx context.Context, queryVector []float32, limit i
 	return results, nil
 }
 
+// qdrantSearchFilter builds the payload filter for the indexed search
+// options: language, symbol kinds and modification time. Points written
+// before these payload fields existed do not match them until re-indexed.
+func qdrantSearchFilter(opts SearchOptions) *qdrant.Filter {
+	var must []*qdrant.Condition
+	if len(opts.Languages) > 0 {
+		must = append(must, qdrant.NewMatchKeywords("language", opts.Languages...))
+	}
+	if len(opts.SymbolKinds) > 0 {
+		must = append(must, qdrant.NewMatchKeywords("symbol_kinds", opts.SymbolKinds...))
+	}
+	if !opts.ModifiedAfter.IsZero() || !opts.ModifiedBefore.IsZero() {
+		r := &qdrant.Range{}
+		if !opts.ModifiedAfter.IsZero() {
+			r.Gte = qdrant.PtrOf(float64(opts.ModifiedAfter.Unix()))
+		}
+		if !opts.ModifiedBefore.IsZero() {
+			r.Lt = qdrant.PtrOf(float64(opts.ModifiedBefore.Unix()))
+		}
+		must = append(must, qdrant.NewRange("mod_time", r))
+	}
+	if len(must) == 0 {
+		return nil
+	}
+	return &qdrant.Filter{Must: must}
+}
+
 func (s *QdrantStore) parseChunkPayload(payload map[string]*qdrant.Value) *Chunk {
 	chunk := &Chunk{}
 	if val, ok := payload["file_path"]; ok {
@@ -300,6 +417,16 @@ func (s *QdrantStore) parseChunkPayload(payload map[string]*qdrant.Value) *Chunk
 	if val, ok := payload["content_hash"]; ok {
 		chunk.ContentHash = val.GetStringValue()
 	}
+	if val, ok := payload["symbol_kinds"]; ok {
+		for _, kind := range val.GetListValue().GetValues() {
+			chunk.SymbolKinds = append(chunk.SymbolKinds, kind.GetStringValue())
+		}
+	}
+	if val, ok := payload["headings"]; ok {
+		for _, heading := range val.GetListValue().GetValues() {
+			chunk.Headings = append(chunk.Headings, heading.GetStringValue())
+		}
+	}
 
 	return chunk
 }
@@ -333,7 +460,27 @@ func (s *QdrantStore) GetDocument(ctx context.Context, filePath string) (*Docume
 	return doc, nil
 }
 
+// SaveDocument records the file modification time on the document's points
+// so searches can filter on it. Qdrant keeps no separate document metadata.
 func (s *QdrantStore) SaveDocument(ctx context.Context, doc Document) error {
+	if doc.ModTime.IsZero() {
+		return nil
+	}
+
+	_, err := s.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: s.collectionName,
+		Payload: map[string]*qdrant.Value{
+			"mod_time": qdrant.NewValueInt(doc.ModTime.Unix()),
+		},
+		PointsSelector: qdrant.NewPointsSelectorFilter(&qdrant.Filter{
+			Must: []*qdrant.Condition{
+				qdrant.NewMatch("file_path", doc.Path),
+			},
+		}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save document metadata: %w", err)
+	}
 	return nil
 }
 
@@ -474,7 +621,7 @@ func (s *QdrantStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
 	scrollResult, err := s.client.Scroll(ctx, &qdrant.ScrollPoints{
 		CollectionName: s.collectionName,
 		Limit:          qdrant.PtrOf(uint32(100000)),
-		WithPayload:    qdrant.NewWithPayloadInclude("file_path", "start_line", "end_line", "content", "hash", "updated_at"),
+		WithPayload:    qdrant.NewWithPayloadInclude("file_path", "start_line", "end_line", "content", "hash", "updated_at", "content_hash", "symbol_kinds", "headings"),
 		WithVectors:    qdrant.NewWithVectors(true),
 	})
 	if err != nil {
@@ -530,3 +677,200 @@ func (s *QdrantStore) LookupByContentHash(ctx context.Context, contentHash strin
 
 	return nil, false, nil
 }
+
+// qdrantManifestKey is the collection metadata key holding the index manifest.
+const qdrantManifestKey = "grepai_manifest"
+
+// GetManifest reads the manifest from the collection metadata.
+func (s *QdrantStore) GetManifest(ctx context.Context) (*IndexManifest, error) {
+	info, err := s.client.GetCollectionInfo(ctx, s.collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection info: %w", err)
+	}
+
+	val, ok := info.GetConfig().GetMetadata()[qdrantManifestKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var manifest IndexManifest
+	if err := json.Unmarshal([]byte(val.GetStringValue()), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// SaveManifest stores the manifest in the collection metadata.
+func (s *QdrantStore) SaveManifest(ctx context.Context, manifest IndexManifest) error {
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	err = s.client.UpdateCollection(ctx, &qdrant.UpdateCollection{
+		CollectionName: s.collectionName,
+		Metadata: map[string]*qdrant.Value{
+			qdrantManifestKey: qdrant.NewValueString(string(encoded)),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+	return nil
+}
+
+// qdrantTextLengthKey is the collection metadata key holding the total
+// length in terms of the indexed chunks, for BM25 length normalization.
+const qdrantTextLengthKey = "grepai_text_length"
+
+// qdrantTextCandidates bounds the points TextSearch scores per query.
+const qdrantTextCandidates = 1000
+
+// sumTextLength adds up the text_length payload of points.
+func sumTextLength(points []*qdrant.RetrievedPoint) int64 {
+	var total int64
+	for _, point := range points {
+		total += point.GetPayload()["text_length"].GetIntegerValue()
+	}
+	return total
+}
+
+// addTextLength adds delta to the text length total of the collection.
+// Caller must hold s.textMu.
+func (s *QdrantStore) addTextLength(ctx context.Context, delta int64) error {
+	if delta == 0 {
+		return nil
+	}
+	info, err := s.client.GetCollectionInfo(ctx, s.collectionName)
+	if err != nil {
+		return fmt.Errorf("failed to get collection info: %w", err)
+	}
+	total := max(info.GetConfig().GetMetadata()[qdrantTextLengthKey].GetIntegerValue()+delta, 0)
+	err = s.client.UpdateCollection(ctx, &qdrant.UpdateCollection{
+		CollectionName: s.collectionName,
+		Metadata: map[string]*qdrant.Value{
+			qdrantTextLengthKey: qdrant.NewValueInt(total),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save text index statistics: %w", err)
+	}
+	return nil
+}
+
+// TextSearch ranks chunks against the query terms with the keyword index on
+// the text_terms payload, scoring with the term frequencies and lengths
+// recorded at save time. Candidates are gathered rarest term first, up to
+// qdrantTextCandidates points, so a term found in most chunks does not load
+// the collection; only the returned chunks are fetched with their content.
+// Document frequencies are Qdrant's index estimates. Points written before
+// the text payload existed do not match until re-indexed.
+func (s *QdrantStore) TextSearch(ctx context.Context, query string, limit int, opts SearchOptions) ([]SearchResult, error) {
+	filter, err := newChunkFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	info, err := s.client.GetCollectionInfo(ctx, s.collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection info: %w", err)
+	}
+	n := int(info.GetPointsCount())
+	if n == 0 {
+		return nil, nil
+	}
+	avgLen := float64(info.GetConfig().GetMetadata()[qdrantTextLengthKey].GetIntegerValue()) / float64(n)
+
+	df := make(map[string]int, len(terms))
+	for _, term := range terms {
+		count, err := s.client.Count(ctx, &qdrant.CountPoints{
+			CollectionName: s.collectionName,
+			Filter:         &qdrant.Filter{Must: []*qdrant.Condition{qdrant.NewMatchKeyword("text_terms", term)}},
+			Exact:          qdrant.PtrOf(false),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to count term matches: %w", err)
+		}
+		df[term] = int(count)
+	}
+	byRarity := slices.Clone(terms)
+	sort.SliceStable(byRarity, func(i, j int) bool { return df[byRarity[i]] < df[byRarity[j]] })
+
+	var indexed []*qdrant.Condition
+	if f := qdrantSearchFilter(filter.opts); f != nil {
+		indexed = f.Must
+	}
+	budget := max(qdrantTextCandidates, limit)
+	scores := make(map[string]float64)
+	var seen []*qdrant.PointId
+	for _, term := range byRarity {
+		if len(seen) >= budget {
+			break
+		}
+		if df[term] == 0 {
+			continue
+		}
+		scrollFilter := &qdrant.Filter{Must: append([]*qdrant.Condition{qdrant.NewMatchKeyword("text_terms", term)}, indexed...)}
+		if len(seen) > 0 {
+			scrollFilter.MustNot = []*qdrant.Condition{qdrant.NewHasID(seen...)}
+		}
+		points, err := s.client.Scroll(ctx, &qdrant.ScrollPoints{
+			CollectionName: s.collectionName,
+			Filter:         scrollFilter,
+			Limit:          qdrant.PtrOf(uint32(budget - len(seen))),
+			WithPayload:    qdrant.NewWithPayloadInclude("file_path", "text_terms", "text_tfs", "text_length"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scroll text matches: %w", err)
+		}
+		for _, point := range points {
+			seen = append(seen, point.GetId())
+			payload := point.GetPayload()
+			if !filter.matchPath(payload["file_path"].GetStringValue()) {
+				continue
+			}
+			docLen := int(payload["text_length"].GetIntegerValue())
+			tfs := payload["text_tfs"].GetListValue().GetValues()
+			var score float64
+			for i, value := range payload["text_terms"].GetListValue().GetValues() {
+				if i < len(tfs) && slices.Contains(terms, value.GetStringValue()) {
+					score += bm25(int(tfs[i].GetIntegerValue()), docLen, avgLen, df[value.GetStringValue()], n)
+				}
+			}
+			scores[point.GetId().GetUuid()] = score
+		}
+	}
+
+	hits := rankHits(scores, limit, nil)
+	if len(hits) == 0 {
+		return nil, nil
+	}
+	ids := make([]*qdrant.PointId, len(hits))
+	for i, hit := range hits {
+		ids[i] = qdrant.NewID(hit.ID)
+	}
+	points, err := s.client.Get(ctx, &qdrant.GetPoints{
+		CollectionName: s.collectionName,
+		Ids:            ids,
+		WithPayload:    qdrant.NewWithPayloadInclude("file_path", "start_line", "end_line", "content", "hash", "updated_at", "symbol_kinds", "headings"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load text search results: %w", err)
+	}
+	chunks := make(map[string]*Chunk, len(points))
+	for _, point := range points {
+		chunks[point.GetId().GetUuid()] = s.parseChunkPayload(point.GetPayload())
+	}
+
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		if chunk, ok := chunks[hit.ID]; ok {
+			results = append(results, SearchResult{Chunk: *chunk, Score: hit.Score})
+		}
+	}
+	return results, nil
+}
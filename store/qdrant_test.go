@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"reflect"
 	"testing"
 	"time"
 
@@ -447,3 +448,48 @@ func TestQdrantStore_ConfigurationVariants(t *testing.T) {
 		})
 	}
 }
+
+// TestBuildChunkPayload_TextTerms verifies that the payload carries the
+// distinct terms of the chunk for the full-text index.
+func TestBuildChunkPayload_TextTerms(t *testing.T) {
+	store := &QdrantStore{}
+
+	payload, err := store.buildChunkPayload(Chunk{
+		FilePath: "auth/login.go",
+		Content:  "func parseHTTPRequest() { parseHTTPRequest() }",
+	})
+	if err != nil {
+		t.Fatalf("buildChunkPayload() error = %v", err)
+	}
+
+	terms := payload["text_terms"].GetListValue().GetValues()
+	tfs := payload["text_tfs"].GetListValue().GetValues()
+	if len(terms) != len(tfs) {
+		t.Fatalf("expected one frequency per term, got %d terms and %d frequencies", len(terms), len(tfs))
+	}
+	got := make(map[string]int64)
+	for i, value := range terms {
+		got[value.GetStringValue()] += tfs[i].GetIntegerValue()
+	}
+	want := map[string]int64{
+		"auth": 1, "login": 1, "go": 1, "func": 1,
+		"parsehttprequest": 2, "parse": 2, "http": 2, "request": 2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("text_terms/text_tfs = %v, want %v", got, want)
+	}
+	if length := payload["text_length"].GetIntegerValue(); length != 12 {
+		t.Errorf("expected text_length 12, got %d", length)
+	}
+}
+
+func TestSumTextLength(t *testing.T) {
+	points := []*qdrant.RetrievedPoint{
+		{Payload: map[string]*qdrant.Value{"text_length": qdrant.NewValueInt(7)}},
+		{Payload: map[string]*qdrant.Value{"text_length": qdrant.NewValueInt(5)}},
+		{Payload: map[string]*qdrant.Value{}}, // written before the text index
+	}
+	if got := sumTextLength(points); got != 12 {
+		t.Errorf("sumTextLength() = %d, want 12", got)
+	}
+}
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// Quantization selects how local stores encode chunk vectors on disk and in memory.
+type Quantization string
+
+const (
+	QuantizationNone   Quantization = "none"   // float32, exact scores
+	QuantizationInt8   Quantization = "int8"   // 1 byte per dimension (4x smaller)
+	QuantizationBinary Quantization = "binary" // 1 bit per dimension (32x smaller)
+)
+
+// ErrQuantizationMismatch is returned when an index was written with a
+// different quantization mode than the one configured.
+var ErrQuantizationMismatch = errors.New("index quantization mismatch")
+
+// ParseQuantization validates a store.quantization config value.
+// An empty value means QuantizationNone.
+func ParseQuantization(value string) (Quantization, error) {
+	switch Quantization(value) {
+	case "", QuantizationNone:
+		return QuantizationNone, nil
+	case QuantizationInt8, QuantizationBinary:
+		return Quantization(value), nil
+	default:
+		return "", fmt.Errorf("invalid store.quantization %q (expected none, int8 or binary)", value)
+	}
+}
+
+// storedQuantization normalizes the mode recorded in an index header.
+// Indexes written before quantization existed have no mode and hold floats.
+func storedQuantization(value string) Quantization {
+	if value == "" {
+		return QuantizationNone
+	}
+	return Quantization(value)
+}
+
+func quantizationMismatchError(indexPath string, stored, configured Quantization) error {
+	return fmt.Errorf("%w: %s was built with quantization %q but store.quantization is %q; set it back to %q or delete the index and re-run 'grepai watch' to rebuild",
+		ErrQuantizationMismatch, indexPath, stored, configured, stored)
+}
+
+// rescoreDepth is how many candidates the quantized first pass keeps for the
+// float rescore. Binary codes are much coarser, so they need a deeper pool.
+// Zero means unlimited.
+func (q Quantization) rescoreDepth(limit int) int {
+	if limit <= 0 {
+		return 0
+	}
+	if q == QuantizationBinary {
+		return limit * 16
+	}
+	return limit * 4
+}
+
+// codeLen returns the number of code bytes of a vector with dims dimensions.
+func (q Quantization) codeLen(dims int) int {
+	if q == QuantizationBinary {
+		return (dims + 7) / 8
+	}
+	return dims
+}
+
+// quantizedVector is the compact encoding of a chunk vector.
+// Fields are exported for gob encoding.
+type quantizedVector struct {
+	Dims     int
+	Scale    float32 // int8: value per code step; binary: mean absolute value
+	CodeNorm float32 // int8 only: L2 norm of the codes, for first-pass cosine
+	Codes    []byte  // int8: one signed byte per dimension; binary: packed sign bits
+}
+
+// quantizeVector encodes vec using the given mode. mode must not be QuantizationNone.
+func quantizeVector(mode Quantization, vec []float32) quantizedVector {
+	if len(vec) == 0 {
+		return quantizedVector{}
+	}
+
+	switch mode {
+	case QuantizationBinary:
+		codes := make([]byte, (len(vec)+7)/8)
+		var absSum float64
+		for i, v := range vec {
+			if v > 0 {
+				codes[i/8] |= 1 << (i % 8)
+			}
+			absSum += math.Abs(float64(v))
+		}
+		return quantizedVector{Dims: len(vec), Scale: float32(absSum / float64(len(vec))), Codes: codes}
+	default:
+		var maxAbs float64
+		for _, v := range vec {
+			maxAbs = math.Max(maxAbs, math.Abs(float64(v)))
+		}
+		qv := quantizedVector{Dims: len(vec), Codes: make([]byte, len(vec))}
+		if maxAbs == 0 {
+			return qv
+		}
+		qv.Scale = float32(maxAbs / 127)
+		var sumSq float64
+		for i, v := range vec {
+			code := int8(math.Round(float64(v) / float64(qv.Scale)))
+			qv.Codes[i] = byte(code)
+			sumSq += float64(code) * float64(code)
+		}
+		qv.CodeNorm = float32(math.Sqrt(sumSq))
+		return qv
+	}
+}
+
+// valid reports whether the codes hold exactly Dims dimensions, so that
+// dequantize and approxSimilarity stay in range.
+func (qv quantizedVector) valid(mode Quantization) bool {
+	return qv.Dims > 0 && len(qv.Codes) == mode.codeLen(qv.Dims)
+}
+
+// dequantize reconstructs an approximate float vector. Invalid codes give nil.
+func (qv quantizedVector) dequantize(mode Quantization) []float32 {
+	if !qv.valid(mode) {
+		return nil
+	}
+	vec := make([]float32, qv.Dims)
+	switch mode {
+	case QuantizationBinary:
+		for i := range vec {
+			if qv.Codes[i/8]&(1<<(i%8)) != 0 {
+				vec[i] = qv.Scale
+			} else {
+				vec[i] = -qv.Scale
+			}
+		}
+	default:
+		for i := range vec {
+			vec[i] = float32(int8(qv.Codes[i])) * qv.Scale
+		}
+	}
+	return vec
+}
+
+func (qv quantizedVector) equal(other quantizedVector) bool {
+	if qv.Dims != other.Dims || qv.Scale != other.Scale || len(qv.Codes) != len(other.Codes) {
+		return false
+	}
+	for i := range qv.Codes {
+		if qv.Codes[i] != other.Codes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// approxSimilarity scores a stored vector against an already quantized query
+// using integer arithmetic only. It approximates cosine similarity.
+func approxSimilarity(mode Quantization, query, qv quantizedVector) float32 {
+	if query.Dims != qv.Dims || !qv.valid(mode) {
+		return -1
+	}
+
+	switch mode {
+	case QuantizationBinary:
+		hamming := 0
+		for i := range query.Codes {
+			hamming += bits.OnesCount8(query.Codes[i] ^ qv.Codes[i])
+		}
+		return 1 - 2*float32(hamming)/float32(qv.Dims)
+	default:
+		if query.CodeNorm == 0 || qv.CodeNorm == 0 {
+			return 0
+		}
+		var dot int64
+		for i := range query.Codes {
+			dot += int64(int8(query.Codes[i])) * int64(int8(qv.Codes[i]))
+		}
+		return float32(dot) / (query.CodeNorm * qv.CodeNorm)
+	}
+}
+
+// encode serializes a quantized vector for storage in a BLOB column.
+// Layout: dims uint32 | scale float32 | code norm float32 | codes.
+func (qv quantizedVector) encode() []byte {
+	if qv.Dims == 0 {
+		return nil
+	}
+	buf := make([]byte, 12+len(qv.Codes))
+	binary.LittleEndian.PutUint32(buf[0:], uint32(qv.Dims)) //nolint:gosec // Dimensions are far below uint32 range.
+	binary.LittleEndian.PutUint32(buf[4:], math.Float32bits(qv.Scale))
+	binary.LittleEndian.PutUint32(buf[8:], math.Float32bits(qv.CodeNorm))
+	copy(buf[12:], qv.Codes)
+	return buf
+}
+
+// decodeQuantizedVector deserializes bytes produced by quantizedVector.encode.
+// An empty buffer is a chunk without a vector. A truncated or corrupt buffer
+// is an error.
+func decodeQuantizedVector(buf []byte, mode Quantization) (quantizedVector, error) {
+	if len(buf) == 0 {
+		return quantizedVector{}, nil
+	}
+	if len(buf) < 12 {
+		return quantizedVector{}, fmt.Errorf("quantized vector truncated to %d bytes", len(buf))
+	}
+	qv := quantizedVector{
+		Dims:     int(binary.LittleEndian.Uint32(buf[0:])),
+		Scale:    math.Float32frombits(binary.LittleEndian.Uint32(buf[4:])),
+		CodeNorm: math.Float32frombits(binary.LittleEndian.Uint32(buf[8:])),
+		Codes:    buf[12:],
+	}
+	if !qv.valid(mode) {
+		return quantizedVector{}, fmt.Errorf("quantized vector has %d code bytes for %d dimensions", len(qv.Codes), qv.Dims)
+	}
+	return qv, nil
+}
+
+// rescoreResults replaces first-pass scores with cosine similarity against
+// the chunk vectors, then sorts and truncates. Quantized stores keep no float
+// vectors, so their candidates carry dequantized vectors and the rescore is
+// itself approximate: it refines the integer first pass, but int8 scores can
+// differ from exact ones in the third decimal and binary scores more.
+func rescoreResults(queryVector []float32, results []SearchResult, limit int) []SearchResult {
+	for i := range results {
+		results[i].Score = cosineSimilarity(queryVector, results[i].Chunk.Vector)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
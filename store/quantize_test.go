@@ -0,0 +1,213 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseQuantization(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Quantization
+		wantErr bool
+	}{
+		{"", QuantizationNone, false},
+		{"none", QuantizationNone, false},
+		{"int8", QuantizationInt8, false},
+		{"binary", QuantizationBinary, false},
+		{"pq", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuantization(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseQuantization(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseQuantization(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestQuantizeVector_RoundTrip(t *testing.T) {
+	vec := []float32{0.5, -0.25, 0.125, -1, 0, 0.75, 0.3, -0.6, 0.9}
+
+	int8Vec := quantizeVector(QuantizationInt8, vec).dequantize(QuantizationInt8)
+	if sim := cosineSimilarity(vec, int8Vec); sim < 0.999 {
+		t.Errorf("int8 round trip too lossy: cosine=%f", sim)
+	}
+
+	qv := quantizeVector(QuantizationBinary, vec)
+	if len(qv.Codes) != 2 {
+		t.Fatalf("expected 9 dims packed into 2 bytes, got %d", len(qv.Codes))
+	}
+	binVec := qv.dequantize(QuantizationBinary)
+	for i, v := range vec {
+		if (v > 0) != (binVec[i] > 0) {
+			t.Errorf("binary sign mismatch at %d: %f vs %f", i, v, binVec[i])
+		}
+	}
+
+	decoded, err := decodeQuantizedVector(quantizeVector(QuantizationInt8, vec).encode(), QuantizationInt8)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !decoded.equal(quantizeVector(QuantizationInt8, vec)) {
+		t.Error("expected encode/decode round trip to be lossless")
+	}
+}
+
+func TestDecodeQuantizedVector_Corrupt(t *testing.T) {
+	vec := []float32{0.5, -0.25, 0.125, -1, 0, 0.75, 0.3, -0.6, 0.9}
+	for _, mode := range []Quantization{QuantizationInt8, QuantizationBinary} {
+		buf := quantizeVector(mode, vec).encode()
+		if _, err := decodeQuantizedVector(buf[:len(buf)-1], mode); err == nil {
+			t.Errorf("%s: expected error for truncated codes", mode)
+		}
+		if _, err := decodeQuantizedVector(buf[:8], mode); err == nil {
+			t.Errorf("%s: expected error for truncated header", mode)
+		}
+		if qv, err := decodeQuantizedVector(nil, mode); err != nil || qv.Dims != 0 {
+			t.Errorf("%s: expected empty buffer to decode to no vector, got %+v (err=%v)", mode, qv, err)
+		}
+
+		corrupt := quantizedVector{Dims: len(vec), Codes: []byte{1}}
+		if corrupt.dequantize(mode) != nil {
+			t.Errorf("%s: expected nil when dequantizing corrupt codes", mode)
+		}
+		if score := approxSimilarity(mode, quantizeVector(mode, vec), corrupt); score != -1 {
+			t.Errorf("%s: expected -1 for corrupt codes, got %f", mode, score)
+		}
+	}
+}
+
+func TestGOBStore_QuantizedSearch(t *testing.T) {
+	for _, mode := range []Quantization{QuantizationInt8, QuantizationBinary} {
+		t.Run(string(mode), func(t *testing.T) {
+			ctx := context.Background()
+			indexPath := filepath.Join(t.TempDir(), "index.gob")
+			chunks := randomChunks(500, 64, 5)
+
+			store1 := NewGOBStore(indexPath, WithQuantization(mode))
+			if err := store1.SaveChunks(ctx, chunks); err != nil {
+				t.Fatalf("failed to save chunks: %v", err)
+			}
+			if err := store1.Persist(ctx); err != nil {
+				t.Fatalf("failed to persist: %v", err)
+			}
+
+			store2 := NewGOBStore(indexPath, WithQuantization(mode))
+			if err := store2.Load(ctx); err != nil {
+				t.Fatalf("failed to load: %v", err)
+			}
+			results, err := store2.Search(ctx, chunks[7].Vector, 3, SearchOptions{})
+			if err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			if len(results) != 3 || results[0].Chunk.ID != chunks[7].ID {
+				t.Fatalf("expected %s first, got %+v", chunks[7].ID, results)
+			}
+			if len(results[0].Chunk.Vector) != 64 {
+				t.Errorf("expected dequantized vector on result, got %d dims", len(results[0].Chunk.Vector))
+			}
+			if results[0].Score < results[1].Score || results[1].Score < results[2].Score {
+				t.Errorf("expected descending scores, got %f %f %f", results[0].Score, results[1].Score, results[2].Score)
+			}
+		})
+	}
+}
+
+func TestGOBStore_QuantizedIndexIsSmaller(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	chunks := randomChunks(200, 256, 6)
+
+	sizes := make(map[Quantization]int64)
+	for _, mode := range []Quantization{QuantizationNone, QuantizationInt8} {
+		indexPath := filepath.Join(dir, string(mode)+".gob")
+		s := NewGOBStore(indexPath, WithQuantization(mode))
+		if err := s.SaveChunks(ctx, chunks); err != nil {
+			t.Fatalf("failed to save chunks: %v", err)
+		}
+		if err := s.Persist(ctx); err != nil {
+			t.Fatalf("failed to persist: %v", err)
+		}
+		info, err := os.Stat(indexPath)
+		if err != nil {
+			t.Fatalf("failed to stat index: %v", err)
+		}
+		sizes[mode] = info.Size()
+	}
+
+	if sizes[QuantizationInt8]*2 > sizes[QuantizationNone] {
+		t.Errorf("expected int8 index to be much smaller: none=%d int8=%d", sizes[QuantizationNone], sizes[QuantizationInt8])
+	}
+}
+
+func TestGOBStore_QuantizationMismatch(t *testing.T) {
+	ctx := context.Background()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+
+	s := NewGOBStore(indexPath)
+	if err := s.SaveChunks(ctx, []Chunk{{ID: "a", FilePath: "a.go", Vector: []float32{1, 0}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := s.Persist(ctx); err != nil {
+		t.Fatalf("failed to persist: %v", err)
+	}
+
+	err := NewGOBStore(indexPath, WithQuantization(QuantizationInt8)).Load(ctx)
+	if !errors.Is(err, ErrQuantizationMismatch) {
+		t.Fatalf("expected ErrQuantizationMismatch, got %v", err)
+	}
+}
+
+func TestSQLiteStore_QuantizedSearchAndMismatch(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "index.db")
+	chunks := randomChunks(300, 32, 7)
+
+	s, err := NewSQLiteStore(ctx, dbPath, WithSQLiteQuantization(QuantizationInt8))
+	if err != nil {
+		t.Fatalf("failed to open sqlite store: %v", err)
+	}
+	if err := s.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	results, err := s.Search(ctx, chunks[11].Vector, 5, SearchOptions{})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 5 || results[0].Chunk.ID != chunks[11].ID {
+		t.Fatalf("expected %s first, got %+v", chunks[11].ID, results)
+	}
+
+	// A truncated vector is skipped instead of crashing the search
+	if _, err := s.db.ExecContext(ctx, `UPDATE chunks SET vector = substr(vector, 1, 20) WHERE id = ?`, chunks[11].ID); err != nil {
+		t.Fatalf("failed to corrupt vector: %v", err)
+	}
+	results, err = s.Search(ctx, chunks[11].Vector, 5, SearchOptions{})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Chunk.ID == chunks[11].ID {
+			t.Errorf("expected corrupt chunk %s to be skipped", r.Chunk.ID)
+		}
+	}
+
+	vec, found, err := s.LookupByContentHash(ctx, "missing")
+	if err != nil || found || vec != nil {
+		t.Errorf("unexpected lookup result: %v %v %v", vec, found, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+
+	if _, err := NewSQLiteStore(ctx, dbPath); !errors.Is(err, ErrQuantizationMismatch) {
+		t.Fatalf("expected ErrQuantizationMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,491 @@
+package store
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+)
+
+// SnapshotFormatVersion is the archive layout written by ExportSnapshot.
+// ImportSnapshot rejects archives with a newer version.
+const SnapshotFormatVersion = 1
+
+// Archive entry names. Entries are written in this order so ImportSnapshot
+// can decide which documents to keep before streaming their chunks.
+const (
+	snapshotHeaderEntry    = "snapshot.json"
+	snapshotDocumentsEntry = "documents.jsonl"
+	snapshotChunksEntry    = "chunks.jsonl"
+	snapshotExtraPrefix    = "extra/"
+)
+
+// SnapshotHeader describes the contents of an index snapshot.
+type SnapshotHeader struct {
+	FormatVersion int            `json:"format_version"`
+	Version       string         `json:"version"` // grepai version that wrote the snapshot
+	CreatedAt     time.Time      `json:"created_at"`
+	Manifest      *IndexManifest `json:"manifest,omitempty"`
+	Documents     int            `json:"documents"`
+	Chunks        int            `json:"chunks"`
+	Extras        []string       `json:"extras,omitempty"`
+}
+
+// SnapshotExportOptions configures ExportSnapshot.
+type SnapshotExportOptions struct {
+	// ProjectRoot is used to turn absolute paths in the index into paths
+	// relative to the project.
+	ProjectRoot string
+	// Version is the grepai version recorded in the header.
+	Version string
+	// Manifest describes the embedder the vectors were produced with.
+	Manifest *IndexManifest
+	// Extras maps archive names (e.g. "symbols.gob") to files to include
+	// verbatim. Missing files are skipped.
+	Extras map[string]string
+}
+
+// SnapshotImportOptions configures ImportSnapshot.
+type SnapshotImportOptions struct {
+	// KeepDocument, if set, decides whether a document and its chunks are
+	// imported. Skipped files are left for the next indexing run.
+	KeepDocument func(doc Document) bool
+	// Extras maps archive names to the destination paths they are written to.
+	// Extras not listed here are ignored.
+	Extras map[string]string
+	// BatchSize is the number of chunks written per SaveChunks call.
+	BatchSize int
+}
+
+// SnapshotImportResult summarizes a completed import.
+type SnapshotImportResult struct {
+	Header           SnapshotHeader
+	Documents        int
+	Chunks           int
+	SkippedDocuments int
+	Extras           []string
+}
+
+// ExportSnapshot writes every document and chunk of st (vectors included),
+// plus the configured extra files, to w as a gzip-compressed tar archive.
+// Paths are stored relative to the project root. Chunks are read in batches
+// and spooled to a temporary file, since tar needs an entry's size before its
+// content, so the index is never held in memory at once.
+func ExportSnapshot(ctx context.Context, w io.Writer, st VectorStore, opts SnapshotExportOptions) (*SnapshotHeader, error) {
+	iter, ok := st.(ChunkIterator)
+	if !TracksDocuments(st) || !ok {
+		return nil, fmt.Errorf("exporting from qdrant is not supported: it does not keep chunk IDs or document metadata")
+	}
+
+	rel := relativizer(opts.ProjectRoot)
+	header := &SnapshotHeader{
+		FormatVersion: SnapshotFormatVersion,
+		Version:       opts.Version,
+		CreatedAt:     time.Now().UTC(),
+		Manifest:      opts.Manifest,
+	}
+
+	docs, err := newJSONLinesSpool()
+	if err != nil {
+		return nil, err
+	}
+	defer docs.remove()
+	paths, err := st.ListDocuments(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	for _, p := range paths {
+		doc, err := st.GetDocument(ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document %s: %w", p, err)
+		}
+		if doc == nil {
+			continue
+		}
+		doc.Path = rel(doc.Path)
+		for i, id := range doc.ChunkIDs {
+			doc.ChunkIDs[i] = rel(id)
+		}
+		if err := docs.encode(doc); err != nil {
+			return nil, err
+		}
+		header.Documents++
+	}
+
+	chunks, err := newJSONLinesSpool()
+	if err != nil {
+		return nil, err
+	}
+	defer chunks.remove()
+	err = iter.IterateChunks(ctx, DefaultMigrateBatchSize, func(batch []Chunk) error {
+		for _, chunk := range batch {
+			if len(chunk.Vector) == 0 {
+				return fmt.Errorf("chunk %s has no vector", chunk.ID)
+			}
+			chunk.ID = rel(chunk.ID)
+			chunk.FilePath = rel(chunk.FilePath)
+			if err := chunks.encode(chunk); err != nil {
+				return err
+			}
+			header.Chunks++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunks: %w", err)
+	}
+
+	for name, src := range opts.Extras {
+		if _, err := os.Stat(src); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", src, err)
+		}
+		header.Extras = append(header.Extras, name)
+	}
+	sort.Strings(header.Extras)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	headerData, err := json.MarshalIndent(header, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot header: %w", err)
+	}
+	if err := writeTarEntry(tw, snapshotHeaderEntry, headerData); err != nil {
+		return nil, err
+	}
+	if err := docs.writeTo(tw, snapshotDocumentsEntry); err != nil {
+		return nil, err
+	}
+	if err := chunks.writeTo(tw, snapshotChunksEntry); err != nil {
+		return nil, err
+	}
+	for _, name := range header.Extras {
+		if err := writeTarFile(tw, snapshotExtraPrefix+name, opts.Extras[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize snapshot: %w", err)
+	}
+	return header, nil
+}
+
+// ImportSnapshot reads an archive written by ExportSnapshot into st, which
+// is expected to be empty. Extra files are written atomically to the
+// destinations in opts.Extras. st is persisted on success.
+func ImportSnapshot(ctx context.Context, r io.Reader, st VectorStore, opts SnapshotImportOptions) (*SnapshotImportResult, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultMigrateBatchSize
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	result := &SnapshotImportResult{}
+	var headerSeen bool
+	kept := make(map[string]bool)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entry, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+
+		if entry.Name != snapshotHeaderEntry && !headerSeen {
+			return nil, fmt.Errorf("invalid snapshot: %s must be the first entry", snapshotHeaderEntry)
+		}
+
+		switch {
+		case entry.Name == snapshotHeaderEntry:
+			header, err := decodeSnapshotHeader(tr)
+			if err != nil {
+				return nil, err
+			}
+			result.Header = *header
+			headerSeen = true
+
+		case entry.Name == snapshotDocumentsEntry:
+			err := decodeJSONLines(tr, func(doc Document) error {
+				if err := validateSnapshotPath(doc.Path); err != nil {
+					return err
+				}
+				if opts.KeepDocument != nil && !opts.KeepDocument(doc) {
+					result.SkippedDocuments++
+					return nil
+				}
+				if err := st.SaveDocument(ctx, doc); err != nil {
+					return fmt.Errorf("failed to write document %s: %w", doc.Path, err)
+				}
+				kept[doc.Path] = true
+				result.Documents++
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+		case entry.Name == snapshotChunksEntry:
+			batch := make([]Chunk, 0, batchSize)
+			flush := func() error {
+				if len(batch) == 0 {
+					return nil
+				}
+				if err := st.SaveChunks(ctx, batch); err != nil {
+					return fmt.Errorf("failed to write chunks: %w", err)
+				}
+				result.Chunks += len(batch)
+				batch = batch[:0]
+				return nil
+			}
+			err := decodeJSONLines(tr, func(chunk Chunk) error {
+				if !kept[chunk.FilePath] {
+					return nil
+				}
+				batch = append(batch, chunk)
+				if len(batch) >= batchSize {
+					return flush()
+				}
+				return nil
+			})
+			if err == nil {
+				err = flush()
+			}
+			if err != nil {
+				return nil, err
+			}
+
+		case strings.HasPrefix(entry.Name, snapshotExtraPrefix):
+			name := strings.TrimPrefix(entry.Name, snapshotExtraPrefix)
+			dst, ok := opts.Extras[name]
+			if !ok {
+				continue
+			}
+			if err := writeFileAtomically(dst, tr); err != nil {
+				return nil, fmt.Errorf("failed to restore %s: %w", name, err)
+			}
+			result.Extras = append(result.Extras, name)
+		}
+	}
+
+	if !headerSeen {
+		return nil, fmt.Errorf("invalid snapshot: missing %s", snapshotHeaderEntry)
+	}
+
+	if result.Header.Manifest != nil {
+		if err := SaveManifest(ctx, st, *result.Header.Manifest); err != nil {
+			return nil, err
+		}
+	}
+	if err := st.Persist(ctx); err != nil {
+		return nil, fmt.Errorf("failed to persist index: %w", err)
+	}
+	return result, nil
+}
+
+// ReadSnapshotHeader reads the header of an archive written by
+// ExportSnapshot without importing it.
+func ReadSnapshotHeader(r io.Reader) (*SnapshotHeader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	entry, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if entry.Name != snapshotHeaderEntry {
+		return nil, fmt.Errorf("invalid snapshot: %s must be the first entry", snapshotHeaderEntry)
+	}
+	return decodeSnapshotHeader(tr)
+}
+
+func decodeSnapshotHeader(r io.Reader) (*SnapshotHeader, error) {
+	var header SnapshotHeader
+	if err := json.NewDecoder(r).Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot header: %w", err)
+	}
+	if header.FormatVersion > SnapshotFormatVersion {
+		return nil, fmt.Errorf("snapshot format version %d is newer than supported version %d; upgrade grepai", header.FormatVersion, SnapshotFormatVersion)
+	}
+	return &header, nil
+}
+
+// relativizer returns a function that rewrites absolute paths under root
+// (and chunk IDs derived from them) as slash-separated relative paths.
+func relativizer(root string) func(string) string {
+	if root == "" {
+		return func(p string) string { return p }
+	}
+	prefix := filepath.ToSlash(filepath.Clean(root)) + "/"
+	return func(p string) string {
+		return strings.TrimPrefix(filepath.ToSlash(p), prefix)
+	}
+}
+
+// validateSnapshotPath rejects paths that would escape the project root.
+func validateSnapshotPath(p string) error {
+	clean := path.Clean(filepath.ToSlash(p))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("invalid snapshot: path %q is not relative to the project root", p)
+	}
+	return nil
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeTarFile writes the file at src as the archive entry name.
+func writeTarFile(tw *tar.Writer, name, src string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	defer file.Close()
+	return writeTarReader(tw, name, file)
+}
+
+// writeTarReader writes the content of file, read from its start, as the
+// archive entry name.
+func writeTarReader(tw *tar.Writer, name string, file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    info.Size(),
+		ModTime: time.Now(),
+	}); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := io.Copy(tw, file); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// jsonLinesSpool buffers a JSON Lines archive entry in a temporary file.
+type jsonLinesSpool struct {
+	file *os.File
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+func newJSONLinesSpool() (*jsonLinesSpool, error) {
+	file, err := os.CreateTemp("", "grepai-snapshot-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	buf := bufio.NewWriter(file)
+	return &jsonLinesSpool{file: file, buf: buf, enc: json.NewEncoder(buf)}, nil
+}
+
+func (s *jsonLinesSpool) encode(item any) error {
+	if err := s.enc.Encode(item); err != nil {
+		return fmt.Errorf("failed to spool snapshot entry: %w", err)
+	}
+	return nil
+}
+
+// writeTo copies the spooled lines into the archive as the entry name.
+func (s *jsonLinesSpool) writeTo(tw *tar.Writer, name string) error {
+	if err := s.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to spool %s: %w", name, err)
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to spool %s: %w", name, err)
+	}
+	return writeTarReader(tw, name, s.file)
+}
+
+func (s *jsonLinesSpool) remove() {
+	_ = s.file.Close()
+	_ = os.Remove(s.file.Name())
+}
+
+func decodeJSONLines[T any](r io.Reader, fn func(T) error) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var item T
+		if err := dec.Decode(&item); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
+
+func writeFileAtomically(dst string, r io.Reader) error {
+	if err := fileutil.EnsureParentDir(dst); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := io.Copy(tmp, r); err != nil { //nolint:gosec // G110: archive is chosen by the user importing it
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err := fileutil.ReplaceFileAtomically(tmpPath, dst); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package store
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot_ExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	root := filepath.Join(dir, "project")
+
+	src := NewGOBStore(filepath.Join(dir, "src.gob"))
+	chunks := []Chunk{
+		{ID: "a.go_0", FilePath: "a.go", Content: "func A() {}", Vector: []float32{1, 0}},
+		{ID: "a.go_1", FilePath: "a.go", Content: "func A2() {}", Vector: []float32{0.9, 0.1}},
+		{ID: root + "/b.go_0", FilePath: root + "/b.go", Content: "func B() {}", Vector: []float32{0, 1}},
+	}
+	if err := src.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	for _, doc := range []Document{
+		{Path: "a.go", Hash: "ha", ChunkIDs: []string{"a.go_0", "a.go_1"}},
+		{Path: root + "/b.go", Hash: "hb", ChunkIDs: []string{root + "/b.go_0"}},
+	} {
+		if err := src.SaveDocument(ctx, doc); err != nil {
+			t.Fatalf("failed to save document: %v", err)
+		}
+	}
+
+	symbolsPath := filepath.Join(dir, "symbols.gob")
+	if err := os.WriteFile(symbolsPath, []byte("symbols"), 0644); err != nil {
+		t.Fatalf("failed to write extra: %v", err)
+	}
+
+	manifest := &IndexManifest{Provider: "ollama", Model: "nomic-embed-text", Dimensions: 2}
+	var buf bytes.Buffer
+	header, err := ExportSnapshot(ctx, &buf, src, SnapshotExportOptions{
+		ProjectRoot: root,
+		Version:     "1.2.3",
+		Manifest:    manifest,
+		Extras: map[string]string{
+			"symbols.gob": symbolsPath,
+			"rpg.gob":     filepath.Join(dir, "missing.gob"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExportSnapshot failed: %v", err)
+	}
+	if header.Documents != 2 || header.Chunks != 3 || len(header.Extras) != 1 {
+		t.Errorf("unexpected header: %+v", header)
+	}
+
+	read, err := ReadSnapshotHeader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadSnapshotHeader failed: %v", err)
+	}
+	if read.FormatVersion != SnapshotFormatVersion || read.Version != "1.2.3" || read.Manifest == nil || read.Manifest.Model != "nomic-embed-text" {
+		t.Errorf("unexpected header: %+v", read)
+	}
+
+	dst := NewGOBStore(filepath.Join(dir, "dst.gob"))
+	restoredSymbols := filepath.Join(dir, "restored", "symbols.gob")
+	result, err := ImportSnapshot(ctx, bytes.NewReader(buf.Bytes()), dst, SnapshotImportOptions{
+		// b.go changed locally and must be re-indexed.
+		KeepDocument: func(doc Document) bool { return doc.Path == "a.go" },
+		Extras:       map[string]string{"symbols.gob": restoredSymbols},
+		BatchSize:    1,
+	})
+	if err != nil {
+		t.Fatalf("ImportSnapshot failed: %v", err)
+	}
+	if result.Documents != 1 || result.Chunks != 2 || result.SkippedDocuments != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	paths, err := dst.ListDocuments(ctx)
+	if err != nil {
+		t.Fatalf("ListDocuments failed: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "a.go" {
+		t.Errorf("documents = %v, want [a.go]", paths)
+	}
+	results, err := dst.Search(ctx, []float32{1, 0}, 1, SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.ID != "a.go_0" {
+		t.Errorf("expected vectors to be imported, got %+v", results)
+	}
+
+	data, err := os.ReadFile(restoredSymbols)
+	if err != nil || string(data) != "symbols" {
+		t.Errorf("symbols.gob not restored: %q (err=%v)", data, err)
+	}
+
+	stored, err := dst.GetManifest(ctx)
+	if err != nil || stored == nil || !stored.EmbedderMatches(*manifest) {
+		t.Errorf("manifest not restored: %+v (err=%v)", stored, err)
+	}
+}
+
+func TestSnapshot_ExportRelativizesPaths(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	root := filepath.Join(dir, "project")
+
+	src := NewGOBStore(filepath.Join(dir, "src.gob"))
+	if err := src.SaveChunks(ctx, []Chunk{{ID: root + "/b.go_0", FilePath: root + "/b.go", Vector: []float32{1}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := src.SaveDocument(ctx, Document{Path: root + "/b.go", ChunkIDs: []string{root + "/b.go_0"}}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := ExportSnapshot(ctx, &buf, src, SnapshotExportOptions{ProjectRoot: root}); err != nil {
+		t.Fatalf("ExportSnapshot failed: %v", err)
+	}
+
+	dst := NewGOBStore(filepath.Join(dir, "dst.gob"))
+	if _, err := ImportSnapshot(ctx, &buf, dst, SnapshotImportOptions{}); err != nil {
+		t.Fatalf("ImportSnapshot failed: %v", err)
+	}
+	chunks, err := dst.GetChunksForFile(ctx, "b.go")
+	if err != nil {
+		t.Fatalf("GetChunksForFile failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].ID != "b.go_0" {
+		t.Errorf("expected relative chunk b.go_0, got %+v", chunks)
+	}
+}
+
+func writeTestSnapshot(t *testing.T, entries map[string]string, order []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range order {
+		if err := writeTarEntry(tw, name, []byte(entries[name])); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImportSnapshot_RejectsInvalidArchives(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		entries map[string]string
+		order   []string
+		wantErr string
+	}{
+		{
+			name:    "newer format",
+			entries: map[string]string{snapshotHeaderEntry: `{"format_version": 99}`},
+			order:   []string{snapshotHeaderEntry},
+			wantErr: "newer than supported",
+		},
+		{
+			name:    "missing header",
+			entries: map[string]string{snapshotDocumentsEntry: ""},
+			order:   []string{snapshotDocumentsEntry},
+			wantErr: "must be the first entry",
+		},
+		{
+			name: "path outside project",
+			entries: map[string]string{
+				snapshotHeaderEntry:    `{"format_version": 1}`,
+				snapshotDocumentsEntry: `{"path": "../secret.go"}` + "\n",
+			},
+			order:   []string{snapshotHeaderEntry, snapshotDocumentsEntry},
+			wantErr: "not relative to the project root",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := writeTestSnapshot(t, tt.entries, tt.order)
+			dst := NewGOBStore(filepath.Join(t.TempDir(), "index.gob"))
+			_, err := ImportSnapshot(ctx, bytes.NewReader(data), dst, SnapshotImportOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,990 @@
+package store
+
+import (
+	"container/heap"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+
+	_ "modernc.org/sqlite" // pure-Go SQLite driver (keeps CGO_ENABLED=0 builds working)
+)
+
+// SQLiteStore is a VectorStore backed by a single SQLite database file.
+// Unlike GOBStore, every SaveChunks/SaveDocument call is committed in its own
+// transaction, so writes are incremental and survive crashes without
+// rewriting the whole index.
+type SQLiteStore struct {
+	db           *sql.DB
+	dbPath       string
+	quantization Quantization
+}
+
+// SQLiteOption configures optional SQLiteStore behaviour.
+type SQLiteOption func(*SQLiteStore)
+
+// WithSQLiteQuantization stores vectors in a compact quantized encoding.
+// The mode is recorded in the database; opening it with another mode fails.
+func WithSQLiteQuantization(mode Quantization) SQLiteOption {
+	return func(s *SQLiteStore) {
+		s.quantization = mode
+	}
+}
+
+// NewSQLiteStore opens (or creates) the SQLite index at dbPath and ensures the schema exists.
+func NewSQLiteStore(ctx context.Context, dbPath string, opts ...SQLiteOption) (*SQLiteStore, error) {
+	if err := fileutil.EnsureParentDir(dbPath); err != nil {
+		return nil, fmt.Errorf("failed to prepare index directory: %w", err)
+	}
+
+	// WAL keeps readers (search, mcp-serve) unblocked while the watcher writes,
+	// and busy_timeout lets concurrent processes wait instead of failing fast.
+	dsn := "file:" + dbPath + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=synchronous(NORMAL)&_pragma=foreign_keys(ON)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	// A single connection serializes writers inside this process and avoids
+	// SQLITE_BUSY errors between pooled connections.
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{
+		db:           db,
+		dbPath:       dbPath,
+		quantization: QuantizationNone,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.ensureSchema(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := s.checkQuantization(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := s.backfillTextIndex(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *SQLiteStore) ensureSchema(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS chunks (
+			id TEXT PRIMARY KEY,
+			file_path TEXT NOT NULL,
+			start_line INTEGER NOT NULL,
+			end_line INTEGER NOT NULL,
+			content TEXT NOT NULL,
+			vector BLOB,
+			hash TEXT NOT NULL,
+			content_hash TEXT NOT NULL DEFAULT '',
+			updated_at INTEGER NOT NULL,
+			symbol_kinds TEXT NOT NULL DEFAULT '',
+			headings TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_chunks_file ON chunks(file_path)`,
+		`CREATE INDEX IF NOT EXISTS idx_chunks_content_hash ON chunks(content_hash) WHERE content_hash != ''`,
+		`CREATE TABLE IF NOT EXISTS documents (
+			path TEXT PRIMARY KEY,
+			hash TEXT NOT NULL,
+			mod_time INTEGER NOT NULL,
+			chunk_ids TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS meta (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)`,
+		// Full-text index: chunk lengths and term frequencies, removed with
+		// their chunk.
+		`CREATE TABLE IF NOT EXISTS text_docs (
+			chunk_id TEXT PRIMARY KEY REFERENCES chunks(id) ON DELETE CASCADE,
+			length INTEGER NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS text_terms (
+			term TEXT NOT NULL,
+			chunk_id TEXT NOT NULL REFERENCES chunks(id) ON DELETE CASCADE,
+			tf INTEGER NOT NULL,
+			PRIMARY KEY (term, chunk_id)
+		) WITHOUT ROWID`,
+		`CREATE INDEX IF NOT EXISTS idx_text_terms_chunk ON text_terms(chunk_id)`,
+	}
+
+	for _, query := range queries {
+		if _, err := s.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to execute schema query: %w", err)
+		}
+	}
+
+	// Databases created before symbol kinds and headings were recorded lack
+	// the columns.
+	for _, column := range []string{"symbol_kinds", "headings"} {
+		if err := s.addChunkColumn(ctx, column); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addChunkColumn adds a text column to the chunks table if it is missing.
+func (s *SQLiteStore) addChunkColumn(ctx context.Context, column string) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM pragma_table_info('chunks') WHERE name = ?)`, column,
+	).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to inspect chunks table: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `ALTER TABLE chunks ADD COLUMN `+column+` TEXT NOT NULL DEFAULT ''`); err != nil {
+		return fmt.Errorf("failed to add %s column: %w", column, err)
+	}
+	return nil
+}
+
+// checkQuantization compares the quantization mode recorded in the database
+// with the configured one. Empty databases adopt the configured mode.
+func (s *SQLiteStore) checkQuantization(ctx context.Context) error {
+	stored := QuantizationNone
+	var value string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM meta WHERE key = 'quantization'`).Scan(&value)
+	switch {
+	case err == nil:
+		stored = storedQuantization(value)
+	case err != sql.ErrNoRows:
+		return fmt.Errorf("failed to read index metadata: %w", err)
+	}
+
+	if stored != s.quantization {
+		var hasChunks bool
+		if err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM chunks)`).Scan(&hasChunks); err != nil {
+			return fmt.Errorf("failed to inspect chunks: %w", err)
+		}
+		if hasChunks {
+			return quantizationMismatchError(s.dbPath, stored, s.quantization)
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO meta (key, value) VALUES ('quantization', ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		string(s.quantization),
+	); err != nil {
+		return fmt.Errorf("failed to write index metadata: %w", err)
+	}
+	return nil
+}
+
+// encodeVector serializes a float32 vector as little-endian bytes.
+func encodeVector(vec []float32) []byte {
+	if len(vec) == 0 {
+		return nil
+	}
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	return buf
+}
+
+// decodeVector deserializes little-endian bytes produced by encodeVector.
+func decodeVector(buf []byte) []float32 {
+	if len(buf) < 4 {
+		return nil
+	}
+	vec := make([]float32, len(buf)/4)
+	for i := range vec {
+		vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vec
+}
+
+// encodeStoredVector encodes a vector in the store's quantization mode.
+func (s *SQLiteStore) encodeStoredVector(vec []float32) []byte {
+	if s.quantization == QuantizationNone {
+		return encodeVector(vec)
+	}
+	return quantizeVector(s.quantization, vec).encode()
+}
+
+// decodeStoredVector decodes a vector column, dequantizing if needed. A
+// corrupt quantized vector decodes to nil.
+func (s *SQLiteStore) decodeStoredVector(buf []byte) []float32 {
+	if s.quantization == QuantizationNone {
+		return decodeVector(buf)
+	}
+	qv, err := decodeQuantizedVector(buf, s.quantization)
+	if err != nil {
+		return nil
+	}
+	return qv.dequantize(s.quantization)
+}
+
+func (s *SQLiteStore) SaveChunks(ctx context.Context, chunks []Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO chunks (`+sqliteChunkColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			file_path = excluded.file_path,
+			start_line = excluded.start_line,
+			end_line = excluded.end_line,
+			content = excluded.content,
+			vector = excluded.vector,
+			hash = excluded.hash,
+			content_hash = excluded.content_hash,
+			updated_at = excluded.updated_at,
+			symbol_kinds = excluded.symbol_kinds,
+			headings = excluded.headings`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare chunk insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, chunk := range chunks {
+		if _, err := stmt.ExecContext(ctx,
+			chunk.ID, chunk.FilePath, chunk.StartLine, chunk.EndLine, chunk.Content,
+			s.encodeStoredVector(chunk.Vector), chunk.Hash, chunk.ContentHash, chunk.UpdatedAt.UnixNano(),
+			strings.Join(chunk.SymbolKinds, ","), strings.Join(chunk.Headings, "\n"),
+		); err != nil {
+			return fmt.Errorf("failed to save chunk: %w", err)
+		}
+	}
+	if err := indexSQLiteChunkText(ctx, tx, chunks); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit chunks: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteByFile(ctx context.Context, filePath string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM chunks WHERE file_path = ?`, filePath); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	return nil
+}
+
+// DeleteChunks removes chunks by ID, leaving documents untouched.
+func (s *SQLiteStore) DeleteChunks(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, `DELETE FROM chunks WHERE id = ?`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare chunk delete: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		if _, err := stmt.ExecContext(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete chunk: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit chunk deletion: %w", err)
+	}
+	return nil
+}
+
+// escapeLike escapes LIKE wildcards so path prefixes are matched literally.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+func (s *SQLiteStore) Search(ctx context.Context, queryVector []float32, limit int, opts SearchOptions) ([]SearchResult, error) {
+	filter, err := newChunkFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := sqliteSearchConditions(filter.opts)
+	query := `SELECT ` + sqliteChunkColumns + ` FROM chunks`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	if s.quantization != QuantizationNone {
+		return s.searchQuantized(rows, queryVector, limit, filter)
+	}
+
+	// Keep only the best `limit` results in a min-heap so memory stays
+	// bounded regardless of index size.
+	h := &resultHeap{}
+	for rows.Next() {
+		chunk, vec, err := scanSQLiteChunk(rows)
+		if err != nil {
+			return nil, err
+		}
+		if !filter.matchPath(chunk.FilePath) {
+			continue
+		}
+		chunk.Vector = decodeVector(vec)
+		score := cosineSimilarity(queryVector, chunk.Vector)
+		if !h.accepts(score, limit) {
+			continue
+		}
+		h.pushBounded(SearchResult{Chunk: chunk, Score: score}, limit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate search rows: %w", err)
+	}
+
+	return h.sorted(), nil
+}
+
+// searchQuantized ranks rows with integer scores on the quantized codes, then
+// rescores the best candidates against their dequantized vectors.
+func (s *SQLiteStore) searchQuantized(rows *sql.Rows, queryVector []float32, limit int, filter *chunkFilter) ([]SearchResult, error) {
+	query := quantizeVector(s.quantization, queryVector)
+	depth := s.quantization.rescoreDepth(limit)
+
+	h := &resultHeap{}
+	for rows.Next() {
+		chunk, buf, err := scanSQLiteChunk(rows)
+		if err != nil {
+			return nil, err
+		}
+		if !filter.matchPath(chunk.FilePath) {
+			continue
+		}
+		qv, err := decodeQuantizedVector(buf, s.quantization)
+		if err != nil {
+			// Skip a corrupt row; re-indexing its file rewrites it
+			continue
+		}
+		score := approxSimilarity(s.quantization, query, qv)
+		if !h.accepts(score, depth) {
+			continue
+		}
+		chunk.Vector = qv.dequantize(s.quantization)
+		h.pushBounded(SearchResult{Chunk: chunk, Score: score}, depth)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate search rows: %w", err)
+	}
+
+	return rescoreResults(queryVector, h.sorted(), limit), nil
+}
+
+// sqliteSearchConditions translates the filters SQLite can evaluate into
+// WHERE clauses. Language and glob filters are applied to each row's path
+// before its vector is decoded.
+func sqliteSearchConditions(opts SearchOptions) ([]string, []interface{}) {
+	var where []string
+	var args []interface{}
+
+	if opts.PathPrefix != "" {
+		where = append(where, `file_path LIKE ? ESCAPE '\'`)
+		args = append(args, escapeLike(opts.PathPrefix)+"%")
+	}
+	if !opts.ModifiedAfter.IsZero() {
+		where = append(where, `EXISTS (SELECT 1 FROM documents d WHERE d.path = chunks.file_path AND d.mod_time >= ?)`)
+		args = append(args, opts.ModifiedAfter.UnixNano())
+	}
+	if !opts.ModifiedBefore.IsZero() {
+		where = append(where, `EXISTS (SELECT 1 FROM documents d WHERE d.path = chunks.file_path AND d.mod_time < ?)`)
+		args = append(args, opts.ModifiedBefore.UnixNano())
+	}
+	if len(opts.SymbolKinds) > 0 {
+		kinds := make([]string, 0, len(opts.SymbolKinds))
+		for _, kind := range opts.SymbolKinds {
+			kinds = append(kinds, `(',' || symbol_kinds || ',') LIKE ?`)
+			args = append(args, "%,"+kind+",%")
+		}
+		where = append(where, `(`+strings.Join(kinds, ` OR `)+`)`)
+	}
+
+	return where, args
+}
+
+// resultHeap is a min-heap of search results ordered by score.
+type resultHeap []SearchResult
+
+func (h resultHeap) Len() int            { return len(h) }
+func (h resultHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h resultHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *resultHeap) Push(x interface{}) { *h = append(*h, x.(SearchResult)) }
+func (h *resultHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// accepts reports whether a result with this score would be kept by
+// pushBounded. capacity <= 0 means unbounded.
+func (h *resultHeap) accepts(score float32, capacity int) bool {
+	return capacity <= 0 || h.Len() < capacity || score > (*h)[0].Score
+}
+
+// pushBounded adds a result, evicting the lowest score once capacity is reached.
+func (h *resultHeap) pushBounded(result SearchResult, capacity int) {
+	if capacity > 0 && h.Len() >= capacity {
+		heap.Pop(h)
+	}
+	heap.Push(h, result)
+}
+
+// sorted drains the heap into a slice ordered by descending score.
+func (h *resultHeap) sorted() []SearchResult {
+	results := make([]SearchResult, h.Len())
+	for i := len(results) - 1; i >= 0; i-- {
+		results[i] = heap.Pop(h).(SearchResult)
+	}
+	return results
+}
+
+// sqliteChunkColumns is the column list scanned by scanSQLiteChunk.
+const sqliteChunkColumns = `id, file_path, start_line, end_line, content, vector, hash, content_hash, updated_at, symbol_kinds, headings`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSQLiteChunk scans a chunk row and returns the raw vector column
+// separately so callers can decode it according to the quantization mode.
+func scanSQLiteChunk(row rowScanner) (Chunk, []byte, error) {
+	var chunk Chunk
+	var vec []byte
+	var updatedAt int64
+	var symbolKinds, headings string
+	if err := row.Scan(
+		&chunk.ID, &chunk.FilePath, &chunk.StartLine, &chunk.EndLine,
+		&chunk.Content, &vec, &chunk.Hash, &chunk.ContentHash, &updatedAt, &symbolKinds, &headings,
+	); err != nil {
+		return Chunk{}, nil, fmt.Errorf("failed to scan chunk: %w", err)
+	}
+	chunk.UpdatedAt = time.Unix(0, updatedAt)
+	if symbolKinds != "" {
+		chunk.SymbolKinds = strings.Split(symbolKinds, ",")
+	}
+	if headings != "" {
+		chunk.Headings = strings.Split(headings, "\n")
+	}
+	return chunk, vec, nil
+}
+
+// scanChunk scans a chunk row and decodes its vector.
+func (s *SQLiteStore) scanChunk(row rowScanner) (Chunk, error) {
+	chunk, vec, err := scanSQLiteChunk(row)
+	if err != nil {
+		return Chunk{}, err
+	}
+	chunk.Vector = s.decodeStoredVector(vec)
+	return chunk, nil
+}
+
+func (s *SQLiteStore) GetDocument(ctx context.Context, filePath string) (*Document, error) {
+	var doc Document
+	var modTime int64
+	var chunkIDs string
+
+	err := s.db.QueryRowContext(ctx,
+		`SELECT path, hash, mod_time, chunk_ids FROM documents WHERE path = ?`,
+		filePath,
+	).Scan(&doc.Path, &doc.Hash, &modTime, &chunkIDs)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+
+	doc.ModTime = time.Unix(0, modTime)
+	if err := json.Unmarshal([]byte(chunkIDs), &doc.ChunkIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode chunk ids for %s: %w", filePath, err)
+	}
+	return &doc, nil
+}
+
+func (s *SQLiteStore) SaveDocument(ctx context.Context, doc Document) error {
+	chunkIDs := doc.ChunkIDs
+	if chunkIDs == nil {
+		chunkIDs = []string{}
+	}
+	encoded, err := json.Marshal(chunkIDs)
+	if err != nil {
+		return fmt.Errorf("failed to encode chunk ids: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO documents (path, hash, mod_time, chunk_ids)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (path) DO UPDATE SET
+			hash = excluded.hash,
+			mod_time = excluded.mod_time,
+			chunk_ids = excluded.chunk_ids`,
+		doc.Path, doc.Hash, doc.ModTime.UnixNano(), string(encoded),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save document: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteDocument(ctx context.Context, filePath string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM documents WHERE path = ?`, filePath); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) ListDocuments(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT path FROM documents`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan path: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+func (s *SQLiteStore) Load(ctx context.Context) error {
+	// No-op for SQLite, rows are read on demand
+	return nil
+}
+
+func (s *SQLiteStore) Persist(ctx context.Context) error {
+	// Every write is already committed; checkpoint the WAL so the main
+	// database file stays self-contained (e.g. for worktree seeding).
+	if _, err := s.db.ExecContext(ctx, `PRAGMA wal_checkpoint(PASSIVE)`); err != nil {
+		return fmt.Errorf("failed to checkpoint sqlite wal: %w", err)
+	}
+	return nil
+}
+
+// Compact rebuilds the database file to release pages freed by deletions
+// and truncates the WAL.
+func (s *SQLiteStore) Compact(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum sqlite database: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("failed to checkpoint sqlite wal: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) GetStats(ctx context.Context) (*IndexStats, error) {
+	var stats IndexStats
+
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM documents`).Scan(&stats.TotalFiles); err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	var lastUpdated int64
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), COALESCE(MAX(updated_at), 0) FROM chunks`,
+	).Scan(&stats.TotalChunks, &lastUpdated); err != nil {
+		return nil, fmt.Errorf("failed to count chunks: %w", err)
+	}
+	if lastUpdated > 0 {
+		stats.LastUpdated = time.Unix(0, lastUpdated)
+	}
+
+	for _, path := range []string{s.dbPath, s.dbPath + "-wal"} {
+		if info, err := os.Stat(path); err == nil {
+			stats.IndexSize += info.Size()
+		}
+	}
+
+	return &stats, nil
+}
+
+func (s *SQLiteStore) ListFilesWithStats(ctx context.Context) ([]FileStats, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT path, mod_time, json_array_length(chunk_ids) FROM documents`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []FileStats
+	for rows.Next() {
+		var f FileStats
+		var modTime int64
+		if err := rows.Scan(&f.Path, &modTime, &f.ChunkCount); err != nil {
+			return nil, fmt.Errorf("failed to scan file: %w", err)
+		}
+		f.ModTime = time.Unix(0, modTime)
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+func (s *SQLiteStore) GetChunksForFile(ctx context.Context, filePath string) ([]Chunk, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+sqliteChunkColumns+`
+		FROM chunks WHERE file_path = ? ORDER BY start_line`,
+		filePath,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []Chunk
+	for rows.Next() {
+		chunk, err := s.scanChunk(rows)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+func (s *SQLiteStore) GetAllChunks(ctx context.Context) ([]Chunk, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+sqliteChunkColumns+` FROM chunks`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []Chunk
+	for rows.Next() {
+		chunk, err := s.scanChunk(rows)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// IterateChunks implements ChunkIterator with keyset pagination on the
+// chunk ID.
+func (s *SQLiteStore) IterateChunks(ctx context.Context, batchSize int, fn func([]Chunk) error) error {
+	after := ""
+	for {
+		batch, err := s.chunksAfter(ctx, after, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
+func (s *SQLiteStore) chunksAfter(ctx context.Context, after string, limit int) ([]Chunk, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+sqliteChunkColumns+`
+		FROM chunks WHERE id > ? ORDER BY id LIMIT ?`,
+		after, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []Chunk
+	for rows.Next() {
+		chunk, err := s.scanChunk(rows)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+// GetManifest reads the manifest from the meta table.
+func (s *SQLiteStore) GetManifest(ctx context.Context) (*IndexManifest, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM meta WHERE key = 'manifest'`).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest IndexManifest
+	if err := json.Unmarshal([]byte(value), &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// SaveManifest stores the manifest in the meta table.
+func (s *SQLiteStore) SaveManifest(ctx context.Context, manifest IndexManifest) error {
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx,
+		`INSERT INTO meta (key, value) VALUES ('manifest', ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`,
+		string(encoded),
+	); err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+	return nil
+}
+
+// LookupByContentHash queries the chunks table for a matching content hash and returns the vector.
+func (s *SQLiteStore) LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error) {
+	if contentHash == "" {
+		return nil, false, nil
+	}
+
+	var vec []byte
+	err := s.db.QueryRowContext(ctx,
+		`SELECT vector FROM chunks WHERE content_hash = ? AND vector IS NOT NULL LIMIT 1`,
+		contentHash,
+	).Scan(&vec)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lookup by content hash: %w", err)
+	}
+
+	return s.decodeStoredVector(vec), true, nil
+}
+
+// sqliteTextBatchSize is the number of ranked chunks loaded per query by
+// TextSearch, well under SQLite's limit on bound parameters.
+const sqliteTextBatchSize = 200
+
+// indexSQLiteChunkText replaces the full-text index entries of chunks.
+func indexSQLiteChunkText(ctx context.Context, tx *sql.Tx, chunks []Chunk) error {
+	deleteStmt, err := tx.PrepareContext(ctx, `DELETE FROM text_terms WHERE chunk_id = ?`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare text index delete: %w", err)
+	}
+	defer deleteStmt.Close()
+
+	termStmt, err := tx.PrepareContext(ctx, `INSERT INTO text_terms (term, chunk_id, tf) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare text index insert: %w", err)
+	}
+	defer termStmt.Close()
+
+	docStmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO text_docs (chunk_id, length) VALUES (?, ?)
+		ON CONFLICT (chunk_id) DO UPDATE SET length = excluded.length`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare text index insert: %w", err)
+	}
+	defer docStmt.Close()
+
+	for _, chunk := range chunks {
+		if _, err := deleteStmt.ExecContext(ctx, chunk.ID); err != nil {
+			return fmt.Errorf("failed to clear text index entries: %w", err)
+		}
+		freqs, length := termFrequencies(chunk)
+		for term, tf := range freqs {
+			if _, err := termStmt.ExecContext(ctx, term, chunk.ID, tf); err != nil {
+				return fmt.Errorf("failed to index chunk text: %w", err)
+			}
+		}
+		if _, err := docStmt.ExecContext(ctx, chunk.ID, length); err != nil {
+			return fmt.Errorf("failed to index chunk text: %w", err)
+		}
+	}
+	return nil
+}
+
+// backfillTextIndex indexes the text of chunks saved before the full-text
+// index existed, a batch per transaction.
+func (s *SQLiteStore) backfillTextIndex(ctx context.Context) error {
+	for {
+		rows, err := s.db.QueryContext(ctx,
+			`SELECT id, file_path, content, hash FROM chunks
+			WHERE id NOT IN (SELECT chunk_id FROM text_docs) LIMIT ?`, sqliteTextBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list chunks missing from the text index: %w", err)
+		}
+		var chunks []Chunk
+		for rows.Next() {
+			var chunk Chunk
+			if err := rows.Scan(&chunk.ID, &chunk.FilePath, &chunk.Content, &chunk.Hash); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan chunk: %w", err)
+			}
+			chunks = append(chunks, chunk)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to iterate chunks: %w", err)
+		}
+		if len(chunks) == 0 {
+			return nil
+		}
+
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		if err := indexSQLiteChunkText(ctx, tx, chunks); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit text index: %w", err)
+		}
+	}
+}
+
+// TextSearch ranks chunks against the query terms with the full-text index.
+// Only the postings of the query terms are read; chunks are then loaded best
+// first until limit of them pass the filters.
+func (s *SQLiteStore) TextSearch(ctx context.Context, query string, limit int, opts SearchOptions) ([]SearchResult, error) {
+	filter, err := newChunkFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var n int
+	var avgLen float64
+	if err := s.db.QueryRowContext(ctx,
+		`SELECT COUNT(*), COALESCE(AVG(length), 0) FROM text_docs`,
+	).Scan(&n, &avgLen); err != nil {
+		return nil, fmt.Errorf("failed to read text index statistics: %w", err)
+	}
+
+	type posting struct {
+		id         string
+		tf, docLen int
+	}
+	scores := make(map[string]float64)
+	for _, term := range terms {
+		rows, err := s.db.QueryContext(ctx,
+			`SELECT t.chunk_id, t.tf, d.length FROM text_terms t
+			JOIN text_docs d ON d.chunk_id = t.chunk_id WHERE t.term = ?`, term)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read text index: %w", err)
+		}
+		var postings []posting
+		for rows.Next() {
+			var p posting
+			if err := rows.Scan(&p.id, &p.tf, &p.docLen); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan text index entry: %w", err)
+			}
+			postings = append(postings, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to iterate text index: %w", err)
+		}
+		for _, p := range postings {
+			scores[p.id] += bm25(p.tf, p.docLen, avgLen, len(postings), n)
+		}
+	}
+
+	hits := rankHits(scores, 0, nil)
+	where, whereArgs := sqliteSearchConditions(filter.opts)
+	var results []SearchResult
+	for start := 0; start < len(hits) && (limit <= 0 || len(results) < limit); start += sqliteTextBatchSize {
+		batch := hits[start:min(start+sqliteTextBatchSize, len(hits))]
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, 0, len(batch)+len(whereArgs))
+		for i, hit := range batch {
+			placeholders[i] = "?"
+			args = append(args, hit.ID)
+		}
+		args = append(args, whereArgs...)
+		conditions := append([]string{`id IN (` + strings.Join(placeholders, `, `) + `)`}, where...)
+
+		rows, err := s.db.QueryContext(ctx,
+			`SELECT `+sqliteChunkColumns+` FROM chunks WHERE `+strings.Join(conditions, ` AND `), args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load text search results: %w", err)
+		}
+		chunks := make(map[string]Chunk, len(batch))
+		for rows.Next() {
+			chunk, err := s.scanChunk(rows)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			chunks[chunk.ID] = chunk
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to iterate text search results: %w", err)
+		}
+
+		for _, hit := range batch {
+			chunk, ok := chunks[hit.ID]
+			if !ok || !filter.matchPath(chunk.FilePath) {
+				continue
+			}
+			results = append(results, SearchResult{Chunk: chunk, Score: hit.Score})
+			if limit > 0 && len(results) == limit {
+				break
+			}
+		}
+	}
+	return results, nil
+}
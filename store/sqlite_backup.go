@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"modernc.org/sqlite"
+)
+
+// CopySQLiteDatabase copies the SQLite database at src to dst with the online
+// backup API. Unlike a file copy it includes pages still in the write-ahead
+// log and is consistent while another process writes to src. It does nothing
+// if src does not exist. It serves as config.SQLiteIndexCopier.
+func CopySQLiteDatabase(ctx context.Context, src, dst string) error {
+	if _, err := os.Stat(src); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	db, err := sql.Open("sqlite", src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		backuper, ok := driverConn.(interface {
+			NewBackup(dstURI string) (*sqlite.Backup, error)
+		})
+		if !ok {
+			return fmt.Errorf("sqlite driver does not support backups")
+		}
+		backup, err := backuper.NewBackup(dst)
+		if err != nil {
+			return fmt.Errorf("failed to start backup of %s: %w", src, err)
+		}
+		for more := true; more; {
+			if more, err = backup.Step(-1); err != nil {
+				_ = backup.Finish()
+				return fmt.Errorf("failed to back up %s: %w", src, err)
+			}
+		}
+		return backup.Finish()
+	})
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopySQLiteDatabase_IncludesWriteAheadLog(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "index.db")
+	dst := filepath.Join(dir, "copy.db")
+
+	// Keep the source open so the rows stay in the WAL file
+	db, err := sql.Open("sqlite", src)
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`PRAGMA journal_mode=WAL`,
+		`CREATE TABLE chunks (id TEXT PRIMARY KEY)`,
+		`INSERT INTO chunks (id) VALUES ('a'), ('b')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	if err := CopySQLiteDatabase(context.Background(), src, dst); err != nil {
+		t.Fatalf("CopySQLiteDatabase failed: %v", err)
+	}
+
+	copied, err := sql.Open("sqlite", dst)
+	if err != nil {
+		t.Fatalf("failed to open copied index: %v", err)
+	}
+	defer copied.Close()
+	var count int
+	if err := copied.QueryRow(`SELECT COUNT(*) FROM chunks`).Scan(&count); err != nil {
+		t.Fatalf("failed to query copied index: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 rows in copied index, got %d", count)
+	}
+}
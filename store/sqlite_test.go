@@ -0,0 +1,219 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	s, err := NewSQLiteStore(context.Background(), filepath.Join(t.TempDir(), ".grepai", "index.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestSQLiteStore_SaveAndSearchChunks(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	ctx := context.Background()
+
+	chunks := []Chunk{
+		{ID: "chunk1", FilePath: "test.go", StartLine: 1, EndLine: 10, Content: "func main() {}", Vector: []float32{1.0, 0.0, 0.0}, Hash: "abc123", UpdatedAt: time.Now()},
+		{ID: "chunk2", FilePath: "test.go", StartLine: 11, EndLine: 20, Content: "func helper() {}", Vector: []float32{0.0, 1.0, 0.0}, Hash: "def456", UpdatedAt: time.Now()},
+		{ID: "chunk3", FilePath: "other.go", StartLine: 1, EndLine: 5, Content: "func other() {}", Vector: []float32{0.7, 0.7, 0.0}, Hash: "ghi789", UpdatedAt: time.Now()},
+	}
+	if err := store.SaveChunks(ctx, chunks); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	results, err := store.Search(ctx, []float32{0.9, 0.1, 0.0}, 2, SearchOptions{})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Chunk.ID != "chunk1" || results[1].Chunk.ID != "chunk3" {
+		t.Errorf("unexpected result order: %s, %s", results[0].Chunk.ID, results[1].Chunk.ID)
+	}
+	if len(results[0].Chunk.Vector) != 3 {
+		t.Errorf("expected vector round-trip, got %v", results[0].Chunk.Vector)
+	}
+
+	results, err = store.Search(ctx, []float32{0.9, 0.1, 0.0}, 10, SearchOptions{PathPrefix: "other"})
+	if err != nil {
+		t.Fatalf("search with prefix failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "other.go" {
+		t.Errorf("expected only other.go with path prefix, got %+v", results)
+	}
+}
+
+func TestSQLiteStore_DocumentsAndStats(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	ctx := context.Background()
+
+	modTime := time.Unix(1700000000, 0)
+	if err := store.SaveChunks(ctx, []Chunk{
+		{ID: "a_0", FilePath: "a.go", Content: "a", Vector: []float32{1, 0}, UpdatedAt: modTime},
+		{ID: "a_1", FilePath: "a.go", Content: "b", Vector: []float32{0, 1}, UpdatedAt: modTime},
+	}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := store.SaveDocument(ctx, Document{Path: "a.go", Hash: "h1", ModTime: modTime, ChunkIDs: []string{"a_0", "a_1"}}); err != nil {
+		t.Fatalf("failed to save document: %v", err)
+	}
+
+	doc, err := store.GetDocument(ctx, "a.go")
+	if err != nil {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+	if doc == nil || doc.Hash != "h1" || len(doc.ChunkIDs) != 2 || !doc.ModTime.Equal(modTime) {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	missing, err := store.GetDocument(ctx, "missing.go")
+	if err != nil {
+		t.Fatalf("GetDocument for missing file failed: %v", err)
+	}
+	if missing != nil {
+		t.Errorf("expected nil for missing document, got %+v", missing)
+	}
+
+	files, err := store.ListFilesWithStats(ctx)
+	if err != nil {
+		t.Fatalf("ListFilesWithStats failed: %v", err)
+	}
+	if len(files) != 1 || files[0].ChunkCount != 2 {
+		t.Errorf("unexpected file stats: %+v", files)
+	}
+
+	stats, err := store.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if stats.TotalFiles != 1 || stats.TotalChunks != 2 || stats.IndexSize == 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	if err := store.DeleteByFile(ctx, "a.go"); err != nil {
+		t.Fatalf("DeleteByFile failed: %v", err)
+	}
+	if err := store.DeleteDocument(ctx, "a.go"); err != nil {
+		t.Fatalf("DeleteDocument failed: %v", err)
+	}
+	chunks, err := store.GetAllChunks(ctx)
+	if err != nil {
+		t.Fatalf("GetAllChunks failed: %v", err)
+	}
+	paths, err := store.ListDocuments(ctx)
+	if err != nil {
+		t.Fatalf("ListDocuments failed: %v", err)
+	}
+	if len(chunks) != 0 || len(paths) != 0 {
+		t.Errorf("expected empty store after delete, got %d chunks and %d documents", len(chunks), len(paths))
+	}
+}
+
+func TestSQLiteStore_ReopenKeepsData(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "index.db")
+	ctx := context.Background()
+
+	store1, err := NewSQLiteStore(ctx, dbPath)
+	if err != nil {
+		t.Fatalf("failed to open sqlite store: %v", err)
+	}
+	if err := store1.SaveChunks(ctx, []Chunk{{ID: "chunk1", FilePath: "test.go", Content: "test content", Vector: []float32{1.0, 0.0}}}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+	if err := store1.Close(); err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+
+	store2, err := NewSQLiteStore(ctx, dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen sqlite store: %v", err)
+	}
+	defer store2.Close()
+
+	chunks, err := store2.GetChunksForFile(ctx, "test.go")
+	if err != nil {
+		t.Fatalf("GetChunksForFile failed: %v", err)
+	}
+	if len(chunks) != 1 || chunks[0].Content != "test content" {
+		t.Errorf("expected persisted chunk after reopen, got %+v", chunks)
+	}
+}
+
+func TestSQLiteStore_LookupByContentHash(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	ctx := context.Background()
+
+	if err := store.SaveChunks(ctx, []Chunk{
+		{ID: "chunk-1", FilePath: "main.go", Content: "func main() {}", Vector: []float32{0.1, 0.2, 0.3}, ContentHash: "abc123"},
+	}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	vec, found, err := store.LookupByContentHash(ctx, "abc123")
+	if err != nil {
+		t.Fatalf("LookupByContentHash failed: %v", err)
+	}
+	if !found || len(vec) != 3 || vec[0] != 0.1 {
+		t.Errorf("unexpected lookup result: found=%v vec=%v", found, vec)
+	}
+
+	_, found, err = store.LookupByContentHash(ctx, "nonexistent")
+	if err != nil {
+		t.Fatalf("LookupByContentHash failed: %v", err)
+	}
+	if found {
+		t.Fatal("expected not found for non-existent hash")
+	}
+}
+
+func TestSQLiteStore_PathPrefixEscapesWildcards(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	ctx := context.Background()
+
+	if err := store.SaveChunks(ctx, []Chunk{
+		{ID: "1", FilePath: "my_pkg/a.go", Vector: []float32{1, 0}},
+		{ID: "2", FilePath: "myXpkg/b.go", Vector: []float32{1, 0}},
+	}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	results, err := store.Search(ctx, []float32{1, 0}, 10, SearchOptions{PathPrefix: "my_pkg/"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.FilePath != "my_pkg/a.go" {
+		t.Errorf("expected literal underscore match only, got %+v", results)
+	}
+}
+
+func TestSQLiteStore_HeadingsRoundTrip(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	ctx := context.Background()
+
+	chunk := Chunk{
+		ID: "readme", FilePath: "README.md", StartLine: 5, EndLine: 12, Content: "Run the image",
+		Vector: []float32{1, 0, 0}, Hash: "h1", UpdatedAt: time.Now(),
+		Headings: []string{"Installation", "Docker, Compose"},
+	}
+	if err := store.SaveChunks(ctx, []Chunk{chunk}); err != nil {
+		t.Fatalf("failed to save chunks: %v", err)
+	}
+
+	results, err := store.Search(ctx, []float32{1, 0, 0}, 1, SearchOptions{})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("search failed: %v, %+v", err, results)
+	}
+	if got := results[0].Chunk.SectionPath(); got != "README.md › Installation › Docker, Compose" {
+		t.Errorf("SectionPath() = %q", got)
+	}
+}
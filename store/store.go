@@ -2,6 +2,9 @@ package store
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -16,6 +19,43 @@ type Chunk struct {
 	Hash        string    `json:"hash"`
 	ContentHash string    `json:"content_hash"` // SHA256 of raw content (path-independent)
 	UpdatedAt   time.Time `json:"updated_at"`
+	SymbolKinds []string  `json:"symbol_kinds,omitempty"` // kinds of symbols defined in the chunk
+	Headings    []string  `json:"headings,omitempty"`     // heading path of Markdown chunks, outermost first
+}
+
+// IsNotebook reports whether path is a Jupyter notebook. Notebook chunks
+// hold 1-based cell numbers in StartLine and EndLine instead of lines.
+func IsNotebook(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".ipynb")
+}
+
+// Cell returns the cell number of a notebook chunk, or 0 for other files.
+func (c Chunk) Cell() int {
+	if IsNotebook(c.FilePath) {
+		return c.StartLine
+	}
+	return 0
+}
+
+// Location returns where the chunk is, as "path:start-end" or, for notebook
+// cells, "path#cell-N".
+func (c Chunk) Location() string {
+	if IsNotebook(c.FilePath) {
+		return fmt.Sprintf("%s#cell-%d", c.FilePath, c.StartLine)
+	}
+	return fmt.Sprintf("%s:%d-%d", c.FilePath, c.StartLine, c.EndLine)
+}
+
+// HeadingSeparator joins the file path and headings of a section path.
+const HeadingSeparator = " › "
+
+// SectionPath returns the file path followed by the chunk's headings, such as
+// "README.md › Installation › Docker", or "" for chunks without headings.
+func (c Chunk) SectionPath() string {
+	if len(c.Headings) == 0 {
+		return ""
+	}
+	return c.FilePath + HeadingSeparator + strings.Join(c.Headings, HeadingSeparator)
 }
 
 // Document represents a file with its chunks
@@ -35,6 +75,25 @@ type SearchResult struct {
 // SearchOptions contains optional filters for vector search queries.
 type SearchOptions struct {
 	PathPrefix string
+
+	// Languages keeps files whose extension belongs to one of these
+	// languages (e.g. "go", "python"). See SupportedLanguages.
+	Languages []string
+
+	// IncludeGlobs keeps files matching at least one pattern; ExcludeGlobs
+	// drops files matching any pattern. Patterns without a slash match the
+	// file name in any directory.
+	IncludeGlobs []string
+	ExcludeGlobs []string
+
+	// ModifiedAfter and ModifiedBefore bound the source file's modification
+	// time. Zero values are ignored.
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+
+	// SymbolKinds keeps chunks defining at least one symbol of these kinds
+	// (function, method, class, interface, type).
+	SymbolKinds []string
 }
 
 // IndexStats contains statistics about the index
@@ -107,3 +166,10 @@ type EmbeddingCache interface {
 	// Returns (vector, true, nil) if found, (nil, false, nil) if not found.
 	LookupByContentHash(ctx context.Context, contentHash string) ([]float32, bool, error)
 }
+
+// TracksDocuments reports whether st keeps document metadata (file hashes
+// and chunk IDs). Qdrant derives documents from chunk payloads and does not.
+func TracksDocuments(st VectorStore) bool {
+	_, ok := st.(*QdrantStore)
+	return !ok
+}
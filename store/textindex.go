@@ -0,0 +1,316 @@
+package store
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/yoanbernabeu/grepai/internal/fileutil"
+)
+
+// BM25 parameters: k1 bounds how much repeated terms add to the score and b
+// how much long chunks are penalized.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// maxTermLength drops longer tokens, such as base64 blobs and hashes, which
+// bloat the index without helping queries.
+const maxTermLength = 64
+
+// textIndexFileVersion is bumped when the persisted format or the tokenizer
+// changes, so older files are rebuilt.
+const textIndexFileVersion = 1
+
+// TextSearcher is an optional interface for stores that keep a full-text
+// index of their chunks, so hybrid search does not load every chunk.
+type TextSearcher interface {
+	// TextSearch returns the chunks best matching the query terms, ranked by
+	// BM25 score.
+	TextSearch(ctx context.Context, query string, limit int, opts SearchOptions) ([]SearchResult, error)
+}
+
+// TextTerms splits text into the terms of the full-text index: lowercase
+// words of at least two characters. Identifiers are also split into their
+// camelCase, snake_case and digit-separated parts, so "parseHTTPRequest"
+// yields "parsehttprequest", "parse", "http" and "request".
+func TextTerms(text string) []string {
+	var terms []string
+	add := func(term string) {
+		if len(term) >= 2 && len(term) <= maxTermLength {
+			terms = append(terms, strings.ToLower(term))
+		}
+	}
+
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		word = strings.Trim(word, "_")
+		parts := identifierParts(word)
+		add(word)
+		if len(parts) > 1 {
+			for _, part := range parts {
+				add(part)
+			}
+		}
+	}
+	return terms
+}
+
+// identifierParts splits an identifier at underscores, lower-to-upper case
+// changes, the last capital of an acronym followed by a lowercase letter
+// (HTTPRequest) and letter/digit changes.
+func identifierParts(word string) []string {
+	var parts []string
+	for _, segment := range strings.Split(word, "_") {
+		runes := []rune(segment)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			split := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+				unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) ||
+				unicode.IsDigit(prev) != unicode.IsDigit(cur)
+			if split {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, string(runes[start:]))
+		}
+	}
+	return parts
+}
+
+// chunkText returns the text indexed for a chunk: its file path, so queries
+// naming a file or directory match, and its content.
+func chunkText(chunk Chunk) string {
+	return chunk.FilePath + "\n" + chunk.Content
+}
+
+// termFrequencies counts the terms of a chunk and returns them with the
+// chunk length in terms.
+func termFrequencies(chunk Chunk) (map[string]uint32, int) {
+	terms := TextTerms(chunkText(chunk))
+	freqs := make(map[string]uint32, len(terms))
+	for _, term := range terms {
+		freqs[term]++
+	}
+	return freqs, len(terms)
+}
+
+// queryTerms returns the distinct terms of a query.
+func queryTerms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range TextTerms(query) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// bm25 scores one query term for a chunk of docLen terms containing it tf
+// times, among n chunks of which df contain it.
+func bm25(tf, docLen int, avgLen float64, df, n int) float64 {
+	idf := math.Log(1 + (float64(n-df)+0.5)/(float64(df)+0.5))
+	norm := 1 - bm25B
+	if avgLen > 0 {
+		norm += bm25B * float64(docLen) / avgLen
+	}
+	return idf * float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*norm)
+}
+
+// TextHit is a chunk matching a full-text query.
+type TextHit struct {
+	ID    string
+	Score float32
+}
+
+// textDoc holds the terms of one indexed chunk.
+type textDoc struct {
+	Hash   string // chunk hash, to detect a stale persisted index
+	Length int
+	Terms  map[string]uint32
+}
+
+// TextIndex is an in-memory inverted index of chunk text with BM25 ranking.
+// It is not safe for concurrent use.
+type TextIndex struct {
+	docs     map[string]textDoc
+	postings map[string]map[string]uint32 // term -> chunk ID -> frequency
+	totalLen int
+}
+
+// NewTextIndex returns an empty text index.
+func NewTextIndex() *TextIndex {
+	return &TextIndex{
+		docs:     make(map[string]textDoc),
+		postings: make(map[string]map[string]uint32),
+	}
+}
+
+// Len returns the number of indexed chunks.
+func (t *TextIndex) Len() int {
+	return len(t.docs)
+}
+
+// Add indexes chunk, replacing any previous entry for its ID.
+func (t *TextIndex) Add(chunk Chunk) {
+	freqs, length := termFrequencies(chunk)
+	t.add(chunk.ID, textDoc{Hash: chunk.Hash, Length: length, Terms: freqs})
+}
+
+func (t *TextIndex) add(id string, doc textDoc) {
+	t.Remove(id)
+	t.docs[id] = doc
+	t.totalLen += doc.Length
+	for term, freq := range doc.Terms {
+		postings, ok := t.postings[term]
+		if !ok {
+			postings = make(map[string]uint32)
+			t.postings[term] = postings
+		}
+		postings[id] = freq
+	}
+}
+
+// Remove drops a chunk from the index.
+func (t *TextIndex) Remove(id string) {
+	doc, ok := t.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.Terms {
+		postings := t.postings[term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(t.postings, term)
+		}
+	}
+	t.totalLen -= doc.Length
+	delete(t.docs, id)
+}
+
+// Search ranks the chunks containing at least one query term by BM25 score
+// and returns the best limit of them, or all when limit is zero. Chunks for
+// which accept returns false are skipped; accept may be nil.
+func (t *TextIndex) Search(query string, limit int, accept func(id string) bool) []TextHit {
+	n := len(t.docs)
+	if n == 0 {
+		return nil
+	}
+	avgLen := float64(t.totalLen) / float64(n)
+
+	scores := make(map[string]float64)
+	for _, term := range queryTerms(query) {
+		postings := t.postings[term]
+		for id, tf := range postings {
+			scores[id] += bm25(int(tf), t.docs[id].Length, avgLen, len(postings), n)
+		}
+	}
+	return rankHits(scores, limit, accept)
+}
+
+// rankHits sorts scored chunks by descending score, breaking ties by ID so
+// results are stable.
+func rankHits(scores map[string]float64, limit int, accept func(id string) bool) []TextHit {
+	hits := make([]TextHit, 0, len(scores))
+	for id, score := range scores {
+		if accept == nil || accept(id) {
+			hits = append(hits, TextHit{ID: id, Score: float32(score)})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// textIndexFile is the on-disk form of a TextIndex. Only the terms of each
+// chunk are stored; postings are rebuilt on load.
+type textIndexFile struct {
+	Version int
+	Docs    map[string]textDoc
+}
+
+// save writes the index to path atomically.
+func (t *TextIndex) save(path string) error {
+	if err := fileutil.EnsureParentDir(path); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create text index file: %w", err)
+	}
+	if err := gob.NewEncoder(file).Encode(textIndexFile{Version: textIndexFileVersion, Docs: t.docs}); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to encode text index: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close text index file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace text index file: %w", err)
+	}
+	return nil
+}
+
+// loadTextIndex reads the index persisted at path. It returns nil, to have
+// the caller rebuild the index from chunks, when the file is missing,
+// corrupt, written by another version or out of sync with chunks.
+func loadTextIndex(path string, chunks map[string]Chunk) (*TextIndex, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open text index file: %w", err)
+	}
+	defer file.Close()
+
+	var data textIndexFile
+	if err := gob.NewDecoder(file).Decode(&data); err != nil {
+		return nil, nil
+	}
+	if data.Version != textIndexFileVersion || len(data.Docs) != len(chunks) {
+		return nil, nil
+	}
+
+	t := NewTextIndex()
+	for id, doc := range data.Docs {
+		chunk, ok := chunks[id]
+		if !ok || chunk.Hash != doc.Hash {
+			return nil, nil
+		}
+		t.add(id, doc)
+	}
+	return t, nil
+}
+
+// buildTextIndex indexes every chunk.
+func buildTextIndex(chunks map[string]Chunk) *TextIndex {
+	t := NewTextIndex()
+	for _, chunk := range chunks {
+		t.Add(chunk)
+	}
+	return t
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTextTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"hello world", []string{"hello", "world"}},
+		{"UPPER CASE", []string{"upper", "case"}},
+		{"a b c", nil},
+		{"", nil},
+		{"getUserName", []string{"getusername", "get", "user", "name"}},
+		{"parseHTTPRequest", []string{"parsehttprequest", "parse", "http", "request"}},
+		{"MAX_RETRY_COUNT", []string{"max_retry_count", "max", "retry", "count"}},
+		{"__init__", []string{"init"}},
+		{"sha256Sum", []string{"sha256sum", "sha", "256", "sum"}},
+		{"user.email(x)", []string{"user", "email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := TextTerms(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TextTerms(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextIndex_Search(t *testing.T) {
+	index := NewTextIndex()
+	index.Add(Chunk{ID: "1", FilePath: "a.go", Content: "func handleLogin() { validateUser() }"})
+	index.Add(Chunk{ID: "2", FilePath: "b.go", Content: "func handleLogout() {}"})
+	index.Add(Chunk{ID: "3", FilePath: "c.go", Content: "func handleSignup() { validateUser(); validateEmail() }"})
+
+	hits := index.Search("validate login", 10, nil)
+	if len(hits) != 2 || hits[0].ID != "1" || hits[1].ID != "3" {
+		t.Fatalf("expected chunks 1 then 3, got %+v", hits)
+	}
+
+	hits = index.Search("validate login", 10, func(id string) bool { return id != "1" })
+	if len(hits) != 1 || hits[0].ID != "3" {
+		t.Errorf("expected accept to drop chunk 1, got %+v", hits)
+	}
+
+	index.Remove("1")
+	if hits := index.Search("login", 10, nil); len(hits) != 0 {
+		t.Errorf("expected removed chunk to be gone, got %+v", hits)
+	}
+	if index.Len() != 2 {
+		t.Errorf("expected 2 indexed chunks, got %d", index.Len())
+	}
+
+	// Re-adding an ID replaces its terms.
+	index.Add(Chunk{ID: "2", FilePath: "b.go", Content: "func renderPage() {}"})
+	if hits := index.Search("logout", 10, nil); len(hits) != 0 {
+		t.Errorf("expected replaced terms to be gone, got %+v", hits)
+	}
+}
+
+func textSearchChunks() []Chunk {
+	return []Chunk{
+		{ID: "auth_0", FilePath: "auth/login.go", StartLine: 1, EndLine: 5, Content: "func checkPassword(hash string) bool", Hash: "h1", Vector: []float32{1, 0}},
+		{ID: "auth_1", FilePath: "auth/login.go", StartLine: 6, EndLine: 9, Content: "func issueToken() string", Hash: "h2", Vector: []float32{0, 1}},
+		{ID: "db_0", FilePath: "db/users.go", StartLine: 1, EndLine: 4, Content: "func findUserByEmail(email string)", Hash: "h3", Vector: []float32{1, 1}},
+	}
+}
+
+func assertTextSearch(t *testing.T, ts TextSearcher, query string, opts SearchOptions, want ...string) {
+	t.Helper()
+	results, err := ts.TextSearch(context.Background(), query, 10, opts)
+	if err != nil {
+		t.Fatalf("TextSearch(%q) failed: %v", query, err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Chunk.ID)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TextSearch(%q) = %v, want %v", query, got, want)
+	}
+}
+
+func TestGOBStore_TextSearch(t *testing.T) {
+	ctx := context.Background()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+	s := NewGOBStore(indexPath)
+	if err := s.SaveChunks(ctx, textSearchChunks()); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if err := s.SaveDocument(ctx, Document{Path: "auth/login.go", ChunkIDs: []string{"auth_0", "auth_1"}}); err != nil {
+		t.Fatalf("SaveDocument failed: %v", err)
+	}
+
+	assertTextSearch(t, s, "token login", SearchOptions{}, "auth_1", "auth_0")
+	assertTextSearch(t, s, "user email", SearchOptions{}, "db_0")
+	assertTextSearch(t, s, "login", SearchOptions{PathPrefix: "db/"})
+
+	if err := s.Persist(ctx); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	if _, err := os.Stat(indexPath + ".bm25"); err != nil {
+		t.Fatalf("expected persisted text index: %v", err)
+	}
+	reloaded := NewGOBStore(indexPath)
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	assertTextSearch(t, reloaded, "password", SearchOptions{}, "auth_0")
+
+	if err := reloaded.DeleteByFile(ctx, "auth/login.go"); err != nil {
+		t.Fatalf("DeleteByFile failed: %v", err)
+	}
+	assertTextSearch(t, reloaded, "password token", SearchOptions{})
+}
+
+func TestGOBStore_TextSearch_RebuildsStaleIndex(t *testing.T) {
+	ctx := context.Background()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+	s := NewGOBStore(indexPath)
+	if err := s.SaveChunks(ctx, textSearchChunks()); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	if err := s.Persist(ctx); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	// Simulate an index file written without its text index.
+	if err := os.WriteFile(indexPath+".bm25", []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewGOBStore(indexPath)
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	assertTextSearch(t, reloaded, "token", SearchOptions{}, "auth_1")
+}
+
+func TestSQLiteStore_TextSearch(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "index.db")
+	s, err := NewSQLiteStore(ctx, dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	if err := s.SaveChunks(ctx, textSearchChunks()); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+
+	assertTextSearch(t, s, "token login", SearchOptions{}, "auth_1", "auth_0")
+	assertTextSearch(t, s, "login", SearchOptions{PathPrefix: "db/"})
+	assertTextSearch(t, s, "find user", SearchOptions{ExcludeGlobs: []string{"*.go"}})
+
+	// Updating a chunk replaces its terms.
+	updated := textSearchChunks()[1]
+	updated.Content = "func revokeSession()"
+	if err := s.SaveChunks(ctx, []Chunk{updated}); err != nil {
+		t.Fatalf("SaveChunks failed: %v", err)
+	}
+	assertTextSearch(t, s, "token", SearchOptions{})
+	assertTextSearch(t, s, "session", SearchOptions{}, "auth_1")
+
+	if err := s.DeleteByFile(ctx, "auth/login.go"); err != nil {
+		t.Fatalf("DeleteByFile failed: %v", err)
+	}
+	assertTextSearch(t, s, "password session", SearchOptions{})
+
+	// Chunks saved before the text index existed are indexed on open.
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM text_docs`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM text_terms`); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, err = NewSQLiteStore(ctx, dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore failed: %v", err)
+	}
+	defer s.Close()
+	assertTextSearch(t, s, "email", SearchOptions{}, "db_0")
+}
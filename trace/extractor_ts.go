@@ -23,27 +23,34 @@ type TreeSitterExtractor struct {
 	parsers map[string]*sitter.Parser
 }
 
+// treeSitterLanguages maps file extensions to their tree-sitter grammar.
+var treeSitterLanguages = map[string]*sitter.Language{
+	".go":  golang.GetLanguage(),
+	".js":  javascript.GetLanguage(),
+	".jsx": javascript.GetLanguage(),
+	".ts":  typescript.GetLanguage(),
+	".tsx": typescript.GetLanguage(),
+	".py":  python.GetLanguage(),
+	".php": php.GetLanguage(),
+	".cs":  csharp.GetLanguage(),
+	".fs":  fsharp.GetLanguage(),
+	".fsx": fsharp.GetLanguage(),
+	".fsi": fsharp.GetLanguage(),
+}
+
+// TreeSitterLanguage returns the tree-sitter grammar for a file extension
+// (including the dot), or nil if none is bundled.
+func TreeSitterLanguage(ext string) *sitter.Language {
+	return treeSitterLanguages[strings.ToLower(ext)]
+}
+
 // NewTreeSitterExtractor creates a new tree-sitter based extractor.
 func NewTreeSitterExtractor() (*TreeSitterExtractor, error) {
 	ext := &TreeSitterExtractor{
 		parsers: make(map[string]*sitter.Parser),
 	}
 
-	languages := map[string]*sitter.Language{
-		".go":  golang.GetLanguage(),
-		".js":  javascript.GetLanguage(),
-		".jsx": javascript.GetLanguage(),
-		".ts":  typescript.GetLanguage(),
-		".tsx": typescript.GetLanguage(),
-		".py":  python.GetLanguage(),
-		".php": php.GetLanguage(),
-		".cs":  csharp.GetLanguage(),
-		".fs":  fsharp.GetLanguage(),
-		".fsx": fsharp.GetLanguage(),
-		".fsi": fsharp.GetLanguage(),
-	}
-
-	for extension, lang := range languages {
+	for extension, lang := range treeSitterLanguages {
 		parser := sitter.NewParser()
 		parser.SetLanguage(lang)
 		ext.parsers[extension] = parser
@@ -0,0 +1,35 @@
+package trace
+
+import (
+	"context"
+	"os"
+
+	"github.com/yoanbernabeu/grepai/config"
+)
+
+// NewProjectSymbolStore returns the symbol index of the project at
+// projectRoot: its symbols.gob when the project is watched on its own,
+// otherwise the shared database of the PostgreSQL workspace it is watched
+// in. It returns nil when the project has neither. The store is returned
+// unloaded.
+func NewProjectSymbolStore(ctx context.Context, projectRoot string) (SymbolStore, error) {
+	indexPath := config.GetSymbolIndexPath(projectRoot)
+	if _, err := os.Stat(indexPath); err == nil {
+		return NewGOBSymbolStore(indexPath), nil
+	}
+
+	ws, project, err := config.FindWorkspaceProject(projectRoot)
+	if err != nil || ws == nil || ws.Store.Backend != "postgres" {
+		return nil, err
+	}
+	return NewPostgresSymbolStore(ctx, ws.Store.Postgres.DSN, ws.SymbolProjectID(project.Name))
+}
+
+// Discard releases the resources held by a store that was only read. Close
+// can't be used there since the GOB store persists on Close and would
+// rewrite an index another process may be updating.
+func Discard(s SymbolStore) {
+	if pg, ok := s.(*PostgresSymbolStore); ok {
+		_ = pg.Close()
+	}
+}
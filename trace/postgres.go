@@ -0,0 +1,614 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresSymbolStore implements SymbolStore on top of PostgreSQL. Symbols,
+// references and call edges are stored in tables keyed by project ID, so
+// several projects (e.g. the members of a workspace) can share one database
+// and be queried together without their files being present locally.
+type PostgresSymbolStore struct {
+	pool *pgxpool.Pool
+
+	// projectIDs scopes every query. Writes are only allowed when the store
+	// targets a single project.
+	projectIDs []string
+
+	// Content hash per indexed file of a single-project store, filled by Load
+	// so IsFileIndexed and GetFileContentHash need no round trip.
+	mu    sync.RWMutex
+	files map[string]string
+}
+
+// NewPostgresSymbolStore connects to the database at dsn and ensures the
+// symbol tables exist. Queries span every project in projectIDs; a store
+// opened on more than one project is read-only.
+func NewPostgresSymbolStore(ctx context.Context, dsn string, projectIDs ...string) (*PostgresSymbolStore, error) {
+	if len(projectIDs) == 0 {
+		return nil, fmt.Errorf("postgres symbol store requires at least one project ID")
+	}
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	s := &PostgresSymbolStore{
+		pool:       pool,
+		projectIDs: projectIDs,
+		files:      make(map[string]string),
+	}
+	if err := s.ensureSchema(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *PostgresSymbolStore) ensureSchema(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS symbol_files (
+			project_id TEXT NOT NULL,
+			path TEXT NOT NULL,
+			content_hash TEXT NOT NULL DEFAULT '',
+			updated_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (project_id, path)
+		)`,
+		`CREATE TABLE IF NOT EXISTS symbols (
+			project_id TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			name TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			line INTEGER NOT NULL,
+			end_line INTEGER NOT NULL,
+			signature TEXT NOT NULL,
+			receiver TEXT NOT NULL,
+			package_name TEXT NOT NULL,
+			exported BOOLEAN NOT NULL,
+			language TEXT NOT NULL,
+			docstring TEXT NOT NULL,
+			feature_path TEXT NOT NULL,
+			FOREIGN KEY (project_id, file_path) REFERENCES symbol_files (project_id, path) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_symbols_name ON symbols(project_id, name)`,
+		`CREATE INDEX IF NOT EXISTS idx_symbols_file ON symbols(project_id, file_path)`,
+		`CREATE TABLE IF NOT EXISTS symbol_refs (
+			project_id TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			symbol_name TEXT NOT NULL,
+			line INTEGER NOT NULL,
+			col INTEGER NOT NULL,
+			context TEXT NOT NULL,
+			caller_name TEXT NOT NULL,
+			caller_file TEXT NOT NULL,
+			caller_line INTEGER NOT NULL,
+			FOREIGN KEY (project_id, file_path) REFERENCES symbol_files (project_id, path) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_symbol_refs_symbol ON symbol_refs(project_id, symbol_name)`,
+		`CREATE INDEX IF NOT EXISTS idx_symbol_refs_caller ON symbol_refs(project_id, caller_name)`,
+		`CREATE INDEX IF NOT EXISTS idx_symbol_refs_file ON symbol_refs(project_id, file_path)`,
+		`CREATE TABLE IF NOT EXISTS call_edges (
+			project_id TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			caller TEXT NOT NULL,
+			callee TEXT NOT NULL,
+			line INTEGER NOT NULL,
+			call_type TEXT NOT NULL,
+			FOREIGN KEY (project_id, file_path) REFERENCES symbol_files (project_id, path) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_call_edges_caller ON call_edges(project_id, caller)`,
+		`CREATE INDEX IF NOT EXISTS idx_call_edges_callee ON call_edges(project_id, callee)`,
+		`CREATE INDEX IF NOT EXISTS idx_call_edges_file ON call_edges(project_id, file_path)`,
+	}
+
+	for _, query := range queries {
+		if _, err := s.pool.Exec(ctx, query); err != nil {
+			return fmt.Errorf("failed to execute schema query: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeProject returns the project receiving writes.
+func (s *PostgresSymbolStore) writeProject() (string, error) {
+	if len(s.projectIDs) != 1 {
+		return "", fmt.Errorf("symbol store spans %d projects and is read-only", len(s.projectIDs))
+	}
+	return s.projectIDs[0], nil
+}
+
+// Load caches the content hashes of the indexed files.
+func (s *PostgresSymbolStore) Load(ctx context.Context) error {
+	files := make(map[string]string)
+	if len(s.projectIDs) == 1 {
+		rows, err := s.pool.Query(ctx,
+			`SELECT path, content_hash FROM symbol_files WHERE project_id = $1`,
+			s.projectIDs[0],
+		)
+		if err != nil {
+			return fmt.Errorf("failed to load symbol files: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var path, hash string
+			if err := rows.Scan(&path, &hash); err != nil {
+				return fmt.Errorf("failed to scan symbol file: %w", err)
+			}
+			files[path] = hash
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to load symbol files: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	s.files = files
+	s.mu.Unlock()
+	return nil
+}
+
+// Persist is a no-op: every write is committed immediately.
+func (s *PostgresSymbolStore) Persist(ctx context.Context) error {
+	return nil
+}
+
+// Close shuts down the connection pool.
+func (s *PostgresSymbolStore) Close() error {
+	s.pool.Close()
+	return nil
+}
+
+// SaveFile persists symbols and references for a file.
+func (s *PostgresSymbolStore) SaveFile(ctx context.Context, filePath string, symbols []Symbol, refs []Reference) error {
+	return s.SaveFileWithContentHash(ctx, filePath, "", symbols, refs)
+}
+
+// SaveFileWithContentHash replaces the symbols, references and call edges
+// of a file in a single transaction.
+func (s *PostgresSymbolStore) SaveFileWithContentHash(ctx context.Context, filePath string, contentHash string, symbols []Symbol, refs []Reference) error {
+	projectID, err := s.writeProject()
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Deleting the file row cascades to its symbols, references and edges.
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM symbol_files WHERE project_id = $1 AND path = $2`,
+		projectID, filePath,
+	); err != nil {
+		return fmt.Errorf("failed to delete symbols: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO symbol_files (project_id, path, content_hash, updated_at) VALUES ($1, $2, $3, $4)`,
+		projectID, filePath, contentHash, time.Now(),
+	); err != nil {
+		return fmt.Errorf("failed to save symbol file: %w", err)
+	}
+
+	symbolRows := make([][]any, 0, len(symbols))
+	for _, sym := range symbols {
+		symbolRows = append(symbolRows, []any{
+			projectID, filePath, sym.Name, string(sym.Kind), sym.Line, sym.EndLine, sym.Signature,
+			sym.Receiver, sym.Package, sym.Exported, sym.Language, sym.Docstring, sym.FeaturePath,
+		})
+	}
+	refRows := make([][]any, 0, len(refs))
+	var edgeRows [][]any
+	for _, ref := range refs {
+		refRows = append(refRows, []any{
+			projectID, filePath, ref.SymbolName, ref.Line, ref.Column, ref.Context,
+			ref.CallerName, ref.CallerFile, ref.CallerLine,
+		})
+		if ref.CallerName != "" && ref.CallerName != "<top-level>" {
+			edgeRows = append(edgeRows, []any{projectID, filePath, ref.CallerName, ref.SymbolName, ref.Line, "direct"})
+		}
+	}
+
+	copies := []struct {
+		table   string
+		columns []string
+		rows    [][]any
+	}{
+		{"symbols", []string{"project_id", "file_path", "name", "kind", "line", "end_line", "signature",
+			"receiver", "package_name", "exported", "language", "docstring", "feature_path"}, symbolRows},
+		{"symbol_refs", []string{"project_id", "file_path", "symbol_name", "line", "col", "context",
+			"caller_name", "caller_file", "caller_line"}, refRows},
+		{"call_edges", []string{"project_id", "file_path", "caller", "callee", "line", "call_type"}, edgeRows},
+	}
+	for _, c := range copies {
+		if len(c.rows) == 0 {
+			continue
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{c.table}, c.columns, pgx.CopyFromRows(c.rows)); err != nil {
+			return fmt.Errorf("failed to save %s: %w", c.table, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit symbols: %w", err)
+	}
+
+	s.mu.Lock()
+	s.files[filePath] = contentHash
+	s.mu.Unlock()
+	return nil
+}
+
+// DeleteFile removes all symbols and references for a file.
+func (s *PostgresSymbolStore) DeleteFile(ctx context.Context, filePath string) error {
+	projectID, err := s.writeProject()
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.pool.Exec(ctx,
+		`DELETE FROM symbol_files WHERE project_id = $1 AND path = $2`,
+		projectID, filePath,
+	); err != nil {
+		return fmt.Errorf("failed to delete symbols: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.files, filePath)
+	s.mu.Unlock()
+	return nil
+}
+
+// RenameFile moves the entries of oldPath to newPath in a single
+// transaction.
+func (s *PostgresSymbolStore) RenameFile(ctx context.Context, oldPath, newPath string) error {
+	projectID, err := s.writeProject()
+	if err != nil {
+		return err
+	}
+	if oldPath == newPath {
+		return nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var hash string
+	err = tx.QueryRow(ctx,
+		`SELECT content_hash FROM symbol_files WHERE project_id = $1 AND path = $2`,
+		projectID, oldPath,
+	).Scan(&hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up symbol file: %w", err)
+	}
+
+	// The new file row must exist before its children point to it; the old
+	// one is deleted once they no longer do.
+	queries := []struct {
+		sql  string
+		args []any
+	}{
+		{`DELETE FROM symbol_files WHERE project_id = $1 AND path = $2`, []any{projectID, newPath}},
+		{`INSERT INTO symbol_files (project_id, path, content_hash, updated_at) VALUES ($1, $2, $3, $4)`, []any{projectID, newPath, hash, time.Now()}},
+		{`UPDATE symbols SET file_path = $3 WHERE project_id = $1 AND file_path = $2`, []any{projectID, oldPath, newPath}},
+		{`UPDATE symbol_refs SET file_path = $3 WHERE project_id = $1 AND file_path = $2`, []any{projectID, oldPath, newPath}},
+		{`UPDATE symbol_refs SET caller_file = $3 WHERE project_id = $1 AND caller_file = $2`, []any{projectID, oldPath, newPath}},
+		{`UPDATE call_edges SET file_path = $3 WHERE project_id = $1 AND file_path = $2`, []any{projectID, oldPath, newPath}},
+		{`DELETE FROM symbol_files WHERE project_id = $1 AND path = $2`, []any{projectID, oldPath}},
+	}
+	for _, q := range queries {
+		if _, err := tx.Exec(ctx, q.sql, q.args...); err != nil {
+			return fmt.Errorf("failed to rename symbols: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit symbols: %w", err)
+	}
+
+	s.mu.Lock()
+	delete(s.files, oldPath)
+	s.files[newPath] = hash
+	s.mu.Unlock()
+	return nil
+}
+
+// IsFileIndexed checks if a file has been indexed. It always reports false
+// for stores spanning several projects.
+func (s *PostgresSymbolStore) IsFileIndexed(filePath string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.files[filePath]
+	return ok
+}
+
+// GetFileContentHash returns the stored content hash for a file when available.
+func (s *PostgresSymbolStore) GetFileContentHash(filePath string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hash, ok := s.files[filePath]
+	if !ok || hash == "" {
+		return "", false
+	}
+	return hash, true
+}
+
+const pgSymbolColumns = `file_path, name, kind, line, end_line, signature, receiver, package_name, exported, language, docstring, feature_path`
+
+func (s *PostgresSymbolStore) querySymbols(ctx context.Context, query string, args ...any) ([]Symbol, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query symbols: %w", err)
+	}
+	defer rows.Close()
+
+	symbols := []Symbol{}
+	for rows.Next() {
+		var sym Symbol
+		var kind string
+		if err := rows.Scan(&sym.File, &sym.Name, &kind, &sym.Line, &sym.EndLine, &sym.Signature, &sym.Receiver,
+			&sym.Package, &sym.Exported, &sym.Language, &sym.Docstring, &sym.FeaturePath); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol: %w", err)
+		}
+		sym.Kind = SymbolKind(kind)
+		symbols = append(symbols, sym)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query symbols: %w", err)
+	}
+	return symbols, nil
+}
+
+func (s *PostgresSymbolStore) queryReferences(ctx context.Context, query string, args ...any) ([]Reference, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query references: %w", err)
+	}
+	defer rows.Close()
+
+	refs := []Reference{}
+	for rows.Next() {
+		var ref Reference
+		if err := rows.Scan(&ref.File, &ref.SymbolName, &ref.Line, &ref.Column, &ref.Context,
+			&ref.CallerName, &ref.CallerFile, &ref.CallerLine); err != nil {
+			return nil, fmt.Errorf("failed to scan reference: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query references: %w", err)
+	}
+	return refs, nil
+}
+
+func (s *PostgresSymbolStore) queryEdges(ctx context.Context, query string, args ...any) ([]CallEdge, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query call edges: %w", err)
+	}
+	defer rows.Close()
+
+	edges := []CallEdge{}
+	for rows.Next() {
+		var edge CallEdge
+		if err := rows.Scan(&edge.Caller, &edge.Callee, &edge.File, &edge.Line, &edge.CallType); err != nil {
+			return nil, fmt.Errorf("failed to scan call edge: %w", err)
+		}
+		edges = append(edges, edge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query call edges: %w", err)
+	}
+	return edges, nil
+}
+
+// LookupSymbol finds symbol definitions by name.
+func (s *PostgresSymbolStore) LookupSymbol(ctx context.Context, name string) ([]Symbol, error) {
+	return s.querySymbols(ctx,
+		`SELECT `+pgSymbolColumns+` FROM symbols
+		WHERE project_id = ANY($1) AND name = $2
+		ORDER BY array_position($1, project_id), file_path, line`,
+		s.projectIDs, name,
+	)
+}
+
+// LookupCallers finds all references/callers of a symbol.
+func (s *PostgresSymbolStore) LookupCallers(ctx context.Context, symbolName string) ([]Reference, error) {
+	return s.queryReferences(ctx,
+		`SELECT file_path, symbol_name, line, col, context, caller_name, caller_file, caller_line FROM symbol_refs
+		WHERE project_id = ANY($1) AND symbol_name = $2
+		ORDER BY array_position($1, project_id), file_path, line`,
+		s.projectIDs, symbolName,
+	)
+}
+
+// LookupCallees finds all symbols called by a function. Like the GOB store,
+// it matches callers by name only and ignores file.
+func (s *PostgresSymbolStore) LookupCallees(ctx context.Context, symbolName string, file string) ([]Reference, error) {
+	return s.queryReferences(ctx,
+		`SELECT DISTINCT ON (array_position($1, project_id), file_path, line)
+			file_path, symbol_name, line, col, context, caller_name, caller_file, caller_line
+		FROM symbol_refs
+		WHERE project_id = ANY($1) AND caller_name = $2 AND caller_name <> '<top-level>'
+		ORDER BY array_position($1, project_id), file_path, line`,
+		s.projectIDs, symbolName,
+	)
+}
+
+// GetCallGraph builds a call graph from a starting symbol. The traversal
+// mirrors GOBSymbolStore.GetCallGraph, fetching nodes and edges on demand.
+func (s *PostgresSymbolStore) GetCallGraph(ctx context.Context, symbolName string, depth int) (*CallGraph, error) {
+	graph := &CallGraph{
+		Root:  symbolName,
+		Nodes: make(map[string]Symbol),
+		Edges: []CallEdge{},
+		Depth: depth,
+	}
+
+	symbolCache := make(map[string][]Symbol)
+	lookup := func(name string) ([]Symbol, error) {
+		if symbols, ok := symbolCache[name]; ok {
+			return symbols, nil
+		}
+		symbols, err := s.LookupSymbol(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		symbolCache[name] = symbols
+		return symbols, nil
+	}
+	isDeclarationSelfEdge := func(edge CallEdge) (bool, error) {
+		if edge.Caller != edge.Callee {
+			return false, nil
+		}
+		symbols, err := lookup(edge.Caller)
+		if err != nil {
+			return false, err
+		}
+		for _, sym := range symbols {
+			if sym.File == edge.File && sym.Line == edge.Line {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	type queueItem struct {
+		name  string
+		depth int
+	}
+	queue := []queueItem{{symbolName, 0}}
+	visited := make(map[string]bool)
+	edgeSeen := make(map[string]bool)
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if visited[current.name] || current.depth > depth {
+			continue
+		}
+		visited[current.name] = true
+
+		symbols, err := lookup(current.name)
+		if err != nil {
+			return nil, err
+		}
+		if len(symbols) > 0 {
+			graph.Nodes[current.name] = symbols[0]
+		}
+
+		// Incoming edges are only followed for the root.
+		edges, err := s.queryEdges(ctx,
+			`SELECT caller, callee, file_path, line, call_type FROM call_edges
+			WHERE project_id = ANY($1) AND (caller = $2 OR ($3 AND callee = $2))
+			ORDER BY array_position($1, project_id), file_path, line`,
+			s.projectIDs, current.name, current.depth == 0,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, edge := range edges {
+			selfEdge, err := isDeclarationSelfEdge(edge)
+			if err != nil {
+				return nil, err
+			}
+			if selfEdge {
+				continue
+			}
+			edgeKey := fmt.Sprintf("%s->%s", edge.Caller, edge.Callee)
+			if !edgeSeen[edgeKey] {
+				graph.Edges = append(graph.Edges, edge)
+				edgeSeen[edgeKey] = true
+			}
+
+			if edge.Caller == current.name {
+				if visited[edge.Callee] {
+					continue
+				}
+				// Avoid exploding through unknown or name-collided symbols.
+				callees, err := lookup(edge.Callee)
+				if err != nil {
+					return nil, err
+				}
+				if len(callees) == 1 {
+					queue = append(queue, queueItem{edge.Callee, current.depth + 1})
+				}
+				continue
+			}
+
+			if _, exists := graph.Nodes[edge.Caller]; !exists {
+				callers, err := lookup(edge.Caller)
+				if err != nil {
+					return nil, err
+				}
+				if len(callers) > 0 {
+					graph.Nodes[edge.Caller] = callers[0]
+				}
+			}
+		}
+	}
+
+	return graph, nil
+}
+
+// GetSymbolsForFile returns all symbols defined in a specific file.
+func (s *PostgresSymbolStore) GetSymbolsForFile(ctx context.Context, filePath string) ([]Symbol, error) {
+	return s.querySymbols(ctx,
+		`SELECT `+pgSymbolColumns+` FROM symbols
+		WHERE project_id = ANY($1) AND file_path = $2
+		ORDER BY array_position($1, project_id), line`,
+		s.projectIDs, filePath,
+	)
+}
+
+// GetCallEdges returns all call graph edges.
+func (s *PostgresSymbolStore) GetCallEdges(ctx context.Context) ([]CallEdge, error) {
+	return s.queryEdges(ctx,
+		`SELECT caller, callee, file_path, line, call_type FROM call_edges
+		WHERE project_id = ANY($1)
+		ORDER BY array_position($1, project_id), file_path, line`,
+		s.projectIDs,
+	)
+}
+
+// GetStats returns statistics about the symbol index.
+func (s *PostgresSymbolStore) GetStats(ctx context.Context) (*SymbolStats, error) {
+	var stats SymbolStats
+	var lastUpdated *time.Time
+	err := s.pool.QueryRow(ctx,
+		`SELECT
+			(SELECT COUNT(*) FROM symbols WHERE project_id = ANY($1)),
+			(SELECT COUNT(*) FROM symbol_refs WHERE project_id = ANY($1)),
+			(SELECT COUNT(*) FROM symbol_files WHERE project_id = ANY($1)),
+			(SELECT MAX(updated_at) FROM symbol_files WHERE project_id = ANY($1))`,
+		s.projectIDs,
+	).Scan(&stats.TotalSymbols, &stats.TotalReferences, &stats.TotalFiles, &lastUpdated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol stats: %w", err)
+	}
+	if lastUpdated != nil {
+		stats.LastUpdated = *lastUpdated
+	}
+	return &stats, nil
+}
@@ -0,0 +1,55 @@
+package trace
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// These tests don't require a real database connection.
+
+func TestNewPostgresSymbolStore_RequiresProjectID(t *testing.T) {
+	_, err := NewPostgresSymbolStore(context.Background(), "postgres://localhost:5432/grepai")
+	if err == nil || !strings.Contains(err.Error(), "project ID") {
+		t.Fatalf("expected missing project ID error, got %v", err)
+	}
+}
+
+func TestPostgresSymbolStore_MultiProjectStoreIsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	s := &PostgresSymbolStore{
+		projectIDs: []string{"workspace:ws/api", "workspace:ws/web"},
+		files:      make(map[string]string),
+	}
+
+	if err := s.SaveFile(ctx, "main.go", nil, nil); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("SaveFile: expected read-only error, got %v", err)
+	}
+	if err := s.DeleteFile(ctx, "main.go"); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("DeleteFile: expected read-only error, got %v", err)
+	}
+	if s.IsFileIndexed("main.go") {
+		t.Error("multi-project store should not report indexed files")
+	}
+}
+
+func TestPostgresSymbolStore_FileCache(t *testing.T) {
+	s := &PostgresSymbolStore{
+		projectIDs: []string{"workspace:ws/api"},
+		files:      map[string]string{"a.go": "hash-a", "b.go": ""},
+	}
+
+	if hash, ok := s.GetFileContentHash("a.go"); !ok || hash != "hash-a" {
+		t.Errorf("GetFileContentHash(a.go) = %q, %v; want hash-a, true", hash, ok)
+	}
+	// Files saved without a hash are indexed but have no hash to compare.
+	if !s.IsFileIndexed("b.go") {
+		t.Error("b.go should be indexed")
+	}
+	if _, ok := s.GetFileContentHash("b.go"); ok {
+		t.Error("b.go should have no content hash")
+	}
+	if s.IsFileIndexed("c.go") {
+		t.Error("c.go should not be indexed")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -264,6 +265,48 @@ func (s *GOBSymbolStore) deleteFileUnlocked(filePath string) {
 	delete(s.fileContentHashes, filePath)
 }
 
+// RenameFile moves the entries of oldPath to newPath.
+func (s *GOBSymbolStore) RenameFile(ctx context.Context, oldPath, newPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.fileIndex[oldPath] || oldPath == newPath {
+		return nil
+	}
+	s.deleteFileUnlocked(newPath)
+
+	for _, symbols := range s.index.Symbols {
+		for i := range symbols {
+			if symbols[i].File == oldPath {
+				symbols[i].File = newPath
+			}
+		}
+	}
+	for _, refs := range s.index.References {
+		for i := range refs {
+			if refs[i].File == oldPath {
+				refs[i].File = newPath
+			}
+			if refs[i].CallerFile == oldPath {
+				refs[i].CallerFile = newPath
+			}
+		}
+	}
+	for i := range s.index.CallGraph {
+		if s.index.CallGraph[i].File == oldPath {
+			s.index.CallGraph[i].File = newPath
+		}
+	}
+
+	s.fileIndex[newPath] = true
+	delete(s.fileIndex, oldPath)
+	if hash, ok := s.fileContentHashes[oldPath]; ok {
+		s.fileContentHashes[newPath] = hash
+		delete(s.fileContentHashes, oldPath)
+	}
+	return nil
+}
+
 // LookupSymbol finds symbol definitions by name.
 func (s *GOBSymbolStore) LookupSymbol(ctx context.Context, name string) ([]Symbol, error) {
 	s.mu.RLock()
@@ -491,6 +534,19 @@ func (s *GOBSymbolStore) IsFileIndexed(filePath string) bool {
 	return s.fileIndex[filePath]
 }
 
+// ListFiles returns the sorted paths of all files in the symbol index.
+func (s *GOBSymbolStore) ListFiles() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files := make([]string, 0, len(s.fileIndex))
+	for path := range s.fileIndex {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
 // GetFileContentHash returns the stored content hash for a file when available.
 func (s *GOBSymbolStore) GetFileContentHash(filePath string) (string, bool) {
 	s.mu.RLock()
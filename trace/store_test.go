@@ -603,3 +603,49 @@ func TestGOBSymbolStore_PersistCreatesLockFileAndNoTempFiles(t *testing.T) {
 		}
 	}
 }
+
+func TestGOBSymbolStore_should_rename_file(t *testing.T) {
+	store := NewGOBSymbolStore(filepath.Join(t.TempDir(), "symbols.gob"))
+	ctx := context.Background()
+
+	symbols := []Symbol{
+		{Name: "Foo", Kind: KindFunction, File: "old.go", Line: 1, Language: "go"},
+	}
+	refs := []Reference{
+		{SymbolName: "Foo", File: "old.go", Line: 5, CallerName: "Bar", CallerFile: "old.go", CallerLine: 4},
+	}
+	if err := store.SaveFileWithContentHash(ctx, "old.go", "hash-old", symbols, refs); err != nil {
+		t.Fatalf("SaveFileWithContentHash failed: %v", err)
+	}
+
+	if err := store.RenameFile(ctx, "old.go", "new.go"); err != nil {
+		t.Fatalf("RenameFile failed: %v", err)
+	}
+
+	if store.IsFileIndexed("old.go") || !store.IsFileIndexed("new.go") {
+		t.Error("expected new.go indexed instead of old.go")
+	}
+	if hash, ok := store.GetFileContentHash("new.go"); !ok || hash != "hash-old" {
+		t.Errorf("GetFileContentHash(new.go) = %q, %v; want hash-old, true", hash, ok)
+	}
+	result, _ := store.LookupSymbol(ctx, "Foo")
+	if len(result) != 1 || result[0].File != "new.go" {
+		t.Errorf("expected Foo in new.go, got %+v", result)
+	}
+	callers, _ := store.LookupCallers(ctx, "Foo")
+	if len(callers) != 1 || callers[0].File != "new.go" || callers[0].CallerFile != "new.go" {
+		t.Errorf("expected the reference moved to new.go, got %+v", callers)
+	}
+	edges, _ := store.GetCallEdges(ctx)
+	if len(edges) != 1 || edges[0].File != "new.go" {
+		t.Errorf("expected the call edge moved to new.go, got %+v", edges)
+	}
+
+	// Renaming a file that is not indexed does nothing.
+	if err := store.RenameFile(ctx, "missing.go", "new.go"); err != nil {
+		t.Fatalf("RenameFile failed: %v", err)
+	}
+	if !store.IsFileIndexed("new.go") {
+		t.Error("new.go should still be indexed")
+	}
+}
@@ -170,3 +170,25 @@ type SymbolStore interface {
 	// GetStats returns statistics about the symbol index.
 	GetStats(ctx context.Context) (*SymbolStats, error)
 }
+
+// ContentHashSymbolStore is a SymbolStore that records the content hash of
+// each indexed file, so indexing can skip files that did not change.
+type ContentHashSymbolStore interface {
+	SymbolStore
+
+	// SaveFileWithContentHash persists symbols and references for a file
+	// together with its content hash.
+	SaveFileWithContentHash(ctx context.Context, filePath string, contentHash string, symbols []Symbol, refs []Reference) error
+
+	// GetFileContentHash returns the stored content hash for a file.
+	GetFileContentHash(filePath string) (string, bool)
+}
+
+// FileRenamer is implemented by symbol stores that can move the entries of a
+// renamed file without extracting its symbols again.
+type FileRenamer interface {
+	// RenameFile moves the symbols, references, call edges and content hash
+	// of oldPath to newPath, replacing the entries of newPath. It does
+	// nothing if oldPath is not indexed.
+	RenameFile(ctx context.Context, oldPath, newPath string) error
+}
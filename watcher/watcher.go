@@ -31,6 +31,7 @@ type Watcher struct {
 	root       string
 	watcher    *fsnotify.Watcher
 	ignore     *indexer.IgnoreMatcher
+	filter     *indexer.FileFilter
 	debounceMs int
 	events     chan FileEvent
 	done       chan struct{}
@@ -51,6 +52,7 @@ func NewWatcher(root string, ignore *indexer.IgnoreMatcher, debounceMs int) (*Wa
 		root:       root,
 		watcher:    fsw,
 		ignore:     ignore,
+		filter:     indexer.DefaultFileFilter(),
 		debounceMs: debounceMs,
 		events:     make(chan FileEvent, 100),
 		done:       make(chan struct{}),
@@ -58,6 +60,12 @@ func NewWatcher(root string, ignore *indexer.IgnoreMatcher, debounceMs int) (*Wa
 	}, nil
 }
 
+// SetFileFilter sets the filter deciding which file events are reported.
+// It must be called before Start.
+func (w *Watcher) SetFileFilter(filter *indexer.FileFilter) {
+	w.filter = filter
+}
+
 func (w *Watcher) Start(ctx context.Context) error {
 	// Add root directory and all subdirectories
 	if err := w.addRecursive(w.root); err != nil {
@@ -148,9 +156,13 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 		return
 	}
 
-	// Check if it's a supported file
-	ext := strings.ToLower(filepath.Ext(event.Name))
-	if !indexer.SupportedExtensions[ext] {
+	// Check if it's a supported file. Deleted files can only be judged by
+	// name.
+	supported := w.filter.MatchName(relPath)
+	if supported && !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		supported = w.filter.CheckType(event.Name, relPath) == ""
+	}
+	if !supported {
 		// Check if it's a directory (for watching new directories)
 		info, err := os.Stat(event.Name)
 		if err != nil || !info.IsDir() {